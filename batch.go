@@ -0,0 +1,266 @@
+package simplified
+
+/**
+ * This file implements batch processing of records with a pool of
+ * workers. Records are read in order, processed concurrently then
+ * handed back in input order so the output matches the input. A
+ * checkpoint records how far a batch got so it can be restarted.
+ */
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// BatchResult is a record of a batch and the outcome of processing it.
+type BatchResult struct {
+	// Index is the position of the record in the input, starting at 1.
+	Index int
+	// Record is the record read, nil if it could not be read.
+	Record *Record
+	// Output holds the encoded record if Work encoded it.
+	Output []byte
+	// Err is the problem reading or processing the record.
+	Err error
+}
+
+// Batch processes the records of a RecordReader with a pool of
+// workers.
+type Batch struct {
+	// Workers is the number of records processed at the same time,
+	// the default is one.
+	Workers int
+	// Skip is the number of records to read and skip before processing,
+	// e.g. the records done before a batch was interrupted.
+	Skip int
+	// Work processes a record, it is called concurrently. It is not
+	// called for records which could not be read.
+	Work func(res *BatchResult)
+	// Emit receives the results in input order. An error stops the
+	// batch.
+	Emit func(res *BatchResult) error
+}
+
+// Run reads and processes the records until the input ends, Emit
+// returns an error or the context is cancelled. Records which could not
+// be read (see RecordError) are passed to Emit with Err set, other read
+// errors stop the batch. When cancelled the records already read are
+// finished and emitted before Run returns the context's error, so no
+// gap is left in the output.
+//
+// ```
+//
+//	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+//	defer stop()
+//	batch := &simplified.Batch{
+//	    Workers: 4,
+//	    Work: func(res *simplified.BatchResult) {
+//	        res.Err = res.Record.Validate()
+//	    },
+//	    Emit: func(res *simplified.BatchResult) error {
+//	        if res.Err != nil {
+//	            fmt.Fprintf(os.Stderr, "record %d, %s\n", res.Index, res.Err)
+//	        }
+//	        return nil
+//	    },
+//	}
+//	err := batch.Run(ctx, simplified.NewJSONLReader(os.Stdin))
+//
+// ```
+func (b *Batch) Run(ctx context.Context, reader RecordReader) error {
+	workers := b.Workers
+	if workers < 1 {
+		workers = 1
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// tokens bounds the records held in memory while waiting for
+	// a slow record to be emitted.
+	tokens := make(chan struct{}, workers*4)
+	jobs := make(chan *BatchResult, workers)
+	done := make(chan *BatchResult, workers)
+	var readErr error
+	go func() {
+		defer close(jobs)
+		for i := 1; ; i++ {
+			select {
+			case <-ctx.Done():
+				return
+			case tokens <- struct{}{}:
+			}
+			rec, err := reader.Read()
+			if err == io.EOF {
+				return
+			}
+			if err != nil && !IsRecordError(err) {
+				readErr = err
+				return
+			}
+			if i <= b.Skip {
+				<-tokens
+				continue
+			}
+			jobs <- &BatchResult{Index: i, Record: rec, Err: err}
+		}
+	}()
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for res := range jobs {
+				if res.Err == nil && b.Work != nil {
+					b.Work(res)
+				}
+				done <- res
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	var emitErr error
+	pending, next := map[int]*BatchResult{}, b.Skip+1
+	for res := range done {
+		if emitErr != nil {
+			// Drain the workers after Emit failed.
+			continue
+		}
+		pending[res.Index] = res
+		for r, ok := pending[next]; ok; r, ok = pending[next] {
+			delete(pending, next)
+			next++
+			<-tokens
+			if b.Emit != nil {
+				if emitErr = b.Emit(r); emitErr != nil {
+					cancel()
+					break
+				}
+			}
+		}
+	}
+	switch {
+	case emitErr != nil:
+		return emitErr
+	case readErr != nil:
+		return readErr
+	}
+	return ctx.Err()
+}
+
+// BatchCheckpoint records the progress of a batch so an interrupted
+// batch can be restarted with Batch.Skip set to Done.
+type BatchCheckpoint struct {
+	// Input names the input of the batch.
+	Input string `json:"input"`
+	// Output names the output of the batch.
+	Output string `json:"output,omitempty"`
+	// Done is the number of records read and emitted.
+	Done int `json:"done"`
+	// Offset is the size of the output, in bytes, when Done records
+	// were written.
+	Offset int64 `json:"offset"`
+	// Succeeded is the number of records processed without error.
+	Succeeded int `json:"succeeded"`
+	// Failed is the number of records with an error.
+	Failed int `json:"failed"`
+	// Updated is when the checkpoint was written.
+	Updated time.Time `json:"updated"`
+}
+
+// OpenOutput opens the output file of a batch for writing. If the batch
+// is being resumed (Done is not zero) the file is truncated to Offset,
+// dropping anything written after the checkpoint, e.g. records which
+// will be written again or a partial record left by a crash. Otherwise
+// the file is created or truncated.
+//
+// ```
+//
+//	out, err := checkpoint.OpenOutput("migrated.jsonl")
+//	// ... handle error ...
+//	defer out.Close()
+//	batch.Skip = checkpoint.Done
+//
+// ```
+func (c *BatchCheckpoint) OpenOutput(name string) (*os.File, error) {
+	if c.Done == 0 {
+		return os.Create(name)
+	}
+	out, err := os.OpenFile(name, os.O_WRONLY, 0664)
+	if err != nil {
+		return nil, err
+	}
+	info, err := out.Stat()
+	if err == nil && info.Size() < c.Offset {
+		err = fmt.Errorf("%s is shorter than the checkpoint, %d bytes instead of %d", name, info.Size(), c.Offset)
+	}
+	if err == nil {
+		err = out.Truncate(c.Offset)
+	}
+	if err == nil {
+		_, err = out.Seek(c.Offset, io.SeekStart)
+	}
+	if err != nil {
+		out.Close()
+		return nil, err
+	}
+	return out, nil
+}
+
+// ReadBatchCheckpoint reads a checkpoint file. If the file does not
+// exist an empty checkpoint is returned.
+//
+// ```
+//
+//	checkpoint, err := simplified.ReadBatchCheckpoint("migration.checkpoint")
+//	// ... handle error ...
+//	batch.Skip = checkpoint.Done
+//
+// ```
+func ReadBatchCheckpoint(name string) (*BatchCheckpoint, error) {
+	checkpoint := new(BatchCheckpoint)
+	src, err := os.ReadFile(name)
+	if os.IsNotExist(err) {
+		return checkpoint, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(src, checkpoint); err != nil {
+		return nil, fmt.Errorf("%s, %s", name, err)
+	}
+	return checkpoint, nil
+}
+
+// Write saves the checkpoint. The file is replaced in a single step so
+// an interrupted write leaves the previous checkpoint in place.
+func (c *BatchCheckpoint) Write(name string) error {
+	c.Updated = time.Now().UTC().Truncate(time.Second)
+	src, err := json.MarshalIndent(c, "", "    ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(name), filepath.Base(name)+".*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(append(src, '\n')); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), name)
+}
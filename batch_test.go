@@ -0,0 +1,242 @@
+package simplified
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// batchTestInput returns JSON Lines with n records, the records listed
+// in bad are not well formed.
+func batchTestInput(n int, bad ...int) string {
+	lines := []string{}
+	for i := 1; i <= n; i++ {
+		line := fmt.Sprintf(`{"id": "r%d", "metadata": {"title": "Title %d"}}`, i, i)
+		for _, j := range bad {
+			if i == j {
+				line = `{"id": "r` + fmt.Sprintf("%d", i)
+			}
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+func TestBatchRun(t *testing.T) {
+	ids, failed := []string{}, []int{}
+	batch := &Batch{
+		Workers: 8,
+		Work: func(res *BatchResult) {
+			// Finish the records out of order.
+			time.Sleep(time.Duration((res.Index*7)%5) * time.Millisecond)
+			res.Record.Metadata.Title = strings.ToUpper(res.Record.Metadata.Title)
+		},
+		Emit: func(res *BatchResult) error {
+			if res.Err != nil {
+				failed = append(failed, res.Index)
+				return nil
+			}
+			ids = append(ids, res.Record.ID)
+			if res.Record.Metadata.Title != fmt.Sprintf("TITLE %d", res.Index) {
+				t.Errorf("record %d not processed, %q", res.Index, res.Record.Metadata.Title)
+			}
+			return nil
+		},
+	}
+	if err := batch.Run(context.Background(), NewJSONLReader(strings.NewReader(batchTestInput(100, 10, 20)))); err != nil {
+		t.Fatal(err)
+	}
+	if len(ids) != 98 {
+		t.Fatalf("expected 98 records, got %d", len(ids))
+	}
+	for i, j := 0, 1; i < len(ids); i, j = i+1, j+1 {
+		if j == 10 || j == 20 {
+			j++
+		}
+		if ids[i] != fmt.Sprintf("r%d", j) {
+			t.Fatalf("expected r%d at %d, got %s", j, i, ids[i])
+		}
+	}
+	if fmt.Sprint(failed) != "[10 20]" {
+		t.Errorf("expected records 10 and 20 to fail, got %v", failed)
+	}
+
+	// Skip the records already done.
+	ids = nil
+	batch.Skip, batch.Work = 95, nil
+	batch.Emit = func(res *BatchResult) error {
+		ids = append(ids, res.Record.ID)
+		return nil
+	}
+	if err := batch.Run(context.Background(), NewJSONLReader(strings.NewReader(batchTestInput(100)))); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(ids, " ") != "r96 r97 r98 r99 r100" {
+		t.Errorf("unexpected records after skip, %v", ids)
+	}
+}
+
+func TestBatchStop(t *testing.T) {
+	// An error from Emit stops the batch.
+	stop := errors.New("stop")
+	count := 0
+	batch := &Batch{
+		Workers: 4,
+		Emit: func(res *BatchResult) error {
+			count++
+			if res.Index == 10 {
+				return stop
+			}
+			return nil
+		},
+	}
+	if err := batch.Run(context.Background(), NewJSONLReader(strings.NewReader(batchTestInput(1000)))); err != stop {
+		t.Errorf("expected the emit error, got %v", err)
+	}
+	if count != 10 {
+		t.Errorf("expected 10 records emitted, got %d", count)
+	}
+
+	// Cancelling finishes the records read, leaving no gap.
+	ctx, cancel := context.WithCancel(context.Background())
+	last := 0
+	batch.Emit = func(res *BatchResult) error {
+		if res.Index != last+1 {
+			t.Errorf("expected record %d, got %d", last+1, res.Index)
+		}
+		last = res.Index
+		if last == 50 {
+			cancel()
+		}
+		return nil
+	}
+	if err := batch.Run(ctx, NewJSONLReader(strings.NewReader(batchTestInput(1000)))); err != context.Canceled {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+	if last < 50 || last == 1000 {
+		t.Errorf("expected the batch to stop after record 50, stopped at %d", last)
+	}
+
+	// A read error which isn't about a single record stops the batch.
+	batch.Emit = nil
+	if err := batch.Run(context.Background(), NewJSONLReader(strings.NewReader(`[{"id": "r1"}, {`))); err == nil || err == io.EOF {
+		t.Errorf("expected a read error, got %v", err)
+	}
+}
+
+func TestBatchCheckpoint(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "batch.checkpoint")
+	checkpoint, err := ReadBatchCheckpoint(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if checkpoint.Done != 0 || checkpoint.Input != "" {
+		t.Errorf("expected an empty checkpoint, got %+v", checkpoint)
+	}
+	checkpoint.Input, checkpoint.Output = "export.jsonl", "migrated.jsonl"
+	checkpoint.Done, checkpoint.Succeeded, checkpoint.Failed = 1500, 1498, 2
+	if err := checkpoint.Write(name); err != nil {
+		t.Fatal(err)
+	}
+	saved, err := ReadBatchCheckpoint(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *saved != *checkpoint {
+		t.Errorf("expected %+v, got %+v", checkpoint, saved)
+	}
+	if matches, _ := filepath.Glob(name + ".*"); len(matches) > 0 {
+		t.Errorf("temporary files left behind, %v", matches)
+	}
+}
+
+func TestBatchResume(t *testing.T) {
+	dir := t.TempDir()
+	name, outName := filepath.Join(dir, "batch.checkpoint"), filepath.Join(dir, "out.jsonl")
+	input := batchTestInput(50)
+	crash := errors.New("crash")
+
+	// run converts the input to JSON Lines saving a checkpoint every ten
+	// records. If crashAt is not zero the run stops after writing that
+	// record and part of the next, as if the process had been killed.
+	run := func(crashAt int) error {
+		checkpoint, err := ReadBatchCheckpoint(name)
+		if err != nil {
+			return err
+		}
+		checkpoint.Input, checkpoint.Output = "input.jsonl", outName
+		out, err := checkpoint.OpenOutput(outName)
+		if err != nil {
+			return err
+		}
+		defer out.Close()
+		output := bufio.NewWriter(out)
+		writer := NewJSONLWriter(output)
+		batch := &Batch{
+			Workers: 4,
+			Skip:    checkpoint.Done,
+			Emit: func(res *BatchResult) error {
+				if err := writer.Write(res.Record); err != nil {
+					return err
+				}
+				if res.Index == crashAt {
+					io.WriteString(output, `{"id": "r`)
+					output.Flush()
+					return crash
+				}
+				if res.Index%10 == 0 {
+					if err := output.Flush(); err != nil {
+						return err
+					}
+					checkpoint.Done = res.Index
+					if checkpoint.Offset, err = out.Seek(0, io.SeekCurrent); err != nil {
+						return err
+					}
+					return checkpoint.Write(name)
+				}
+				return nil
+			},
+		}
+		if err := batch.Run(context.Background(), NewJSONLReader(strings.NewReader(input))); err != nil {
+			return err
+		}
+		return output.Flush()
+	}
+
+	if err := run(25); err != crash {
+		t.Fatalf("expected the run to crash, got %v", err)
+	}
+	if checkpoint, _ := ReadBatchCheckpoint(name); checkpoint.Done != 20 {
+		t.Fatalf("expected the checkpoint at record 20, got %d", checkpoint.Done)
+	}
+	if err := run(0); err != nil {
+		t.Fatal(err)
+	}
+	src, err := os.ReadFile(outName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	records, errs := readAll(t, NewJSONLReader(bytes.NewReader(src)))
+	if len(records) != 50 || len(errs) > 0 {
+		t.Fatalf("expected 50 records, got %d, %v", len(records), errs)
+	}
+	for i, rec := range records {
+		if rec.ID != fmt.Sprintf("r%d", i+1) {
+			t.Fatalf("expected r%d at %d, got %s", i+1, i, rec.ID)
+		}
+	}
+
+	// A checkpoint beyond the end of the output can't be resumed.
+	checkpoint := &BatchCheckpoint{Done: 10, Offset: 1 << 20}
+	if _, err := checkpoint.OpenOutput(outName); err == nil {
+		t.Errorf("expected an error for an output shorter than the checkpoint")
+	}
+}
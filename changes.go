@@ -0,0 +1,321 @@
+package simplified
+
+/**
+ * This file implements element level change sets for Metadata. Lists
+ * like creators or identifiers are compared element by element, matching
+ * elements on a key (e.g. clpid or ORCID for creators, scheme and value
+ * for identifiers) so a change to one creator in a long author list is
+ * reported as a change to that creator only.
+ */
+
+import (
+	"strings"
+)
+
+// ChangeKind describes how an element changed between two versions.
+type ChangeKind string
+
+const (
+	// Inserted is an element only found in the new version.
+	Inserted ChangeKind = "inserted"
+	// Removed is an element only found in the old version.
+	Removed ChangeKind = "removed"
+	// Moved is an unchanged element found at a different position. It
+	// is only reported for creators and contributors, the order of the
+	// other lists (e.g. identifiers, rights, dates and funding) is not
+	// significant so reordering them is not a change.
+	Moved ChangeKind = "moved"
+	// Modified is an element (or field) whose value changed. If the
+	// element also changed position From and To will differ.
+	Modified ChangeKind = "modified"
+)
+
+// Change describes a change to a Metadata field or to an element of
+// one of its lists. Field is the JSON attribute name, e.g. "creators".
+// From and To are the element's positions in the old and new lists,
+// they are nil for fields that are not lists.
+type Change struct {
+	Field string      `json:"field"`
+	Kind  ChangeKind  `json:"kind"`
+	Key   string      `json:"key,omitempty"`
+	From  *int        `json:"from,omitempty"`
+	To    *int        `json:"to,omitempty"`
+	Old   interface{} `json:"old,omitempty"`
+	New   interface{} `json:"new,omitempty"`
+}
+
+// ChangeSet is the list of changes between two Metadata structs.
+type ChangeSet []*Change
+
+// Field returns the changes for a given field, e.g. "creators".
+func (cs ChangeSet) Field(field string) ChangeSet {
+	l := ChangeSet{}
+	for _, c := range cs {
+		if c.Field == field {
+			l = append(l, c)
+		}
+	}
+	return l
+}
+
+func intPtr(i int) *int {
+	return &i
+}
+
+// normalizeKey lower cases and trims a value used in a match key.
+func normalizeKey(s string) string {
+	return strings.ToLower(strings.TrimSpace(s))
+}
+
+// creatorKey identifies a creator by clpid, ORCID or ROR falling
+// back to the name.
+func creatorKey(c *Creator) string {
+	if c == nil || c.PersonOrOrg == nil {
+		return ""
+	}
+	p := c.PersonOrOrg
+	if p.ID != "" {
+		return "clpid:" + normalizeKey(p.ID)
+	}
+	for _, scheme := range []string{"clpid", "orcid", "ror", "isni"} {
+		for _, id := range p.Identifiers {
+			if id != nil && id.Identifier != "" && normalizeKey(id.Scheme) == scheme {
+				return scheme + ":" + normalizeKey(id.Identifier)
+			}
+		}
+	}
+	return "name:" + normalizeKey(personOrOrgName(p))
+}
+
+// identifierKey identifies an identifier by scheme and value.
+func identifierKey(id *Identifier) string {
+	if id == nil {
+		return ""
+	}
+	value := id.Identifier
+	if value == "" {
+		value = id.ID
+	}
+	return normalizeKey(id.Scheme) + ":" + normalizeKey(normalizeIdentifierValue(id.Scheme, value))
+}
+
+func subjectKey(s *Subject) string {
+	if s == nil {
+		return ""
+	}
+	if s.ID != "" {
+		return "id:" + normalizeKey(s.ID)
+	}
+	return "subject:" + normalizeKey(s.Subject)
+}
+
+func dateKey(d *DateType) string {
+	if d == nil {
+		return ""
+	}
+	key := normalizeKey(d.Date)
+	if d.Type != nil {
+		key = normalizeKey(d.Type.ID) + ":" + key
+	}
+	return key
+}
+
+func funderKey(f *Funder) string {
+	if f == nil {
+		return ""
+	}
+	key := ""
+	if f.Funder != nil {
+		if f.Funder.Identifier != "" {
+			key = normalizeKey(f.Funder.Identifier)
+		} else {
+			key = normalizeKey(f.Funder.Name)
+		}
+	}
+	if f.Award != nil {
+		if f.Award.Number != "" {
+			key += ":" + normalizeKey(f.Award.Number)
+		} else {
+			key += ":" + normalizeKey(f.Award.Identifier)
+		}
+	}
+	return key
+}
+
+func rightKey(r *Right) string {
+	if r == nil {
+		return ""
+	}
+	if r.ID != "" {
+		return "id:" + normalizeKey(r.ID)
+	}
+	if r.Link != "" {
+		return "link:" + normalizeKey(r.Link)
+	}
+	return "title:" + normalizeKey(localized(r.Title))
+}
+
+// diffList compares two lists matching elements by key. Matched
+// elements that are not the same are reported as modified. If ordered
+// is true matched elements which changed position relative to the
+// other matched elements are reported as moved. If ordered is false the
+// list is treated as a set, as it is by IsSame, and reordering it is
+// not a change.
+func diffList[T any](field string, a []T, b []T, key func(T) string, same func(T, T) bool, ordered bool) ChangeSet {
+	changes := ChangeSet{}
+	// Match elements with the same key in order of occurrence.
+	unmatched := map[string][]int{}
+	for j, elem := range b {
+		k := key(elem)
+		unmatched[k] = append(unmatched[k], j)
+	}
+	matchA := make([]int, len(a))
+	matchedB := make([]bool, len(b))
+	for i, elem := range a {
+		k := key(elem)
+		matchA[i] = -1
+		if l := unmatched[k]; len(l) > 0 {
+			matchA[i] = l[0]
+			matchedB[l[0]] = true
+			unmatched[k] = l[1:]
+		}
+	}
+	// Matched elements in the longest increasing run of new positions
+	// kept their relative order, the others moved.
+	stayed := longestIncreasing(matchA)
+	for i, j := range matchA {
+		if j < 0 {
+			changes = append(changes, &Change{Field: field, Kind: Removed, Key: key(a[i]), From: intPtr(i), Old: a[i]})
+			continue
+		}
+		modified := !same(a[i], b[j])
+		switch {
+		case modified:
+			changes = append(changes, &Change{Field: field, Kind: Modified, Key: key(a[i]), From: intPtr(i), To: intPtr(j), Old: a[i], New: b[j]})
+		case ordered && !stayed[i]:
+			changes = append(changes, &Change{Field: field, Kind: Moved, Key: key(a[i]), From: intPtr(i), To: intPtr(j), Old: a[i], New: b[j]})
+		}
+	}
+	for j, ok := range matchedB {
+		if !ok {
+			changes = append(changes, &Change{Field: field, Kind: Inserted, Key: key(b[j]), To: intPtr(j), New: b[j]})
+		}
+	}
+	return changes
+}
+
+// longestIncreasing returns which of the matched positions (values >= 0)
+// form the longest strictly increasing subsequence.
+func longestIncreasing(positions []int) []bool {
+	// tails[k] is the index into positions ending the best run of length k+1
+	tails := []int{}
+	prev := make([]int, len(positions))
+	for i, p := range positions {
+		prev[i] = -1
+		if p < 0 {
+			continue
+		}
+		lo, hi := 0, len(tails)
+		for lo < hi {
+			mid := (lo + hi) / 2
+			if positions[tails[mid]] < p {
+				lo = mid + 1
+			} else {
+				hi = mid
+			}
+		}
+		if lo > 0 {
+			prev[i] = tails[lo-1]
+		}
+		if lo == len(tails) {
+			tails = append(tails, i)
+		} else {
+			tails[lo] = i
+		}
+	}
+	in := make([]bool, len(positions))
+	if len(tails) > 0 {
+		for i := tails[len(tails)-1]; i >= 0; i = prev[i] {
+			in[i] = true
+		}
+	}
+	return in
+}
+
+// Changes compares the Metadata with a new version, t, and returns
+// the structured set of changes. List fields (creators, contributors,
+// subjects, identifiers, related identifiers, dates, funding and rights)
+// are compared element by element, other fields are reported as
+// modified when they differ. Values are compared using IsSame so
+// whitespace, identifier case and the order of subjects, identifiers,
+// related identifiers, dates, funding and rights are not reported.
+//
+// ```
+//
+//	changes := oldRecord.Metadata.Changes(newRecord.Metadata)
+//	for _, change := range changes.Field("creators") {
+//	    fmt.Printf("%s %s\n", change.Kind, change.Key)
+//	}
+//
+// ```
+func (m *Metadata) Changes(t *Metadata) ChangeSet {
+	if m == nil {
+		m = new(Metadata)
+	}
+	if t == nil {
+		t = new(Metadata)
+	}
+	changes := ChangeSet{}
+	field := func(name string, a interface{}, b interface{}, same bool) {
+		if !same {
+			changes = append(changes, &Change{Field: name, Kind: Modified, Old: a, New: b})
+		}
+	}
+	field("resource_type", m.ResourceType, t.ResourceType, sameJSON(m.ResourceType, t.ResourceType))
+	changes = append(changes, diffList("creators", m.Creators, t.Creators, creatorKey, (*Creator).IsSame, true)...)
+	field("title", m.Title, t.Title, sameString(m.Title, t.Title))
+	field("publication_date", m.PublicationDate, t.PublicationDate, sameString(m.PublicationDate, t.PublicationDate))
+	field("additional_titles", m.AdditionalTitles, t.AdditionalTitles, sameList(m.AdditionalTitles, t.AdditionalTitles, (*TitleDetail).IsSame))
+	field("description", m.Description, t.Description, sameString(m.Description, t.Description))
+	field("additional_descriptions", m.AdditionalDescriptions, t.AdditionalDescriptions, sameList(m.AdditionalDescriptions, t.AdditionalDescriptions, (*Description).IsSame))
+	changes = append(changes, diffList("rights", m.Rights, t.Rights, rightKey, (*Right).IsSame, false)...)
+	changes = append(changes, diffList("contributors", m.Contributors, t.Contributors, creatorKey, (*Creator).IsSame, true)...)
+	changes = append(changes, diffList("subjects", m.Subjects, t.Subjects, subjectKey, (*Subject).IsSame, false)...)
+	field("languages", m.Languages, t.Languages, sameSet(m.Languages, t.Languages, func(a map[string]interface{}, b map[string]interface{}) bool { return sameJSON(a, b) }))
+	changes = append(changes, diffList("dates", m.Dates, t.Dates, dateKey, (*DateType).IsSame, false)...)
+	field("version", m.Version, t.Version, sameString(m.Version, t.Version))
+	field("publisher", m.Publisher, t.Publisher, sameString(m.Publisher, t.Publisher))
+	changes = append(changes, diffList("identifiers", m.Identifiers, t.Identifiers, identifierKey, (*Identifier).IsSame, false)...)
+	changes = append(changes, diffList("related_identifiers", m.RelatedIdentifiers, t.RelatedIdentifiers, identifierKey, (*Identifier).IsSame, false)...)
+	changes = append(changes, diffList("funding", m.Funding, t.Funding, funderKey, (*Funder).IsSame, false)...)
+	return changes
+}
+
+// changedElements returns the old and new elements of a list field
+// involved in a change, in list order.
+func changedElements[T any](changes ChangeSet, a []T, b []T) ([]T, []T) {
+	if len(changes) == 0 {
+		return nil, nil
+	}
+	inOld, inNew := make([]bool, len(a)), make([]bool, len(b))
+	for _, c := range changes {
+		if c.From != nil {
+			inOld[*c.From] = true
+		}
+		if c.To != nil {
+			inNew[*c.To] = true
+		}
+	}
+	o, n := []T{}, []T{}
+	for i, ok := range inOld {
+		if ok {
+			o = append(o, a[i])
+		}
+	}
+	for j, ok := range inNew {
+		if ok {
+			n = append(n, b[j])
+		}
+	}
+	return o, n
+}
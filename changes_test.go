@@ -0,0 +1,152 @@
+package simplified
+
+import (
+	"fmt"
+	"testing"
+)
+
+// makeCreators builds a list of n creators with ORCIDs.
+func makeCreators(n int) []*Creator {
+	creators := []*Creator{}
+	for i := 0; i < n; i++ {
+		creators = append(creators, &Creator{
+			PersonOrOrg: &PersonOrOrg{
+				Type:       "personal",
+				FamilyName: fmt.Sprintf("Author%03d", i),
+				GivenName:  "A.",
+				Identifiers: []*Identifier{
+					{Scheme: "orcid", Identifier: fmt.Sprintf("0000-0000-0000-%04d", i)},
+				},
+			},
+			Affiliations: []*Affiliation{{ID: "05dxps055", Name: "Caltech"}},
+		})
+	}
+	return creators
+}
+
+func TestMetadataChanges(t *testing.T) {
+	m, n := new(Metadata), new(Metadata)
+	m.Title, n.Title = "A title", "A title"
+	m.Creators, n.Creators = makeCreators(200), makeCreators(200)
+
+	changes := m.Changes(n)
+	if len(changes) != 0 {
+		t.Fatalf("expected no changes, got %d", len(changes))
+	}
+
+	// Change the affiliation of creator #40
+	n.Creators[40].Affiliations[0] = &Affiliation{ID: "00hx57361", Name: "Princeton"}
+	changes = m.Changes(n)
+	if len(changes) != 1 {
+		t.Fatalf("expected one change, got %d", len(changes))
+	}
+	c := changes[0]
+	if c.Field != "creators" || c.Kind != Modified || *c.From != 40 || *c.To != 40 || c.Key != "orcid:0000-0000-0000-0040" {
+		t.Errorf("unexpected change %+v", c)
+	}
+	o, nw := m.Diff(n)
+	if len(o.Creators) != 1 || len(nw.Creators) != 1 {
+		t.Errorf("expected Diff to report one creator, got %d and %d", len(o.Creators), len(nw.Creators))
+	}
+
+	// Remove the first creator, insert a new one at the end and
+	// move creator #10 to the front.
+	n.Creators = makeCreators(200)
+	moved := n.Creators[10]
+	n.Creators = append(n.Creators[1:10], n.Creators[11:]...)
+	n.Creators = append([]*Creator{moved}, n.Creators...)
+	n.Creators = append(n.Creators, &Creator{
+		PersonOrOrg: &PersonOrOrg{Type: "personal", ID: "Doe-J", FamilyName: "Doe", GivenName: "Jane"},
+	})
+	changes = m.Changes(n).Field("creators")
+	kinds := map[ChangeKind]int{}
+	for _, c := range changes {
+		kinds[c.Kind]++
+	}
+	if kinds[Removed] != 1 || kinds[Inserted] != 1 || kinds[Moved] != 1 || kinds[Modified] != 0 {
+		t.Errorf("unexpected changes %+v", kinds)
+	}
+	for _, c := range changes {
+		switch c.Kind {
+		case Removed:
+			if *c.From != 0 {
+				t.Errorf("expected creator 0 to be removed, got %d", *c.From)
+			}
+		case Inserted:
+			if c.Key != "clpid:doe-j" || *c.To != 199 {
+				t.Errorf("unexpected insert %s at %d", c.Key, *c.To)
+			}
+		case Moved:
+			if *c.From != 10 || *c.To != 0 {
+				t.Errorf("expected creator to move from 10 to 0, got %d to %d", *c.From, *c.To)
+			}
+		}
+	}
+}
+
+func TestMetadataChangesIdentifiers(t *testing.T) {
+	m := &Metadata{
+		Title: "A title",
+		Identifiers: []*Identifier{
+			{Scheme: "doi", Identifier: "10.1234/ABC"},
+			{Scheme: "issn", Identifier: "1091-6490"},
+		},
+		Subjects: []*Subject{{Subject: "Physics"}, {Subject: "Chemistry"}},
+	}
+	n := &Metadata{
+		Title: "A new title",
+		Identifiers: []*Identifier{
+			{Scheme: "issn", Identifier: "1091-6490"},
+			{Scheme: "doi", Identifier: "10.1234/abc", Name: "DOI"},
+		},
+		Subjects: []*Subject{{Subject: "physics"}},
+	}
+	changes := m.Changes(n)
+	if title := changes.Field("title"); len(title) != 1 || title[0].New != "A new title" {
+		t.Errorf("expected a title change, got %+v", title)
+	}
+	ids := changes.Field("identifiers")
+	if len(ids) != 1 || ids[0].Kind != Modified || *ids[0].From != 0 || *ids[0].To != 1 {
+		t.Errorf("expected the DOI to be modified and moved, got %+v", ids)
+	}
+	subjects := changes.Field("subjects")
+	kinds := map[ChangeKind]int{}
+	for _, c := range subjects {
+		kinds[c.Kind]++
+	}
+	if kinds[Modified] != 1 || kinds[Removed] != 1 {
+		t.Errorf("unexpected subject changes %+v", kinds)
+	}
+}
+
+func TestMetadataChangesUnordered(t *testing.T) {
+	m := &Metadata{
+		Creators:           makeCreators(2),
+		Rights:             []*Right{{ID: "cc-by-4.0"}, {ID: "cc0-1.0"}},
+		Dates:              []*DateType{{Date: "2020", Type: &Type{ID: "accepted"}}, {Date: "2021", Type: &Type{ID: "issued"}}},
+		Identifiers:        []*Identifier{{Scheme: "doi", Identifier: "10.1234/abc"}, {Scheme: "issn", Identifier: "1091-6490"}},
+		RelatedIdentifiers: []*Identifier{{Scheme: "url", Identifier: "https://example.edu/a"}, {Scheme: "url", Identifier: "https://example.edu/b"}},
+		Funding:            []*Funder{{Funder: &FunderIdentifier{Name: "NSF"}}, {Funder: &FunderIdentifier{Name: "DOE"}}},
+	}
+	n := &Metadata{
+		Creators:           []*Creator{m.Creators[1], m.Creators[0]},
+		Rights:             []*Right{m.Rights[1], m.Rights[0]},
+		Dates:              []*DateType{m.Dates[1], m.Dates[0]},
+		Identifiers:        []*Identifier{m.Identifiers[1], m.Identifiers[0]},
+		RelatedIdentifiers: []*Identifier{m.RelatedIdentifiers[1], m.RelatedIdentifiers[0]},
+		Funding:            []*Funder{m.Funding[1], m.Funding[0]},
+	}
+	changes := m.Changes(n)
+	// Creator order is significant, the order of the other lists is not.
+	if creators := changes.Field("creators"); len(creators) != 1 || creators[0].Kind != Moved {
+		t.Errorf("expected a creator to move, got %+v", creators)
+	}
+	for _, field := range []string{"rights", "dates", "identifiers", "related_identifiers", "funding"} {
+		if c := changes.Field(field); len(c) != 0 {
+			t.Errorf("expected reordering %s not to be a change, got %+v", field, c)
+		}
+	}
+	if len(changes) != 1 {
+		t.Errorf("expected a single change, got %d", len(changes))
+	}
+}
@@ -25,7 +25,7 @@ pubDate: {release_date}
 
 # SYNOPSIS
 
-{app_name} [OPTIONS] SIMPLIFIED_JSON_FILE
+{app_name} [OPTIONS] SIMPLIFIED_JSON_FILE [OUTPUT_FILENAME]
 
 # DESCRIPTION
 
@@ -35,6 +35,10 @@ a visual reference for the data structures. In practice you would want
 to use something like Pandoc with templates to render useful
 Markdown or HTML content.
 
+The Markdown is rendered using a Go text/template. You can supply
+your own template with the "-template" option. The record is passed
+to the template as the simplified Record struct.
+
 # OPTIONS
 
 -help
@@ -46,11 +50,25 @@ Markdown or HTML content.
 -version
 : display version
 
+-template TEMPLATE_FILE
+: render the record using a custom Go text/template
+
+-show-template
+: display the default template, useful as a starting point for your own
+
 # EXAMPLE
 
 ~~~
 	{app_name} my-record.json > my-record.md
 ~~~
+
+Render the record using a custom template.
+
+~~~
+	{app_name} -show-template > my-template.tmpl
+	# ... edit my-template.tmpl ...
+	{app_name} -template my-template.tmpl my-record.json > my-record.md
+~~~
 `
 )
 
@@ -59,7 +77,9 @@ func main() {
 		showHelp bool
 		showLicense bool
 		showVersion bool
+		showTemplate bool
 
+		templateName string
 		newline bool
 
 		err error
@@ -75,6 +95,8 @@ func main() {
 	flag.BoolVar(&showHelp, "help", false, "display help")
 	flag.BoolVar(&showLicense, "license", false, "display license")
 	flag.BoolVar(&showVersion, "version", false, "display version")
+	flag.BoolVar(&showTemplate, "show-template", false, "display the default Markdown template")
+	flag.StringVar(&templateName, "template", "", "render using a custom Go text/template file")
 	flag.BoolVar(&newline, "newline", true, "add a tailing newline")
 	flag.Parse()
 
@@ -96,6 +118,20 @@ func main() {
 		fmt.Fprintf(out, "%s %s %s\n", appName, version, releaseHash)
 		os.Exit(0)
 	}
+	if showTemplate {
+		fmt.Fprintf(out, "%s", simplified.MarkdownTemplate)
+		os.Exit(0)
+	}
+
+	tmplSrc := simplified.MarkdownTemplate
+	if templateName != "" {
+		src, err := os.ReadFile(templateName)
+		if err != nil {
+			fmt.Fprintf(eout, "%s\n", err)
+			os.Exit(1)
+		}
+		tmplSrc = string(src)
+	}
 
 	if len(args) == 0 {
 		fmt.Fprintf(eout, "expected the name of a simplified record JSON document or '-' to read from standard input")
@@ -130,7 +166,12 @@ func main() {
 		fmt.Fprintf(eout, "%s\n", err)
 		os.Exit(1)
 	}
-	fmt.Fprintf(out, "%s", record.AsMarkdown())
+	md, err := record.RenderMarkdown(tmplSrc)
+	if err != nil {
+		fmt.Fprintf(eout, "%s\n", err)
+		os.Exit(1)
+	}
+	fmt.Fprintf(out, "%s", md)
 	if newline {
 		fmt.Fprintln(out)
 	}
@@ -0,0 +1,280 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	// Caltech Library Packages
+	"github.com/caltechlibrary/simplified"
+)
+
+var (
+	helpText = `---
+title: "{app_name} (1) user manual {version} {release_hash}"
+author: "R. S. Doiel"
+pubDate: {release_date}
+---
+
+# NAME
+
+{app_name}
+
+# SYNOPSIS
+
+{app_name} [OPTIONS] RECORDS
+
+{app_name} [OPTIONS] RECORDS QUERY
+
+# DESCRIPTION
+
+{app_name} serves simplified JSON records as an OAI-PMH 2.0 data provider.
+RECORDS is either a directory of JSON files, each holding a record or an
+array of records, or a JSON lines file holding one record per line.
+
+The provider supports the Identify, ListMetadataFormats, ListSets,
+ListIdentifiers, ListRecords and GetRecord verbs. Records are disseminated
+as "oai_dc" (Dublin Core) and "oai_datacite" (DataCite 4.5 XML). Sets are
+the community ids found in each record's "parent.communities". The record
+datestamp is the "updated" time, it is used for "from" and "until"
+selective harvesting. Records with a "tombstone" are reported as deleted.
+Lists longer than the page size are returned in pages with resumption
+tokens.
+
+Records are identified by their "oai" PID or, if there is none,
+"oai:REPOSITORY_IDENTIFIER:ID" where REPOSITORY_IDENTIFIER defaults to
+the host name of the base URL.
+
+No network access is needed beyond the local listener. If a QUERY is
+given, e.g. "verb=Identify", the response is written to standard output
+and {app_name} exits without starting a web service. This is useful for
+testing harvesters and records offline.
+
+# OPTIONS
+
+-help
+: display help
+
+-license
+: display license
+
+-version
+: display version
+
+-listen ADDRESS
+: the address to listen on, defaults to "localhost:8000"
+
+-base-url URL
+: the base URL reported by the provider, defaults to "http://ADDRESS/oai"
+
+-name NAME
+: the repository name reported by Identify
+
+-admin-email ADDRESS
+: the administrator email reported by Identify
+
+-repository-identifier ID
+: the repository identifier used to form OAI identifiers
+
+-page-size N
+: the number of headers or records before a resumption token is issued, defaults to 100
+
+# EXAMPLES
+
+Serve a directory of records then harvest them.
+
+~~~
+{app_name} -name "Test Repository" records/
+curl 'http://localhost:8000/oai?verb=ListRecords&metadataPrefix=oai_dc'
+~~~
+
+Answer a single request from a JSON lines file without starting a service.
+
+~~~
+{app_name} records.jsonl 'verb=GetRecord&metadataPrefix=oai_dc&identifier=oai:localhost:rd9fg-k5282'
+~~~
+
+`
+)
+
+// decodeRecords decodes a JSON document holding a record or an array of
+// records.
+func decodeRecords(fName string, src []byte) ([]*simplified.Record, error) {
+	src = bytes.TrimSpace(src)
+	if bytes.HasPrefix(src, []byte("[")) {
+		records := []*simplified.Record{}
+		if err := json.Unmarshal(src, &records); err != nil {
+			return nil, fmt.Errorf("%s, %s", fName, err)
+		}
+		return records, nil
+	}
+	rec := new(simplified.Record)
+	if err := json.Unmarshal(src, &rec); err != nil {
+		return nil, fmt.Errorf("%s, %s", fName, err)
+	}
+	return []*simplified.Record{rec}, nil
+}
+
+// readRecords reads the records from a directory of JSON files or a
+// JSON lines file.
+func readRecords(fName string) ([]*simplified.Record, error) {
+	info, err := os.Stat(fName)
+	if err != nil {
+		return nil, err
+	}
+	records := []*simplified.Record{}
+	if info.IsDir() {
+		names, err := filepath.Glob(filepath.Join(fName, "*.json"))
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range names {
+			src, err := os.ReadFile(name)
+			if err != nil {
+				return nil, err
+			}
+			l, err := decodeRecords(name, src)
+			if err != nil {
+				return nil, err
+			}
+			records = append(records, l...)
+		}
+		return records, nil
+	}
+	fp, err := os.Open(fName)
+	if err != nil {
+		return nil, err
+	}
+	defer fp.Close()
+	scanner := bufio.NewScanner(fp)
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+	for i := 1; scanner.Scan(); i++ {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		rec := new(simplified.Record)
+		if err := json.Unmarshal(line, &rec); err != nil {
+			return nil, fmt.Errorf("%s line %d, %s", fName, i, err)
+		}
+		records = append(records, rec)
+	}
+	return records, scanner.Err()
+}
+
+func main() {
+	var (
+		showHelp    bool
+		showLicense bool
+		showVersion bool
+
+		listen               string
+		baseURL              string
+		repositoryName       string
+		adminEmail           string
+		repositoryIdentifier string
+		pageSize             int
+	)
+
+	appName := path.Base(os.Args[0])
+	// NOTE: the following are set when version.go is generated
+	version := simplified.Version
+	releaseDate := simplified.ReleaseDate
+	releaseHash := simplified.ReleaseHash
+	fmtHelp := simplified.FmtHelp
+
+	flag.BoolVar(&showHelp, "help", false, "display help")
+	flag.BoolVar(&showLicense, "license", false, "display license")
+	flag.BoolVar(&showVersion, "version", false, "display version")
+	flag.StringVar(&listen, "listen", "localhost:8000", "the address to listen on")
+	flag.StringVar(&baseURL, "base-url", "", "the base URL reported by the provider")
+	flag.StringVar(&repositoryName, "name", "", "the repository name reported by Identify")
+	flag.StringVar(&adminEmail, "admin-email", "", "the administrator email reported by Identify")
+	flag.StringVar(&repositoryIdentifier, "repository-identifier", "", "the repository identifier used in OAI identifiers")
+	flag.IntVar(&pageSize, "page-size", 100, "the number of headers or records per page")
+	flag.Parse()
+
+	args := flag.Args()
+
+	out := os.Stdout
+	eout := os.Stderr
+
+	if showHelp {
+		fmt.Fprintf(out, "%s\n", fmtHelp(helpText, appName, version, releaseDate, releaseHash))
+		os.Exit(0)
+	}
+	if showLicense {
+		fmt.Fprintf(out, "%s\n", fmtHelp(simplified.LicenseText, appName, version, releaseDate, releaseHash))
+		os.Exit(0)
+	}
+	if showVersion {
+		fmt.Fprintf(out, "%s %s %s\n", appName, version, releaseHash)
+		os.Exit(0)
+	}
+	if len(args) == 0 {
+		fmt.Fprintf(eout, "expected a directory of JSON records or a JSON lines file\n")
+		os.Exit(1)
+	}
+	records, err := readRecords(args[0])
+	if err != nil {
+		fmt.Fprintf(eout, "%s\n", err)
+		os.Exit(1)
+	}
+	if baseURL == "" {
+		baseURL = "http://" + listen + "/oai"
+	}
+	provider, err := simplified.NewOAIProvider(baseURL, records)
+	if err != nil {
+		fmt.Fprintf(eout, "%s\n", err)
+		os.Exit(1)
+	}
+	if repositoryName != "" {
+		provider.RepositoryName = repositoryName
+	}
+	if adminEmail != "" {
+		provider.AdminEmail = []string{adminEmail}
+	}
+	if repositoryIdentifier != "" {
+		provider.RepositoryIdentifier = repositoryIdentifier
+	}
+	provider.PageSize = pageSize
+
+	if len(args) > 1 {
+		query, err := url.ParseQuery(strings.TrimPrefix(args[1], "?"))
+		if err != nil {
+			fmt.Fprintf(eout, "%s\n", err)
+			os.Exit(1)
+		}
+		src, err := provider.Response(query)
+		if err != nil {
+			fmt.Fprintf(eout, "%s\n", err)
+			os.Exit(1)
+		}
+		fmt.Fprintf(out, "%s\n", src)
+		os.Exit(0)
+	}
+
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		fmt.Fprintf(eout, "%s\n", err)
+		os.Exit(1)
+	}
+	endpoint := u.Path
+	if endpoint == "" {
+		endpoint = "/"
+	}
+	http.Handle(endpoint, provider)
+	fmt.Fprintf(eout, "%s serving %d records at %s\n", appName, len(records), baseURL)
+	if err := http.ListenAndServe(listen, nil); err != nil {
+		fmt.Fprintf(eout, "%s\n", err)
+		os.Exit(1)
+	}
+}
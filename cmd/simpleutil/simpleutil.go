@@ -1,12 +1,17 @@
 package main
 
 import (
+	"bufio"
+	"context"
 	"encoding/json"
 	"flag"
 	"fmt"
 	"io"
 	"os"
+	"os/signal"
 	"path"
+	"strings"
+	"time"
 
 	// Caltech Library Packages
 	"github.com/caltechlibrary/simplified"
@@ -29,15 +34,84 @@ pubDate: {release_date}
 
 {app_name} -diff SIMPLIFIED_JSON_FILE SIMPLIFIED_JSON_FILE [OUTPUT_FILENAME]
 
+{app_name} -validate SIMPLIFIED_JSON_FILE [OUTPUT_FILENAME]
+
+{app_name} -patch SIMPLIFIED_JSON_FILE SIMPLIFIED_JSON_FILE [OUTPUT_FILENAME]
+
+{app_name} -apply SIMPLIFIED_JSON_FILE JSON_PATCH_FILE [OUTPUT_FILENAME]
+
+{app_name} -merge [-strategy STRATEGY] BASE_JSON_FILE OURS_JSON_FILE THEIRS_JSON_FILE [OUTPUT_FILENAME]
+
+{app_name} [-from FORMAT] [-to FORMAT] INPUT_FILE [OUTPUT_FILENAME]
+
+{app_name} -formats
+
 # DESCRIPTION
 
-{app_name} reads a simplified JSON record, validates and pretty prints
-the JSON output.  This is primarily a test of using the simplified record.
+{app_name} reads a simplified JSON record and writes it as Markdown
+(see "-to markdown").  This is primarily a test of using the simplified record.
 The "-diff" option will take to JSON records and perform a diff operation
 returning a JSON array with the first cell holding the in difference from
 the first file and the second one holding the attribitutes in difference
 for the second file.
 
+The "-validate" option checks the record against the InvenioRDM metadata
+rules (e.g. required title, resource type, creators, valid EDTF publication
+date, access values). It writes a JSON array of violations, each with
+a JSON pointer "path", a "kind" and a "message". An empty array means
+the record is valid. The exit code is 1 if any violations were found.
+
+The "-patch" option compares two JSON records and writes an RFC 6902
+JSON Patch which transforms the first record into the second. Lists
+are compared element by element so the patch only holds the changes.
+The "-apply" option applies a JSON Patch to a record and writes
+the updated record.
+
+The "-merge" option performs a three-way merge of two edited versions of
+a record (ours and theirs) given the version both were edited from (base).
+Changes made in only one version are applied. Changes made in both are
+conflicts. They are resolved using the "-strategy" option, one of
+"prefer-ours" (the default), "prefer-theirs" or "union-lists". The merged
+record is written to standard output (or OUTPUT_FILENAME), conflicts are
+written as a JSON array to standard error and the exit code is 2.
+
+The "-from" and "-to" options convert records between formats. The
+"-formats" option lists the supported formats, e.g. "json", "bibtex",
+"ris", "csl", "datacite", "mods", "marcxml" or "cff". If "-from" is not
+given the input format is detected from the file extension and the
+content, if "-to" is not given a JSON array of records is written.
+Formats which can hold a list of records (e.g. BibTeX, RIS or MARCXML)
+are written as a single document, other formats are limited to a single
+record. Some formats are written only, e.g. "markdown" or "schemaorg".
+
+Large batches are best converted as JSON Lines ("jsonl", one record per
+line, also known as NDJSON) or a JSON array. These, and RIS, are read
+and written one record at a time. A record which can't be read or
+written is reported on standard error and the conversion continues with
+the next record. When done a summary of the records converted and failed
+is written to standard error, the exit code is 1 if any record failed.
+
+The "-workers" option converts several records at the same time, the
+output keeps the order of the input. With "-validate" each record is
+also checked against the InvenioRDM metadata rules, invalid records
+are reported and left out. The "-progress" option reports the records
+done every few seconds. The "-checkpoint" option saves the progress of
+the conversion in a file. If the conversion is interrupted (e.g. with
+Ctrl-C, or a crash) running the same command again continues where it
+stopped. The output file is cut back to the last checkpoint so no record
+is written twice. The checkpoint file is removed when the conversion
+completes. A checkpoint needs an output file and a format written one
+record at a time, e.g. JSON Lines.
+
+The input formats "crossref" and "datacite" read the JSON returned by
+the Crossref REST API works route and the DataCite REST API dois route.
+A saved response, or one piped to standard input, holding a single DOI
+or a list of DOIs is converted without a network connection.
+
+The output format "crossref-xml" is a Crossref deposit. Crossref sends
+the deposit report to the depositor's email address, it is set with the
+"-crossref-email" option and required.
+
 You can use a filename of "-" to read input from standard input.
 
 # OPTIONS
@@ -54,10 +128,49 @@ You can use a filename of "-" to read input from standard input.
 -diff 
 : will difference two simple records in JSON files.
 
+-validate
+: validate the simple record against the InvenioRDM metadata rules
+
+-patch
+: write the RFC 6902 JSON Patch between two simple records
+
+-apply
+: apply an RFC 6902 JSON Patch to a simple record
+
+-merge
+: three-way merge base, ours and theirs versions of a simple record
+
+-strategy STRATEGY
+: conflict resolution strategy for merge, "prefer-ours", "prefer-theirs" or "union-lists"
+
+-from FORMAT
+: read the input in FORMAT, detected from the input if not given
+
+-to FORMAT
+: write the output in FORMAT, "json" if not given
+
+-formats
+: list the formats supported by "-from" and "-to"
+
+-quiet
+: only write the conversion summary when records failed
+
+-workers N
+: convert N records at the same time, the default is 1
+
+-checkpoint FILE
+: save the progress of a conversion in FILE, continue from it if present
+
+-progress
+: report the progress of a conversion on standard error
+
+-crossref-email EMAIL
+: the depositor email address of a "crossref-xml" deposit
+
 
 # EXAMPLES
 
-Pretty print a simplified JSON record.
+Render a simplified JSON record as Markdown.
 
 ~~~
 {app_name} my-record.json
@@ -69,16 +182,323 @@ Compare the differences between JSON records.
 {app_name} -diff record-old.json record-new.json
 ~~~
 
+Validate a JSON record.
+
+~~~
+{app_name} -validate my-record.json
+~~~
+
+Generate a JSON Patch from two versions of a record then replay it.
+
+~~~
+{app_name} -patch record-old.json record-new.json changes.json
+{app_name} -apply record-old.json changes.json record-updated.json
+~~~
+
+Merge the EPrints and RDM edits of a record preferring the RDM changes.
+
+~~~
+{app_name} -merge -strategy prefer-theirs base.json eprints.json rdm.json merged.json
+~~~
+
+Export a record as BibTeX and import records from a BibTeX file.
+
+~~~
+{app_name} -to bibtex my-record.json my-record.bib
+{app_name} -from bibtex references.bib references.json
+~~~
+
+Convert a RIS file from a reference manager into BibTeX.
+
+~~~
+{app_name} -from ris -to bibtex references.ris references.bib
+~~~
+
+Convert a JSON Lines export into a JSON array, skipping bad records.
+
+~~~
+{app_name} -from jsonl -to json -quiet export.jsonl records.json
+~~~
+
+Validate and convert a large JSON Lines export with eight workers so an
+interrupted run can be continued.
+
+~~~
+{app_name} -from jsonl -to jsonl -validate -workers 8 -progress \
+    -checkpoint migration.checkpoint export.jsonl migrated.jsonl
+~~~
+
+Convert a MARCXML collection into MODS, detecting the input format.
+
+~~~
+{app_name} -to mods record.marcxml record.mods
+~~~
+
+Seed a record from the Crossref metadata of a DOI saved earlier.
+
+~~~
+curl -o work.json https://api.crossref.org/works/10.1073/pnas.2302156120
+{app_name} -from crossref work.json record.json
+~~~
+
 
 `
 )
 
+// readJSON reads a JSON document from a file into obj. A filename
+// of "-" reads from standard input.
+func readJSON(fName string, obj interface{}) error {
+	in := os.Stdin
+	if fName != "-" {
+		fp, err := os.Open(fName)
+		if err != nil {
+			return err
+		}
+		defer fp.Close()
+		in = fp
+	}
+	src, err := io.ReadAll(in)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(src, obj); err != nil {
+		return fmt.Errorf("%s, %s", fName, err)
+	}
+	return nil
+}
+
+// readRecord reads a simplified JSON record from a file. A filename
+// of "-" reads from standard input.
+func readRecord(fName string) (*simplified.Record, error) {
+	rec := new(simplified.Record)
+	if err := readJSON(fName, &rec); err != nil {
+		return nil, err
+	}
+	return rec, nil
+}
+
+// inputCrosswalk returns the crosswalk to read the input with. If
+// format is empty it is detected from the file extension and the start
+// of the input.
+func inputCrosswalk(fName string, input *bufio.Reader, format string) (simplified.Crosswalk, error) {
+	if format != "" {
+		cw, ok := simplified.LookupCrosswalk(format)
+		if !ok {
+			return nil, fmt.Errorf("unsupported input format %q", format)
+		}
+		return cw, nil
+	}
+	// Peek returns what is buffered when the input is larger.
+	src, _ := input.Peek(input.Size())
+	return simplified.DetectCrosswalk(fName, src)
+}
+
+// conversion holds the options of a batch conversion.
+type conversion struct {
+	fromFormat string
+	toFormat   string
+	workers    int
+	validate   bool
+	checkpoint string
+	progress   bool
+}
+
+// progressInterval is how often progress is reported.
+const progressInterval = 5 * time.Second
+
+// checkpointInterval is how many records are emitted between
+// checkpoints.
+const checkpointInterval = 1000
+
+// convertRecords reads the records of fName and writes them to outName
+// in input order, processing up to c.workers records at a time. A
+// record which can't be read, validated or written is reported on eout
+// and the batch continues. If c.checkpoint names a file the progress is
+// saved in it so an interrupted conversion continues where it stopped,
+// the file is removed when the conversion completes. It returns the
+// counts of records converted and failed.
+func convertRecords(ctx context.Context, fName string, outName string, eout io.Writer, c *conversion) (int, int, error) {
+	checkpoint := &simplified.BatchCheckpoint{Input: fName, Output: outName}
+	if c.checkpoint != "" {
+		saved, err := simplified.ReadBatchCheckpoint(c.checkpoint)
+		if err != nil {
+			return 0, 0, err
+		}
+		if saved.Done > 0 {
+			if saved.Input != fName || saved.Output != outName {
+				return 0, 0, fmt.Errorf("%s is for %s to %s, not %s to %s", c.checkpoint, saved.Input, saved.Output, fName, outName)
+			}
+			checkpoint = saved
+		}
+	}
+	in := os.Stdin
+	if fName != "-" {
+		fp, err := os.Open(fName)
+		if err != nil {
+			return 0, 0, err
+		}
+		defer fp.Close()
+		in = fp
+	}
+	input := bufio.NewReaderSize(in, 64*1024)
+	from, err := inputCrosswalk(fName, input, c.fromFormat)
+	if err != nil {
+		return 0, 0, err
+	}
+	to, ok := simplified.LookupCrosswalk(c.toFormat)
+	if !ok {
+		return 0, 0, fmt.Errorf("unsupported output format %q", c.toFormat)
+	}
+	encodes := false
+	if _, ok := simplified.NewRecordWriter(to, io.Discard).(simplified.RecordEncoder); ok {
+		encodes = to.Name() != "json"
+	}
+	if c.checkpoint != "" && (!encodes || outName == "-") {
+		return 0, 0, fmt.Errorf("a %s conversion can't be resumed, use -to jsonl and an output file for large batches", to.Name())
+	}
+	out := os.Stdout
+	if outName != "-" {
+		out, err = checkpoint.OpenOutput(outName)
+		if err != nil {
+			return 0, 0, err
+		}
+		defer out.Close()
+	}
+	output := bufio.NewWriter(out)
+	defer output.Flush()
+	writer := simplified.NewRecordWriter(to, output)
+	encoder, _ := writer.(simplified.RecordEncoder)
+
+	started, reported := time.Now(), time.Now()
+	converted, failed, done := 0, 0, checkpoint.Done
+	fail := func(res *simplified.BatchResult) {
+		failed++
+		err := res.Err
+		if !simplified.IsRecordError(err) {
+			e := &simplified.RecordError{Index: res.Index, Err: err}
+			if res.Record != nil {
+				e.ID = res.Record.ID
+			}
+			err = e
+		}
+		fmt.Fprintf(eout, "%s, %s\n", fName, err)
+	}
+	save := func(done int) error {
+		if c.checkpoint == "" {
+			return nil
+		}
+		if err := output.Flush(); err != nil {
+			return err
+		}
+		offset, err := out.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return err
+		}
+		checkpoint.Done, checkpoint.Offset = done, offset
+		checkpoint.Succeeded += converted
+		checkpoint.Failed += failed
+		err = checkpoint.Write(c.checkpoint)
+		checkpoint.Succeeded -= converted
+		checkpoint.Failed -= failed
+		return err
+	}
+	batch := &simplified.Batch{
+		Workers: c.workers,
+		Skip:    checkpoint.Done,
+		Work: func(res *simplified.BatchResult) {
+			if c.validate {
+				if res.Err = res.Record.Validate(); res.Err != nil {
+					return
+				}
+			}
+			if encodes {
+				res.Output, res.Err = encoder.EncodeRecord(res.Record)
+			}
+		},
+		Emit: func(res *simplified.BatchResult) error {
+			done = res.Index
+			switch {
+			case res.Err != nil:
+				fail(res)
+			case encodes:
+				if res.Err = encoder.WriteEncoded(res.Output); res.Err != nil {
+					fail(res)
+				} else {
+					converted++
+				}
+			default:
+				if res.Err = writer.Write(res.Record); res.Err != nil {
+					fail(res)
+				} else {
+					converted++
+				}
+			}
+			if res.Index%checkpointInterval == 0 {
+				if err := save(res.Index); err != nil {
+					return err
+				}
+			}
+			if c.progress && time.Since(reported) >= progressInterval {
+				reported = time.Now()
+				rate := float64(converted+failed) / time.Since(started).Seconds()
+				fmt.Fprintf(eout, "%s, %d records, %d converted, %d failed, %.0f records/s\n", fName, res.Index, converted, failed, rate)
+			}
+			return nil
+		},
+	}
+	runErr := batch.Run(ctx, simplified.NewRecordReader(from, input))
+	if runErr == nil {
+		if runErr = writer.Close(); runErr == nil {
+			runErr = output.Flush()
+		}
+		if runErr == nil {
+			// The conversion is complete so a later run starts afresh.
+			if c.checkpoint != "" {
+				if err := os.Remove(c.checkpoint); err != nil && !os.IsNotExist(err) {
+					return converted, failed, err
+				}
+			}
+			return converted, failed, nil
+		}
+	}
+	if err := save(done); err != nil && runErr == nil {
+		runErr = err
+	}
+	if runErr == context.Canceled && c.checkpoint != "" {
+		runErr = fmt.Errorf("interrupted after %d records, run again with -checkpoint %s to continue", done, c.checkpoint)
+	}
+	return converted, failed, runErr
+}
+
+// listFormats writes the registered formats as a Markdown table.
+func listFormats(out io.Writer) {
+	fmt.Fprintf(out, "| name | MIME type | extensions |\n")
+	fmt.Fprintf(out, "|------|-----------|------------|\n")
+	for _, cw := range simplified.Crosswalks() {
+		fmt.Fprintf(out, "| %s | %s | %s |\n", cw.Name(), cw.MIMEType(), strings.Join(cw.Extensions(), " "))
+	}
+}
+
 func main() {
 	var (
 		showHelp bool
 		showLicense bool
 		showVersion bool
+		showFormats bool
+		quiet bool
+		showProgress bool
+		crossrefEmail string
 		diffRecords bool 
+		validateRecord bool
+		makePatch bool
+		applyPatch bool
+		mergeRecords bool
+
+		strategy string
+		fromFormat string
+		toFormat string
+		checkpoint string
+		workers int
 
 		newline bool
 
@@ -96,10 +516,24 @@ func main() {
 	flag.BoolVar(&showLicense, "license", false, "display license")
 	flag.BoolVar(&showVersion, "version", false, "display version")
 	flag.BoolVar(&diffRecords, "diff", false, "display difference between two JSON records")
+	flag.BoolVar(&validateRecord, "validate", false, "validate a JSON record against InvenioRDM metadata rules")
+	flag.BoolVar(&makePatch, "patch", false, "display the JSON Patch between two JSON records")
+	flag.BoolVar(&applyPatch, "apply", false, "apply a JSON Patch to a JSON record")
+	flag.BoolVar(&mergeRecords, "merge", false, "three-way merge base, ours and theirs JSON records")
+	flag.StringVar(&strategy, "strategy", string(simplified.PreferOurs), "merge conflict strategy, prefer-ours, prefer-theirs or union-lists")
+	flag.StringVar(&fromFormat, "from", "", "input format, detected if not given, see -formats")
+	flag.StringVar(&toFormat, "to", "", "output format, json if not given, see -formats")
+	flag.BoolVar(&showFormats, "formats", false, "list the supported formats")
+	flag.BoolVar(&quiet, "quiet", false, "only report the conversion summary when records failed")
+	flag.IntVar(&workers, "workers", 1, "number of records converted at the same time")
+	flag.StringVar(&checkpoint, "checkpoint", "", "save the progress of a conversion in this file to resume it")
+	flag.BoolVar(&showProgress, "progress", false, "report the progress of a conversion on standard error")
+	flag.StringVar(&crossrefEmail, "crossref-email", "", "the depositor email address of a Crossref deposit")
 	flag.BoolVar(&newline, "newline", true, "add a trailing newline")
 	flag.Parse()
 
 	args := flag.Args()
+	simplified.DefaultCrossrefHead.EmailAddress = crossrefEmail
 
 	in := os.Stdin
 	out := os.Stdout
@@ -118,11 +552,147 @@ func main() {
 		os.Exit(0)
 	}
 
+	if showFormats {
+		listFormats(out)
+		os.Exit(0)
+	}
+
 	if len(args) == 0 {
 		fmt.Fprintf(eout, "expected the name of a simplified record JSON document or '-' to read from standard input")
 		os.Exit(1)
 	}
 
+	if fromFormat != "" || toFormat != "" || workers > 1 || checkpoint != "" {
+		if toFormat == "" {
+			toFormat = "json"
+		}
+		outName := "-"
+		if len(args) > 1 {
+			outName = args[1]
+		}
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		converted, failed, err := convertRecords(ctx, args[0], outName, eout, &conversion{
+			fromFormat: fromFormat,
+			toFormat:   toFormat,
+			workers:    workers,
+			validate:   validateRecord,
+			checkpoint: checkpoint,
+			progress:   showProgress,
+		})
+		stop()
+		if err != nil {
+			fmt.Fprintf(eout, "%s\n", err)
+			os.Exit(1)
+		}
+		if !quiet || failed > 0 {
+			fmt.Fprintf(eout, "%d converted, %d failed\n", converted, failed)
+		}
+		if failed > 0 {
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
+	if mergeRecords {
+		mergeStrategy := simplified.MergeStrategy(strategy)
+		switch mergeStrategy {
+		case simplified.PreferOurs, simplified.PreferTheirs, simplified.UnionLists:
+		default:
+			fmt.Fprintf(eout, "unknown merge strategy %q\n", strategy)
+			os.Exit(1)
+		}
+		if len(args) < 3 {
+			fmt.Fprintf(eout, "expected base, ours and theirs filenames\n")
+			os.Exit(1)
+		}
+		if len(args) > 3 && args[3] != "-" {
+			out, err = os.Create(args[3])
+			if err != nil {
+				fmt.Fprintf(eout, "%s\n", err)
+				os.Exit(1)
+			}
+			defer out.Close()
+		}
+		records := []*simplified.Record{}
+		for _, fName := range args[0:3] {
+			rec, err := readRecord(fName)
+			if err != nil {
+				fmt.Fprintf(eout, "%s\n", err)
+				os.Exit(1)
+			}
+			records = append(records, rec)
+		}
+		merged, conflicts := simplified.MergeWithStrategy(records[0], records[1], records[2], mergeStrategy)
+		fmt.Fprintf(out, "%s", merged.ToString())
+		if newline {
+			fmt.Fprintln(out)
+		}
+		if len(conflicts) > 0 {
+			src, err := json.MarshalIndent(conflicts, "", "    ")
+			if err != nil {
+				fmt.Fprintf(eout, "%s\n", err)
+				os.Exit(1)
+			}
+			fmt.Fprintf(eout, "%s\n", src)
+			os.Exit(2)
+		}
+		os.Exit(0)
+	}
+
+	if makePatch || applyPatch {
+		if len(args) < 2 {
+			fmt.Fprintf(eout, "expected two filenames\n")
+			os.Exit(1)
+		}
+		if len(args) > 2 && args[2] != "-" {
+			out, err = os.Create(args[2])
+			if err != nil {
+				fmt.Fprintf(eout, "%s\n", err)
+				os.Exit(1)
+			}
+			defer out.Close()
+		}
+		rec, err := readRecord(args[0])
+		if err != nil {
+			fmt.Fprintf(eout, "%s\n", err)
+			os.Exit(1)
+		}
+		var src []byte
+		if makePatch {
+			rec2, err := readRecord(args[1])
+			if err != nil {
+				fmt.Fprintf(eout, "%s\n", err)
+				os.Exit(1)
+			}
+			patch, err := rec.DiffAsPatch(rec2)
+			if err != nil {
+				fmt.Fprintf(eout, "%s\n", err)
+				os.Exit(1)
+			}
+			src, err = json.MarshalIndent(patch, "", "    ")
+			if err != nil {
+				fmt.Fprintf(eout, "%s\n", err)
+				os.Exit(1)
+			}
+		} else {
+			patch := simplified.Patch{}
+			if err := readJSON(args[1], &patch); err != nil {
+				fmt.Fprintf(eout, "%s\n", err)
+				os.Exit(1)
+			}
+			if err := rec.ApplyPatch(patch); err != nil {
+				fmt.Fprintf(eout, "%s\n", err)
+				os.Exit(1)
+			}
+			src = rec.ToString()
+		}
+		fmt.Fprintf(out, "%s", src)
+		if newline {
+			fmt.Fprintln(out)
+		}
+		os.Exit(0)
+	}
+
 	if diffRecords {
 		in1 := os.Stdin
 		in2 := os.Stdin
@@ -209,6 +779,22 @@ func main() {
 			fmt.Fprintf(eout, "%s\n", err)
 			os.Exit(1)
 		}
+		if validateRecord {
+			violations := simplified.ValidationError{}
+			if err := record.Validate(); err != nil {
+				violations = err.(simplified.ValidationError)
+			}
+			src, err := json.MarshalIndent(violations, "", "    ")
+			if err != nil {
+				fmt.Fprintf(eout, "%s\n", err)
+				os.Exit(1)
+			}
+			fmt.Fprintf(out, "%s\n", src)
+			if len(violations) > 0 {
+				os.Exit(1)
+			}
+			os.Exit(0)
+		}
 		fmt.Fprintf(out, "%s", record.AsMarkdown())
 	}
 	if newline {
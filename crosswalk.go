@@ -0,0 +1,434 @@
+package simplified
+
+/**
+ * This file holds helper functions shared by the crosswalks between
+ * the simplified Record and other metadata formats (e.g. BibTeX).
+ */
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+)
+
+// resourceTypeID returns the resource type id of a record, e.g.
+// "publication-article".
+func resourceTypeID(rec *Record) string {
+	if rec == nil || rec.Metadata == nil || rec.Metadata.ResourceType == nil {
+		return ""
+	}
+	if id, ok := rec.Metadata.ResourceType["id"].(string); ok {
+		return id
+	}
+	return ""
+}
+
+// ensureMetadata makes sure the record has a Metadata struct.
+func ensureMetadata(rec *Record) *Metadata {
+	if rec.Metadata == nil {
+		rec.Metadata = new(Metadata)
+	}
+	return rec.Metadata
+}
+
+// setResourceType sets the resource type id of a record.
+func setResourceType(rec *Record, id string) {
+	ensureMetadata(rec).ResourceType = map[string]interface{}{"id": id}
+}
+
+// customFields returns a custom field of a record holding a map, e.g.
+// "journal:journal", as a map of strings.
+func customFields(rec *Record, name string) map[string]string {
+	fields := map[string]string{}
+	if rec == nil || rec.CustomFields == nil {
+		return fields
+	}
+	field, ok := rec.CustomFields[name].(map[string]interface{})
+	if !ok {
+		return fields
+	}
+	for k, v := range field {
+		if v == nil {
+			continue
+		}
+		if s := strings.TrimSpace(fmt.Sprintf("%v", v)); s != "" {
+			fields[k] = s
+		}
+	}
+	return fields
+}
+
+// setCustomField sets a value in a custom field holding a map. Empty
+// values are ignored.
+func setCustomField(rec *Record, name string, key string, value string) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return
+	}
+	if rec.CustomFields == nil {
+		rec.CustomFields = map[string]interface{}{}
+	}
+	field, ok := rec.CustomFields[name].(map[string]interface{})
+	if !ok {
+		field = map[string]interface{}{}
+		rec.CustomFields[name] = field
+	}
+	field[key] = value
+}
+
+// journalFields returns the "journal:journal" custom field of a record
+// as a map of strings, e.g. title, volume, issue, pages and issn.
+func journalFields(rec *Record) map[string]string {
+	return customFields(rec, "journal:journal")
+}
+
+// setJournalField sets a value in the "journal:journal" custom field.
+// Empty values are ignored.
+func setJournalField(rec *Record, key string, value string) {
+	setCustomField(rec, "journal:journal", key, value)
+}
+
+// thesisFields returns the "thesis:thesis" custom field of a record as
+// a map of strings, e.g. university, department and type.
+func thesisFields(rec *Record) map[string]string {
+	return customFields(rec, "thesis:thesis")
+}
+
+// setThesisField sets a value in the "thesis:thesis" custom field.
+// Empty values are ignored.
+func setThesisField(rec *Record, key string, value string) {
+	setCustomField(rec, "thesis:thesis", key, value)
+}
+
+// identifierValue returns the first identifier with the given scheme.
+func identifierValue(identifiers []*Identifier, scheme string) string {
+	for _, id := range identifiers {
+		if id != nil && strings.EqualFold(id.Scheme, scheme) && id.Identifier != "" {
+			return id.Identifier
+		}
+	}
+	return ""
+}
+
+// identifierValues returns all identifiers with the given scheme.
+func identifierValues(identifiers []*Identifier, scheme string) []string {
+	l := []string{}
+	for _, id := range identifiers {
+		if id != nil && strings.EqualFold(id.Scheme, scheme) && id.Identifier != "" {
+			l = append(l, id.Identifier)
+		}
+	}
+	return l
+}
+
+// addIdentifier adds an identifier to a list unless it is empty or
+// already present.
+func addIdentifier(identifiers []*Identifier, scheme string, value string) []*Identifier {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return identifiers
+	}
+	for _, id := range identifiers {
+		if id != nil && strings.EqualFold(id.Scheme, scheme) && id.Identifier == value {
+			return identifiers
+		}
+	}
+	return append(identifiers, &Identifier{Scheme: scheme, Identifier: value})
+}
+
+// recordDOI returns the DOI of a record from the external PIDs falling
+// back to the metadata identifiers.
+func recordDOI(rec *Record) string {
+	if rec == nil {
+		return ""
+	}
+	if pid, ok := rec.ExternalPIDs["doi"]; ok && pid != nil && pid.Identifier != "" {
+		return pid.Identifier
+	}
+	if rec.Metadata != nil {
+		return identifierValue(rec.Metadata.Identifiers, "doi")
+	}
+	return ""
+}
+
+// setRecordDOI sets the DOI as an external PID of the record.
+func setRecordDOI(rec *Record, doi string) {
+	doi = normalizeIdentifierValue("doi", doi)
+	if doi == "" {
+		return
+	}
+	if rec.ExternalPIDs == nil {
+		rec.ExternalPIDs = map[string]*PersistentIdentifier{}
+	}
+	rec.ExternalPIDs["doi"] = &PersistentIdentifier{Identifier: doi, Provider: "external"}
+}
+
+// recordISSN returns the ISSN of a record from the metadata identifiers
+// falling back to the "journal:journal" custom field.
+func recordISSN(rec *Record) string {
+	if rec == nil {
+		return ""
+	}
+	if rec.Metadata != nil {
+		if issn := identifierValue(rec.Metadata.Identifiers, "issn"); issn != "" {
+			return issn
+		}
+	}
+	return journalFields(rec)["issn"]
+}
+
+// creatorRole returns the role id of a creator or contributor.
+func creatorRole(c *Creator) string {
+	if c == nil {
+		return ""
+	}
+	if c.Role != nil && c.Role.ID != "" {
+		return c.Role.ID
+	}
+	if c.PersonOrOrg != nil && c.PersonOrOrg.Role != nil {
+		return c.PersonOrOrg.Role.ID
+	}
+	return ""
+}
+
+// creatorsWithRole returns the creators or contributors with one of the
+// given role ids.
+func creatorsWithRole(creators []*Creator, roles ...string) []*Creator {
+	l := []*Creator{}
+	for _, c := range creators {
+		role := creatorRole(c)
+		for _, r := range roles {
+			if strings.EqualFold(role, r) {
+				l = append(l, c)
+				break
+			}
+		}
+	}
+	return l
+}
+
+// isOrganization checks if a PersonOrOrg is an organization.
+func isOrganization(p *PersonOrOrg) bool {
+	return p != nil && (p.Type == "organizational" || (p.FamilyName == "" && p.GivenName == "" && p.Name != ""))
+}
+
+// personNames returns the family and given names of a PersonOrOrg.
+// If only the name is set it is split on the first comma.
+func personNames(p *PersonOrOrg) (string, string) {
+	if p == nil {
+		return "", ""
+	}
+	if p.FamilyName != "" || p.GivenName != "" {
+		return p.FamilyName, p.GivenName
+	}
+	if family, given, ok := strings.Cut(p.Name, ","); ok {
+		return strings.TrimSpace(family), strings.TrimSpace(given)
+	}
+	return p.Name, ""
+}
+
+// personIdentifier returns the identifier of a PersonOrOrg for the scheme.
+func personIdentifier(p *PersonOrOrg, scheme string) string {
+	if p == nil {
+		return ""
+	}
+	if scheme == "clpid" && p.ID != "" {
+		return p.ID
+	}
+	return identifierValue(p.Identifiers, scheme)
+}
+
+// newPerson creates a personal creator with an optional role.
+func newPerson(family string, given string, role string) *Creator {
+	family, given = strings.TrimSpace(family), strings.TrimSpace(given)
+	p := &PersonOrOrg{Type: "personal", FamilyName: family, GivenName: given}
+	p.Name = family
+	if given != "" {
+		p.Name = fmt.Sprintf("%s, %s", family, given)
+	}
+	c := &Creator{PersonOrOrg: p}
+	if role != "" {
+		c.Role = &Role{ID: role}
+	}
+	return c
+}
+
+// newOrganization creates an organizational creator with an optional role.
+func newOrganization(name string, role string) *Creator {
+	c := &Creator{PersonOrOrg: &PersonOrOrg{Type: "organizational", Name: strings.TrimSpace(name)}}
+	if role != "" {
+		c.Role = &Role{ID: role}
+	}
+	return c
+}
+
+// addPersonIdentifier adds an identifier to the creator's PersonOrOrg.
+func addPersonIdentifier(c *Creator, scheme string, value string) {
+	if c == nil || c.PersonOrOrg == nil {
+		return
+	}
+	c.PersonOrOrg.Identifiers = addIdentifier(c.PersonOrOrg.Identifiers, scheme, value)
+}
+
+// addAffiliation adds a named affiliation to a creator.
+func addAffiliation(c *Creator, name string, id string) {
+	name, id = strings.TrimSpace(name), strings.TrimSpace(id)
+	if c == nil || (name == "" && id == "") {
+		return
+	}
+	target := &Affiliation{ID: id, Name: name}
+	if c.HasAffiliation(target) {
+		return
+	}
+	c.Affiliations = append(c.Affiliations, target)
+}
+
+// entryURL returns the URL of a file entry from its links or
+// metadata.
+func entryURL(e *Entry) string {
+	for _, key := range []string{"content", "self"} {
+		if s, ok := e.Links[key].(string); ok && s != "" {
+			return s
+		}
+	}
+	if s, ok := e.Metadata["url"].(string); ok {
+		return s
+	}
+	return ""
+}
+
+// dateParts splits an EDTF date into year, month and day. For an
+// interval the start is used. Qualifiers are removed.
+func dateParts(date string) (string, string, string) {
+	date = strings.TrimSpace(date)
+	if start, _, ok := strings.Cut(date, "/"); ok {
+		date = start
+	}
+	date = strings.TrimRight(date, "?~%")
+	parts := strings.SplitN(date, "-", 3)
+	year, month, day := parts[0], "", ""
+	if len(parts) > 1 {
+		month = parts[1]
+	}
+	if len(parts) > 2 {
+		day = parts[2]
+		if len(day) > 2 {
+			day = day[0:2]
+		}
+	}
+	return year, month, day
+}
+
+// joinDate builds an ISO 8601 date from year, month and day parts
+// skipping the parts that are missing.
+func joinDate(year string, month string, day string) string {
+	year, month, day = strings.TrimSpace(year), strings.TrimSpace(month), strings.TrimSpace(day)
+	if year == "" {
+		return ""
+	}
+	date := year
+	if month != "" {
+		if len(month) == 1 {
+			month = "0" + month
+		}
+		date += "-" + month
+		if day != "" {
+			if len(day) == 1 {
+				day = "0" + day
+			}
+			date += "-" + day
+		}
+	}
+	return date
+}
+
+// splitPages splits a page range, e.g. "15-23", into start and end.
+func splitPages(pages string) (string, string) {
+	pages = strings.ReplaceAll(strings.ReplaceAll(pages, "–", "-"), "--", "-")
+	if start, end, ok := strings.Cut(pages, "-"); ok {
+		return strings.TrimSpace(start), strings.TrimSpace(end)
+	}
+	return strings.TrimSpace(pages), ""
+}
+
+// joinPages joins a start and end page into a range.
+func joinPages(start string, end string) string {
+	start, end = strings.TrimSpace(start), strings.TrimSpace(end)
+	if end == "" || end == start {
+		return start
+	}
+	if start == "" {
+		return end
+	}
+	return start + "-" + end
+}
+
+// subjectLabels returns the subject text (or id) of each subject.
+func subjectLabels(subjects []*Subject) []string {
+	l := []string{}
+	for _, s := range subjects {
+		if s == nil {
+			continue
+		}
+		if s.Subject != "" {
+			l = append(l, s.Subject)
+		} else if s.ID != "" {
+			l = append(l, s.ID)
+		}
+	}
+	return l
+}
+
+var reCreativeCommons = regexp.MustCompile(`^https?://creativecommons\.org/(?:licenses|publicdomain)/([a-z-]+)/([0-9.]+)`)
+
+// licenseRight returns a Right for a license URL. Creative Commons and
+// SPDX license URLs are given the InvenioRDM license id, e.g.
+// "cc-by-4.0".
+func licenseRight(u string) *Right {
+	u = strings.TrimSpace(u)
+	if u == "" {
+		return nil
+	}
+	if m := reCreativeCommons.FindStringSubmatch(u); m != nil {
+		if m[1] == "zero" {
+			return &Right{ID: "cc0-" + m[2], Link: u}
+		}
+		return &Right{ID: "cc-" + m[1] + "-" + m[2], Link: u}
+	}
+	return spdxRight(u)
+}
+
+var (
+	reHTMLTag   = regexp.MustCompile(`<[^>]*>`)
+	reHTMLBreak = regexp.MustCompile(`(?i)<br\b[^>]*>`)
+	// reHTMLBlock matches the tags of HTML and JATS block elements.
+	reHTMLBlock     = regexp.MustCompile(`(?i)</?\s*([a-z]+:)?(p|div|h[1-6]|ul|ol|li|dl|dt|dd|blockquote|pre|table|tr|hr|sec|title|list|list-item)\b[^>]*>`)
+	reHTMLParagraph = regexp.MustCompile(`\n\s*\n`)
+)
+
+// stripHTML removes HTML markup and unescapes entities leaving plain
+// text. Block elements become paragraphs separated by a blank line and
+// "<br>" a line break, other whitespace is collapsed. The line breaks
+// of text without markup are kept.
+func stripHTML(s string) string {
+	if reHTMLTag.MatchString(s) {
+		s = strings.Join(strings.Fields(s), " ")
+	}
+	s = reHTMLBreak.ReplaceAllString(s, "\n")
+	s = reHTMLBlock.ReplaceAllString(s, "\n\n")
+	s = html.UnescapeString(reHTMLTag.ReplaceAllString(s, ""))
+	paragraphs := []string{}
+	for _, paragraph := range reHTMLParagraph.Split(s, -1) {
+		lines := []string{}
+		for _, line := range strings.Split(paragraph, "\n") {
+			if line = strings.Join(strings.Fields(line), " "); line != "" {
+				lines = append(lines, line)
+			}
+		}
+		if len(lines) > 0 {
+			paragraphs = append(paragraphs, strings.Join(lines, "\n"))
+		}
+	}
+	return strings.Join(paragraphs, "\n\n")
+}
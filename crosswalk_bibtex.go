@@ -0,0 +1,687 @@
+package simplified
+
+/**
+ * This file implements a crosswalk between the simplified Record and
+ * BibTeX. The entry types "software" and "dataset" are from BibLaTeX.
+ */
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// bibTeXTypes maps InvenioRDM resource type ids to BibTeX entry types.
+var bibTeXTypes = map[string]string{
+	"publication-article":         "article",
+	"publication-preprint":        "article",
+	"publication-book":            "book",
+	"publication-section":         "incollection",
+	"publication-conferencepaper": "inproceedings",
+	"publication-report":          "techreport",
+	"publication-technicalnote":   "techreport",
+	"publication-workingpaper":    "techreport",
+	"publication-thesis":          "phdthesis",
+	"software":                    "software",
+	"dataset":                     "dataset",
+}
+
+// bibTeXResourceTypes maps BibTeX entry types to resource type ids.
+var bibTeXResourceTypes = map[string]string{
+	"article":       "publication-article",
+	"book":          "publication-book",
+	"booklet":       "publication-book",
+	"inbook":        "publication-section",
+	"incollection":  "publication-section",
+	"inproceedings": "publication-conferencepaper",
+	"conference":    "publication-conferencepaper",
+	"techreport":    "publication-report",
+	"report":        "publication-report",
+	"phdthesis":     "publication-thesis",
+	"mastersthesis": "publication-thesis",
+	"thesis":        "publication-thesis",
+	"software":      "software",
+	"dataset":       "dataset",
+	"misc":          "other",
+	"unpublished":   "publication-other",
+	"manual":        "publication-technicalnote",
+}
+
+var bibTeXMonths = []string{"jan", "feb", "mar", "apr", "may", "jun", "jul", "aug", "sep", "oct", "nov", "dec"}
+
+// bibTeXEscape escapes the characters special to BibTeX/LaTeX.
+func bibTeXEscape(s string) string {
+	var sb strings.Builder
+	for _, r := range s {
+		switch r {
+		case '&', '%', '$', '#', '_', '{', '}':
+			sb.WriteRune('\\')
+			sb.WriteRune(r)
+		case '\n', '\r', '\t':
+			sb.WriteRune(' ')
+		default:
+			sb.WriteRune(r)
+		}
+	}
+	return strings.Join(strings.Fields(sb.String()), " ")
+}
+
+// bibTeXName formats a creator as a BibTeX name, organizations are
+// wrapped in braces so they are not parsed as a personal name.
+func bibTeXName(c *Creator) string {
+	if c == nil || c.PersonOrOrg == nil {
+		return ""
+	}
+	if isOrganization(c.PersonOrOrg) {
+		return "{" + bibTeXEscape(c.PersonOrOrg.Name) + "}"
+	}
+	family, given := personNames(c.PersonOrOrg)
+	if given == "" {
+		return bibTeXEscape(family)
+	}
+	return bibTeXEscape(family) + ", " + bibTeXEscape(given)
+}
+
+func bibTeXNames(creators []*Creator) string {
+	names := []string{}
+	for _, c := range creators {
+		if name := bibTeXName(c); name != "" {
+			names = append(names, name)
+		}
+	}
+	return strings.Join(names, " and ")
+}
+
+// bibTeXKey makes a citation key for a record, e.g. "Orphan2023".
+func bibTeXKey(rec *Record) string {
+	key := ""
+	if rec.Metadata != nil && len(rec.Metadata.Creators) > 0 && rec.Metadata.Creators[0].PersonOrOrg != nil {
+		family, _ := personNames(rec.Metadata.Creators[0].PersonOrOrg)
+		key = family
+		year, _, _ := dateParts(rec.Metadata.PublicationDate)
+		key += year
+	}
+	if key == "" {
+		key = rec.ID
+	}
+	clean := strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '_' || r == ':' {
+			return r
+		}
+		return -1
+	}, key)
+	if clean == "" {
+		return "record"
+	}
+	return clean
+}
+
+// AsBibTeX renders the record as a BibTeX entry. The entry type is
+// chosen from the resource type, journal details come from the
+// "journal:journal" custom field.
+func (rec *Record) AsBibTeX() []byte {
+	if rec == nil {
+		return nil
+	}
+	m := rec.Metadata
+	if m == nil {
+		m = new(Metadata)
+	}
+	entryType, ok := bibTeXTypes[resourceTypeID(rec)]
+	if !ok {
+		entryType = "misc"
+	}
+	fields := [][2]string{}
+	add := func(name string, value string) {
+		if value = strings.TrimSpace(value); value != "" {
+			fields = append(fields, [2]string{name, value})
+		}
+	}
+	authors := creatorsWithoutRole(m.Creators, "editor")
+	add("author", bibTeXNames(authors))
+	editors := append(creatorsWithRole(m.Creators, "editor"), creatorsWithRole(m.Contributors, "editor")...)
+	add("editor", bibTeXNames(editors))
+	add("title", bibTeXEscape(m.Title))
+	journal := journalFields(rec)
+	switch entryType {
+	case "article":
+		add("journal", bibTeXEscape(journal["title"]))
+	case "incollection", "inproceedings":
+		add("booktitle", bibTeXEscape(journal["title"]))
+	}
+	year, month, _ := dateParts(m.PublicationDate)
+	add("year", year)
+	add("volume", bibTeXEscape(journal["volume"]))
+	add("number", bibTeXEscape(journal["issue"]))
+	add("pages", strings.ReplaceAll(journal["pages"], "-", "--"))
+	switch entryType {
+	case "phdthesis":
+		add("school", bibTeXEscape(m.Publisher))
+	case "techreport":
+		add("institution", bibTeXEscape(m.Publisher))
+	default:
+		add("publisher", bibTeXEscape(m.Publisher))
+	}
+	add("version", bibTeXEscape(m.Version))
+	add("doi", recordDOI(rec))
+	add("issn", recordISSN(rec))
+	add("isbn", identifierValue(m.Identifiers, "isbn"))
+	add("url", identifierValue(m.Identifiers, "url"))
+	add("abstract", bibTeXEscape(stripHTML(m.Description)))
+	for i, s := range subjectLabels(m.Subjects) {
+		if i == 0 {
+			fields = append(fields, [2]string{"keywords", bibTeXEscape(s)})
+		} else {
+			fields[len(fields)-1][1] += ", " + bibTeXEscape(s)
+		}
+	}
+
+	buf := new(bytes.Buffer)
+	fmt.Fprintf(buf, "@%s{%s", entryType, bibTeXKey(rec))
+	for _, field := range fields {
+		fmt.Fprintf(buf, ",\n  %s = {%s}", field[0], field[1])
+	}
+	if n, ok := monthNumber(month); ok {
+		fmt.Fprintf(buf, ",\n  month = %s", bibTeXMonths[n-1])
+	}
+	buf.WriteString("\n}\n")
+	return buf.Bytes()
+}
+
+// creatorsWithoutRole returns the creators without one of the given roles.
+func creatorsWithoutRole(creators []*Creator, roles ...string) []*Creator {
+	l := []*Creator{}
+	for _, c := range creators {
+		if len(creatorsWithRole([]*Creator{c}, roles...)) == 0 {
+			l = append(l, c)
+		}
+	}
+	return l
+}
+
+func monthNumber(month string) (int, bool) {
+	n := 0
+	if _, err := fmt.Sscanf(month, "%d", &n); err != nil || n < 1 || n > 12 {
+		return 0, false
+	}
+	return n, true
+}
+
+//
+// BibTeX parser
+//
+
+// bibTeXEntry holds a parsed BibTeX entry.
+type bibTeXEntry struct {
+	Type   string
+	Key    string
+	Fields map[string]string
+}
+
+// bibTeXParser is a small recursive descent parser for BibTeX.
+type bibTeXParser struct {
+	src    []rune
+	pos    int
+	line   int
+	macros map[string]string
+}
+
+func (p *bibTeXParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("bibtex line %d, %s", p.line, fmt.Sprintf(format, args...))
+}
+
+func (p *bibTeXParser) peek() rune {
+	if p.pos >= len(p.src) {
+		return 0
+	}
+	return p.src[p.pos]
+}
+
+func (p *bibTeXParser) next() rune {
+	r := p.peek()
+	if r == '\n' {
+		p.line++
+	}
+	p.pos++
+	return r
+}
+
+func (p *bibTeXParser) skipSpace() {
+	for p.pos < len(p.src) && unicode.IsSpace(p.peek()) {
+		p.next()
+	}
+}
+
+func (p *bibTeXParser) ident() string {
+	p.skipSpace()
+	start := p.pos
+	for p.pos < len(p.src) {
+		r := p.peek()
+		if unicode.IsSpace(r) || strings.ContainsRune("{}(),=#\"", r) {
+			break
+		}
+		p.next()
+	}
+	return string(p.src[start:p.pos])
+}
+
+// braced reads a value delimited by braces keeping nested braces.
+func (p *bibTeXParser) braced() (string, error) {
+	depth := 0
+	var sb strings.Builder
+	for p.pos < len(p.src) {
+		r := p.next()
+		switch r {
+		case '\\':
+			sb.WriteRune(r)
+			if p.pos < len(p.src) {
+				sb.WriteRune(p.next())
+			}
+			continue
+		case '{':
+			depth++
+			if depth == 1 {
+				continue
+			}
+		case '}':
+			depth--
+			if depth == 0 {
+				return sb.String(), nil
+			}
+		}
+		sb.WriteRune(r)
+	}
+	return "", p.errorf("unterminated braced value")
+}
+
+// quoted reads a value delimited by double quotes.
+func (p *bibTeXParser) quoted() (string, error) {
+	p.next()
+	depth := 0
+	var sb strings.Builder
+	for p.pos < len(p.src) {
+		r := p.next()
+		switch {
+		case r == '\\':
+			sb.WriteRune(r)
+			if p.pos < len(p.src) {
+				sb.WriteRune(p.next())
+			}
+			continue
+		case r == '{':
+			depth++
+		case r == '}':
+			depth--
+		case r == '"' && depth == 0:
+			return sb.String(), nil
+		}
+		sb.WriteRune(r)
+	}
+	return "", p.errorf("unterminated quoted value")
+}
+
+// value reads a field value, concatenating parts joined by "#".
+func (p *bibTeXParser) value() (string, error) {
+	var sb strings.Builder
+	for {
+		p.skipSpace()
+		switch p.peek() {
+		case '{':
+			s, err := p.braced()
+			if err != nil {
+				return "", err
+			}
+			sb.WriteString(s)
+		case '"':
+			s, err := p.quoted()
+			if err != nil {
+				return "", err
+			}
+			sb.WriteString(s)
+		default:
+			name := p.ident()
+			if name == "" {
+				return "", p.errorf("expected a value")
+			}
+			if s, ok := p.macros[strings.ToLower(name)]; ok {
+				sb.WriteString(s)
+			} else {
+				sb.WriteString(name)
+			}
+		}
+		p.skipSpace()
+		if p.peek() != '#' {
+			return sb.String(), nil
+		}
+		p.next()
+	}
+}
+
+// entry parses the body of an entry after the "@type".
+func (p *bibTeXParser) entry(entryType string) (*bibTeXEntry, error) {
+	p.skipSpace()
+	open := p.next()
+	if open != '{' && open != '(' {
+		return nil, p.errorf("expected { or ( after @%s", entryType)
+	}
+	closer := '}'
+	if open == '(' {
+		closer = ')'
+	}
+	entry := &bibTeXEntry{Type: strings.ToLower(entryType), Fields: map[string]string{}}
+	if entry.Type == "string" {
+		name := strings.ToLower(p.ident())
+		p.skipSpace()
+		if p.next() != '=' {
+			return nil, p.errorf("expected = in @string")
+		}
+		val, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		p.macros[name] = val
+		p.skipSpace()
+		if p.next() != closer {
+			return nil, p.errorf("expected %c closing @string", closer)
+		}
+		return nil, nil
+	}
+	entry.Key = p.ident()
+	for {
+		p.skipSpace()
+		switch p.peek() {
+		case ',':
+			p.next()
+			continue
+		case closer:
+			p.next()
+			return entry, nil
+		case 0:
+			return nil, p.errorf("unterminated entry %q", entry.Key)
+		}
+		name := strings.ToLower(p.ident())
+		if name == "" {
+			return nil, p.errorf("expected a field name in %q", entry.Key)
+		}
+		p.skipSpace()
+		if p.next() != '=' {
+			return nil, p.errorf("expected = after %s in %q", name, entry.Key)
+		}
+		val, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		entry.Fields[name] = val
+	}
+}
+
+// skipBalanced skips an @comment or @preamble body.
+func (p *bibTeXParser) skipBalanced() error {
+	p.skipSpace()
+	switch p.peek() {
+	case '{':
+		_, err := p.braced()
+		return err
+	case '(':
+		depth := 0
+		for p.pos < len(p.src) {
+			switch p.next() {
+			case '(':
+				depth++
+			case ')':
+				depth--
+				if depth == 0 {
+					return nil
+				}
+			}
+		}
+		return p.errorf("unterminated entry")
+	}
+	return nil
+}
+
+func parseBibTeXEntries(src []byte) ([]*bibTeXEntry, error) {
+	p := &bibTeXParser{src: []rune(string(src)), line: 1, macros: map[string]string{}}
+	for i, month := range bibTeXMonths {
+		p.macros[month] = fmt.Sprintf("%d", i+1)
+	}
+	entries := []*bibTeXEntry{}
+	for p.pos < len(p.src) {
+		// Text outside of entries is a comment
+		if p.next() != '@' {
+			continue
+		}
+		entryType := strings.ToLower(p.ident())
+		switch entryType {
+		case "comment", "preamble":
+			if err := p.skipBalanced(); err != nil {
+				return nil, err
+			}
+			continue
+		case "":
+			return nil, p.errorf("expected an entry type after @")
+		}
+		entry, err := p.entry(entryType)
+		if err != nil {
+			return nil, err
+		}
+		if entry != nil {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
+
+var (
+	reLaTeXCommand = regexp.MustCompile(`\\[a-zA-Z]+\s*\{([^{}]*)\}`)
+	reLaTeXAccent  = regexp.MustCompile(`\\(["'` + "`" + `^~=.uvHckr])\s*\{?([a-zA-Z])\}?`)
+)
+
+// latexAccents maps LaTeX accent commands to combining characters.
+var latexAccents = map[string]rune{
+	`"`: '̈', `'`: '́', "`": '̀', `^`: '̂', `~`: '̃',
+	`=`: '̄', `.`: '̇', `u`: '̆', `v`: '̌', `H`: '̋',
+	`c`: '̧', `k`: '̨', `r`: '̊',
+}
+
+// bibTeXText converts a BibTeX value to plain text, removing braces
+// and converting common LaTeX escapes.
+func bibTeXText(s string) string {
+	s = reLaTeXAccent.ReplaceAllStringFunc(s, func(m string) string {
+		parts := reLaTeXAccent.FindStringSubmatch(m)
+		if r, ok := latexAccents[parts[1]]; ok {
+			return composeAccent(parts[2], r)
+		}
+		return parts[2]
+	})
+	for reLaTeXCommand.MatchString(s) {
+		s = reLaTeXCommand.ReplaceAllString(s, "$1")
+	}
+	replacer := strings.NewReplacer(`\&`, "&", `\%`, "%", `\$`, "$", `\#`, "#", `\_`, "_", `\{`, "\x00", `\}`, "\x01", "---", "—", "--", "–", "~", " ")
+	s = replacer.Replace(s)
+	s = strings.NewReplacer("{", "", "}", "", "\x00", "{", "\x01", "}").Replace(s)
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// composeAccent applies a combining accent to a letter returning the
+// precomposed character for common Latin letters.
+func composeAccent(letter string, accent rune) string {
+	precomposed := map[string]string{
+		"ä": "ä", "ö": "ö", "ü": "ü", "Ä": "Ä", "Ö": "Ö", "Ü": "Ü", "ë": "ë", "ï": "ï",
+		"á": "á", "é": "é", "í": "í", "ó": "ó", "ú": "ú", "É": "É", "ý": "ý", "ć": "ć", "ń": "ń", "ś": "ś", "ź": "ź",
+		"à": "à", "è": "è", "ì": "ì", "ò": "ò", "ù": "ù",
+		"â": "â", "ê": "ê", "î": "î", "ô": "ô", "û": "û",
+		"ã": "ã", "ñ": "ñ", "õ": "õ", "Ñ": "Ñ",
+		"ç": "ç", "Ç": "Ç", "ş": "ş",
+		"č": "č", "š": "š", "ž": "ž", "ř": "ř", "ě": "ě", "Č": "Č", "Š": "Š", "Ž": "Ž",
+		"å": "å", "Å": "Å", "ő": "ő", "ű": "ű",
+	}
+	if s, ok := precomposed[letter+string(accent)]; ok {
+		return s
+	}
+	return letter + string(accent)
+}
+
+// splitBibTeXNames splits a list of names on " and " outside of braces.
+func splitBibTeXNames(s string) []string {
+	names := []string{}
+	depth, start := 0, 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+		default:
+			if depth == 0 && i+5 <= len(s) && strings.EqualFold(s[i:i+5], " and ") {
+				names = append(names, strings.TrimSpace(s[start:i]))
+				start = i + 5
+				i += 4
+			}
+		}
+	}
+	names = append(names, strings.TrimSpace(s[start:]))
+	l := []string{}
+	for _, name := range names {
+		if name != "" {
+			l = append(l, name)
+		}
+	}
+	return l
+}
+
+// bibTeXCreator converts a BibTeX name to a creator. Names wrapped in
+// braces are organizations, "Family, Given" and "Given Family" forms
+// are supported for people.
+func bibTeXCreator(name string, role string) *Creator {
+	name = strings.TrimSpace(name)
+	if strings.HasPrefix(name, "{") && strings.HasSuffix(name, "}") && !strings.Contains(name[1:len(name)-1], "{") {
+		return newOrganization(bibTeXText(name), role)
+	}
+	if family, given, ok := strings.Cut(name, ","); ok {
+		// "von Last, Jr, First" puts the first name last.
+		if last, first, ok := strings.Cut(given, ","); ok {
+			given = first
+			family = family + ", " + strings.TrimSpace(last)
+		}
+		return newPerson(bibTeXText(family), bibTeXText(given), role)
+	}
+	words := strings.Fields(name)
+	if len(words) == 1 {
+		return newPerson(bibTeXText(words[0]), "", role)
+	}
+	// A "von" part starts with a lower case word.
+	i := len(words) - 1
+	for j := 1; j < len(words)-1; j++ {
+		if r := []rune(words[j])[0]; unicode.IsLower(r) {
+			i = j
+			break
+		}
+	}
+	return newPerson(bibTeXText(strings.Join(words[i:], " ")), bibTeXText(strings.Join(words[:i], " ")), role)
+}
+
+// recordFromBibTeX converts a parsed entry into a Record.
+func recordFromBibTeX(entry *bibTeXEntry) *Record {
+	rec := new(Record)
+	m := ensureMetadata(rec)
+	resourceType, ok := bibTeXResourceTypes[entry.Type]
+	if !ok {
+		resourceType = "other"
+	}
+	setResourceType(rec, resourceType)
+	f := func(name string) string {
+		return bibTeXText(entry.Fields[name])
+	}
+	m.Title = f("title")
+	for _, name := range splitBibTeXNames(entry.Fields["author"]) {
+		m.Creators = append(m.Creators, bibTeXCreator(name, ""))
+	}
+	for _, name := range splitBibTeXNames(entry.Fields["editor"]) {
+		editor := bibTeXCreator(name, "editor")
+		if len(m.Creators) == 0 {
+			m.Creators = append(m.Creators, editor)
+		} else {
+			m.Contributors = append(m.Contributors, editor)
+		}
+	}
+	month := f("month")
+	if n, ok := monthNumber(month); ok {
+		month = fmt.Sprintf("%02d", n)
+	} else {
+		for i, name := range bibTeXMonths {
+			if strings.HasPrefix(strings.ToLower(month), name) {
+				month = fmt.Sprintf("%02d", i+1)
+			}
+		}
+		if _, ok := monthNumber(month); !ok {
+			month = ""
+		}
+	}
+	m.PublicationDate = joinDate(f("year"), month, "")
+	if date := f("date"); m.PublicationDate == "" && date != "" {
+		m.PublicationDate = date
+	}
+	for _, name := range []string{"publisher", "school", "institution", "organization"} {
+		if s := f(name); s != "" {
+			m.Publisher = s
+			break
+		}
+	}
+	m.Version = f("version")
+	m.Description = f("abstract")
+	for _, keyword := range strings.FieldsFunc(f("keywords"), func(r rune) bool { return r == ',' || r == ';' }) {
+		if keyword = strings.TrimSpace(keyword); keyword != "" {
+			m.Subjects = append(m.Subjects, &Subject{Subject: keyword})
+		}
+	}
+	journalTitle := f("journal")
+	if journalTitle == "" {
+		journalTitle = f("journaltitle")
+	}
+	if journalTitle == "" {
+		journalTitle = f("booktitle")
+	}
+	setJournalField(rec, "title", journalTitle)
+	setJournalField(rec, "volume", f("volume"))
+	issue := f("number")
+	if issue == "" {
+		issue = f("issue")
+	}
+	setJournalField(rec, "issue", issue)
+	setJournalField(rec, "pages", strings.ReplaceAll(f("pages"), "–", "-"))
+	setJournalField(rec, "issn", f("issn"))
+	setRecordDOI(rec, f("doi"))
+	m.Identifiers = addIdentifier(m.Identifiers, "isbn", f("isbn"))
+	m.Identifiers = addIdentifier(m.Identifiers, "url", f("url"))
+	return rec
+}
+
+// ParseBibTeX parses BibTeX source returning a Record for each entry.
+// @string macros and the standard month macros are expanded, @comment
+// and @preamble entries are skipped.
+//
+// ```
+//
+//	src, err := os.ReadFile("references.bib")
+//	// ... handle error ...
+//	records, err := simplified.ParseBibTeX(src)
+//	// ... handle error ...
+//	for _, rec := range records {
+//	    fmt.Printf("%s\n", rec.Metadata.Title)
+//	}
+//
+// ```
+func ParseBibTeX(src []byte) ([]*Record, error) {
+	entries, err := parseBibTeXEntries(src)
+	if err != nil {
+		return nil, err
+	}
+	records := []*Record{}
+	for _, entry := range entries {
+		records = append(records, recordFromBibTeX(entry))
+	}
+	return records, nil
+}
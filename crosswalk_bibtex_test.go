@@ -0,0 +1,151 @@
+package simplified
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAsBibTeX(t *testing.T) {
+	rec := testRecord(t)
+	rec.Metadata.Creators = append(rec.Metadata.Creators, newOrganization("Caltech R&D Group", ""))
+	src := string(rec.AsBibTeX())
+	for _, expected := range []string{
+		"@article{Orphan2023,",
+		"author = {Orphan, Victoria J. and {Caltech R\\&D Group}}",
+		"title = {Microbially induced precipitation of silica}",
+		"journal = {PNAS}",
+		"year = {2023}",
+		"month = dec",
+		"volume = {120}",
+		"number = {51}",
+		"publisher = {National Academy of Sciences}",
+		"doi = {10.1073/pnas.2302156120}",
+		"issn = {1091-6490}",
+		"abstract = {An abstract.}",
+		"keywords = {Multidisciplinary}",
+	} {
+		if !strings.Contains(src, expected) {
+			t.Errorf("expected %q in BibTeX ->\n%s", expected, src)
+		}
+	}
+	if strings.Contains(src, "Caltech Library") {
+		t.Errorf("expected hosting institution contributor to be left out ->\n%s", src)
+	}
+	setResourceType(rec, "publication-thesis")
+	if src := string(rec.AsBibTeX()); !strings.HasPrefix(src, "@phdthesis{") || !strings.Contains(src, "school = {National Academy of Sciences}") {
+		t.Errorf("expected a phdthesis with a school ->\n%s", src)
+	}
+	setResourceType(rec, "image-photo")
+	if src := string(rec.AsBibTeX()); !strings.HasPrefix(src, "@misc{") {
+		t.Errorf("expected misc for an unmapped resource type ->\n%s", src)
+	}
+}
+
+func TestParseBibTeX(t *testing.T) {
+	src := []byte(`This text is ignored.
+@string{pnas = "Proceedings of the National Academy of Sciences"}
+@comment{ @article{skipped, title = {Skipped}} }
+@preamble{"\newcommand{\noop}[1]{}"}
+
+@Article{orphan2023,
+  author = {Orphan, Victoria J. and Ludwig van Beethoven and {Caltech Library}},
+  title = {Microbially induced {P}recipitation of \emph{silica} in G{\"o}ttingen},
+  journal = pnas,
+  year = 2023,
+  month = dec,
+  volume = "120",
+  number = {51},
+  pages = {15--23},
+  doi = {https://doi.org/10.1073/PNAS.2302156120},
+  keywords = {geobiology, silica},
+  abstract = "50\% of " # "the {"}silica{"}"
+}
+
+@techreport(report1,
+  title = {A Report},
+  author = {Doe, Jr, Jane},
+  institution = {Caltech},
+  year = {2020}
+)
+`)
+	records, err := ParseBibTeX(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("expected 2 records, got %d", len(records))
+	}
+	rec := records[0]
+	m := rec.Metadata
+	if id := resourceTypeID(rec); id != "publication-article" {
+		t.Errorf("expected publication-article, got %q", id)
+	}
+	if expected := "Microbially induced Precipitation of silica in Göttingen"; m.Title != expected {
+		t.Errorf("expected title %q, got %q", expected, m.Title)
+	}
+	if len(m.Creators) != 3 {
+		t.Fatalf("expected 3 creators, got %d", len(m.Creators))
+	}
+	for i, expected := range [][2]string{{"Orphan", "Victoria J."}, {"van Beethoven", "Ludwig"}} {
+		p := m.Creators[i].PersonOrOrg
+		if p.FamilyName != expected[0] || p.GivenName != expected[1] {
+			t.Errorf("expected creator %d to be %q, %q, got %q, %q", i, expected[0], expected[1], p.FamilyName, p.GivenName)
+		}
+	}
+	if p := m.Creators[2].PersonOrOrg; p.Type != "organizational" || p.Name != "Caltech Library" {
+		t.Errorf("expected an organization, got %+v", p)
+	}
+	if m.PublicationDate != "2023-12" {
+		t.Errorf("expected publication date 2023-12, got %q", m.PublicationDate)
+	}
+	journal := journalFields(rec)
+	for k, v := range map[string]string{"title": "Proceedings of the National Academy of Sciences", "volume": "120", "issue": "51", "pages": "15-23"} {
+		if journal[k] != v {
+			t.Errorf("expected journal %s %q, got %q", k, v, journal[k])
+		}
+	}
+	if doi := recordDOI(rec); doi != "10.1073/pnas.2302156120" {
+		t.Errorf("expected normalized DOI, got %q", doi)
+	}
+	if len(m.Subjects) != 2 || m.Subjects[1].Subject != "silica" {
+		t.Errorf("expected two keywords, got %+v", m.Subjects)
+	}
+	if expected := `50% of the "silica"`; m.Description != expected {
+		t.Errorf("expected abstract %q, got %q", expected, m.Description)
+	}
+
+	rec = records[1]
+	if id := resourceTypeID(rec); id != "publication-report" || rec.Metadata.Publisher != "Caltech" {
+		t.Errorf("expected a report published by Caltech, got %q, %q", id, rec.Metadata.Publisher)
+	}
+	if p := rec.Metadata.Creators[0].PersonOrOrg; p.FamilyName != "Doe, Jr" || p.GivenName != "Jane" {
+		t.Errorf("expected Doe, Jr, Jane, got %q, %q", p.FamilyName, p.GivenName)
+	}
+
+	if _, err := ParseBibTeX([]byte(`@article{broken, title = {Unterminated`)); err == nil {
+		t.Errorf("expected an error for an unterminated entry")
+	}
+}
+
+func TestBibTeXRoundTrip(t *testing.T) {
+	rec := testRecord(t)
+	records, err := ParseBibTeX(rec.AsBibTeX())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 1 {
+		t.Fatalf("expected one record, got %d", len(records))
+	}
+	got := records[0]
+	if got.Metadata.Title != rec.Metadata.Title || got.Metadata.PublicationDate != "2023-12" {
+		t.Errorf("expected title and date to round trip, got %q, %q", got.Metadata.Title, got.Metadata.PublicationDate)
+	}
+	if !sameList(got.Metadata.Creators, rec.Metadata.Creators, func(a *Creator, b *Creator) bool {
+		return a.PersonOrOrg.FamilyName == b.PersonOrOrg.FamilyName && a.PersonOrOrg.GivenName == b.PersonOrOrg.GivenName
+	}) {
+		t.Errorf("expected creators to round trip")
+	}
+	if recordDOI(got) != recordDOI(rec) {
+		t.Errorf("expected DOI to round trip, got %q", recordDOI(got))
+	}
+}
@@ -0,0 +1,306 @@
+package simplified
+
+/**
+ * This file implements a crosswalk between the simplified Record and
+ * the Citation File Format (CITATION.cff) version 1.2.0. See
+ * https://github.com/citation-file-format/citation-file-format
+ *
+ * Licenses, repository links and the code repository relation are
+ * handled as in the CodeMeta crosswalk.
+ */
+
+import (
+	"fmt"
+	"strings"
+
+	// 3rd Party Packages
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// CFFVersion is the version of the Citation File Format written.
+	CFFVersion = "1.2.0"
+	// cffMessage is the default message of a CITATION.cff file.
+	cffMessage = "If you use this software, please cite it as below."
+)
+
+// cffText is a scalar read as text, YAML would otherwise read a
+// version of 1.10 as a number and a date as a timestamp.
+type cffText string
+
+// UnmarshalYAML reads any scalar as its text.
+func (s *cffText) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind != yaml.ScalarNode {
+		return fmt.Errorf("line %d, expected a scalar", value.Line)
+	}
+	*s = cffText(strings.TrimSpace(value.Value))
+	return nil
+}
+
+// cffLicenses is a license, or a list of licenses, as SPDX ids.
+type cffLicenses []string
+
+// UnmarshalYAML reads a single license or a list of licenses.
+func (l *cffLicenses) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		*l = cffLicenses{strings.TrimSpace(value.Value)}
+		return nil
+	}
+	values := []string{}
+	if err := value.Decode(&values); err != nil {
+		return err
+	}
+	*l = cffLicenses(values)
+	return nil
+}
+
+// MarshalYAML writes a single license as a scalar.
+func (l cffLicenses) MarshalYAML() (interface{}, error) {
+	if len(l) == 1 {
+		return l[0], nil
+	}
+	return []string(l), nil
+}
+
+// cffPerson is a person or, when Name is set, an entity.
+type cffPerson struct {
+	FamilyNames  cffText `yaml:"family-names,omitempty"`
+	GivenNames   cffText `yaml:"given-names,omitempty"`
+	NameParticle cffText `yaml:"name-particle,omitempty"`
+	NameSuffix   cffText `yaml:"name-suffix,omitempty"`
+	Name         cffText `yaml:"name,omitempty"`
+	Affiliation  cffText `yaml:"affiliation,omitempty"`
+	ORCID        cffText `yaml:"orcid,omitempty"`
+	Email        cffText `yaml:"email,omitempty"`
+}
+
+type cffIdentifier struct {
+	Type        cffText `yaml:"type"`
+	Value       cffText `yaml:"value"`
+	Description cffText `yaml:"description,omitempty"`
+}
+
+// cff is a CITATION.cff file.
+type cff struct {
+	CFFVersion         cffText          `yaml:"cff-version"`
+	Message            cffText          `yaml:"message"`
+	Type               cffText          `yaml:"type,omitempty"`
+	Title              cffText          `yaml:"title"`
+	Abstract           cffText          `yaml:"abstract,omitempty"`
+	Authors            []*cffPerson     `yaml:"authors"`
+	Version            cffText          `yaml:"version,omitempty"`
+	DOI                cffText          `yaml:"doi,omitempty"`
+	Identifiers        []*cffIdentifier `yaml:"identifiers,omitempty"`
+	License            cffLicenses      `yaml:"license,omitempty"`
+	LicenseURL         cffText          `yaml:"license-url,omitempty"`
+	RepositoryCode     cffText          `yaml:"repository-code,omitempty"`
+	RepositoryArtifact cffText          `yaml:"repository-artifact,omitempty"`
+	Repository         cffText          `yaml:"repository,omitempty"`
+	URL                cffText          `yaml:"url,omitempty"`
+	Keywords           []string         `yaml:"keywords,omitempty"`
+	DateReleased       cffText          `yaml:"date-released,omitempty"`
+}
+
+//
+// Record to CFF
+//
+
+// cffPersonOf converts a creator into a CFF person or entity. The
+// ORCID is written as a URL as required by the schema.
+func cffPersonOf(c *Creator) *cffPerson {
+	p := c.PersonOrOrg
+	person := new(cffPerson)
+	if isOrganization(p) {
+		person.Name = cffText(p.Name)
+	} else {
+		family, given := personNames(p)
+		person.FamilyNames, person.GivenNames = cffText(family), cffText(given)
+		if orcid := personIdentifier(p, "orcid"); orcid != "" {
+			person.ORCID = cffText(dublinCoreIdentifier("orcid", orcid))
+		}
+	}
+	affiliations := []string{}
+	for _, a := range c.Affiliations {
+		if a != nil && a.Name != "" {
+			affiliations = append(affiliations, a.Name)
+		}
+	}
+	person.Affiliation = cffText(strings.Join(affiliations, "; "))
+	return person
+}
+
+// asCFF maps the record to the CFF structures.
+func (rec *Record) asCFF() *cff {
+	m := rec.Metadata
+	if m == nil {
+		m = new(Metadata)
+	}
+	doc := &cff{CFFVersion: CFFVersion, Message: cffMessage, Type: "software"}
+	if strings.HasPrefix(resourceTypeID(rec), "dataset") {
+		doc.Type = "dataset"
+	}
+	doc.Title = cffText(m.Title)
+	doc.Abstract = cffText(stripHTML(m.Description))
+	for _, c := range m.Creators {
+		if c != nil && c.PersonOrOrg != nil {
+			doc.Authors = append(doc.Authors, cffPersonOf(c))
+		}
+	}
+	doc.Version = cffText(m.Version)
+	doc.DOI = cffText(recordDOI(rec))
+	for _, id := range m.Identifiers {
+		if id == nil || id.Identifier == "" {
+			continue
+		}
+		switch scheme := strings.ToLower(id.Scheme); scheme {
+		case "url":
+			if doc.URL == "" {
+				doc.URL = cffText(id.Identifier)
+				continue
+			}
+			doc.Identifiers = append(doc.Identifiers, &cffIdentifier{Type: "url", Value: cffText(id.Identifier)})
+		case "swh":
+			doc.Identifiers = append(doc.Identifiers, &cffIdentifier{Type: "swh", Value: cffText(id.Identifier)})
+		case "doi":
+		default:
+			doc.Identifiers = append(doc.Identifiers, &cffIdentifier{Type: "other", Value: cffText(id.Identifier), Description: cffText(scheme)})
+		}
+	}
+	for _, r := range m.Rights {
+		if id := rightSPDX(r); id != "" {
+			doc.License = append(doc.License, id)
+		} else if r != nil && r.Link != "" && doc.LicenseURL == "" {
+			doc.LicenseURL = cffText(r.Link)
+		}
+	}
+	doc.RepositoryCode = cffText(codeRepository(rec))
+	doc.Keywords = subjectLabels(m.Subjects)
+	doc.DateReleased = cffText(m.PublicationDate)
+	return doc
+}
+
+// AsCFF renders the record as a CITATION.cff file. Creators become
+// authors with ORCID URLs and affiliations, rights become SPDX license
+// ids and the related URL with the relation "issupplementto" becomes
+// "repository-code".
+//
+// ```
+//
+//	src, err := rec.AsCFF()
+//	// ... handle error ...
+//	os.WriteFile("CITATION.cff", src, 0664)
+//
+// ```
+func (rec *Record) AsCFF() ([]byte, error) {
+	if rec == nil {
+		return nil, fmt.Errorf("no record")
+	}
+	return yaml.Marshal(rec.asCFF())
+}
+
+//
+// CFF to Record
+//
+
+// creator converts a CFF person or entity into a Creator.
+func (person *cffPerson) creator() *Creator {
+	var c *Creator
+	if person.FamilyNames == "" && person.GivenNames == "" {
+		c = newOrganization(string(person.Name), "")
+	} else {
+		family := strings.TrimSpace(string(person.NameParticle + " " + person.FamilyNames))
+		if person.NameSuffix != "" {
+			family += " " + string(person.NameSuffix)
+		}
+		c = newPerson(family, string(person.GivenNames), "")
+	}
+	if scheme, value := dublinCoreSchemeID(string(person.ORCID)); value != "" {
+		if scheme == "url" {
+			scheme = "orcid"
+		}
+		addPersonIdentifier(c, scheme, value)
+	}
+	for _, a := range strings.Split(string(person.Affiliation), ";") {
+		addAffiliation(c, a, "")
+	}
+	return c
+}
+
+// asRecord maps the CFF structures to a Record.
+func (doc *cff) asRecord() *Record {
+	rec := new(Record)
+	m := ensureMetadata(rec)
+	if doc.Type == "dataset" {
+		setResourceType(rec, "dataset")
+	} else {
+		setResourceType(rec, "software")
+	}
+	m.Title = string(doc.Title)
+	m.Description = string(doc.Abstract)
+	for _, person := range doc.Authors {
+		if person != nil {
+			m.Creators = append(m.Creators, person.creator())
+		}
+	}
+	m.Version = string(doc.Version)
+	if doc.DOI != "" {
+		setRecordDOI(rec, string(doc.DOI))
+	}
+	if doc.URL != "" {
+		m.Identifiers = addIdentifier(m.Identifiers, "url", string(doc.URL))
+	}
+	for _, id := range doc.Identifiers {
+		if id == nil || id.Value == "" {
+			continue
+		}
+		scheme := string(id.Type)
+		switch {
+		case scheme == "doi" && recordDOI(rec) == "":
+			setRecordDOI(rec, string(id.Value))
+			continue
+		case scheme == "other" && id.Description != "" && !strings.Contains(string(id.Description), " "):
+			scheme = string(id.Description)
+		}
+		m.Identifiers = addIdentifier(m.Identifiers, scheme, string(id.Value))
+	}
+	for _, license := range doc.License {
+		if r := spdxRight(license); r != nil {
+			m.Rights = append(m.Rights, r)
+		}
+	}
+	if doc.LicenseURL != "" {
+		if len(m.Rights) == 1 {
+			m.Rights[0].Link = string(doc.LicenseURL)
+		} else {
+			m.Rights = append(m.Rights, spdxRight(string(doc.LicenseURL)))
+		}
+	}
+	for _, k := range doc.Keywords {
+		if k = strings.TrimSpace(k); k != "" {
+			m.Subjects = append(m.Subjects, &Subject{Subject: k})
+		}
+	}
+	addRelatedURL(rec, string(doc.RepositoryCode), "issupplementto")
+	addRelatedURL(rec, string(doc.RepositoryArtifact), "references")
+	addRelatedURL(rec, string(doc.Repository), "references")
+	m.PublicationDate = string(doc.DateReleased)
+	return rec
+}
+
+// RecordFromCFF converts a CITATION.cff file into a Record.
+//
+// ```
+//
+//	src, err := os.ReadFile("CITATION.cff")
+//	// ... handle error ...
+//	rec, err := simplified.RecordFromCFF(src)
+//	// ... handle error ...
+//
+// ```
+func RecordFromCFF(src []byte) (*Record, error) {
+	doc := new(cff)
+	if err := yaml.Unmarshal(src, doc); err != nil {
+		return nil, fmt.Errorf("cff, %s", err)
+	}
+	return doc.asRecord(), nil
+}
@@ -0,0 +1,130 @@
+package simplified
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestRecordFromCFF(t *testing.T) {
+	src, err := os.ReadFile("CITATION.cff")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec, err := RecordFromCFF(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := rec.Metadata
+	if resourceTypeID(rec) != "software" || m.Title != "simplified" || m.Version == "" || len(m.PublicationDate) != 10 {
+		t.Errorf("unexpected record %s", rec.ToString())
+	}
+	if len(m.Creators) != 1 || m.Creators[0].PersonOrOrg.FamilyName != "Doiel" || len(m.Creators[0].PersonOrOrg.Identifiers) != 0 {
+		t.Errorf("unexpected creators %s", rec.ToString())
+	}
+	if len(m.Rights) != 1 || m.Rights[0].ID != "bsd-3-clause-lbnl" {
+		t.Errorf("unexpected rights %s", rec.ToString())
+	}
+	if len(m.Subjects) != 3 || codeRepository(rec) != "https://github.com/caltechlibrary/simplified" {
+		t.Errorf("unexpected keywords or repository %s", rec.ToString())
+	}
+
+	rec, err = RecordFromCFF([]byte(`cff-version: 1.2.0
+message: Please cite
+title: tool
+version: 1.10
+doi: 10.5281/zenodo.1234
+license: [MIT, Apache-2.0]
+authors:
+  - given-names: Ludwig
+    name-particle: van
+    family-names: Beethoven
+    orcid: https://orcid.org/0000-0001-2345-6789
+    affiliation: Caltech
+  - name: Caltech Library
+identifiers:
+  - type: swh
+    value: swh:1:rel:99f6850374dc6597af01bd0ee1d3fc0699301b9f
+date-released: 2024-01-02
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m = rec.Metadata
+	if m.Version != "1.10" || m.PublicationDate != "2024-01-02" || recordDOI(rec) != "10.5281/zenodo.1234" {
+		t.Errorf("unexpected record %s", rec.ToString())
+	}
+	if len(m.Creators) != 2 || m.Creators[0].PersonOrOrg.FamilyName != "van Beethoven" || personIdentifier(m.Creators[0].PersonOrOrg, "orcid") != "0000-0001-2345-6789" || !isOrganization(m.Creators[1].PersonOrOrg) {
+		t.Errorf("unexpected creators %s", rec.ToString())
+	}
+	if len(m.Rights) != 2 || m.Rights[1].ID != "apache-2.0" || identifierValue(m.Identifiers, "swh") == "" {
+		t.Errorf("unexpected rights or identifiers %s", rec.ToString())
+	}
+	if _, err := RecordFromCFF([]byte("title: [")); err == nil {
+		t.Errorf("expected an error for invalid YAML")
+	}
+}
+
+func TestAsCFF(t *testing.T) {
+	src, err := os.ReadFile("codemeta.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec1, err := RecordFromCodeMeta(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	src, err = rec1.AsCFF()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{
+		"cff-version: 1.2.0\n",
+		"type: software\n",
+		"title: simplified\n",
+		"family-names: Doiel\n",
+		"orcid: https://orcid.org/0000-0003-0900-6903\n",
+		"affiliation: Caltech Library\n",
+		"license: BSD-3-Clause-LBNL\n",
+		"repository-code: https://github.com/caltechlibrary/simplified\n",
+	} {
+		if !strings.Contains(string(src), expected) {
+			t.Errorf("expected %q in CFF ->\n%s", expected, src)
+		}
+	}
+	rec2, err := RecordFromCFF(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m1, m2 := rec1.Metadata, rec2.Metadata
+	if m1.Title != m2.Title || m1.Version != m2.Version || m1.PublicationDate != m2.PublicationDate || codeRepository(rec2) != codeRepository(rec1) {
+		t.Errorf("expected title, version, date and repository to round trip\n%s", rec2.ToString())
+	}
+	if len(m2.Creators) != 1 || !sameCreatorNames(m1.Creators[0], m2.Creators[0]) {
+		t.Errorf("expected creators to round trip\n%s", rec2.ToString())
+	}
+	if len(m2.Rights) != 1 || m2.Rights[0].ID != m1.Rights[0].ID || len(m2.Subjects) != len(m1.Subjects) {
+		t.Errorf("expected license and keywords to round trip\n%s", rec2.ToString())
+	}
+}
+
+func TestCFFLicense(t *testing.T) {
+	rec := testRecord(t)
+	rec.Metadata.Rights = []*Right{
+		{ID: "lgpl-2.1-or-later"},
+		{ID: "zlib"},
+		{ID: "caltech-custom", Link: "https://example.edu/license"},
+	}
+	src, err := rec.AsCFF()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{"- LGPL-2.1-or-later\n", "- Zlib\n", "license-url: https://example.edu/license\n"} {
+		if !strings.Contains(string(src), expected) {
+			t.Errorf("expected %q in CFF ->\n%s", expected, src)
+		}
+	}
+	if strings.Contains(strings.ToUpper(string(src)), "CALTECH-CUSTOM") {
+		t.Errorf("did not expect an SPDX id for an unknown license ->\n%s", src)
+	}
+}
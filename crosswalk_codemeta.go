@@ -0,0 +1,603 @@
+package simplified
+
+/**
+ * This file implements a crosswalk between the simplified Record and
+ * CodeMeta JSON-LD (codemeta.json) describing software. See
+ * https://codemeta.github.io/terms/
+ *
+ * CodeMeta files are written by hand so the reader is lenient, e.g. a
+ * single author may be an object rather than an array and keywords may
+ * be a comma separated string. The code repository becomes a related
+ * identifier with the relation "issupplementto" as in the InvenioRDM
+ * GitHub integration.
+ */
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// CodeMetaContext is the JSON-LD context written by AsCodeMeta.
+const CodeMetaContext = "https://w3id.org/codemeta/3.0"
+
+// spdxURL is the URL prefix of the SPDX license list.
+const spdxURL = "https://spdx.org/licenses/"
+
+// spdxIDs maps lowercase license ids, as used by the InvenioRDM
+// licenses vocabulary, to SPDX license ids. Only the ids listed here
+// are written as SPDX ids.
+var spdxIDs = map[string]string{
+	"0bsd":                "0BSD",
+	"afl-3.0":             "AFL-3.0",
+	"agpl-3.0-only":       "AGPL-3.0-only",
+	"agpl-3.0-or-later":   "AGPL-3.0-or-later",
+	"apache-1.1":          "Apache-1.1",
+	"apache-2.0":          "Apache-2.0",
+	"artistic-2.0":        "Artistic-2.0",
+	"bsd-1-clause":        "BSD-1-Clause",
+	"bsd-2-clause":        "BSD-2-Clause",
+	"bsd-2-clause-patent": "BSD-2-Clause-Patent",
+	"bsd-3-clause":        "BSD-3-Clause",
+	"bsd-3-clause-clear":  "BSD-3-Clause-Clear",
+	"bsd-3-clause-lbnl":   "BSD-3-Clause-LBNL",
+	"bsd-4-clause":        "BSD-4-Clause",
+	"bsl-1.0":             "BSL-1.0",
+	"cc-by-1.0":           "CC-BY-1.0",
+	"cc-by-2.0":           "CC-BY-2.0",
+	"cc-by-2.5":           "CC-BY-2.5",
+	"cc-by-3.0":           "CC-BY-3.0",
+	"cc-by-4.0":           "CC-BY-4.0",
+	"cc-by-nc-4.0":        "CC-BY-NC-4.0",
+	"cc-by-nc-nd-4.0":     "CC-BY-NC-ND-4.0",
+	"cc-by-nc-sa-4.0":     "CC-BY-NC-SA-4.0",
+	"cc-by-nd-4.0":        "CC-BY-ND-4.0",
+	"cc-by-sa-3.0":        "CC-BY-SA-3.0",
+	"cc-by-sa-4.0":        "CC-BY-SA-4.0",
+	"cc-pddc":             "CC-PDDC",
+	"cc0-1.0":             "CC0-1.0",
+	"cddl-1.0":            "CDDL-1.0",
+	"cddl-1.1":            "CDDL-1.1",
+	"cecill-2.1":          "CECILL-2.1",
+	"ecl-2.0":             "ECL-2.0",
+	"epl-1.0":             "EPL-1.0",
+	"epl-2.0":             "EPL-2.0",
+	"eupl-1.1":            "EUPL-1.1",
+	"eupl-1.2":            "EUPL-1.2",
+	"gfdl-1.3-only":       "GFDL-1.3-only",
+	"gfdl-1.3-or-later":   "GFDL-1.3-or-later",
+	"gpl-2.0-only":        "GPL-2.0-only",
+	"gpl-2.0-or-later":    "GPL-2.0-or-later",
+	"gpl-3.0-only":        "GPL-3.0-only",
+	"gpl-3.0-or-later":    "GPL-3.0-or-later",
+	"isc":                 "ISC",
+	"lgpl-2.1-only":       "LGPL-2.1-only",
+	"lgpl-2.1-or-later":   "LGPL-2.1-or-later",
+	"lgpl-3.0-only":       "LGPL-3.0-only",
+	"lgpl-3.0-or-later":   "LGPL-3.0-or-later",
+	"lppl-1.3c":           "LPPL-1.3c",
+	"mit":                 "MIT",
+	"mit-0":               "MIT-0",
+	"mpl-1.1":             "MPL-1.1",
+	"mpl-2.0":             "MPL-2.0",
+	"ms-pl":               "MS-PL",
+	"ms-rl":               "MS-RL",
+	"ncsa":                "NCSA",
+	"odc-by-1.0":          "ODC-By-1.0",
+	"odbl-1.0":            "ODbL-1.0",
+	"ofl-1.1":             "OFL-1.1",
+	"osl-3.0":             "OSL-3.0",
+	"pddl-1.0":            "PDDL-1.0",
+	"postgresql":          "PostgreSQL",
+	"python-2.0":          "Python-2.0",
+	"unlicense":           "Unlicense",
+	"upl-1.0":             "UPL-1.0",
+	"vim":                 "Vim",
+	"w3c":                 "W3C",
+	"wtfpl":               "WTFPL",
+	"zlib":                "Zlib",
+	"zpl-2.1":             "ZPL-2.1",
+}
+
+// spdxRight returns a Right for an SPDX license id or URL. The id is
+// the lowercase SPDX id as used by the InvenioRDM licenses vocabulary.
+func spdxRight(license string) *Right {
+	license = strings.TrimSpace(license)
+	if license == "" {
+		return nil
+	}
+	if strings.HasPrefix(license, "http://") || strings.HasPrefix(license, "https://") {
+		r := &Right{Link: license}
+		for _, prefix := range []string{spdxURL, strings.Replace(spdxURL, "https:", "http:", 1)} {
+			if id, ok := strings.CutPrefix(license, prefix); ok {
+				r.ID = strings.ToLower(strings.TrimSuffix(strings.TrimSuffix(id, ".html"), ".json"))
+			}
+		}
+		return r
+	}
+	if id, ok := spdxIDs[strings.ToLower(license)]; ok {
+		return &Right{ID: strings.ToLower(id), Link: spdxURL + id}
+	}
+	return &Right{ID: strings.ToLower(license)}
+}
+
+// rightSPDX returns the SPDX license id of a Right, or an empty string
+// if it is not in spdxIDs.
+func rightSPDX(r *Right) string {
+	if r == nil {
+		return ""
+	}
+	if id, ok := spdxIDs[strings.ToLower(r.ID)]; ok {
+		return id
+	}
+	if id, ok := strings.CutPrefix(r.Link, spdxURL); ok {
+		return spdxIDs[strings.ToLower(strings.TrimSuffix(strings.TrimSuffix(id, ".html"), ".json"))]
+	}
+	return ""
+}
+
+// codeMetaText is a text property which may have been written as a
+// number, e.g. a version of 1.0.
+type codeMetaText string
+
+// UnmarshalJSON accepts a string or a number.
+func (s *codeMetaText) UnmarshalJSON(src []byte) error {
+	var v interface{}
+	if err := json.Unmarshal(src, &v); err != nil {
+		return err
+	}
+	switch v := v.(type) {
+	case string:
+		*s = codeMetaText(strings.TrimSpace(v))
+	case nil:
+		*s = ""
+	default:
+		*s = codeMetaText(strings.TrimSpace(string(src)))
+	}
+	return nil
+}
+
+// codeMetaValues holds a property which may be a single value or a
+// list. Objects contribute their "@id", "id", "url", "value" or "name".
+type codeMetaValues []string
+
+// UnmarshalJSON accepts a value, an object or a list of them.
+func (l *codeMetaValues) UnmarshalJSON(src []byte) error {
+	var v interface{}
+	if err := json.Unmarshal(src, &v); err != nil {
+		return err
+	}
+	values := []interface{}{v}
+	if a, ok := v.([]interface{}); ok {
+		values = a
+	}
+	*l = codeMetaValues{}
+	for _, item := range values {
+		switch item := item.(type) {
+		case string:
+			if s := strings.TrimSpace(item); s != "" {
+				*l = append(*l, s)
+			}
+		case float64:
+			*l = append(*l, fmt.Sprintf("%v", item))
+		case map[string]interface{}:
+			for _, key := range []string{"@id", "id", "url", "value", "name"} {
+				if s, ok := item[key].(string); ok && strings.TrimSpace(s) != "" {
+					*l = append(*l, strings.TrimSpace(s))
+					break
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// codeMetaAgent is a Person or Organization.
+type codeMetaAgent struct {
+	Type        string         `json:"type,omitempty"`
+	AtType      string         `json:"@type,omitempty"`
+	ID          string         `json:"id,omitempty"`
+	AtID        string         `json:"@id,omitempty"`
+	GivenName   codeMetaText   `json:"givenName,omitempty"`
+	FamilyName  codeMetaText   `json:"familyName,omitempty"`
+	Name        codeMetaText   `json:"name,omitempty"`
+	Email       string         `json:"email,omitempty"`
+	Affiliation codeMetaAgents `json:"affiliation,omitempty"`
+}
+
+// codeMetaAgents holds a single agent or a list of agents. An agent
+// given as a string is an organization name.
+type codeMetaAgents []*codeMetaAgent
+
+// UnmarshalJSON accepts an agent, a name or a list of them.
+func (l *codeMetaAgents) UnmarshalJSON(src []byte) error {
+	src = bytes.TrimSpace(src)
+	items := []json.RawMessage{}
+	if bytes.HasPrefix(src, []byte("[")) {
+		if err := json.Unmarshal(src, &items); err != nil {
+			return err
+		}
+	} else {
+		items = append(items, src)
+	}
+	*l = codeMetaAgents{}
+	for _, item := range items {
+		var name string
+		if err := json.Unmarshal(item, &name); err == nil {
+			if name = strings.TrimSpace(name); name != "" {
+				*l = append(*l, &codeMetaAgent{Type: "Organization", Name: codeMetaText(name)})
+			}
+			continue
+		}
+		agent := new(codeMetaAgent)
+		if err := json.Unmarshal(item, agent); err != nil {
+			return err
+		}
+		*l = append(*l, agent)
+	}
+	return nil
+}
+
+// codeMeta holds the CodeMeta properties used by the crosswalk.
+type codeMeta struct {
+	Context             interface{}    `json:"@context"`
+	Type                string         `json:"type,omitempty"`
+	AtType              string         `json:"@type,omitempty"`
+	Name                codeMetaText   `json:"name,omitempty"`
+	Description         string         `json:"description,omitempty"`
+	Identifier          codeMetaValues `json:"identifier,omitempty"`
+	Version             codeMetaText   `json:"version,omitempty"`
+	SoftwareVersion     codeMetaText   `json:"softwareVersion,omitempty"`
+	Author              codeMetaAgents `json:"author,omitempty"`
+	Contributor         codeMetaAgents `json:"contributor,omitempty"`
+	Publisher           codeMetaAgents `json:"publisher,omitempty"`
+	Funder              codeMetaAgents `json:"funder,omitempty"`
+	License             codeMetaValues `json:"license,omitempty"`
+	Keywords            codeMetaValues `json:"keywords,omitempty"`
+	CodeRepository      string         `json:"codeRepository,omitempty"`
+	URL                 string         `json:"url,omitempty"`
+	IssueTracker        string         `json:"issueTracker,omitempty"`
+	RelatedLink         codeMetaValues `json:"relatedLink,omitempty"`
+	DateCreated         string         `json:"dateCreated,omitempty"`
+	DateModified        string         `json:"dateModified,omitempty"`
+	DatePublished       string         `json:"datePublished,omitempty"`
+	ProgrammingLanguage codeMetaValues `json:"programmingLanguage,omitempty"`
+	DevelopmentStatus   string         `json:"developmentStatus,omitempty"`
+	ReleaseNotes        string         `json:"releaseNotes,omitempty"`
+}
+
+// repositoryURL removes the "git+" prefix used by package managers
+// from a repository URL.
+func repositoryURL(s string) string {
+	return strings.TrimPrefix(strings.TrimSpace(s), "git+")
+}
+
+// codeRepository returns the code repository of a record, the first
+// related URL with the relation "issupplementto".
+func codeRepository(rec *Record) string {
+	if rec == nil || rec.Metadata == nil {
+		return ""
+	}
+	for _, id := range rec.Metadata.RelatedIdentifiers {
+		if id != nil && strings.EqualFold(id.Scheme, "url") && id.RelationType != nil && id.RelationType.ID == "issupplementto" {
+			return id.Identifier
+		}
+	}
+	return ""
+}
+
+// addRelatedURL adds a related URL unless it is already present.
+func addRelatedURL(rec *Record, u string, relation string) {
+	if u = repositoryURL(u); u == "" {
+		return
+	}
+	m := ensureMetadata(rec)
+	for _, id := range m.RelatedIdentifiers {
+		if id != nil && id.Identifier == u {
+			return
+		}
+	}
+	m.RelatedIdentifiers = append(m.RelatedIdentifiers, &Identifier{Scheme: "url", Identifier: u, RelationType: &TypeDetail{ID: relation}})
+}
+
+//
+// Record to CodeMeta
+//
+
+// codeMetaAgentOf converts a creator or contributor into a CodeMeta
+// Person or Organization identified by its ORCID or ROR URL.
+func codeMetaAgentOf(c *Creator) *codeMetaAgent {
+	p := c.PersonOrOrg
+	if isOrganization(p) {
+		agent := &codeMetaAgent{Type: "Organization", Name: codeMetaText(p.Name)}
+		if ror := personIdentifier(p, "ror"); ror != "" {
+			agent.ID = dublinCoreIdentifier("ror", ror)
+		}
+		return agent
+	}
+	family, given := personNames(p)
+	agent := &codeMetaAgent{Type: "Person", GivenName: codeMetaText(given), FamilyName: codeMetaText(family)}
+	if orcid := personIdentifier(p, "orcid"); orcid != "" {
+		agent.ID = dublinCoreIdentifier("orcid", orcid)
+	}
+	for _, a := range c.Affiliations {
+		if a != nil && a.Name != "" {
+			org := &codeMetaAgent{Type: "Organization", Name: codeMetaText(a.Name)}
+			if a.ID != "" {
+				org.ID = dublinCoreIdentifier("ror", a.ID)
+			}
+			agent.Affiliation = append(agent.Affiliation, org)
+		}
+	}
+	return agent
+}
+
+// asCodeMeta maps the record to the CodeMeta structures.
+func (rec *Record) asCodeMeta() *codeMeta {
+	m := rec.Metadata
+	if m == nil {
+		m = new(Metadata)
+	}
+	cm := &codeMeta{Context: CodeMetaContext, Type: "SoftwareSourceCode"}
+	cm.Name = codeMetaText(m.Title)
+	cm.Description = stripHTML(m.Description)
+	if doi := recordDOI(rec); doi != "" {
+		cm.Identifier = append(cm.Identifier, dublinCoreIdentifier("doi", doi))
+	}
+	cm.Version = codeMetaText(m.Version)
+	for _, c := range m.Creators {
+		if c != nil && c.PersonOrOrg != nil {
+			cm.Author = append(cm.Author, codeMetaAgentOf(c))
+		}
+	}
+	for _, c := range m.Contributors {
+		if c != nil && c.PersonOrOrg != nil {
+			cm.Contributor = append(cm.Contributor, codeMetaAgentOf(c))
+		}
+	}
+	if m.Publisher != "" {
+		cm.Publisher = codeMetaAgents{{Type: "Organization", Name: codeMetaText(m.Publisher)}}
+	}
+	for _, f := range m.Funding {
+		if f == nil || f.Funder == nil || f.Funder.Name == "" {
+			continue
+		}
+		funder := &codeMetaAgent{Type: "Organization", Name: codeMetaText(f.Funder.Name)}
+		if f.Funder.Identifier != "" && (f.Funder.Scheme == "" || strings.EqualFold(f.Funder.Scheme, "ror")) {
+			funder.ID = dublinCoreIdentifier("ror", f.Funder.Identifier)
+		}
+		cm.Funder = append(cm.Funder, funder)
+	}
+	for _, r := range m.Rights {
+		if id := rightSPDX(r); id != "" {
+			cm.License = append(cm.License, spdxURL+id)
+		} else if r != nil && r.Link != "" {
+			cm.License = append(cm.License, r.Link)
+		}
+	}
+	cm.Keywords = subjectLabels(m.Subjects)
+	cm.CodeRepository = codeRepository(rec)
+	for _, id := range m.RelatedIdentifiers {
+		if id != nil && strings.EqualFold(id.Scheme, "url") && id.Identifier != cm.CodeRepository {
+			cm.RelatedLink = append(cm.RelatedLink, id.Identifier)
+		}
+	}
+	cm.URL = identifierValue(m.Identifiers, "url")
+	for _, d := range m.Dates {
+		if d == nil || d.Type == nil {
+			continue
+		}
+		switch d.Type.ID {
+		case "created":
+			cm.DateCreated = d.Date
+		case "updated":
+			cm.DateModified = d.Date
+		}
+	}
+	cm.DatePublished = m.PublicationDate
+	if languages, ok := rec.CustomFields["code:programmingLanguage"].([]interface{}); ok {
+		for _, lang := range languages {
+			if lang, ok := lang.(map[string]interface{}); ok {
+				title, _ := lang["title"].(map[string]interface{})
+				if label := localizedInterface(title); label != "" {
+					cm.ProgrammingLanguage = append(cm.ProgrammingLanguage, label)
+				} else if id, ok := lang["id"].(string); ok {
+					cm.ProgrammingLanguage = append(cm.ProgrammingLanguage, id)
+				}
+			}
+		}
+	}
+	if status, ok := rec.CustomFields["code:developmentStatus"].(map[string]interface{}); ok {
+		cm.DevelopmentStatus, _ = status["id"].(string)
+	}
+	for _, d := range m.AdditionalDescriptions {
+		if d != nil && d.Type != nil && d.Type.ID == "technical-info" {
+			cm.ReleaseNotes = d.Description
+			break
+		}
+	}
+	return cm
+}
+
+// AsCodeMeta renders the record as a CodeMeta 3.0 codemeta.json file.
+// Creators become authors with ORCID URLs and affiliations, rights
+// become SPDX license URLs, subjects become keywords and the related
+// URL with the relation "issupplementto" becomes the code repository.
+//
+// ```
+//
+//	src, err := rec.AsCodeMeta()
+//	// ... handle error ...
+//	os.WriteFile("codemeta.json", src, 0664)
+//
+// ```
+func (rec *Record) AsCodeMeta() ([]byte, error) {
+	if rec == nil {
+		return nil, fmt.Errorf("no record")
+	}
+	return json.MarshalIndent(rec.asCodeMeta(), "", "    ")
+}
+
+//
+// CodeMeta to Record
+//
+
+// identifier splits an agent "@id" or "id" into a scheme and
+// value, e.g. an ORCID URL becomes "orcid" and the bare ORCID.
+func (a *codeMetaAgent) identifier() (string, string) {
+	id := a.AtID
+	if id == "" {
+		id = a.ID
+	}
+	if id == "" || strings.HasPrefix(id, "_:") {
+		return "", ""
+	}
+	return dublinCoreSchemeID(id)
+}
+
+// creator converts a CodeMeta agent into a Creator.
+func (a *codeMetaAgent) creator(role string) *Creator {
+	var c *Creator
+	agentType := a.Type
+	if agentType == "" {
+		agentType = a.AtType
+	}
+	family, given, name := string(a.FamilyName), string(a.GivenName), string(a.Name)
+	switch {
+	case strings.HasSuffix(agentType, "Organization"):
+		c = newOrganization(name, role)
+	case family != "" || given != "":
+		c = newPerson(family, given, role)
+	default:
+		if f, g, ok := strings.Cut(name, ","); ok {
+			c = newPerson(f, g, role)
+		} else if i := strings.LastIndex(name, " "); i > 0 {
+			c = newPerson(name[i+1:], name[0:i], role)
+		} else {
+			c = newPerson(name, "", role)
+		}
+	}
+	if scheme, value := a.identifier(); value != "" && scheme != "url" {
+		addPersonIdentifier(c, scheme, value)
+	}
+	for _, org := range a.Affiliation {
+		id := ""
+		if scheme, value := org.identifier(); scheme == "ror" {
+			id = value
+		}
+		addAffiliation(c, string(org.Name), id)
+	}
+	return c
+}
+
+// asRecord maps the CodeMeta structures to a Record.
+func (cm *codeMeta) asRecord() *Record {
+	rec := new(Record)
+	m := ensureMetadata(rec)
+	setResourceType(rec, "software")
+	m.Title = string(cm.Name)
+	m.Description = strings.TrimSpace(cm.Description)
+	m.Version = string(cm.Version)
+	if m.Version == "" {
+		m.Version = string(cm.SoftwareVersion)
+	}
+	for _, a := range cm.Author {
+		m.Creators = append(m.Creators, a.creator(""))
+	}
+	for _, a := range cm.Contributor {
+		m.Contributors = append(m.Contributors, a.creator("other"))
+	}
+	if len(cm.Publisher) > 0 {
+		m.Publisher = string(cm.Publisher[0].Name)
+	}
+	for _, a := range cm.Funder {
+		if a.Name == "" {
+			continue
+		}
+		funder := &FunderIdentifier{Name: string(a.Name)}
+		if scheme, value := a.identifier(); scheme == "ror" {
+			funder.Identifier = value
+		}
+		m.Funding = append(m.Funding, &Funder{Funder: funder})
+	}
+	for _, id := range cm.Identifier {
+		scheme, value := dublinCoreSchemeID(id)
+		if scheme == "doi" && recordDOI(rec) == "" {
+			setRecordDOI(rec, value)
+		} else if value != "" {
+			m.Identifiers = addIdentifier(m.Identifiers, scheme, value)
+		}
+	}
+	for _, license := range cm.License {
+		if r := spdxRight(license); r != nil {
+			m.Rights = append(m.Rights, r)
+		}
+	}
+	keywords := cm.Keywords
+	if len(keywords) == 1 && strings.Contains(keywords[0], ",") {
+		keywords = strings.Split(keywords[0], ",")
+	}
+	for _, k := range keywords {
+		if k = strings.TrimSpace(k); k != "" {
+			m.Subjects = append(m.Subjects, &Subject{Subject: k})
+		}
+	}
+	addRelatedURL(rec, cm.CodeRepository, "issupplementto")
+	addRelatedURL(rec, cm.IssueTracker, "references")
+	for _, u := range cm.RelatedLink {
+		addRelatedURL(rec, u, "references")
+	}
+	if cm.URL != "" {
+		m.Identifiers = addIdentifier(m.Identifiers, "url", cm.URL)
+	}
+	if cm.DateCreated != "" {
+		m.Dates = append(m.Dates, &DateType{Date: cm.DateCreated, Type: &Type{ID: "created"}})
+	}
+	if cm.DateModified != "" {
+		m.Dates = append(m.Dates, &DateType{Date: cm.DateModified, Type: &Type{ID: "updated"}})
+	}
+	m.PublicationDate = cm.DatePublished
+	if len(cm.ProgrammingLanguage) > 0 {
+		languages := []interface{}{}
+		for _, lang := range cm.ProgrammingLanguage {
+			languages = append(languages, map[string]interface{}{
+				"id":    strings.ToLower(lang),
+				"title": map[string]interface{}{"en": lang},
+			})
+		}
+		if rec.CustomFields == nil {
+			rec.CustomFields = map[string]interface{}{}
+		}
+		rec.CustomFields["code:programmingLanguage"] = languages
+	}
+	if cm.DevelopmentStatus != "" {
+		if rec.CustomFields == nil {
+			rec.CustomFields = map[string]interface{}{}
+		}
+		rec.CustomFields["code:developmentStatus"] = map[string]interface{}{"id": cm.DevelopmentStatus}
+	}
+	if notes := strings.TrimSpace(cm.ReleaseNotes); notes != "" {
+		m.AdditionalDescriptions = append(m.AdditionalDescriptions, &Description{Description: notes, Type: &Type{ID: "technical-info"}})
+	}
+	return rec
+}
+
+// RecordFromCodeMeta converts a codemeta.json file into a software
+// Record. CodeMeta 2.0 and 3.0 are read.
+//
+// ```
+//
+//	src, err := os.ReadFile("codemeta.json")
+//	// ... handle error ...
+//	rec, err := simplified.RecordFromCodeMeta(src)
+//	// ... handle error ...
+//
+// ```
+func RecordFromCodeMeta(src []byte) (*Record, error) {
+	cm := new(codeMeta)
+	if err := json.Unmarshal(src, cm); err != nil {
+		return nil, fmt.Errorf("codemeta, %s", err)
+	}
+	return cm.asRecord(), nil
+}
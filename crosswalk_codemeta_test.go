@@ -0,0 +1,140 @@
+package simplified
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestRecordFromCodeMeta(t *testing.T) {
+	src, err := os.ReadFile("codemeta.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec, err := RecordFromCodeMeta(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := rec.Metadata
+	if resourceTypeID(rec) != "software" || m.Title != "simplified" || m.Version == "" || m.PublicationDate == "" {
+		t.Errorf("unexpected record %s", rec.ToString())
+	}
+	if len(m.Creators) != 1 || m.Creators[0].PersonOrOrg.FamilyName != "Doiel" || personIdentifier(m.Creators[0].PersonOrOrg, "orcid") != "0000-0003-0900-6903" {
+		t.Errorf("unexpected creators %s", rec.ToString())
+	}
+	if len(m.Creators[0].Affiliations) != 1 || m.Creators[0].Affiliations[0].Name != "Caltech Library" {
+		t.Errorf("unexpected affiliations %s", rec.ToString())
+	}
+	if len(m.Rights) != 1 || m.Rights[0].ID != "bsd-3-clause-lbnl" || m.Rights[0].Link != "https://spdx.org/licenses/BSD-3-Clause-LBNL" {
+		t.Errorf("unexpected rights %s", rec.ToString())
+	}
+	if len(m.Subjects) != 3 || m.Subjects[0].Subject != "library" {
+		t.Errorf("unexpected subjects %s", rec.ToString())
+	}
+	if codeRepository(rec) != "https://github.com/caltechlibrary/simplified" {
+		t.Errorf("unexpected code repository %s", rec.ToString())
+	}
+	if len(m.Dates) != 2 || m.Dates[0].Type.ID != "created" {
+		t.Errorf("unexpected dates %s", rec.ToString())
+	}
+
+	// Hand written files are often less regular.
+	rec, err = RecordFromCodeMeta([]byte(`{
+  "@context": "https://doi.org/10.5063/schema/codemeta-2.0",
+  "@type": "SoftwareSourceCode",
+  "name": "tool",
+  "version": 1.5,
+  "identifier": "https://doi.org/10.22002/ABC",
+  "author": {"@type": "Person", "name": "Jane Doe", "affiliation": "Caltech"},
+  "license": ["MIT"],
+  "keywords": "a, b",
+  "programmingLanguage": {"@type": "ComputerLanguage", "name": "Go"}
+}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m = rec.Metadata
+	if m.Version != "1.5" || recordDOI(rec) != "10.22002/abc" || len(m.Subjects) != 2 {
+		t.Errorf("unexpected record %s", rec.ToString())
+	}
+	if len(m.Creators) != 1 || m.Creators[0].PersonOrOrg.FamilyName != "Doe" || m.Creators[0].Affiliations[0].Name != "Caltech" {
+		t.Errorf("unexpected creators %s", rec.ToString())
+	}
+	if len(m.Rights) != 1 || m.Rights[0].ID != "mit" {
+		t.Errorf("unexpected rights %s", rec.ToString())
+	}
+	if _, err := RecordFromCodeMeta([]byte(`[`)); err == nil {
+		t.Errorf("expected an error for invalid JSON")
+	}
+}
+
+func TestCodeMetaRoundTrip(t *testing.T) {
+	src, err := os.ReadFile("codemeta.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec1, err := RecordFromCodeMeta(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	setRecordDOI(rec1, "10.22002/example")
+	src, err = rec1.AsCodeMeta()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{
+		`"@context": "https://w3id.org/codemeta/3.0"`,
+		`"type": "SoftwareSourceCode"`,
+		`"id": "https://orcid.org/0000-0003-0900-6903"`,
+		`"license": [`,
+		`"https://spdx.org/licenses/BSD-3-Clause-LBNL"`,
+		`"codeRepository": "https://github.com/caltechlibrary/simplified"`,
+		`"https://doi.org/10.22002/example"`,
+		`"programmingLanguage": [`,
+	} {
+		if !strings.Contains(string(src), expected) {
+			t.Errorf("expected %q in CodeMeta ->\n%s", expected, src)
+		}
+	}
+	rec2, err := RecordFromCodeMeta(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	src1, _ := json.Marshal(rec1)
+	src2, _ := json.Marshal(rec2)
+	if string(src1) != string(src2) {
+		t.Errorf("expected the record to round trip\n%s\n%s", src1, src2)
+	}
+}
+
+func TestCodeMetaLicense(t *testing.T) {
+	rec := testRecord(t)
+	rec.Metadata.Rights = []*Right{
+		{ID: "lgpl-2.1-or-later"},
+		{ID: "zlib"},
+		{ID: "caltech-custom", Link: "https://example.edu/license"},
+		{ID: "caltech-other"},
+	}
+	src, err := rec.AsCodeMeta()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cm := map[string]interface{}{}
+	if err := json.Unmarshal(src, &cm); err != nil {
+		t.Fatal(err)
+	}
+	licenses, _ := cm["license"].([]interface{})
+	expected := []string{"https://spdx.org/licenses/LGPL-2.1-or-later", "https://spdx.org/licenses/Zlib", "https://example.edu/license"}
+	if len(licenses) != len(expected) {
+		t.Fatalf("expected licenses %v, got %v", expected, licenses)
+	}
+	for i, license := range expected {
+		if licenses[i] != license {
+			t.Errorf("expected license %q, got %q", license, licenses[i])
+		}
+	}
+	if r := spdxRight("ZLIB"); r.ID != "zlib" || r.Link != "https://spdx.org/licenses/Zlib" {
+		t.Errorf("expected the SPDX URL of Zlib, got %+v", r)
+	}
+}
@@ -0,0 +1,673 @@
+package simplified
+
+/**
+ * This file renders the simplified Record as Crossref 5.3.1 deposit XML
+ * used to register DOIs for journal articles, reports and dissertations.
+ * See https://www.crossref.org/documentation/schema-library/
+ *
+ * Funding is written with the FundRef program, rights with the access
+ * indicators program and related identifiers with the relation
+ * "references" or "cites" as the citation list. The deposit XML is
+ * written only, use the Crossref REST API JSON to read Crossref
+ * metadata.
+ */
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	// CrossrefNamespace is the XML namespace of the Crossref 5.3.1
+	// deposit schema.
+	CrossrefNamespace = "http://www.crossref.org/schema/5.3.1"
+	// CrossrefSchemaLocation is the location of the deposit schema.
+	CrossrefSchemaLocation = "http://www.crossref.org/schema/5.3.1 https://www.crossref.org/schemas/crossref5.3.1.xsd"
+
+	crossrefJATSNamespace             = "http://www.ncbi.nlm.nih.gov/JATS1"
+	crossrefFundRefNamespace          = "http://www.crossref.org/fundref.xsd"
+	crossrefAccessIndicatorsNamespace = "http://www.crossref.org/AccessIndicators.xsd"
+
+	// crossrefFunderDOIPrefix is the DOI prefix of the Crossref
+	// Funder Registry.
+	crossrefFunderDOIPrefix = "10.13039/"
+)
+
+// crossrefKinds maps resource type ids to the kind of deposit.
+var crossrefKinds = map[string]string{
+	"publication-article":       "journal",
+	"publication-report":        "report",
+	"publication-technicalnote": "report",
+	"publication-workingpaper":  "report",
+	"publication-thesis":        "dissertation",
+}
+
+// crossrefRoles maps InvenioRDM contributor roles to Crossref
+// contributor roles. Contributors with other roles are not deposited.
+var crossrefRoles = map[string]string{
+	"editor":     "editor",
+	"translator": "translator",
+}
+
+// CrossrefHead holds the deposit head. Empty values are filled in by
+// CrossrefDeposit.
+type CrossrefHead struct {
+	// BatchID identifies the deposit, it defaults to the first record
+	// id and the timestamp.
+	BatchID string `xml:"doi_batch_id"`
+	// Timestamp must increase with each deposit of a DOI, it defaults
+	// to the current time as YYYYMMDDhhmmss.
+	Timestamp string `xml:"timestamp"`
+	// DepositorName defaults to the publisher of the first record.
+	DepositorName string `xml:"depositor>depositor_name"`
+	// EmailAddress receives the deposit report.
+	EmailAddress string `xml:"depositor>email_address"`
+	// Registrant defaults to the publisher of the first record.
+	Registrant string `xml:"registrant"`
+}
+
+// DefaultCrossrefHead fills in the depositor and registrant a head
+// passed to CrossrefDeposit leaves empty, it is also the head used by
+// AsCrossrefDeposit and the "crossref-xml" crosswalk. Crossref requires a
+// depositor email address so set EmailAddress before depositing.
+var DefaultCrossrefHead = new(CrossrefHead)
+
+type crossrefDate struct {
+	MediaType string `xml:"media_type,attr,omitempty"`
+	Month     string `xml:"month,omitempty"`
+	Day       string `xml:"day,omitempty"`
+	Year      string `xml:"year"`
+}
+
+type crossrefInstitution struct {
+	Name       string                   `xml:"institution_name"`
+	ID         []*crossrefInstitutionID `xml:"institution_id,omitempty"`
+	Department string                   `xml:"institution_department,omitempty"`
+}
+
+type crossrefInstitutionID struct {
+	Type  string `xml:"type,attr"`
+	Value string `xml:",chardata"`
+}
+
+type crossrefPerson struct {
+	XMLName      xml.Name               `xml:"person_name"`
+	Sequence     string                 `xml:"sequence,attr"`
+	Role         string                 `xml:"contributor_role,attr"`
+	GivenName    string                 `xml:"given_name,omitempty"`
+	Surname      string                 `xml:"surname"`
+	Affiliations []*crossrefInstitution `xml:"affiliations>institution,omitempty"`
+	ORCID        string                 `xml:"ORCID,omitempty"`
+}
+
+type crossrefOrganization struct {
+	XMLName  xml.Name `xml:"organization"`
+	Sequence string   `xml:"sequence,attr"`
+	Role     string   `xml:"contributor_role,attr"`
+	Name     string   `xml:",chardata"`
+}
+
+type crossrefTitles struct {
+	Title    string `xml:"title"`
+	Subtitle string `xml:"subtitle,omitempty"`
+}
+
+type crossrefAbstract struct {
+	Paragraphs []string `xml:"jats:p"`
+}
+
+type crossrefAssertion struct {
+	Name       string               `xml:"name,attr"`
+	Value      string               `xml:",chardata"`
+	Assertions []*crossrefAssertion `xml:"fr:assertion,omitempty"`
+}
+
+type crossrefFundRef struct {
+	Name       string               `xml:"name,attr"`
+	Assertions []*crossrefAssertion `xml:"fr:assertion"`
+}
+
+type crossrefLicense struct {
+	AppliesTo string `xml:"applies_to,attr"`
+	StartDate string `xml:"start_date,attr,omitempty"`
+	Value     string `xml:",chardata"`
+}
+
+type crossrefAccessIndicators struct {
+	Name       string             `xml:"name,attr"`
+	FreeToRead *struct{}          `xml:"ai:free_to_read,omitempty"`
+	Licenses   []*crossrefLicense `xml:"ai:license_ref"`
+}
+
+type crossrefDOIData struct {
+	DOI      string `xml:"doi"`
+	Resource string `xml:"resource"`
+}
+
+type crossrefCitation struct {
+	Key          string `xml:"key,attr"`
+	DOI          string `xml:"doi,omitempty"`
+	ISBN         string `xml:"isbn,omitempty"`
+	Unstructured string `xml:"unstructured_citation,omitempty"`
+}
+
+type crossrefCitationList struct {
+	Citations []*crossrefCitation `xml:"citation"`
+}
+
+// crossrefContributorList holds person_name and organization elements
+// in the order of the contributors.
+type crossrefContributorList struct {
+	Contributors []interface{}
+}
+
+type crossrefPublisher struct {
+	Name string `xml:"publisher_name"`
+}
+
+type crossrefPages struct {
+	FirstPage string `xml:"first_page"`
+	LastPage  string `xml:"last_page,omitempty"`
+}
+
+// crossrefWork holds the elements shared by journal articles, report
+// metadata and dissertations. Each kind orders them differently, see
+// crossrefJournalArticle, crossrefReportMetadata and
+// crossrefDissertation.
+type crossrefWork struct {
+	Titles           *crossrefTitles
+	Contributors     []interface{}
+	Abstract         *crossrefAbstract
+	FundRef          *crossrefFundRef
+	AccessIndicators *crossrefAccessIndicators
+	DOIData          *crossrefDOIData
+	Citations        *crossrefCitationList
+}
+
+// crossrefJournalArticle is a "journal_article", the fields are in the
+// order of the 5.3.1 schema.
+type crossrefJournalArticle struct {
+	PublicationType  string                    `xml:"publication_type,attr,omitempty"`
+	Titles           *crossrefTitles           `xml:"titles"`
+	Contributors     *crossrefContributorList  `xml:"contributors,omitempty"`
+	Abstract         *crossrefAbstract         `xml:"jats:abstract,omitempty"`
+	PublicationDate  *crossrefDate             `xml:"publication_date,omitempty"`
+	Pages            *crossrefPages            `xml:"pages,omitempty"`
+	FundRef          *crossrefFundRef          `xml:"fr:program,omitempty"`
+	AccessIndicators *crossrefAccessIndicators `xml:"ai:program,omitempty"`
+	DOIData          *crossrefDOIData          `xml:"doi_data"`
+	Citations        *crossrefCitationList     `xml:"citation_list,omitempty"`
+}
+
+// crossrefReportMetadata is a "report-paper_metadata", the fields are
+// in the order of the 5.3.1 schema, the contributors come before the
+// titles.
+type crossrefReportMetadata struct {
+	Contributors     *crossrefContributorList  `xml:"contributors,omitempty"`
+	Titles           *crossrefTitles           `xml:"titles"`
+	EditionNumber    string                    `xml:"edition_number,omitempty"`
+	Abstract         *crossrefAbstract         `xml:"jats:abstract,omitempty"`
+	PublicationDate  *crossrefDate             `xml:"publication_date,omitempty"`
+	ISBN             []string                  `xml:"isbn,omitempty"`
+	Publisher        *crossrefPublisher        `xml:"publisher,omitempty"`
+	FundRef          *crossrefFundRef          `xml:"fr:program,omitempty"`
+	AccessIndicators *crossrefAccessIndicators `xml:"ai:program,omitempty"`
+	DOIData          *crossrefDOIData          `xml:"doi_data"`
+	Citations        *crossrefCitationList     `xml:"citation_list,omitempty"`
+}
+
+// crossrefDissertation is a "dissertation", the fields are in the
+// order of the 5.3.1 schema, the ISBN comes after the institution and
+// degree.
+type crossrefDissertation struct {
+	PublicationType  string                    `xml:"publication_type,attr,omitempty"`
+	Person           *crossrefPerson           `xml:"person_name"`
+	Titles           *crossrefTitles           `xml:"titles"`
+	Abstract         *crossrefAbstract         `xml:"jats:abstract,omitempty"`
+	ApprovalDate     *crossrefDate             `xml:"approval_date,omitempty"`
+	Institution      *crossrefInstitution      `xml:"institution,omitempty"`
+	Degree           string                    `xml:"degree,omitempty"`
+	ISBN             []string                  `xml:"isbn,omitempty"`
+	FundRef          *crossrefFundRef          `xml:"fr:program,omitempty"`
+	AccessIndicators *crossrefAccessIndicators `xml:"ai:program,omitempty"`
+	DOIData          *crossrefDOIData          `xml:"doi_data"`
+	Citations        *crossrefCitationList     `xml:"citation_list,omitempty"`
+}
+
+type crossrefJournalMetadata struct {
+	FullTitle string `xml:"full_title"`
+	ISSN      *struct {
+		MediaType string `xml:"media_type,attr"`
+		Value     string `xml:",chardata"`
+	} `xml:"issn,omitempty"`
+}
+
+type crossrefJournalIssue struct {
+	PublicationDate *crossrefDate `xml:"publication_date,omitempty"`
+	Volume          string        `xml:"journal_volume>volume,omitempty"`
+	Issue           string        `xml:"issue,omitempty"`
+}
+
+type crossrefJournal struct {
+	Metadata *crossrefJournalMetadata `xml:"journal_metadata"`
+	Issue    *crossrefJournalIssue    `xml:"journal_issue,omitempty"`
+	Article  *crossrefJournalArticle  `xml:"journal_article"`
+}
+
+type crossrefReport struct {
+	Metadata *crossrefReportMetadata `xml:"report-paper_metadata"`
+}
+
+type crossrefBody struct {
+	Journals      []*crossrefJournal      `xml:"journal,omitempty"`
+	Reports       []*crossrefReport       `xml:"report-paper,omitempty"`
+	Dissertations []*crossrefDissertation `xml:"dissertation,omitempty"`
+}
+
+// crossrefBatch is the root "doi_batch" element of a deposit.
+type crossrefBatch struct {
+	XMLName        xml.Name      `xml:"doi_batch"`
+	Version        string        `xml:"version,attr"`
+	Xmlns          string        `xml:"xmlns,attr"`
+	XmlnsXSI       string        `xml:"xmlns:xsi,attr"`
+	SchemaLocation string        `xml:"xsi:schemaLocation,attr"`
+	XmlnsJATS      string        `xml:"xmlns:jats,attr"`
+	XmlnsFR        string        `xml:"xmlns:fr,attr"`
+	XmlnsAI        string        `xml:"xmlns:ai,attr"`
+	Head           *CrossrefHead `xml:"head"`
+	Body           *crossrefBody `xml:"body"`
+}
+
+// crossrefDateOf converts an EDTF date into a Crossref date.
+func crossrefDateOf(date string, mediaType string) *crossrefDate {
+	year, month, day := dateParts(date)
+	if len(year) != 4 {
+		return nil
+	}
+	return &crossrefDate{MediaType: mediaType, Year: year, Month: month, Day: day}
+}
+
+// crossrefContributors converts creators, and contributors with a
+// Crossref role, into person_name and organization elements.
+func crossrefContributors(m *Metadata) []interface{} {
+	l := []interface{}{}
+	add := func(c *Creator, role string) {
+		sequence := "additional"
+		if len(l) == 0 {
+			sequence = "first"
+		}
+		p := c.PersonOrOrg
+		if isOrganization(p) {
+			l = append(l, &crossrefOrganization{Sequence: sequence, Role: role, Name: p.Name})
+			return
+		}
+		family, given := personNames(p)
+		person := &crossrefPerson{Sequence: sequence, Role: role, GivenName: given, Surname: family}
+		if orcid := personIdentifier(p, "orcid"); orcid != "" {
+			person.ORCID = dublinCoreIdentifier("orcid", orcid)
+		}
+		for _, a := range c.Affiliations {
+			if a == nil || a.Name == "" {
+				continue
+			}
+			institution := &crossrefInstitution{Name: a.Name}
+			if a.ID != "" {
+				institution.ID = []*crossrefInstitutionID{{Type: "ror", Value: dublinCoreIdentifier("ror", a.ID)}}
+			}
+			person.Affiliations = append(person.Affiliations, institution)
+		}
+		l = append(l, person)
+	}
+	for _, c := range m.Creators {
+		if c != nil && c.PersonOrOrg != nil {
+			add(c, "author")
+		}
+	}
+	for _, c := range m.Contributors {
+		if c == nil || c.PersonOrOrg == nil {
+			continue
+		}
+		if role, ok := crossrefRoles[creatorRole(c)]; ok {
+			add(c, role)
+		}
+	}
+	return l
+}
+
+// crossrefFundRefOf converts the funding into a FundRef program.
+// Funder ids in the Crossref Funder Registry and ROR ids are written.
+func crossrefFundRefOf(funding []*Funder) *crossrefFundRef {
+	program := &crossrefFundRef{Name: "fundref"}
+	for _, f := range funding {
+		if f == nil || f.Funder == nil || f.Funder.Name == "" {
+			continue
+		}
+		funder := &crossrefAssertion{Name: "funder_name", Value: f.Funder.Name}
+		if id := f.Funder.Identifier; id != "" {
+			switch {
+			case strings.Contains(id, crossrefFunderDOIPrefix):
+				_, doi, _ := strings.Cut(id, crossrefFunderDOIPrefix)
+				funder.Assertions = append(funder.Assertions, &crossrefAssertion{Name: "funder_identifier", Value: dublinCoreIdentifier("doi", crossrefFunderDOIPrefix+doi)})
+			case f.Funder.Scheme == "" || strings.EqualFold(f.Funder.Scheme, "ror"):
+				funder.Assertions = append(funder.Assertions, &crossrefAssertion{Name: "ror", Value: dublinCoreIdentifier("ror", id)})
+			}
+		}
+		group := &crossrefAssertion{Name: "fundgroup", Assertions: []*crossrefAssertion{funder}}
+		if f.Award != nil && f.Award.Number != "" {
+			group.Assertions = append(group.Assertions, &crossrefAssertion{Name: "award_number", Value: f.Award.Number})
+		}
+		program.Assertions = append(program.Assertions, group)
+	}
+	if len(program.Assertions) == 0 {
+		return nil
+	}
+	return program
+}
+
+// crossrefAccessIndicatorsOf converts the rights and file access into
+// an access indicators program. Licenses start at the end of an active
+// embargo, otherwise at the publication date.
+func (rec *Record) crossrefAccessIndicatorsOf() *crossrefAccessIndicators {
+	m := rec.Metadata
+	program := &crossrefAccessIndicators{Name: "AccessIndicators"}
+	startDate, embargoed := m.PublicationDate, false
+	if access := rec.RecordAccess; access != nil {
+		if access.Embargo != nil && access.Embargo.Active {
+			startDate, embargoed = access.Embargo.Until, true
+		}
+		if access.Files != "restricted" && !embargoed {
+			program.FreeToRead = &struct{}{}
+		}
+	}
+	if year, month, day := dateParts(startDate); len(year) != 4 || month == "" || day == "" {
+		startDate = ""
+	}
+	for _, r := range m.Rights {
+		if r != nil && r.Link != "" {
+			program.Licenses = append(program.Licenses, &crossrefLicense{AppliesTo: "vor", StartDate: startDate, Value: r.Link})
+		}
+	}
+	if program.FreeToRead == nil && len(program.Licenses) == 0 {
+		return nil
+	}
+	return program
+}
+
+// crossrefCitations converts related identifiers with the relation
+// "references" or "cites" into a citation list.
+func crossrefCitations(related []*Identifier) []*crossrefCitation {
+	l := []*crossrefCitation{}
+	for _, id := range related {
+		if id == nil || id.Identifier == "" || id.RelationType == nil {
+			continue
+		}
+		if id.RelationType.ID != "references" && id.RelationType.ID != "cites" {
+			continue
+		}
+		citation := &crossrefCitation{Key: fmt.Sprintf("ref%d", len(l)+1)}
+		switch strings.ToLower(id.Scheme) {
+		case "doi":
+			citation.DOI = id.Identifier
+		case "isbn":
+			citation.ISBN = id.Identifier
+		default:
+			citation.Unstructured = dublinCoreIdentifier(id.Scheme, id.Identifier)
+		}
+		l = append(l, citation)
+	}
+	return l
+}
+
+// asCrossrefWork maps the record to the elements shared by the kinds
+// of deposit.
+func (rec *Record) asCrossrefWork() (*crossrefWork, error) {
+	m := rec.Metadata
+	doi := recordDOI(rec)
+	if doi == "" {
+		return nil, fmt.Errorf("crossref, record %q has no DOI", rec.ID)
+	}
+	resource := identifierValue(m.Identifiers, "url")
+	if resource == "" {
+		// InvenioRDM records hold their landing page in the links.
+		resource, _ = rec.Links["self_html"].(string)
+	}
+	if resource == "" {
+		return nil, fmt.Errorf("crossref, record %q has no url identifier or landing page to resolve the DOI to", rec.ID)
+	}
+	if m.Title == "" {
+		return nil, fmt.Errorf("crossref, record %q has no title", rec.ID)
+	}
+	work := &crossrefWork{Titles: &crossrefTitles{Title: m.Title}}
+	for _, t := range m.AdditionalTitles {
+		if t != nil && t.Type != nil && t.Type.ID == "subtitle" {
+			work.Titles.Subtitle = t.Title
+			break
+		}
+	}
+	if abstract := stripHTML(m.Description); abstract != "" {
+		work.Abstract = &crossrefAbstract{Paragraphs: []string{abstract}}
+	}
+	work.Contributors = crossrefContributors(m)
+	work.FundRef = crossrefFundRefOf(m.Funding)
+	work.AccessIndicators = rec.crossrefAccessIndicatorsOf()
+	work.DOIData = &crossrefDOIData{DOI: doi, Resource: resource}
+	if citations := crossrefCitations(m.RelatedIdentifiers); len(citations) > 0 {
+		work.Citations = &crossrefCitationList{Citations: citations}
+	}
+	return work, nil
+}
+
+// contributorList returns the contributors element, nil if there are
+// none.
+func (work *crossrefWork) contributorList() *crossrefContributorList {
+	if len(work.Contributors) == 0 {
+		return nil
+	}
+	return &crossrefContributorList{Contributors: work.Contributors}
+}
+
+// add adds the record to the deposit body. The record isn't changed.
+func (body *crossrefBody) add(rec *Record) error {
+	if rec == nil {
+		return fmt.Errorf("no record")
+	}
+	if rec.Metadata == nil {
+		r := *rec
+		r.Metadata = new(Metadata)
+		rec = &r
+	}
+	m := rec.Metadata
+	typeID := resourceTypeID(rec)
+	kind, ok := crossrefKinds[typeID]
+	if !ok {
+		return fmt.Errorf("crossref, record %q has an unsupported resource type %q", rec.ID, typeID)
+	}
+	work, err := rec.asCrossrefWork()
+	if err != nil {
+		return err
+	}
+	switch kind {
+	case "journal":
+		journal := journalFields(rec)
+		if journal["title"] == "" {
+			return fmt.Errorf("crossref, article %q has no journal title", rec.ID)
+		}
+		article := &crossrefJournalArticle{
+			PublicationType:  "full_text",
+			Titles:           work.Titles,
+			Contributors:     work.contributorList(),
+			Abstract:         work.Abstract,
+			PublicationDate:  crossrefDateOf(m.PublicationDate, "online"),
+			FundRef:          work.FundRef,
+			AccessIndicators: work.AccessIndicators,
+			DOIData:          work.DOIData,
+			Citations:        work.Citations,
+		}
+		if start, end := splitPages(journal["pages"]); start != "" {
+			article.Pages = &crossrefPages{FirstPage: start, LastPage: end}
+		}
+		metadata := &crossrefJournalMetadata{FullTitle: journal["title"]}
+		if issn := recordISSN(rec); issn != "" {
+			metadata.ISSN = &struct {
+				MediaType string `xml:"media_type,attr"`
+				Value     string `xml:",chardata"`
+			}{MediaType: "electronic", Value: issn}
+		}
+		j := &crossrefJournal{Metadata: metadata, Article: article}
+		if journal["volume"] != "" || journal["issue"] != "" {
+			j.Issue = &crossrefJournalIssue{PublicationDate: crossrefDateOf(m.PublicationDate, "online"), Volume: journal["volume"], Issue: journal["issue"]}
+		}
+		body.Journals = append(body.Journals, j)
+	case "report":
+		report := &crossrefReportMetadata{
+			Contributors:     work.contributorList(),
+			Titles:           work.Titles,
+			EditionNumber:    m.Version,
+			Abstract:         work.Abstract,
+			PublicationDate:  crossrefDateOf(m.PublicationDate, "online"),
+			ISBN:             identifierValues(m.Identifiers, "isbn"),
+			FundRef:          work.FundRef,
+			AccessIndicators: work.AccessIndicators,
+			DOIData:          work.DOIData,
+			Citations:        work.Citations,
+		}
+		if m.Publisher != "" {
+			report.Publisher = &crossrefPublisher{Name: m.Publisher}
+		}
+		body.Reports = append(body.Reports, &crossrefReport{Metadata: report})
+	case "dissertation":
+		thesis := thesisFields(rec)
+		dissertation := &crossrefDissertation{
+			PublicationType:  "full_text",
+			Titles:           work.Titles,
+			Abstract:         work.Abstract,
+			ApprovalDate:     crossrefDateOf(m.PublicationDate, "online"),
+			Degree:           thesis["type"],
+			ISBN:             identifierValues(m.Identifiers, "isbn"),
+			FundRef:          work.FundRef,
+			AccessIndicators: work.AccessIndicators,
+			DOIData:          work.DOIData,
+			Citations:        work.Citations,
+		}
+		// A dissertation has a single author.
+		for _, c := range work.Contributors {
+			if person, ok := c.(*crossrefPerson); ok && person.Role == "author" {
+				dissertation.Person = person
+				break
+			}
+		}
+		if dissertation.Person == nil {
+			return fmt.Errorf("crossref, dissertation %q has no personal author", rec.ID)
+		}
+		dissertation.Person.Sequence = "first"
+		university := thesis["university"]
+		if university == "" {
+			university = m.Publisher
+		}
+		if university != "" {
+			dissertation.Institution = &crossrefInstitution{Name: university, Department: thesis["department"]}
+		}
+		body.Dissertations = append(body.Dissertations, dissertation)
+	}
+	return nil
+}
+
+// CrossrefDeposit renders the records as a single Crossref 5.3.1
+// deposit. Journal articles ("publication-article"), reports and
+// dissertations ("publication-thesis") are supported. Each record needs
+// a DOI and a "url" identifier, or a "self_html" link, for the DOI to
+// resolve to. If head is nil, or has empty values, they are filled in
+// from DefaultCrossrefHead then the first record. The depositor email
+// address is required.
+//
+// ```
+//
+//	head := &simplified.CrossrefHead{DepositorName: "Caltech Library", EmailAddress: "library@example.edu"}
+//	src, err := simplified.CrossrefDeposit(head, records...)
+//	// ... handle error ...
+//	os.WriteFile("deposit.xml", src, 0664)
+//
+// ```
+func CrossrefDeposit(head *CrossrefHead, records ...*Record) ([]byte, error) {
+	if len(records) == 0 {
+		return nil, fmt.Errorf("crossref, no records to deposit")
+	}
+	body := new(crossrefBody)
+	for _, rec := range records {
+		if err := body.add(rec); err != nil {
+			return nil, err
+		}
+	}
+	h := new(CrossrefHead)
+	if head != nil {
+		*h = *head
+	}
+	if d := DefaultCrossrefHead; d != nil {
+		if h.DepositorName == "" {
+			h.DepositorName = d.DepositorName
+		}
+		if h.EmailAddress == "" {
+			h.EmailAddress = d.EmailAddress
+		}
+		if h.Registrant == "" {
+			h.Registrant = d.Registrant
+		}
+	}
+	if strings.TrimSpace(h.EmailAddress) == "" {
+		return nil, fmt.Errorf("crossref, a depositor email address is required, see CrossrefHead")
+	}
+	if h.Timestamp == "" {
+		h.Timestamp = time.Now().UTC().Format("20060102150405")
+	}
+	if h.BatchID == "" {
+		h.BatchID = strings.TrimPrefix(records[0].ID+"-"+h.Timestamp, "-")
+	}
+	if h.DepositorName == "" {
+		h.DepositorName = records[0].Metadata.Publisher
+	}
+	if h.Registrant == "" {
+		h.Registrant = records[0].Metadata.Publisher
+	}
+	batch := &crossrefBatch{
+		Version:        "5.3.1",
+		Xmlns:          CrossrefNamespace,
+		XmlnsXSI:       xsiNamespace,
+		SchemaLocation: CrossrefSchemaLocation,
+		XmlnsJATS:      crossrefJATSNamespace,
+		XmlnsFR:        crossrefFundRefNamespace,
+		XmlnsAI:        crossrefAccessIndicatorsNamespace,
+		Head:           h,
+		Body:           body,
+	}
+	src, err := xml.MarshalIndent(batch, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), src...), nil
+}
+
+// AsCrossrefDeposit renders the record as a Crossref 5.3.1 deposit. The
+// "journal:journal" custom field describes the journal of an article
+// and the "thesis:thesis" custom field the institution and degree of a
+// dissertation. Creators, and editors or translators, are written with
+// their ORCID and affiliations, funding as FundRef, rights as access
+// indicators and related identifiers with the relation "references" or
+// "cites" as the citation list. The head is DefaultCrossrefHead which
+// needs at least the depositor email address, see CrossrefDeposit.
+//
+// ```
+//
+//	simplified.DefaultCrossrefHead.EmailAddress = "library@example.edu"
+//	src, err := rec.AsCrossrefDeposit()
+//	// ... handle error ...
+//	fmt.Printf("%s\n", src)
+//
+// ```
+func (rec *Record) AsCrossrefDeposit() ([]byte, error) {
+	if rec == nil {
+		return nil, fmt.Errorf("no record")
+	}
+	return CrossrefDeposit(nil, rec)
+}
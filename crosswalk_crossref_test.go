@@ -0,0 +1,184 @@
+package simplified
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+)
+
+// crossrefSchemaOrder is the sequence of child elements of each kind of
+// work in the Crossref 5.3.1 schema. The FundRef, access indicators and
+// relations programs are all named "program".
+var crossrefSchemaOrder = map[string][]string{
+	"journal_article":       {"titles", "contributors", "abstract", "publication_date", "acceptance_date", "pages", "publisher_item", "crossmark", "program", "program", "program", "archive_locations", "scn_policies", "doi_data", "citation_list", "component_list"},
+	"report-paper_metadata": {"contributors", "titles", "edition_number", "abstract", "publication_date", "approval_date", "isbn", "publisher", "institution", "publisher_item", "contract_number", "crossmark", "program", "program", "program", "archive_locations", "scn_policies", "doi_data", "citation_list", "component_list"},
+	"dissertation":          {"person_name", "titles", "abstract", "approval_date", "institution", "degree", "isbn", "publisher_item", "crossmark", "program", "program", "program", "scn_policies", "doi_data", "citation_list", "component_list"},
+}
+
+// checkCrossrefOrder checks the child elements of the first element
+// named kind follow the order of the schema.
+func checkCrossrefOrder(t *testing.T, src []byte, kind string) {
+	t.Helper()
+	decoder := xml.NewDecoder(bytes.NewReader(src))
+	children, depth := []string{}, -1
+	for {
+		tok, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			if depth < 0 && tok.Name.Local == kind {
+				depth = 0
+			} else if depth >= 0 {
+				if depth == 0 {
+					children = append(children, tok.Name.Local)
+				}
+				depth++
+			}
+		case xml.EndElement:
+			if depth == 0 {
+				depth = -2
+			} else if depth > 0 {
+				depth--
+			}
+		}
+	}
+	if len(children) == 0 {
+		t.Fatalf("expected a %s ->\n%s", kind, src)
+	}
+	order, i := crossrefSchemaOrder[kind], 0
+	for _, child := range children {
+		for i < len(order) && order[i] != child {
+			i++
+		}
+		if i == len(order) {
+			t.Errorf("%s, %s is out of schema order in %v", kind, child, children)
+			return
+		}
+		// isbn may repeat.
+		if child != "isbn" {
+			i++
+		}
+	}
+}
+
+func TestAsCrossrefDeposit(t *testing.T) {
+	rec := testRecord(t)
+	if _, err := rec.AsCrossrefDeposit(); err == nil {
+		t.Errorf("expected an error for a record without a url identifier or landing page")
+	}
+	// The landing page of an InvenioRDM record is used when there is
+	// no url identifier.
+	rec.Links = map[string]interface{}{"self_html": "https://authors.library.caltech.edu/records/rd9fg-k5282"}
+	m := rec.Metadata
+	m.RelatedIdentifiers = append(m.RelatedIdentifiers, &Identifier{Scheme: "doi", Identifier: "10.1126/science.1234", RelationType: &TypeDetail{ID: "references"}})
+	if _, err := rec.AsCrossrefDeposit(); err == nil || !strings.Contains(err.Error(), "email address") {
+		t.Errorf("expected an error for a deposit without a depositor email address, got %v", err)
+	}
+	defaultHead := DefaultCrossrefHead
+	t.Cleanup(func() { DefaultCrossrefHead = defaultHead })
+	DefaultCrossrefHead = &CrossrefHead{EmailAddress: "library@example.edu"}
+	src, err := rec.AsCrossrefDeposit()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{
+		`<doi_batch version="5.3.1" xmlns="http://www.crossref.org/schema/5.3.1"`,
+		`<full_title>PNAS</full_title>`,
+		`<issn media_type="electronic">1091-6490</issn>`,
+		`<journal_volume>`,
+		`<journal_article publication_type="full_text">`,
+		`<subtitle>a subtitle</subtitle>`,
+		`<person_name sequence="first" contributor_role="author">`,
+		`<institution_id type="ror">https://ror.org/05dxps055</institution_id>`,
+		`<ORCID>https://orcid.org/0000-0002-5374-6178</ORCID>`,
+		`<jats:p>An abstract.</jats:p>`,
+		`<fr:assertion name="funder_name">National Science Foundation`,
+		`<fr:assertion name="award_number">OCE-1634002</fr:assertion>`,
+		`<ai:license_ref applies_to="vor" start_date="2131-01-01">https://creativecommons.org/licenses/by/4.0/</ai:license_ref>`,
+		`<doi>10.1073/pnas.2302156120</doi>`,
+		`<email_address>library@example.edu</email_address>`,
+		`<resource>https://authors.library.caltech.edu/records/rd9fg-k5282</resource>`,
+		`<citation key="ref1">`,
+		`<doi>10.1126/science.1234</doi>`,
+	} {
+		if !strings.Contains(string(src), expected) {
+			t.Errorf("expected %q in deposit ->\n%s", expected, src)
+		}
+	}
+	if strings.Contains(string(src), "<publisher>") {
+		t.Errorf("did not expect a publisher in a journal article ->\n%s", src)
+	}
+	checkCrossrefOrder(t, src, "journal_article")
+
+	// A report lists the contributors before the titles.
+	setResourceType(rec, "publication-report")
+	m.Version = "2"
+	m.Identifiers = addIdentifier(m.Identifiers, "isbn", "978-0-306-40615-7")
+	if src, err = rec.AsCrossrefDeposit(); err != nil {
+		t.Fatal(err)
+	}
+	checkCrossrefOrder(t, src, "report-paper_metadata")
+	for _, expected := range []string{"<edition_number>2</edition_number>", "<isbn>978-0-306-40615-7</isbn>", "<publisher_name>National Academy of Sciences</publisher_name>"} {
+		if !strings.Contains(string(src), expected) {
+			t.Errorf("expected %q in deposit ->\n%s", expected, src)
+		}
+	}
+
+	setResourceType(rec, "dataset")
+	if _, err := rec.AsCrossrefDeposit(); err == nil {
+		t.Errorf("expected an error for an unsupported resource type")
+	}
+}
+
+func TestCrossrefDissertation(t *testing.T) {
+	rec := new(Record)
+	m := ensureMetadata(rec)
+	setResourceType(rec, "publication-thesis")
+	m.Title = "A Thesis"
+	m.PublicationDate = "2024-06-01"
+	m.Creators = append(m.Creators, newPerson("Doe", "Jane", ""))
+	setThesisField(rec, "university", "California Institute of Technology")
+	setThesisField(rec, "type", "phd")
+	setRecordDOI(rec, "10.7907/example")
+	m.Identifiers = addIdentifier(m.Identifiers, "url", "https://thesis.library.caltech.edu/1/")
+	m.Identifiers = addIdentifier(m.Identifiers, "isbn", "978-0-306-40615-7")
+	head := &CrossrefHead{BatchID: "batch-1", Timestamp: "20240601000000", DepositorName: "Caltech Library", EmailAddress: "library@example.edu"}
+	src, err := CrossrefDeposit(head, rec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{
+		`<doi_batch_id>batch-1</doi_batch_id>`,
+		`<email_address>library@example.edu</email_address>`,
+		`<dissertation publication_type="full_text">`,
+		`<surname>Doe</surname>`,
+		`<approval_date media_type="online">`,
+		`<institution_name>California Institute of Technology</institution_name>`,
+		`<degree>phd</degree>`,
+		`<isbn>978-0-306-40615-7</isbn>`,
+	} {
+		if !strings.Contains(string(src), expected) {
+			t.Errorf("expected %q in deposit ->\n%s", expected, src)
+		}
+	}
+	checkCrossrefOrder(t, src, "dissertation")
+	m.Creators = nil
+	if _, err := CrossrefDeposit(head, rec); err == nil {
+		t.Errorf("expected an error for a dissertation without an author")
+	}
+	if _, err := CrossrefDeposit(head); err == nil {
+		t.Errorf("expected an error without records")
+	}
+	// Exporting doesn't change the record.
+	empty := &Record{ID: "empty"}
+	if _, err := CrossrefDeposit(head, empty); err == nil || empty.Metadata != nil {
+		t.Errorf("expected an error and the record unchanged, got %v, %+v", err, empty.Metadata)
+	}
+}
@@ -0,0 +1,425 @@
+package simplified
+
+/**
+ * This file reads the work JSON returned by the Crossref REST API, e.g.
+ * https://api.crossref.org/works/10.1073/pnas.2302156120, into the
+ * simplified Record. See https://api.crossref.org/swagger-ui/index.html
+ *
+ * The JSON is read from bytes so a saved response or standard input can
+ * be converted without a network connection. Both a single work and a
+ * list of works (the "work-list" message) are accepted.
+ */
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// crossrefResourceTypes maps Crossref work types to InvenioRDM
+// resource type ids.
+var crossrefResourceTypes = map[string]string{
+	"journal-article":     "publication-article",
+	"posted-content":      "publication-preprint",
+	"book":                "publication-book",
+	"monograph":           "publication-book",
+	"edited-book":         "publication-book",
+	"reference-book":      "publication-book",
+	"book-chapter":        "publication-section",
+	"book-section":        "publication-section",
+	"book-part":           "publication-section",
+	"reference-entry":     "publication-section",
+	"proceedings-article": "publication-conferencepaper",
+	"proceedings":         "publication-conferenceproceeding",
+	"report":              "publication-report",
+	"report-component":    "publication-report",
+	"dissertation":        "publication-thesis",
+	"standard":            "publication-standard",
+	"peer-review":         "publication-peerreview",
+	"dataset":             "dataset",
+	"database":            "dataset",
+}
+
+// crossrefRelations maps Crossref relation types which have no
+// DataCite equivalent of the same name to InvenioRDM relation ids.
+var crossrefRelations = map[string]string{
+	"is-preprint-of":       "isversionof",
+	"has-preprint":         "hasversion",
+	"is-review-of":         "reviews",
+	"has-review":           "isreviewedby",
+	"is-replaced-by":       "isobsoletedby",
+	"replaces":             "obsoletes",
+	"is-manifestation-of":  "isvariantformof",
+	"has-manifestation":    "isoriginalformof",
+	"is-supplemented-by":   "issupplementedby",
+	"is-related-material":  "references",
+	"has-related-material": "isreferencedby",
+}
+
+// crossrefAPIDate is a Crossref date, only the date parts are used.
+type crossrefAPIDate struct {
+	DateParts [][]interface{} `json:"date-parts"`
+}
+
+// date returns the date as YYYY, YYYY-MM or YYYY-MM-DD.
+func (d *crossrefAPIDate) date() string {
+	if d == nil {
+		return ""
+	}
+	return cslPublicationDate(&cslDate{DateParts: d.DateParts})
+}
+
+type crossrefAPIAffiliation struct {
+	Name string `json:"name"`
+	ID   []*struct {
+		ID     string `json:"id"`
+		IDType string `json:"id-type"`
+	} `json:"id,omitempty"`
+}
+
+type crossrefAPIName struct {
+	Given       string                    `json:"given,omitempty"`
+	Family      string                    `json:"family,omitempty"`
+	Suffix      string                    `json:"suffix,omitempty"`
+	Name        string                    `json:"name,omitempty"`
+	ORCID       string                    `json:"ORCID,omitempty"`
+	Sequence    string                    `json:"sequence,omitempty"`
+	Affiliation []*crossrefAPIAffiliation `json:"affiliation,omitempty"`
+}
+
+type crossrefAPILicense struct {
+	URL            string           `json:"URL"`
+	Start          *crossrefAPIDate `json:"start,omitempty"`
+	ContentVersion string           `json:"content-version,omitempty"`
+}
+
+type crossrefAPIFunder struct {
+	DOI   string   `json:"DOI,omitempty"`
+	Name  string   `json:"name,omitempty"`
+	Award []string `json:"award,omitempty"`
+}
+
+type crossrefAPIReference struct {
+	Key          string `json:"key,omitempty"`
+	DOI          string `json:"DOI,omitempty"`
+	ISBN         string `json:"ISBN,omitempty"`
+	Unstructured string `json:"unstructured,omitempty"`
+}
+
+type crossrefAPIRelation struct {
+	ID     string `json:"id"`
+	IDType string `json:"id-type"`
+}
+
+type crossrefAPIInstitution struct {
+	Name       string   `json:"name"`
+	Department []string `json:"department,omitempty"`
+}
+
+// crossrefAPIWork is the work message of the Crossref REST API. Only
+// the fields mapped to the Record are included.
+type crossrefAPIWork struct {
+	DOI             string                            `json:"DOI"`
+	Type            string                            `json:"type"`
+	Title           []string                          `json:"title,omitempty"`
+	Subtitle        []string                          `json:"subtitle,omitempty"`
+	ContainerTitle  []string                          `json:"container-title,omitempty"`
+	Author          []*crossrefAPIName                `json:"author,omitempty"`
+	Editor          []*crossrefAPIName                `json:"editor,omitempty"`
+	Translator      []*crossrefAPIName                `json:"translator,omitempty"`
+	Publisher       string                            `json:"publisher,omitempty"`
+	Issued          *crossrefAPIDate                  `json:"issued,omitempty"`
+	PublishedPrint  *crossrefAPIDate                  `json:"published-print,omitempty"`
+	PublishedOnline *crossrefAPIDate                  `json:"published-online,omitempty"`
+	Approved        *crossrefAPIDate                  `json:"approved,omitempty"`
+	Volume          string                            `json:"volume,omitempty"`
+	Issue           string                            `json:"issue,omitempty"`
+	Page            string                            `json:"page,omitempty"`
+	EditionNumber   string                            `json:"edition-number,omitempty"`
+	ISSN            []string                          `json:"ISSN,omitempty"`
+	ISBN            []string                          `json:"ISBN,omitempty"`
+	Abstract        string                            `json:"abstract,omitempty"`
+	Language        string                            `json:"language,omitempty"`
+	Subject         []string                          `json:"subject,omitempty"`
+	License         []*crossrefAPILicense             `json:"license,omitempty"`
+	Funder          []*crossrefAPIFunder              `json:"funder,omitempty"`
+	Reference       []*crossrefAPIReference           `json:"reference,omitempty"`
+	Relation        map[string][]*crossrefAPIRelation `json:"relation,omitempty"`
+	Institution     []*crossrefAPIInstitution         `json:"institution,omitempty"`
+	Degree          []string                          `json:"degree,omitempty"`
+	Resource        *struct {
+		Primary *struct {
+			URL string `json:"URL"`
+		} `json:"primary,omitempty"`
+	} `json:"resource,omitempty"`
+}
+
+// reJATSTitle matches the title of a JATS abstract, e.g.
+// "<jats:title>Abstract</jats:title>".
+var reJATSTitle = regexp.MustCompile(`(?s)<jats:title>.*?</jats:title>`)
+
+// creator converts a Crossref author, editor or translator into a
+// Creator with its ORCID and affiliations.
+func (name *crossrefAPIName) creator(role string) *Creator {
+	var c *Creator
+	if name.Family == "" && name.Given == "" {
+		c = newOrganization(name.Name, role)
+	} else {
+		family := name.Family
+		if name.Suffix != "" {
+			family += ", " + name.Suffix
+		}
+		c = newPerson(family, name.Given, role)
+	}
+	if name.ORCID != "" {
+		_, orcid := dataCiteSchemeID("orcid", name.ORCID)
+		addPersonIdentifier(c, "orcid", orcid)
+	}
+	for _, a := range name.Affiliation {
+		if a == nil {
+			continue
+		}
+		id := ""
+		for _, aid := range a.ID {
+			if aid != nil && strings.EqualFold(aid.IDType, "ROR") {
+				_, id = dataCiteSchemeID("ror", aid.ID)
+				break
+			}
+		}
+		addAffiliation(c, a.Name, id)
+	}
+	return c
+}
+
+// asRecord maps the Crossref work to a Record.
+func (work *crossrefAPIWork) asRecord() *Record {
+	rec := new(Record)
+	m := ensureMetadata(rec)
+	resourceType, ok := crossrefResourceTypes[work.Type]
+	if !ok {
+		resourceType = "other"
+	}
+	setResourceType(rec, resourceType)
+	setRecordDOI(rec, work.DOI)
+	if len(work.Title) > 0 {
+		m.Title = strings.TrimSpace(work.Title[0])
+	}
+	for _, subtitle := range work.Subtitle {
+		if subtitle = strings.TrimSpace(subtitle); subtitle != "" {
+			m.AdditionalTitles = append(m.AdditionalTitles, &TitleDetail{Title: subtitle, Type: &Type{ID: "subtitle"}})
+		}
+	}
+	for _, name := range work.Author {
+		if name != nil {
+			m.Creators = append(m.Creators, name.creator(""))
+		}
+	}
+	for _, name := range work.Editor {
+		if name == nil {
+			continue
+		}
+		if len(work.Author) == 0 {
+			m.Creators = append(m.Creators, name.creator("editor"))
+		} else {
+			m.Contributors = append(m.Contributors, name.creator("editor"))
+		}
+	}
+	for _, name := range work.Translator {
+		if name != nil {
+			m.Contributors = append(m.Contributors, name.creator("translator"))
+		}
+	}
+	m.Publisher = strings.TrimSpace(work.Publisher)
+	for _, d := range []*crossrefAPIDate{work.Issued, work.PublishedPrint, work.PublishedOnline, work.Approved} {
+		if m.PublicationDate = d.date(); m.PublicationDate != "" {
+			break
+		}
+	}
+	if len(work.ContainerTitle) > 0 && (resourceType == "publication-article" || resourceType == "publication-preprint") {
+		setJournalField(rec, "title", strings.TrimSpace(work.ContainerTitle[0]))
+		setJournalField(rec, "volume", work.Volume)
+		setJournalField(rec, "issue", work.Issue)
+		setJournalField(rec, "pages", strings.ReplaceAll(work.Page, "–", "-"))
+		if len(work.ISSN) > 0 {
+			setJournalField(rec, "issn", work.ISSN[0])
+		}
+	}
+	if resourceType == "publication-thesis" {
+		for _, institution := range work.Institution {
+			if institution != nil && institution.Name != "" {
+				setThesisField(rec, "university", institution.Name)
+				setThesisField(rec, "department", strings.Join(institution.Department, "; "))
+				break
+			}
+		}
+		if len(work.Degree) > 0 {
+			setThesisField(rec, "type", work.Degree[0])
+		}
+	}
+	m.Version = work.EditionNumber
+	for _, issn := range work.ISSN {
+		m.Identifiers = addIdentifier(m.Identifiers, "issn", issn)
+	}
+	for _, isbn := range work.ISBN {
+		m.Identifiers = addIdentifier(m.Identifiers, "isbn", isbn)
+	}
+	if work.Resource != nil && work.Resource.Primary != nil {
+		m.Identifiers = addIdentifier(m.Identifiers, "url", work.Resource.Primary.URL)
+	}
+	m.Description = stripHTML(reJATSTitle.ReplaceAllString(work.Abstract, ""))
+	if work.Language != "" {
+		m.Languages = []map[string]interface{}{{"id": work.Language}}
+	}
+	for _, subject := range work.Subject {
+		if subject = strings.TrimSpace(subject); subject != "" {
+			m.Subjects = append(m.Subjects, &Subject{Subject: subject})
+		}
+	}
+	licenses := map[string]bool{}
+	for _, l := range work.License {
+		if l == nil || l.URL == "" || licenses[l.URL] {
+			continue
+		}
+		licenses[l.URL] = true
+		m.Rights = append(m.Rights, licenseRight(l.URL))
+	}
+	for _, f := range work.Funder {
+		if f == nil || (f.Name == "" && f.DOI == "") {
+			continue
+		}
+		funder := &FunderIdentifier{Name: f.Name}
+		if f.DOI != "" {
+			funder.Scheme, funder.Identifier = "doi", normalizeIdentifierValue("doi", f.DOI)
+		}
+		if len(f.Award) == 0 {
+			m.Funding = append(m.Funding, &Funder{Funder: funder})
+			continue
+		}
+		// Each award becomes a separate funding entry as in InvenioRDM.
+		for _, award := range f.Award {
+			if award = strings.TrimSpace(award); award != "" {
+				copied := *funder
+				m.Funding = append(m.Funding, &Funder{Funder: &copied, Award: &AwardIdentifier{Number: award}})
+			}
+		}
+	}
+	for _, ref := range work.Reference {
+		switch {
+		case ref == nil:
+		case ref.DOI != "":
+			m.RelatedIdentifiers = append(m.RelatedIdentifiers, &Identifier{Scheme: "doi", Identifier: normalizeIdentifierValue("doi", ref.DOI), RelationType: &TypeDetail{ID: "references"}})
+		case ref.ISBN != "":
+			m.RelatedIdentifiers = append(m.RelatedIdentifiers, &Identifier{Scheme: "isbn", Identifier: ref.ISBN, RelationType: &TypeDetail{ID: "references"}})
+		}
+	}
+	kinds := []string{}
+	for kind := range work.Relation {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	for _, kind := range kinds {
+		relation, ok := crossrefRelations[kind]
+		if !ok {
+			term, known := dataCiteTerm(dataCiteRelationTypes, kind)
+			if !known {
+				continue
+			}
+			relation = rdmTerm(term, false)
+		}
+		for _, r := range work.Relation[kind] {
+			if r == nil || r.ID == "" {
+				continue
+			}
+			scheme := strings.ToLower(r.IDType)
+			value := r.ID
+			if scheme == "doi" {
+				value = normalizeIdentifierValue("doi", value)
+			}
+			m.RelatedIdentifiers = append(m.RelatedIdentifiers, &Identifier{Scheme: scheme, Identifier: value, RelationType: &TypeDetail{ID: relation}})
+		}
+	}
+	return rec
+}
+
+// crossrefAPIMessage is the envelope of a Crossref REST API response.
+// The message is a work or, for a "work-list", holds the works as items.
+type crossrefAPIMessage struct {
+	MessageType string          `json:"message-type"`
+	Message     json.RawMessage `json:"message"`
+}
+
+// RecordsFromCrossrefJSON converts Crossref REST API JSON into records.
+// The source may be a response from the works route, with a single
+// work or a list of works, the work message alone or an array of works.
+//
+// ```
+//
+//	src, err := os.ReadFile("works.json")
+//	// ... handle error ...
+//	records, err := simplified.RecordsFromCrossrefJSON(src)
+//	// ... handle error ...
+//
+// ```
+func RecordsFromCrossrefJSON(src []byte) ([]*Record, error) {
+	envelope := new(crossrefAPIMessage)
+	if err := json.Unmarshal(src, envelope); err == nil && envelope.Message != nil {
+		if envelope.MessageType == "work-list" {
+			list := struct {
+				Items json.RawMessage `json:"items"`
+			}{}
+			if err := json.Unmarshal(envelope.Message, &list); err != nil {
+				return nil, fmt.Errorf("crossref, %s", err)
+			}
+			src = list.Items
+		} else {
+			src = envelope.Message
+		}
+	}
+	works := []*crossrefAPIWork{}
+	if trimmed := strings.TrimSpace(string(src)); strings.HasPrefix(trimmed, "[") {
+		if err := json.Unmarshal(src, &works); err != nil {
+			return nil, fmt.Errorf("crossref, %s", err)
+		}
+	} else {
+		work := new(crossrefAPIWork)
+		if err := json.Unmarshal(src, work); err != nil {
+			return nil, fmt.Errorf("crossref, %s", err)
+		}
+		works = append(works, work)
+	}
+	records := []*Record{}
+	for _, work := range works {
+		if work != nil {
+			records = append(records, work.asRecord())
+		}
+	}
+	return records, nil
+}
+
+// RecordFromCrossrefJSON converts a Crossref REST API work into a
+// Record. Authors become creators with their ORCID and affiliations,
+// funders and awards become funding, license URLs become rights and
+// references with a DOI or ISBN become related identifiers with the
+// relation "references". See RecordsFromCrossrefJSON for a list of
+// works.
+//
+// ```
+//
+//	src, err := io.ReadAll(os.Stdin)
+//	// ... handle error ...
+//	rec, err := simplified.RecordFromCrossrefJSON(src)
+//	// ... handle error ...
+//
+// ```
+func RecordFromCrossrefJSON(src []byte) (*Record, error) {
+	records, err := RecordsFromCrossrefJSON(src)
+	if err != nil {
+		return nil, err
+	}
+	if len(records) != 1 {
+		return nil, fmt.Errorf("crossref, expected one work, found %d", len(records))
+	}
+	return records[0], nil
+}
@@ -0,0 +1,126 @@
+package simplified
+
+import (
+	"testing"
+)
+
+// crossrefTestWork is an abridged Crossref REST API works response.
+var crossrefTestWork = []byte(`{"status": "ok", "message-type": "work", "message-version": "1.0.0", "message": {
+    "DOI": "10.1073/PNAS.2302156120",
+    "type": "journal-article",
+    "title": ["Microbially induced precipitation of silica"],
+    "subtitle": ["a subtitle"],
+    "container-title": ["Proceedings of the National Academy of Sciences"],
+    "author": [
+        {"given": "Victoria J.", "family": "Orphan", "sequence": "first", "ORCID": "http://orcid.org/0000-0002-5374-6178", "authenticated-orcid": true,
+         "affiliation": [{"name": "California Institute of Technology", "id": [{"id": "https://ror.org/05dxps055", "id-type": "ROR", "asserted-by": "publisher"}]}]},
+        {"name": "Deep Sea Consortium", "sequence": "additional", "affiliation": []}
+    ],
+    "publisher": "Proceedings of the National Academy of Sciences",
+    "issued": {"date-parts": [[2023, 12, 19]]},
+    "published-print": {"date-parts": [[2023, 12, 19]]},
+    "volume": "120",
+    "issue": "51",
+    "page": "e2302156120",
+    "ISSN": ["0027-8424", "1091-6490"],
+    "abstract": "<jats:title>Significance</jats:title><jats:p>First paragraph.</jats:p><jats:p>Second <jats:italic>paragraph</jats:italic>.</jats:p>",
+    "language": "en",
+    "subject": ["Multidisciplinary"],
+    "license": [
+        {"URL": "https://creativecommons.org/licenses/by/4.0/", "start": {"date-parts": [[2023, 12, 19]]}, "content-version": "vor", "delay-in-days": 0},
+        {"URL": "https://creativecommons.org/licenses/by/4.0/", "start": {"date-parts": [[2023, 12, 19]]}, "content-version": "tdm", "delay-in-days": 0}
+    ],
+    "funder": [
+        {"DOI": "10.13039/100000001", "name": "National Science Foundation", "doi-asserted-by": "publisher", "award": ["OCE-1634002", "OCE-1758981"]},
+        {"name": "Simons Foundation"}
+    ],
+    "reference": [
+        {"key": "e_1_3_3_1_2", "DOI": "10.1126/SCIENCE.1234", "doi-asserted-by": "crossref"},
+        {"key": "e_1_3_3_2_2", "unstructured": "A. Author, An unlinked work (2001)."}
+    ],
+    "relation": {"has-preprint": [{"id-type": "doi", "id": "10.1101/2023.01.01.000001", "asserted-by": "subject"}], "cites": [], "unknown-relation": [{"id-type": "doi", "id": "10.1/x"}]},
+    "resource": {"primary": {"URL": "https://www.pnas.org/doi/10.1073/pnas.2302156120"}},
+    "URL": "https://doi.org/10.1073/pnas.2302156120"
+}}`)
+
+func TestRecordFromCrossrefJSON(t *testing.T) {
+	rec, err := RecordFromCrossrefJSON(crossrefTestWork)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := rec.Metadata
+	if resourceTypeID(rec) != "publication-article" || recordDOI(rec) != "10.1073/pnas.2302156120" || m.PublicationDate != "2023-12-19" {
+		t.Errorf("unexpected record %s", rec.ToString())
+	}
+	if m.Title != "Microbially induced precipitation of silica" || len(m.AdditionalTitles) != 1 || m.AdditionalTitles[0].Title != "a subtitle" {
+		t.Errorf("unexpected titles %s", rec.ToString())
+	}
+	journal := journalFields(rec)
+	if journal["title"] != "Proceedings of the National Academy of Sciences" || journal["volume"] != "120" || journal["issue"] != "51" || journal["pages"] != "e2302156120" || journal["issn"] != "0027-8424" {
+		t.Errorf("unexpected journal %v", journal)
+	}
+	if len(m.Creators) != 2 || m.Creators[0].PersonOrOrg.FamilyName != "Orphan" || !isOrganization(m.Creators[1].PersonOrOrg) {
+		t.Errorf("unexpected creators %s", rec.ToString())
+	}
+	if orcid := personIdentifier(m.Creators[0].PersonOrOrg, "orcid"); orcid != "0000-0002-5374-6178" {
+		t.Errorf("expected a bare ORCID, got %q", orcid)
+	}
+	if a := m.Creators[0].Affiliations; len(a) != 1 || a[0].ID != "05dxps055" || a[0].Name != "California Institute of Technology" {
+		t.Errorf("unexpected affiliations %s", rec.ToString())
+	}
+	if m.Description != "First paragraph.\n\nSecond paragraph." {
+		t.Errorf("unexpected abstract %q", m.Description)
+	}
+	if len(m.Rights) != 1 || m.Rights[0].ID != "cc-by-4.0" || m.Rights[0].Link != "https://creativecommons.org/licenses/by/4.0/" {
+		t.Errorf("expected a single cc-by-4.0 license %s", rec.ToString())
+	}
+	if len(m.Funding) != 3 {
+		t.Fatalf("expected an entry for each award and the funder without one %s", rec.ToString())
+	}
+	if f := m.Funding[1]; f.Funder.Identifier != "10.13039/100000001" || f.Funder.Scheme != "doi" || f.Award.Number != "OCE-1758981" {
+		t.Errorf("unexpected funding %+v", f)
+	}
+	if f := m.Funding[2]; f.Funder.Name != "Simons Foundation" || f.Award != nil {
+		t.Errorf("unexpected funding %+v", f)
+	}
+	if len(m.RelatedIdentifiers) != 2 {
+		t.Fatalf("expected a reference and a preprint %s", rec.ToString())
+	}
+	if r := m.RelatedIdentifiers[0]; r.Identifier != "10.1126/science.1234" || r.RelationType.ID != "references" {
+		t.Errorf("unexpected reference %+v", r)
+	}
+	if r := m.RelatedIdentifiers[1]; r.Identifier != "10.1101/2023.01.01.000001" || r.RelationType.ID != "hasversion" {
+		t.Errorf("unexpected relation %+v", r)
+	}
+	if identifierValue(m.Identifiers, "url") != "https://www.pnas.org/doi/10.1073/pnas.2302156120" || len(identifierValues(m.Identifiers, "issn")) != 2 {
+		t.Errorf("unexpected identifiers %s", rec.ToString())
+	}
+}
+
+func TestRecordsFromCrossrefJSON(t *testing.T) {
+	src := []byte(`{"status": "ok", "message-type": "work-list", "message": {"total-results": 2, "items": [
+    {"DOI": "10.7907/example", "type": "dissertation", "title": ["A Thesis"], "author": [{"given": "Jane", "family": "Doe", "sequence": "first"}],
+     "approved": {"date-parts": [[2024, 6]]}, "institution": [{"name": "California Institute of Technology", "department": ["Geology"]}], "degree": ["PhD"]},
+    {"DOI": "10.1000/book", "type": "book", "title": ["A Book"], "editor": [{"given": "Ed", "family": "Itor"}]}
+]}}`)
+	records, err := RecordsFromCrossrefJSON(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("expected two records, got %d", len(records))
+	}
+	thesis := thesisFields(records[0])
+	if resourceTypeID(records[0]) != "publication-thesis" || records[0].Metadata.PublicationDate != "2024-06" || thesis["university"] != "California Institute of Technology" || thesis["department"] != "Geology" || thesis["type"] != "PhD" {
+		t.Errorf("unexpected thesis %s", records[0].ToString())
+	}
+	if c := records[1].Metadata.Creators; len(c) != 1 || creatorRole(c[0]) != "editor" {
+		t.Errorf("expected the editor as creator %s", records[1].ToString())
+	}
+	if _, err := RecordFromCrossrefJSON(src); err == nil {
+		t.Errorf("expected an error for a list given to RecordFromCrossrefJSON")
+	}
+	if _, err := RecordsFromCrossrefJSON([]byte(`{"message": [`)); err == nil {
+		t.Errorf("expected an error for invalid JSON")
+	}
+}
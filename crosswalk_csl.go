@@ -0,0 +1,344 @@
+package simplified
+
+/**
+ * This file implements a crosswalk between the simplified Record and
+ * CSL-JSON, the input format of citeproc citation processors. See
+ * https://citeproc-js.readthedocs.io/en/latest/csl-json/markup.html
+ */
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// cslTypes maps InvenioRDM resource type ids to CSL item types.
+var cslTypes = map[string]string{
+	"publication-article":              "article-journal",
+	"publication-preprint":             "article",
+	"publication-book":                 "book",
+	"publication-section":              "chapter",
+	"publication-conferencepaper":      "paper-conference",
+	"publication-conferenceproceeding": "book",
+	"publication-report":               "report",
+	"publication-technicalnote":        "report",
+	"publication-workingpaper":         "report",
+	"publication-thesis":               "thesis",
+	"publication-patent":               "patent",
+	"publication-standard":             "standard",
+	"publication-deliverable":          "report",
+	"publication-datamanagementplan":   "report",
+	"publication-other":                "document",
+	"poster":                           "speech",
+	"presentation":                     "speech",
+	"dataset":                          "dataset",
+	"image":                            "graphic",
+	"image-figure":                     "figure",
+	"image-photo":                      "graphic",
+	"image-plot":                       "figure",
+	"video":                            "motion_picture",
+	"audio":                            "song",
+	"software":                         "software",
+	"other":                            "document",
+}
+
+// cslResourceTypes maps CSL item types to InvenioRDM resource type ids.
+var cslResourceTypes = map[string]string{
+	"article":            "publication-preprint",
+	"article-journal":    "publication-article",
+	"article-magazine":   "publication-article",
+	"article-newspaper":  "publication-article",
+	"book":               "publication-book",
+	"chapter":            "publication-section",
+	"entry":              "publication-section",
+	"entry-dictionary":   "publication-section",
+	"entry-encyclopedia": "publication-section",
+	"paper-conference":   "publication-conferencepaper",
+	"report":             "publication-report",
+	"thesis":             "publication-thesis",
+	"patent":             "publication-patent",
+	"standard":           "publication-standard",
+	"manuscript":         "publication-other",
+	"document":           "publication-other",
+	"speech":             "presentation",
+	"dataset":            "dataset",
+	"figure":             "image-figure",
+	"graphic":            "image",
+	"map":                "image",
+	"motion_picture":     "video",
+	"broadcast":          "video",
+	"song":               "audio",
+	"software":           "software",
+	"webpage":            "other",
+	"post":               "other",
+	"post-weblog":        "other",
+}
+
+// cslName is a CSL-JSON name. Organizations use Literal.
+type cslName struct {
+	Family              string `json:"family,omitempty"`
+	Given               string `json:"given,omitempty"`
+	NonDroppingParticle string `json:"non-dropping-particle,omitempty"`
+	DroppingParticle    string `json:"dropping-particle,omitempty"`
+	Suffix              string `json:"suffix,omitempty"`
+	Literal             string `json:"literal,omitempty"`
+}
+
+// cslDate is a CSL-JSON date. Date parts may be numbers or strings.
+type cslDate struct {
+	DateParts [][]interface{} `json:"date-parts,omitempty"`
+	Raw       string          `json:"raw,omitempty"`
+	Literal   string          `json:"literal,omitempty"`
+	Circa     interface{}     `json:"circa,omitempty"`
+}
+
+// cslItem is a CSL-JSON item. Number variables may be numbers or
+// strings so they are held as interface{}.
+type cslItem struct {
+	ID             interface{} `json:"id"`
+	Type           string      `json:"type"`
+	Title          string      `json:"title,omitempty"`
+	Author         []*cslName  `json:"author,omitempty"`
+	Editor         []*cslName  `json:"editor,omitempty"`
+	Translator     []*cslName  `json:"translator,omitempty"`
+	Issued         *cslDate    `json:"issued,omitempty"`
+	ContainerTitle string      `json:"container-title,omitempty"`
+	Volume         interface{} `json:"volume,omitempty"`
+	Issue          interface{} `json:"issue,omitempty"`
+	Page           interface{} `json:"page,omitempty"`
+	Publisher      string      `json:"publisher,omitempty"`
+	Version        interface{} `json:"version,omitempty"`
+	Language       string      `json:"language,omitempty"`
+	Abstract       string      `json:"abstract,omitempty"`
+	Keyword        string      `json:"keyword,omitempty"`
+	DOI            string      `json:"DOI,omitempty"`
+	ISBN           string      `json:"ISBN,omitempty"`
+	ISSN           string      `json:"ISSN,omitempty"`
+	URL            string      `json:"URL,omitempty"`
+}
+
+// cslString converts a CSL-JSON string or number variable to a string.
+func cslString(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return strings.TrimSpace(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return strings.TrimSpace(fmt.Sprintf("%v", v))
+}
+
+func cslNames(creators []*Creator) []*cslName {
+	names := []*cslName{}
+	for _, c := range creators {
+		if c == nil || c.PersonOrOrg == nil {
+			continue
+		}
+		if isOrganization(c.PersonOrOrg) {
+			names = append(names, &cslName{Literal: c.PersonOrOrg.Name})
+			continue
+		}
+		family, given := personNames(c.PersonOrOrg)
+		names = append(names, &cslName{Family: family, Given: given})
+	}
+	if len(names) == 0 {
+		return nil
+	}
+	return names
+}
+
+// cslCreator converts a CSL-JSON name to a creator. Particles and
+// suffixes are kept with the family name.
+func cslCreator(name *cslName, role string) *Creator {
+	if name == nil {
+		return nil
+	}
+	if name.Family == "" && name.Given == "" {
+		return newOrganization(name.Literal, role)
+	}
+	family := strings.TrimSpace(strings.Join([]string{name.DroppingParticle, name.NonDroppingParticle, name.Family}, " "))
+	if name.Suffix != "" {
+		family += ", " + name.Suffix
+	}
+	return newPerson(family, name.Given, role)
+}
+
+// cslIssued converts a publication date into CSL-JSON date parts.
+func cslIssued(date string) *cslDate {
+	if date == "" {
+		return nil
+	}
+	parts := []interface{}{}
+	year, month, day := dateParts(date)
+	for _, s := range []string{year, month, day} {
+		n, err := strconv.Atoi(s)
+		if err != nil || n == 0 {
+			break
+		}
+		parts = append(parts, n)
+	}
+	if len(parts) == 0 {
+		return &cslDate{Raw: date}
+	}
+	issued := &cslDate{DateParts: [][]interface{}{parts}}
+	if strings.ContainsAny(date, "?~") {
+		issued.Circa = true
+	}
+	return issued
+}
+
+// cslPublicationDate converts a CSL-JSON date into a publication date.
+// For a range the start date is used.
+func cslPublicationDate(d *cslDate) string {
+	if d == nil {
+		return ""
+	}
+	if len(d.DateParts) > 0 && len(d.DateParts[0]) > 0 {
+		parts := []string{"", "", ""}
+		for i, v := range d.DateParts[0] {
+			if i < len(parts) {
+				parts[i] = cslString(v)
+			}
+		}
+		return joinDate(parts[0], parts[1], parts[2])
+	}
+	if d.Raw != "" {
+		return d.Raw
+	}
+	return d.Literal
+}
+
+// AsCSLJSON renders the record as a CSL-JSON item for use with citeproc
+// citation processors. Creators become authors except those with the
+// editor role, editors and translators are taken from the creators and
+// contributors.
+//
+// ```
+//
+//	src, err := rec.AsCSLJSON()
+//	// ... handle error ...
+//	fmt.Printf("%s\n", src)
+//
+// ```
+func (rec *Record) AsCSLJSON() ([]byte, error) {
+	if rec == nil {
+		return nil, fmt.Errorf("no record")
+	}
+	m := rec.Metadata
+	if m == nil {
+		m = new(Metadata)
+	}
+	item := new(cslItem)
+	item.ID = rec.ID
+	if rec.ID == "" {
+		item.ID = bibTeXKey(rec)
+	}
+	item.Type = "document"
+	if cslType, ok := cslTypes[resourceTypeID(rec)]; ok {
+		item.Type = cslType
+	}
+	item.Title = m.Title
+	item.Author = cslNames(creatorsWithoutRole(m.Creators, "editor"))
+	item.Editor = cslNames(append(creatorsWithRole(m.Creators, "editor"), creatorsWithRole(m.Contributors, "editor")...))
+	item.Translator = cslNames(creatorsWithRole(m.Contributors, "translator"))
+	item.Issued = cslIssued(m.PublicationDate)
+	journal := journalFields(rec)
+	item.ContainerTitle = journal["title"]
+	if s := journal["volume"]; s != "" {
+		item.Volume = s
+	}
+	if s := journal["issue"]; s != "" {
+		item.Issue = s
+	}
+	if s := journal["pages"]; s != "" {
+		item.Page = s
+	}
+	item.Publisher = m.Publisher
+	if m.Version != "" {
+		item.Version = m.Version
+	}
+	for _, lang := range m.Languages {
+		if id, ok := lang["id"].(string); ok && id != "" {
+			item.Language = id
+			break
+		}
+	}
+	item.Abstract = stripHTML(m.Description)
+	item.Keyword = strings.Join(subjectLabels(m.Subjects), ", ")
+	item.DOI = recordDOI(rec)
+	item.ISBN = identifierValue(m.Identifiers, "isbn")
+	item.ISSN = recordISSN(rec)
+	item.URL = identifierValue(m.Identifiers, "url")
+	return json.MarshalIndent(item, "", "    ")
+}
+
+// RecordFromCSLJSON converts a CSL-JSON item into a Record. Journal
+// details are stored in the "journal:journal" custom field, the DOI
+// as an external PID and ISBN, ISSN and URL as identifiers.
+//
+// ```
+//
+//	rec, err := simplified.RecordFromCSLJSON(src)
+//	// ... handle error ...
+//	fmt.Printf("%s\n", rec.Metadata.Title)
+//
+// ```
+func RecordFromCSLJSON(src []byte) (*Record, error) {
+	item := new(cslItem)
+	if err := json.Unmarshal(src, &item); err != nil {
+		return nil, err
+	}
+	rec := new(Record)
+	m := ensureMetadata(rec)
+	resourceType, ok := cslResourceTypes[item.Type]
+	if !ok {
+		resourceType = "other"
+	}
+	setResourceType(rec, resourceType)
+	m.Title = strings.TrimSpace(item.Title)
+	for _, name := range item.Author {
+		if c := cslCreator(name, ""); c != nil {
+			m.Creators = append(m.Creators, c)
+		}
+	}
+	for _, name := range item.Editor {
+		c := cslCreator(name, "editor")
+		if c == nil {
+			continue
+		}
+		if len(item.Author) == 0 {
+			m.Creators = append(m.Creators, c)
+		} else {
+			m.Contributors = append(m.Contributors, c)
+		}
+	}
+	for _, name := range item.Translator {
+		if c := cslCreator(name, "translator"); c != nil {
+			m.Contributors = append(m.Contributors, c)
+		}
+	}
+	m.PublicationDate = cslPublicationDate(item.Issued)
+	setJournalField(rec, "title", item.ContainerTitle)
+	setJournalField(rec, "volume", cslString(item.Volume))
+	setJournalField(rec, "issue", cslString(item.Issue))
+	setJournalField(rec, "pages", strings.ReplaceAll(cslString(item.Page), "–", "-"))
+	m.Publisher = strings.TrimSpace(item.Publisher)
+	m.Version = cslString(item.Version)
+	if item.Language != "" {
+		m.Languages = []map[string]interface{}{{"id": item.Language}}
+	}
+	m.Description = strings.TrimSpace(item.Abstract)
+	for _, keyword := range strings.Split(item.Keyword, ",") {
+		if keyword = strings.TrimSpace(keyword); keyword != "" {
+			m.Subjects = append(m.Subjects, &Subject{Subject: keyword})
+		}
+	}
+	setRecordDOI(rec, item.DOI)
+	m.Identifiers = addIdentifier(m.Identifiers, "isbn", item.ISBN)
+	m.Identifiers = addIdentifier(m.Identifiers, "issn", item.ISSN)
+	m.Identifiers = addIdentifier(m.Identifiers, "url", item.URL)
+	return rec, nil
+}
@@ -0,0 +1,160 @@
+package simplified
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestAsCSLJSON(t *testing.T) {
+	rec := testRecord(t)
+	rec.Metadata.Contributors = append(rec.Metadata.Contributors, newPerson("Doe", "Jane", "editor"))
+	src, err := rec.AsCSLJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	item := map[string]interface{}{}
+	if err := json.Unmarshal(src, &item); err != nil {
+		t.Fatalf("expected valid JSON, %s\n%s", err, src)
+	}
+	for k, v := range map[string]string{
+		"id":              "rd9fg-k5282",
+		"type":            "article-journal",
+		"title":           "Microbially induced precipitation of silica",
+		"container-title": "PNAS",
+		"volume":          "120",
+		"issue":           "51",
+		"publisher":       "National Academy of Sciences",
+		"DOI":             "10.1073/pnas.2302156120",
+		"ISSN":            "1091-6490",
+		"abstract":        "An abstract.",
+		"keyword":         "Multidisciplinary",
+	} {
+		if s, _ := item[k].(string); s != v {
+			t.Errorf("expected %s %q, got %v", k, v, item[k])
+		}
+	}
+	for _, expected := range []string{
+		`"author": [`, `"family": "Orphan"`, `"given": "Victoria J."`,
+		`"editor": [`, `"family": "Doe"`,
+		"\"date-parts\": [\n            [\n                2023,\n                12,\n                19\n",
+	} {
+		if !strings.Contains(string(src), expected) {
+			t.Errorf("expected %q in CSL-JSON ->\n%s", expected, src)
+		}
+	}
+	if strings.Contains(string(src), "Caltech Library") {
+		t.Errorf("expected hosting institution contributor to be left out ->\n%s", src)
+	}
+}
+
+func TestRecordFromCSLJSON(t *testing.T) {
+	src := []byte(`{
+    "id": "item-1",
+    "type": "chapter",
+    "title": "A Chapter",
+    "author": [
+        {"family": "Beethoven", "given": "Ludwig", "non-dropping-particle": "van"},
+        {"literal": "Caltech Library"}
+    ],
+    "editor": [{"family": "Doe", "given": "Jane"}],
+    "issued": {"date-parts": [["2021", 3]]},
+    "container-title": "A Book",
+    "volume": 2,
+    "page": "15–23",
+    "ISBN": "978-3-16-148410-0",
+    "DOI": "https://doi.org/10.1000/XYZ",
+    "keyword": "music, history"
+}`)
+	rec, err := RecordFromCSLJSON(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := rec.Metadata
+	if id := resourceTypeID(rec); id != "publication-section" {
+		t.Errorf("expected publication-section, got %q", id)
+	}
+	if len(m.Creators) != 2 || m.Creators[0].PersonOrOrg.FamilyName != "van Beethoven" || m.Creators[1].PersonOrOrg.Type != "organizational" {
+		t.Errorf("unexpected creators %s", rec.ToString())
+	}
+	if len(m.Contributors) != 1 || creatorRole(m.Contributors[0]) != "editor" {
+		t.Errorf("expected an editor contributor, got %s", rec.ToString())
+	}
+	if m.PublicationDate != "2021-03" {
+		t.Errorf("expected 2021-03, got %q", m.PublicationDate)
+	}
+	journal := journalFields(rec)
+	for k, v := range map[string]string{"title": "A Book", "volume": "2", "pages": "15-23"} {
+		if journal[k] != v {
+			t.Errorf("expected journal %s %q, got %q", k, v, journal[k])
+		}
+	}
+	if doi := recordDOI(rec); doi != "10.1000/xyz" {
+		t.Errorf("expected normalized DOI, got %q", doi)
+	}
+	if isbn := identifierValue(m.Identifiers, "isbn"); isbn != "978-3-16-148410-0" {
+		t.Errorf("expected ISBN, got %q", isbn)
+	}
+	if len(m.Subjects) != 2 {
+		t.Errorf("expected two subjects, got %d", len(m.Subjects))
+	}
+	if _, err := RecordFromCSLJSON([]byte(`[{"type": "book"}]`)); err == nil {
+		t.Errorf("expected an error for a CSL-JSON array")
+	}
+}
+
+func TestCSLJSONRoundTrip(t *testing.T) {
+	rec := testRecord(t)
+	src, err := rec.AsCSLJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := RecordFromCSLJSON(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !sameJSON(got.Metadata.ResourceType["id"], rec.Metadata.ResourceType["id"]) {
+		t.Errorf("expected resource type to round trip, got %v", got.Metadata.ResourceType)
+	}
+	m1, m2 := rec.Metadata, got.Metadata
+	if !sameString(m1.Title, m2.Title) || !sameString(m1.PublicationDate, m2.PublicationDate) || !sameString(m1.Publisher, m2.Publisher) {
+		t.Errorf("expected title, date and publisher to round trip, got %s", got.ToString())
+	}
+	if !sameList(m1.Creators, m2.Creators, func(a *Creator, b *Creator) bool {
+		return a.PersonOrOrg.FamilyName == b.PersonOrOrg.FamilyName && a.PersonOrOrg.GivenName == b.PersonOrOrg.GivenName
+	}) {
+		t.Errorf("expected creators to round trip, got %s", got.ToString())
+	}
+	if !sameSet(m1.Subjects, m2.Subjects, (*Subject).IsSame) {
+		t.Errorf("expected subjects to round trip")
+	}
+	if !sameSet(m1.Identifiers, m2.Identifiers, (*Identifier).IsSame) {
+		t.Errorf("expected identifiers to round trip, got %+v", m2.Identifiers)
+	}
+	if recordDOI(got) != recordDOI(rec) {
+		t.Errorf("expected DOI to round trip")
+	}
+	j1, j2 := journalFields(rec), journalFields(got)
+	if !sameStringMap(j1, j2) {
+		t.Errorf("expected journal fields to round trip, %v != %v", j1, j2)
+	}
+
+	// A CSL-JSON item should survive a trip through Record.
+	item := []byte(`{"id": "x", "type": "dataset", "title": "Data", "author": [{"family": "Doe", "given": "Jane"}], "issued": {"date-parts": [[2020]]}, "publisher": "CaltechDATA", "version": "1.0"}`)
+	rec, err = RecordFromCSLJSON(item)
+	if err != nil {
+		t.Fatal(err)
+	}
+	src, err = rec.AsCSLJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, b := map[string]interface{}{}, map[string]interface{}{}
+	json.Unmarshal(item, &a)
+	json.Unmarshal(src, &b)
+	delete(a, "id")
+	delete(b, "id")
+	if !sameJSON(a, b) {
+		t.Errorf("expected CSL-JSON item to round trip, got %s", src)
+	}
+}
@@ -0,0 +1,1411 @@
+package simplified
+
+/**
+ * This file implements a crosswalk between the simplified Record and
+ * the DataCite Metadata Schema 4.5 in both its XML and JSON forms. See
+ * https://schema.datacite.org/meta/kernel-4.5/
+ *
+ * The JSON form is the "attributes" object used by the DataCite REST API.
+ * Records are mapped to the JSON structures then the JSON structures are
+ * converted to and from the XML structures.
+ */
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	// DataCiteNamespace is the XML namespace of DataCite Metadata Schema 4.x
+	DataCiteNamespace = "http://datacite.org/schema/kernel-4"
+	// DataCiteSchemaLocation is the location of the DataCite 4.5 XML schema
+	DataCiteSchemaLocation = "http://datacite.org/schema/kernel-4 http://schema.datacite.org/meta/kernel-4.5/metadata.xsd"
+
+	xsiNamespace = "http://www.w3.org/2001/XMLSchema-instance"
+)
+
+// dataCiteResourceTypes maps InvenioRDM resource type ids to the DataCite
+// resourceTypeGeneral vocabulary. Ids not listed use their prefix, e.g.
+// "image-photo" uses "image".
+var dataCiteResourceTypes = map[string]string{
+	"publication":                      "Text",
+	"publication-article":              "JournalArticle",
+	"publication-book":                 "Book",
+	"publication-section":              "BookChapter",
+	"publication-conferencepaper":      "ConferencePaper",
+	"publication-conferenceproceeding": "ConferenceProceeding",
+	"publication-thesis":               "Dissertation",
+	"publication-report":               "Report",
+	"publication-preprint":             "Preprint",
+	"publication-standard":             "Standard",
+	"publication-datamanagementplan":   "OutputManagementPlan",
+	"publication-peerreview":           "PeerReview",
+	"publication-journal":              "Journal",
+	"poster":                           "Text",
+	"presentation":                     "Text",
+	"dataset":                          "Dataset",
+	"image":                            "Image",
+	"video":                            "Audiovisual",
+	"audio":                            "Sound",
+	"software":                         "Software",
+	"software-computationalnotebook":   "ComputationalNotebook",
+	"model":                            "Model",
+	"physicalobject":                   "PhysicalObject",
+	"workflow":                         "Workflow",
+	"event":                            "Event",
+	"lesson":                           "InteractiveResource",
+	"other":                            "Other",
+}
+
+// dataCiteResourceTypeIDs maps DataCite resourceTypeGeneral to InvenioRDM
+// resource type ids.
+var dataCiteResourceTypeIDs = map[string]string{
+	"Text":                  "publication",
+	"JournalArticle":        "publication-article",
+	"DataPaper":             "publication-article",
+	"Book":                  "publication-book",
+	"BookChapter":           "publication-section",
+	"ConferencePaper":       "publication-conferencepaper",
+	"ConferenceProceeding":  "publication-conferenceproceeding",
+	"Dissertation":          "publication-thesis",
+	"Report":                "publication-report",
+	"Preprint":              "publication-preprint",
+	"Standard":              "publication-standard",
+	"OutputManagementPlan":  "publication-datamanagementplan",
+	"PeerReview":            "publication-peerreview",
+	"Journal":               "publication-journal",
+	"Dataset":               "dataset",
+	"Image":                 "image",
+	"Audiovisual":           "video",
+	"Sound":                 "audio",
+	"Software":              "software",
+	"ComputationalNotebook": "software-computationalnotebook",
+	"Model":                 "model",
+	"PhysicalObject":        "physicalobject",
+	"Workflow":              "workflow",
+	"Event":                 "event",
+	"InteractiveResource":   "lesson",
+}
+
+// The DataCite controlled vocabularies. InvenioRDM uses the lower case
+// (or hyphenated) form of the same terms for its ids.
+var (
+	dataCiteContributorTypes = []string{
+		"ContactPerson", "DataCollector", "DataCurator", "DataManager",
+		"Distributor", "Editor", "HostingInstitution", "Producer",
+		"ProjectLeader", "ProjectManager", "ProjectMember",
+		"RegistrationAgency", "RegistrationAuthority", "RelatedPerson",
+		"Researcher", "ResearchGroup", "RightsHolder", "Sponsor",
+		"Supervisor", "Translator", "WorkPackageLeader", "Other",
+	}
+	dataCiteDateTypes = []string{
+		"Accepted", "Available", "Copyrighted", "Collected", "Coverage",
+		"Created", "Issued", "Submitted", "Updated", "Valid", "Withdrawn",
+		"Other",
+	}
+	dataCiteTitleTypes = []string{
+		"AlternativeTitle", "Subtitle", "TranslatedTitle", "Other",
+	}
+	dataCiteDescriptionTypes = []string{
+		"Abstract", "Methods", "SeriesInformation", "TableOfContents",
+		"TechnicalInfo", "Other",
+	}
+	dataCiteRelationTypes = []string{
+		"IsCitedBy", "Cites", "IsSupplementTo", "IsSupplementedBy",
+		"IsContinuedBy", "Continues", "IsDescribedBy", "Describes",
+		"HasMetadata", "IsMetadataFor", "HasVersion", "IsVersionOf",
+		"IsNewVersionOf", "IsPreviousVersionOf", "IsPartOf", "HasPart",
+		"IsPublishedIn", "IsReferencedBy", "References", "IsDocumentedBy",
+		"Documents", "IsCompiledBy", "Compiles", "IsVariantFormOf",
+		"IsOriginalFormOf", "IsIdenticalTo", "IsReviewedBy", "Reviews",
+		"IsDerivedFrom", "IsSourceOf", "IsRequiredBy", "Requires",
+		"IsObsoletedBy", "Obsoletes", "IsCollectedBy", "Collects",
+		"IsTranslationOf", "HasTranslation",
+	}
+	dataCiteIdentifierTypes = []string{
+		"ARK", "arXiv", "bibcode", "CSTR", "DOI", "EAN13", "EISSN",
+		"Handle", "IGSN", "ISBN", "ISSN", "ISTC", "LISSN", "LSID", "PMID",
+		"PURL", "RRID", "UPC", "URL", "URN", "w3id",
+	}
+)
+
+// dataCiteTerm returns the DataCite vocabulary term matching an
+// InvenioRDM id ignoring case and hyphens, e.g. "table-of-contents"
+// matches "TableOfContents".
+func dataCiteTerm(vocabulary []string, id string) (string, bool) {
+	key := strings.ToLower(strings.NewReplacer("-", "", "_", "", " ", "").Replace(id))
+	for _, term := range vocabulary {
+		if strings.ToLower(term) == key {
+			return term, true
+		}
+	}
+	return "", false
+}
+
+// rdmTerm converts a DataCite vocabulary term into an InvenioRDM id,
+// e.g. "IsSupplementTo" becomes "issupplementto". If hyphenate is true
+// words are joined with hyphens, e.g. "TableOfContents" becomes
+// "table-of-contents".
+func rdmTerm(term string, hyphenate bool) string {
+	if !hyphenate {
+		return strings.ToLower(term)
+	}
+	var sb strings.Builder
+	for i, r := range term {
+		if i > 0 && r >= 'A' && r <= 'Z' {
+			sb.WriteRune('-')
+		}
+		sb.WriteRune(r)
+	}
+	return strings.ToLower(sb.String())
+}
+
+// dataCiteSchemes maps identifier schemes used for people and
+// organizations to the DataCite scheme names and scheme URIs.
+var dataCiteSchemes = map[string][2]string{
+	"orcid": {"ORCID", "https://orcid.org/"},
+	"isni":  {"ISNI", "https://isni.org/isni/"},
+	"ror":   {"ROR", "https://ror.org/"},
+	"gnd":   {"GND", "https://d-nb.info/gnd/"},
+	"doi":   {"Crossref Funder ID", "https://doi.org/"},
+}
+
+// dataCiteSchemeID returns the InvenioRDM scheme and bare identifier
+// for a DataCite name, affiliation or funder identifier.
+func dataCiteSchemeID(scheme string, value string) (string, string) {
+	value = strings.TrimSpace(value)
+	lower := strings.ToLower(scheme)
+	if lower == "crossref funder id" {
+		lower = "doi"
+	}
+	if info, ok := dataCiteSchemes[lower]; ok {
+		value = strings.TrimPrefix(value, info[1])
+		value = strings.TrimPrefix(value, strings.Replace(info[1], "https:", "http:", 1))
+		return lower, value
+	}
+	for key, info := range dataCiteSchemes {
+		if strings.HasPrefix(value, info[1]) {
+			return key, strings.TrimPrefix(value, info[1])
+		}
+	}
+	return lower, value
+}
+
+// dataCiteNumber is a number which may be encoded as a JSON string.
+type dataCiteNumber float64
+
+func (n *dataCiteNumber) UnmarshalJSON(src []byte) error {
+	s := strings.Trim(string(src), `"`)
+	if s == "" || s == "null" {
+		return nil
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return err
+	}
+	*n = dataCiteNumber(f)
+	return nil
+}
+
+// dataCiteString is a string which may be encoded as a JSON number,
+// e.g. publicationYear.
+type dataCiteString string
+
+func (s *dataCiteString) UnmarshalJSON(src []byte) error {
+	if len(src) > 0 && src[0] == '"' {
+		var str string
+		if err := json.Unmarshal(src, &str); err != nil {
+			return err
+		}
+		*s = dataCiteString(str)
+		return nil
+	}
+	if string(src) != "null" {
+		*s = dataCiteString(src)
+	}
+	return nil
+}
+
+//
+// DataCite JSON structures
+//
+
+type dataCiteNameIdentifier struct {
+	NameIdentifier       string `json:"nameIdentifier"`
+	NameIdentifierScheme string `json:"nameIdentifierScheme,omitempty"`
+	SchemeURI            string `json:"schemeUri,omitempty"`
+}
+
+type dataCiteAffiliation struct {
+	Name                        string `json:"name,omitempty"`
+	AffiliationIdentifier       string `json:"affiliationIdentifier,omitempty"`
+	AffiliationIdentifierScheme string `json:"affiliationIdentifierScheme,omitempty"`
+	SchemeURI                   string `json:"schemeUri,omitempty"`
+}
+
+// UnmarshalJSON accepts an affiliation given as a plain string, the
+// DataCite REST API default.
+func (a *dataCiteAffiliation) UnmarshalJSON(src []byte) error {
+	if len(src) > 0 && src[0] == '"' {
+		return json.Unmarshal(src, &a.Name)
+	}
+	type affiliation dataCiteAffiliation
+	return json.Unmarshal(src, (*affiliation)(a))
+}
+
+type dataCiteName struct {
+	Name            string                    `json:"name"`
+	NameType        string                    `json:"nameType,omitempty"`
+	GivenName       string                    `json:"givenName,omitempty"`
+	FamilyName      string                    `json:"familyName,omitempty"`
+	NameIdentifiers []*dataCiteNameIdentifier `json:"nameIdentifiers,omitempty"`
+	Affiliation     []*dataCiteAffiliation    `json:"affiliation,omitempty"`
+	ContributorType string                    `json:"contributorType,omitempty"`
+}
+
+type dataCiteTitle struct {
+	Title     string `json:"title"`
+	TitleType string `json:"titleType,omitempty"`
+	Lang      string `json:"lang,omitempty"`
+}
+
+type dataCitePublisher struct {
+	Name                      string `json:"name"`
+	PublisherIdentifier       string `json:"publisherIdentifier,omitempty"`
+	PublisherIdentifierScheme string `json:"publisherIdentifierScheme,omitempty"`
+	SchemeURI                 string `json:"schemeUri,omitempty"`
+	Lang                      string `json:"lang,omitempty"`
+}
+
+// UnmarshalJSON accepts a publisher given as a plain string as in
+// DataCite Metadata Schema 4.4 and earlier.
+func (p *dataCitePublisher) UnmarshalJSON(src []byte) error {
+	if len(src) > 0 && src[0] == '"' {
+		return json.Unmarshal(src, &p.Name)
+	}
+	type publisher dataCitePublisher
+	return json.Unmarshal(src, (*publisher)(p))
+}
+
+type dataCiteTypes struct {
+	ResourceTypeGeneral string `json:"resourceTypeGeneral"`
+	ResourceType        string `json:"resourceType,omitempty"`
+}
+
+type dataCiteSubject struct {
+	Subject       string `json:"subject"`
+	SubjectScheme string `json:"subjectScheme,omitempty"`
+	SchemeURI     string `json:"schemeUri,omitempty"`
+	ValueURI      string `json:"valueUri,omitempty"`
+	Lang          string `json:"lang,omitempty"`
+}
+
+type dataCiteDate struct {
+	Date            string `json:"date"`
+	DateType        string `json:"dateType"`
+	DateInformation string `json:"dateInformation,omitempty"`
+}
+
+type dataCiteIdentifier struct {
+	Identifier     string `json:"identifier"`
+	IdentifierType string `json:"identifierType"`
+}
+
+type dataCiteRelatedIdentifier struct {
+	RelatedIdentifier     string `json:"relatedIdentifier"`
+	RelatedIdentifierType string `json:"relatedIdentifierType"`
+	RelationType          string `json:"relationType"`
+	ResourceTypeGeneral   string `json:"resourceTypeGeneral,omitempty"`
+}
+
+type dataCiteRights struct {
+	Rights                 string `json:"rights,omitempty"`
+	RightsURI              string `json:"rightsUri,omitempty"`
+	RightsIdentifier       string `json:"rightsIdentifier,omitempty"`
+	RightsIdentifierScheme string `json:"rightsIdentifierScheme,omitempty"`
+	Lang                   string `json:"lang,omitempty"`
+}
+
+type dataCiteDescription struct {
+	Description     string `json:"description"`
+	DescriptionType string `json:"descriptionType"`
+	Lang            string `json:"lang,omitempty"`
+}
+
+type dataCitePoint struct {
+	PointLongitude dataCiteNumber `json:"pointLongitude"`
+	PointLatitude  dataCiteNumber `json:"pointLatitude"`
+}
+
+type dataCiteBox struct {
+	WestBoundLongitude dataCiteNumber `json:"westBoundLongitude"`
+	EastBoundLongitude dataCiteNumber `json:"eastBoundLongitude"`
+	SouthBoundLatitude dataCiteNumber `json:"southBoundLatitude"`
+	NorthBoundLatitude dataCiteNumber `json:"northBoundLatitude"`
+}
+
+type dataCiteGeoLocation struct {
+	GeoLocationPlace string         `json:"geoLocationPlace,omitempty"`
+	GeoLocationPoint *dataCitePoint `json:"geoLocationPoint,omitempty"`
+	GeoLocationBox   *dataCiteBox   `json:"geoLocationBox,omitempty"`
+}
+
+type dataCiteFundingReference struct {
+	FunderName           string `json:"funderName"`
+	FunderIdentifier     string `json:"funderIdentifier,omitempty"`
+	FunderIdentifierType string `json:"funderIdentifierType,omitempty"`
+	AwardNumber          string `json:"awardNumber,omitempty"`
+	AwardURI             string `json:"awardUri,omitempty"`
+	AwardTitle           string `json:"awardTitle,omitempty"`
+}
+
+// dataCiteJSON holds the attributes of a DataCite JSON record.
+type dataCiteJSON struct {
+	DOI                string                       `json:"doi,omitempty"`
+	Identifiers        []*dataCiteIdentifier        `json:"identifiers,omitempty"`
+	Creators           []*dataCiteName              `json:"creators"`
+	Titles             []*dataCiteTitle             `json:"titles"`
+	Publisher          *dataCitePublisher           `json:"publisher,omitempty"`
+	PublicationYear    dataCiteString               `json:"publicationYear,omitempty"`
+	Subjects           []*dataCiteSubject           `json:"subjects,omitempty"`
+	Contributors       []*dataCiteName              `json:"contributors,omitempty"`
+	Dates              []*dataCiteDate              `json:"dates,omitempty"`
+	Language           string                       `json:"language,omitempty"`
+	Types              *dataCiteTypes               `json:"types,omitempty"`
+	RelatedIdentifiers []*dataCiteRelatedIdentifier `json:"relatedIdentifiers,omitempty"`
+	Sizes              []string                     `json:"sizes,omitempty"`
+	Formats            []string                     `json:"formats,omitempty"`
+	Version            dataCiteString               `json:"version,omitempty"`
+	RightsList         []*dataCiteRights            `json:"rightsList,omitempty"`
+	Descriptions       []*dataCiteDescription       `json:"descriptions,omitempty"`
+	GeoLocations       []*dataCiteGeoLocation       `json:"geoLocations,omitempty"`
+	FundingReferences  []*dataCiteFundingReference  `json:"fundingReferences,omitempty"`
+	SchemaVersion      string                       `json:"schemaVersion,omitempty"`
+	// URL is the landing page the DOI resolves to, it is only
+	// present in DataCite REST API responses.
+	URL string `json:"url,omitempty"`
+}
+
+//
+// DataCite XML structures
+//
+
+type dataCiteXMLValue struct {
+	Type  string `xml:"identifierType,attr,omitempty"`
+	Value string `xml:",chardata"`
+}
+
+type dataCiteXMLNameValue struct {
+	NameType string `xml:"nameType,attr,omitempty"`
+	Lang     string `xml:"http://www.w3.org/XML/1998/namespace lang,attr,omitempty"`
+	Value    string `xml:",chardata"`
+}
+
+type dataCiteXMLNameIdentifier struct {
+	Scheme    string `xml:"nameIdentifierScheme,attr,omitempty"`
+	SchemeURI string `xml:"schemeURI,attr,omitempty"`
+	Value     string `xml:",chardata"`
+}
+
+type dataCiteXMLAffiliation struct {
+	Identifier string `xml:"affiliationIdentifier,attr,omitempty"`
+	Scheme     string `xml:"affiliationIdentifierScheme,attr,omitempty"`
+	SchemeURI  string `xml:"schemeURI,attr,omitempty"`
+	Value      string `xml:",chardata"`
+}
+
+// dataCiteXMLName is used for both creator and contributor elements.
+type dataCiteXMLName struct {
+	ContributorType string                       `xml:"contributorType,attr,omitempty"`
+	CreatorName     *dataCiteXMLNameValue        `xml:"creatorName,omitempty"`
+	ContributorName *dataCiteXMLNameValue        `xml:"contributorName,omitempty"`
+	GivenName       string                       `xml:"givenName,omitempty"`
+	FamilyName      string                       `xml:"familyName,omitempty"`
+	NameIdentifiers []*dataCiteXMLNameIdentifier `xml:"nameIdentifier,omitempty"`
+	Affiliations    []*dataCiteXMLAffiliation    `xml:"affiliation,omitempty"`
+}
+
+type dataCiteXMLTitle struct {
+	Lang      string `xml:"http://www.w3.org/XML/1998/namespace lang,attr,omitempty"`
+	TitleType string `xml:"titleType,attr,omitempty"`
+	Value     string `xml:",chardata"`
+}
+
+type dataCiteXMLPublisher struct {
+	Identifier string `xml:"publisherIdentifier,attr,omitempty"`
+	Scheme     string `xml:"publisherIdentifierScheme,attr,omitempty"`
+	SchemeURI  string `xml:"schemeURI,attr,omitempty"`
+	Lang       string `xml:"http://www.w3.org/XML/1998/namespace lang,attr,omitempty"`
+	Value      string `xml:",chardata"`
+}
+
+type dataCiteXMLResourceType struct {
+	ResourceTypeGeneral string `xml:"resourceTypeGeneral,attr"`
+	Value               string `xml:",chardata"`
+}
+
+type dataCiteXMLSubject struct {
+	Scheme    string `xml:"subjectScheme,attr,omitempty"`
+	SchemeURI string `xml:"schemeURI,attr,omitempty"`
+	ValueURI  string `xml:"valueURI,attr,omitempty"`
+	Lang      string `xml:"http://www.w3.org/XML/1998/namespace lang,attr,omitempty"`
+	Value     string `xml:",chardata"`
+}
+
+type dataCiteXMLDate struct {
+	DateType        string `xml:"dateType,attr"`
+	DateInformation string `xml:"dateInformation,attr,omitempty"`
+	Value           string `xml:",chardata"`
+}
+
+type dataCiteXMLAlternateIdentifier struct {
+	Type  string `xml:"alternateIdentifierType,attr"`
+	Value string `xml:",chardata"`
+}
+
+type dataCiteXMLRelatedIdentifier struct {
+	Type                string `xml:"relatedIdentifierType,attr"`
+	RelationType        string `xml:"relationType,attr"`
+	ResourceTypeGeneral string `xml:"resourceTypeGeneral,attr,omitempty"`
+	Value               string `xml:",chardata"`
+}
+
+type dataCiteXMLRights struct {
+	Lang       string `xml:"http://www.w3.org/XML/1998/namespace lang,attr,omitempty"`
+	URI        string `xml:"rightsURI,attr,omitempty"`
+	Identifier string `xml:"rightsIdentifier,attr,omitempty"`
+	Scheme     string `xml:"rightsIdentifierScheme,attr,omitempty"`
+	Value      string `xml:",chardata"`
+}
+
+type dataCiteXMLDescription struct {
+	Lang            string `xml:"http://www.w3.org/XML/1998/namespace lang,attr,omitempty"`
+	DescriptionType string `xml:"descriptionType,attr"`
+	Value           string `xml:",chardata"`
+}
+
+type dataCiteXMLPoint struct {
+	PointLongitude float64 `xml:"pointLongitude"`
+	PointLatitude  float64 `xml:"pointLatitude"`
+}
+
+type dataCiteXMLBox struct {
+	WestBoundLongitude float64 `xml:"westBoundLongitude"`
+	EastBoundLongitude float64 `xml:"eastBoundLongitude"`
+	SouthBoundLatitude float64 `xml:"southBoundLatitude"`
+	NorthBoundLatitude float64 `xml:"northBoundLatitude"`
+}
+
+type dataCiteXMLGeoLocation struct {
+	GeoLocationPlace string            `xml:"geoLocationPlace,omitempty"`
+	GeoLocationPoint *dataCiteXMLPoint `xml:"geoLocationPoint,omitempty"`
+	GeoLocationBox   *dataCiteXMLBox   `xml:"geoLocationBox,omitempty"`
+}
+
+type dataCiteXMLAwardNumber struct {
+	AwardURI string `xml:"awardURI,attr,omitempty"`
+	Value    string `xml:",chardata"`
+}
+
+type dataCiteXMLFunderIdentifier struct {
+	Type  string `xml:"funderIdentifierType,attr,omitempty"`
+	Value string `xml:",chardata"`
+}
+
+type dataCiteXMLFundingReference struct {
+	FunderName       string                       `xml:"funderName"`
+	FunderIdentifier *dataCiteXMLFunderIdentifier `xml:"funderIdentifier,omitempty"`
+	AwardNumber      *dataCiteXMLAwardNumber      `xml:"awardNumber,omitempty"`
+	AwardTitle       string                       `xml:"awardTitle,omitempty"`
+}
+
+// dataCiteXML is the root "resource" element of a DataCite XML record.
+type dataCiteXML struct {
+	XMLName              xml.Name                          `xml:"resource"`
+	Xmlns                string                            `xml:"xmlns,attr,omitempty"`
+	XmlnsXSI             string                            `xml:"xmlns:xsi,attr,omitempty"`
+	SchemaLocation       string                            `xml:"xsi:schemaLocation,attr,omitempty"`
+	Identifier           *dataCiteXMLValue                 `xml:"identifier,omitempty"`
+	Creators             []*dataCiteXMLName                `xml:"creators>creator"`
+	Titles               []*dataCiteXMLTitle               `xml:"titles>title"`
+	Publisher            *dataCiteXMLPublisher             `xml:"publisher,omitempty"`
+	PublicationYear      string                            `xml:"publicationYear,omitempty"`
+	ResourceType         *dataCiteXMLResourceType          `xml:"resourceType,omitempty"`
+	Subjects             []*dataCiteXMLSubject             `xml:"subjects>subject,omitempty"`
+	Contributors         []*dataCiteXMLName                `xml:"contributors>contributor,omitempty"`
+	Dates                []*dataCiteXMLDate                `xml:"dates>date,omitempty"`
+	Language             string                            `xml:"language,omitempty"`
+	AlternateIdentifiers []*dataCiteXMLAlternateIdentifier `xml:"alternateIdentifiers>alternateIdentifier,omitempty"`
+	RelatedIdentifiers   []*dataCiteXMLRelatedIdentifier   `xml:"relatedIdentifiers>relatedIdentifier,omitempty"`
+	Sizes                []string                          `xml:"sizes>size,omitempty"`
+	Formats              []string                          `xml:"formats>format,omitempty"`
+	Version              string                            `xml:"version,omitempty"`
+	RightsList           []*dataCiteXMLRights              `xml:"rightsList>rights,omitempty"`
+	Descriptions         []*dataCiteXMLDescription         `xml:"descriptions>description,omitempty"`
+	GeoLocations         []*dataCiteXMLGeoLocation         `xml:"geoLocations>geoLocation,omitempty"`
+	FundingReferences    []*dataCiteXMLFundingReference    `xml:"fundingReferences>fundingReference,omitempty"`
+}
+
+//
+// Record to DataCite
+//
+
+// dataCiteNameFromCreator converts a creator or contributor.
+func dataCiteNameFromCreator(c *Creator) *dataCiteName {
+	if c == nil || c.PersonOrOrg == nil {
+		return nil
+	}
+	p := c.PersonOrOrg
+	name := new(dataCiteName)
+	if isOrganization(p) {
+		name.NameType = "Organizational"
+		name.Name = p.Name
+	} else {
+		name.NameType = "Personal"
+		name.FamilyName, name.GivenName = personNames(p)
+		name.Name = name.FamilyName
+		if name.GivenName != "" {
+			name.Name = name.FamilyName + ", " + name.GivenName
+		}
+	}
+	for _, id := range p.Identifiers {
+		if id == nil || id.Identifier == "" {
+			continue
+		}
+		if info, ok := dataCiteSchemes[strings.ToLower(id.Scheme)]; ok && info[0] != "Crossref Funder ID" {
+			name.NameIdentifiers = append(name.NameIdentifiers, &dataCiteNameIdentifier{
+				NameIdentifier:       id.Identifier,
+				NameIdentifierScheme: info[0],
+				SchemeURI:            info[1],
+			})
+		}
+	}
+	for _, a := range c.Affiliations {
+		if a == nil {
+			continue
+		}
+		affiliation := &dataCiteAffiliation{Name: a.Name}
+		ror := a.ROR
+		if ror == "" {
+			ror = a.ID
+		}
+		if ror != "" {
+			_, ror = dataCiteSchemeID("ror", ror)
+			affiliation.AffiliationIdentifier = "https://ror.org/" + ror
+			affiliation.AffiliationIdentifierScheme = "ROR"
+			affiliation.SchemeURI = "https://ror.org/"
+		}
+		name.Affiliation = append(name.Affiliation, affiliation)
+	}
+	return name
+}
+
+// dataCiteIdentifierType returns the DataCite identifier type for
+// an InvenioRDM scheme.
+func dataCiteIdentifierType(scheme string) string {
+	if term, ok := dataCiteTerm(dataCiteIdentifierTypes, scheme); ok {
+		return term
+	}
+	return strings.ToUpper(scheme)
+}
+
+// dataCiteResourceTypeGeneral returns the resourceTypeGeneral for an
+// InvenioRDM resource type id.
+func dataCiteResourceTypeGeneral(id string) string {
+	if id == "" {
+		return ""
+	}
+	if general, ok := dataCiteResourceTypes[id]; ok {
+		return general
+	}
+	if prefix, _, ok := strings.Cut(id, "-"); ok {
+		if general, ok := dataCiteResourceTypes[prefix]; ok {
+			return general
+		}
+	}
+	return "Other"
+}
+
+// typeLang returns the id of a language type.
+func typeLang(lang *Type) string {
+	if lang == nil {
+		return ""
+	}
+	return lang.ID
+}
+
+// asDataCiteJSON maps a record to the DataCite JSON structures.
+func (rec *Record) asDataCiteJSON() *dataCiteJSON {
+	m := rec.Metadata
+	if m == nil {
+		m = new(Metadata)
+	}
+	dc := &dataCiteJSON{SchemaVersion: DataCiteNamespace}
+	dc.DOI = recordDOI(rec)
+	if dc.DOI != "" {
+		dc.Identifiers = append(dc.Identifiers, &dataCiteIdentifier{Identifier: dc.DOI, IdentifierType: "DOI"})
+	}
+	dc.Creators = []*dataCiteName{}
+	for _, c := range m.Creators {
+		if name := dataCiteNameFromCreator(c); name != nil {
+			dc.Creators = append(dc.Creators, name)
+		}
+	}
+	dc.Titles = []*dataCiteTitle{}
+	if m.Title != "" {
+		dc.Titles = append(dc.Titles, &dataCiteTitle{Title: m.Title})
+	}
+	for _, t := range m.AdditionalTitles {
+		if t == nil || t.Title == "" {
+			continue
+		}
+		title := &dataCiteTitle{Title: t.Title, TitleType: "Other", Lang: typeLang(t.Lang)}
+		if t.Type != nil {
+			if term, ok := dataCiteTerm(dataCiteTitleTypes, t.Type.ID); ok {
+				title.TitleType = term
+			}
+		}
+		dc.Titles = append(dc.Titles, title)
+	}
+	if m.Publisher != "" {
+		dc.Publisher = &dataCitePublisher{Name: m.Publisher}
+	}
+	year, _, _ := dateParts(m.PublicationDate)
+	dc.PublicationYear = dataCiteString(year)
+	for _, s := range m.Subjects {
+		if s == nil || (s.Subject == "" && s.ID == "") {
+			continue
+		}
+		subject := &dataCiteSubject{Subject: s.Subject}
+		if strings.HasPrefix(s.ID, "http") {
+			subject.ValueURI = s.ID
+		}
+		if subject.Subject == "" {
+			subject.Subject = s.ID
+		}
+		dc.Subjects = append(dc.Subjects, subject)
+	}
+	for _, c := range m.Contributors {
+		name := dataCiteNameFromCreator(c)
+		if name == nil {
+			continue
+		}
+		name.ContributorType = "Other"
+		if term, ok := dataCiteTerm(dataCiteContributorTypes, creatorRole(c)); ok {
+			name.ContributorType = term
+		}
+		dc.Contributors = append(dc.Contributors, name)
+	}
+	hasIssued := false
+	for _, d := range m.Dates {
+		if d == nil || d.Date == "" {
+			continue
+		}
+		date := &dataCiteDate{Date: d.Date, DateType: "Other", DateInformation: d.Description}
+		if d.Type != nil {
+			if term, ok := dataCiteTerm(dataCiteDateTypes, d.Type.ID); ok {
+				date.DateType = term
+			}
+		}
+		hasIssued = hasIssued || date.DateType == "Issued"
+		dc.Dates = append(dc.Dates, date)
+	}
+	if m.PublicationDate != "" && !hasIssued {
+		dc.Dates = append(dc.Dates, &dataCiteDate{Date: m.PublicationDate, DateType: "Issued"})
+	}
+	for _, lang := range m.Languages {
+		if id, ok := lang["id"].(string); ok && id != "" {
+			dc.Language = id
+			break
+		}
+	}
+	if id := resourceTypeID(rec); id != "" {
+		dc.Types = &dataCiteTypes{ResourceTypeGeneral: dataCiteResourceTypeGeneral(id), ResourceType: id}
+		if title, ok := m.ResourceType["title"].(map[string]interface{}); ok {
+			if s := localizedInterface(title); s != "" {
+				dc.Types.ResourceType = s
+			}
+		}
+	}
+	for _, id := range m.Identifiers {
+		if id == nil || id.Identifier == "" || (strings.EqualFold(id.Scheme, "doi") && normalizeIdentifierValue("doi", id.Identifier) == dc.DOI) {
+			continue
+		}
+		dc.Identifiers = append(dc.Identifiers, &dataCiteIdentifier{Identifier: id.Identifier, IdentifierType: dataCiteIdentifierType(id.Scheme)})
+	}
+	for _, id := range m.RelatedIdentifiers {
+		if id == nil || id.Identifier == "" {
+			continue
+		}
+		related := &dataCiteRelatedIdentifier{
+			RelatedIdentifier:     id.Identifier,
+			RelatedIdentifierType: dataCiteIdentifierType(id.Scheme),
+			RelationType:          "References",
+		}
+		if id.RelationType != nil {
+			if term, ok := dataCiteTerm(dataCiteRelationTypes, id.RelationType.ID); ok {
+				related.RelationType = term
+			}
+		}
+		if id.ResourceType != nil {
+			related.ResourceTypeGeneral = dataCiteResourceTypeGeneral(id.ResourceType.ID)
+		}
+		dc.RelatedIdentifiers = append(dc.RelatedIdentifiers, related)
+	}
+	if rec.Files != nil {
+		formats := map[string]bool{}
+		for _, f := range rec.Files.Formats {
+			formats[f] = true
+		}
+		for _, entry := range rec.Files.Entries {
+			if entry != nil && entry.MimeType != "" {
+				formats[entry.MimeType] = true
+			}
+		}
+		for f := range formats {
+			dc.Formats = append(dc.Formats, f)
+		}
+		sort.Strings(dc.Formats)
+		dc.Sizes = append(dc.Sizes, rec.Files.Sizes...)
+	}
+	dc.Version = dataCiteString(m.Version)
+	for _, r := range m.Rights {
+		if r == nil {
+			continue
+		}
+		rights := &dataCiteRights{Rights: localized(r.Title), RightsURI: r.Link, RightsIdentifier: r.ID}
+		if rightSPDX(r) != "" {
+			rights.RightsIdentifierScheme = "SPDX"
+		}
+		dc.RightsList = append(dc.RightsList, rights)
+	}
+	if m.Description != "" {
+		dc.Descriptions = append(dc.Descriptions, &dataCiteDescription{Description: m.Description, DescriptionType: "Abstract"})
+	}
+	for _, d := range m.AdditionalDescriptions {
+		if d == nil || d.Description == "" {
+			continue
+		}
+		description := &dataCiteDescription{Description: d.Description, DescriptionType: "Other", Lang: typeLang(d.Lang)}
+		if d.Type != nil {
+			if term, ok := dataCiteTerm(dataCiteDescriptionTypes, d.Type.ID); ok {
+				description.DescriptionType = term
+			}
+		}
+		dc.Descriptions = append(dc.Descriptions, description)
+	}
+	if rec.Files != nil && rec.Files.Locations != nil {
+		for _, feature := range rec.Files.Locations.Feature {
+			if feature == nil {
+				continue
+			}
+			location := &dataCiteGeoLocation{GeoLocationPlace: feature.Place}
+			if g := feature.Geometry; g != nil && strings.EqualFold(g.Type, "Point") && len(g.Coordinates) >= 2 {
+				location.GeoLocationPoint = &dataCitePoint{
+					PointLongitude: dataCiteNumber(g.Coordinates[0]),
+					PointLatitude:  dataCiteNumber(g.Coordinates[1]),
+				}
+			}
+			if location.GeoLocationPlace == "" {
+				location.GeoLocationPlace = feature.Description
+			}
+			if location.GeoLocationPlace != "" || location.GeoLocationPoint != nil {
+				dc.GeoLocations = append(dc.GeoLocations, location)
+			}
+		}
+	}
+	for _, f := range m.Funding {
+		if f == nil || f.Funder == nil {
+			continue
+		}
+		ref := &dataCiteFundingReference{FunderName: f.Funder.Name}
+		if id := f.Funder.Identifier; id != "" {
+			scheme, value := dataCiteSchemeID(f.Funder.Scheme, id)
+			if scheme == "" {
+				scheme = "ror"
+				if strings.HasPrefix(value, "10.13039/") {
+					scheme = "doi"
+				}
+			}
+			if info, ok := dataCiteSchemes[scheme]; ok {
+				ref.FunderIdentifier = info[1] + value
+				ref.FunderIdentifierType = info[0]
+			} else {
+				ref.FunderIdentifier = value
+				ref.FunderIdentifierType = "Other"
+			}
+		}
+		if a := f.Award; a != nil {
+			ref.AwardNumber = a.Number
+			if strings.HasPrefix(a.Identifier, "http") {
+				ref.AwardURI = a.Identifier
+			}
+			if a.Title != nil {
+				ref.AwardTitle = a.Title.Title
+			}
+		}
+		dc.FundingReferences = append(dc.FundingReferences, ref)
+	}
+	return dc
+}
+
+// xmlName converts a JSON name into a creator or contributor element.
+func (name *dataCiteName) xmlName(contributor bool) *dataCiteXMLName {
+	n := &dataCiteXMLName{
+		ContributorType: name.ContributorType,
+		GivenName:       name.GivenName,
+		FamilyName:      name.FamilyName,
+	}
+	value := &dataCiteXMLNameValue{NameType: name.NameType, Value: name.Name}
+	if contributor {
+		n.ContributorName = value
+	} else {
+		n.CreatorName = value
+	}
+	for _, id := range name.NameIdentifiers {
+		n.NameIdentifiers = append(n.NameIdentifiers, &dataCiteXMLNameIdentifier{Scheme: id.NameIdentifierScheme, SchemeURI: id.SchemeURI, Value: id.NameIdentifier})
+	}
+	for _, a := range name.Affiliation {
+		n.Affiliations = append(n.Affiliations, &dataCiteXMLAffiliation{Identifier: a.AffiliationIdentifier, Scheme: a.AffiliationIdentifierScheme, SchemeURI: a.SchemeURI, Value: a.Name})
+	}
+	return n
+}
+
+// jsonName converts a creator or contributor element into a JSON name.
+func (n *dataCiteXMLName) jsonName() *dataCiteName {
+	name := &dataCiteName{ContributorType: n.ContributorType, GivenName: n.GivenName, FamilyName: n.FamilyName}
+	value := n.CreatorName
+	if value == nil {
+		value = n.ContributorName
+	}
+	if value != nil {
+		name.Name, name.NameType = strings.TrimSpace(value.Value), value.NameType
+	}
+	for _, id := range n.NameIdentifiers {
+		name.NameIdentifiers = append(name.NameIdentifiers, &dataCiteNameIdentifier{NameIdentifier: strings.TrimSpace(id.Value), NameIdentifierScheme: id.Scheme, SchemeURI: id.SchemeURI})
+	}
+	for _, a := range n.Affiliations {
+		name.Affiliation = append(name.Affiliation, &dataCiteAffiliation{Name: strings.TrimSpace(a.Value), AffiliationIdentifier: a.Identifier, AffiliationIdentifierScheme: a.Scheme, SchemeURI: a.SchemeURI})
+	}
+	return name
+}
+
+// asXML converts the JSON structures into the XML structures.
+func (dc *dataCiteJSON) asXML() *dataCiteXML {
+	doc := &dataCiteXML{Xmlns: DataCiteNamespace, XmlnsXSI: xsiNamespace, SchemaLocation: DataCiteSchemaLocation}
+	if dc.DOI != "" {
+		doc.Identifier = &dataCiteXMLValue{Type: "DOI", Value: dc.DOI}
+	}
+	for _, name := range dc.Creators {
+		doc.Creators = append(doc.Creators, name.xmlName(false))
+	}
+	for _, t := range dc.Titles {
+		doc.Titles = append(doc.Titles, &dataCiteXMLTitle{Lang: t.Lang, TitleType: t.TitleType, Value: t.Title})
+	}
+	if p := dc.Publisher; p != nil {
+		doc.Publisher = &dataCiteXMLPublisher{Identifier: p.PublisherIdentifier, Scheme: p.PublisherIdentifierScheme, SchemeURI: p.SchemeURI, Lang: p.Lang, Value: p.Name}
+	}
+	doc.PublicationYear = string(dc.PublicationYear)
+	if dc.Types != nil {
+		doc.ResourceType = &dataCiteXMLResourceType{ResourceTypeGeneral: dc.Types.ResourceTypeGeneral, Value: dc.Types.ResourceType}
+	}
+	for _, s := range dc.Subjects {
+		doc.Subjects = append(doc.Subjects, &dataCiteXMLSubject{Scheme: s.SubjectScheme, SchemeURI: s.SchemeURI, ValueURI: s.ValueURI, Lang: s.Lang, Value: s.Subject})
+	}
+	for _, name := range dc.Contributors {
+		doc.Contributors = append(doc.Contributors, name.xmlName(true))
+	}
+	for _, d := range dc.Dates {
+		doc.Dates = append(doc.Dates, &dataCiteXMLDate{DateType: d.DateType, DateInformation: d.DateInformation, Value: d.Date})
+	}
+	doc.Language = dc.Language
+	for _, id := range dc.Identifiers {
+		// The DOI is the identifier element, others are alternate identifiers.
+		if id.IdentifierType == "DOI" && id.Identifier == dc.DOI {
+			continue
+		}
+		doc.AlternateIdentifiers = append(doc.AlternateIdentifiers, &dataCiteXMLAlternateIdentifier{Type: id.IdentifierType, Value: id.Identifier})
+	}
+	for _, id := range dc.RelatedIdentifiers {
+		doc.RelatedIdentifiers = append(doc.RelatedIdentifiers, &dataCiteXMLRelatedIdentifier{Type: id.RelatedIdentifierType, RelationType: id.RelationType, ResourceTypeGeneral: id.ResourceTypeGeneral, Value: id.RelatedIdentifier})
+	}
+	doc.Sizes, doc.Formats = dc.Sizes, dc.Formats
+	doc.Version = string(dc.Version)
+	for _, r := range dc.RightsList {
+		doc.RightsList = append(doc.RightsList, &dataCiteXMLRights{Lang: r.Lang, URI: r.RightsURI, Identifier: r.RightsIdentifier, Scheme: r.RightsIdentifierScheme, Value: r.Rights})
+	}
+	for _, d := range dc.Descriptions {
+		doc.Descriptions = append(doc.Descriptions, &dataCiteXMLDescription{Lang: d.Lang, DescriptionType: d.DescriptionType, Value: d.Description})
+	}
+	for _, g := range dc.GeoLocations {
+		location := &dataCiteXMLGeoLocation{GeoLocationPlace: g.GeoLocationPlace}
+		if p := g.GeoLocationPoint; p != nil {
+			location.GeoLocationPoint = &dataCiteXMLPoint{PointLongitude: float64(p.PointLongitude), PointLatitude: float64(p.PointLatitude)}
+		}
+		if b := g.GeoLocationBox; b != nil {
+			location.GeoLocationBox = &dataCiteXMLBox{
+				WestBoundLongitude: float64(b.WestBoundLongitude),
+				EastBoundLongitude: float64(b.EastBoundLongitude),
+				SouthBoundLatitude: float64(b.SouthBoundLatitude),
+				NorthBoundLatitude: float64(b.NorthBoundLatitude),
+			}
+		}
+		doc.GeoLocations = append(doc.GeoLocations, location)
+	}
+	for _, f := range dc.FundingReferences {
+		ref := &dataCiteXMLFundingReference{FunderName: f.FunderName, AwardTitle: f.AwardTitle}
+		if f.FunderIdentifier != "" {
+			ref.FunderIdentifier = &dataCiteXMLFunderIdentifier{Type: f.FunderIdentifierType, Value: f.FunderIdentifier}
+		}
+		if f.AwardNumber != "" || f.AwardURI != "" {
+			ref.AwardNumber = &dataCiteXMLAwardNumber{AwardURI: f.AwardURI, Value: f.AwardNumber}
+		}
+		doc.FundingReferences = append(doc.FundingReferences, ref)
+	}
+	return doc
+}
+
+// asJSON converts the XML structures into the JSON structures.
+func (doc *dataCiteXML) asJSON() *dataCiteJSON {
+	dc := new(dataCiteJSON)
+	if doc.Identifier != nil {
+		dc.DOI = strings.TrimSpace(doc.Identifier.Value)
+		if dc.DOI != "" {
+			dc.Identifiers = append(dc.Identifiers, &dataCiteIdentifier{Identifier: dc.DOI, IdentifierType: "DOI"})
+		}
+	}
+	for _, n := range doc.Creators {
+		dc.Creators = append(dc.Creators, n.jsonName())
+	}
+	for _, t := range doc.Titles {
+		dc.Titles = append(dc.Titles, &dataCiteTitle{Title: strings.TrimSpace(t.Value), TitleType: t.TitleType, Lang: t.Lang})
+	}
+	if p := doc.Publisher; p != nil {
+		dc.Publisher = &dataCitePublisher{Name: strings.TrimSpace(p.Value), PublisherIdentifier: p.Identifier, PublisherIdentifierScheme: p.Scheme, SchemeURI: p.SchemeURI, Lang: p.Lang}
+	}
+	dc.PublicationYear = dataCiteString(strings.TrimSpace(doc.PublicationYear))
+	if doc.ResourceType != nil {
+		dc.Types = &dataCiteTypes{ResourceTypeGeneral: doc.ResourceType.ResourceTypeGeneral, ResourceType: strings.TrimSpace(doc.ResourceType.Value)}
+	}
+	for _, s := range doc.Subjects {
+		dc.Subjects = append(dc.Subjects, &dataCiteSubject{Subject: strings.TrimSpace(s.Value), SubjectScheme: s.Scheme, SchemeURI: s.SchemeURI, ValueURI: s.ValueURI, Lang: s.Lang})
+	}
+	for _, n := range doc.Contributors {
+		dc.Contributors = append(dc.Contributors, n.jsonName())
+	}
+	for _, d := range doc.Dates {
+		dc.Dates = append(dc.Dates, &dataCiteDate{Date: strings.TrimSpace(d.Value), DateType: d.DateType, DateInformation: d.DateInformation})
+	}
+	dc.Language = strings.TrimSpace(doc.Language)
+	for _, id := range doc.AlternateIdentifiers {
+		dc.Identifiers = append(dc.Identifiers, &dataCiteIdentifier{Identifier: strings.TrimSpace(id.Value), IdentifierType: id.Type})
+	}
+	for _, id := range doc.RelatedIdentifiers {
+		dc.RelatedIdentifiers = append(dc.RelatedIdentifiers, &dataCiteRelatedIdentifier{RelatedIdentifier: strings.TrimSpace(id.Value), RelatedIdentifierType: id.Type, RelationType: id.RelationType, ResourceTypeGeneral: id.ResourceTypeGeneral})
+	}
+	dc.Sizes, dc.Formats = doc.Sizes, doc.Formats
+	dc.Version = dataCiteString(strings.TrimSpace(doc.Version))
+	for _, r := range doc.RightsList {
+		dc.RightsList = append(dc.RightsList, &dataCiteRights{Rights: strings.TrimSpace(r.Value), RightsURI: r.URI, RightsIdentifier: r.Identifier, RightsIdentifierScheme: r.Scheme, Lang: r.Lang})
+	}
+	for _, d := range doc.Descriptions {
+		dc.Descriptions = append(dc.Descriptions, &dataCiteDescription{Description: strings.TrimSpace(d.Value), DescriptionType: d.DescriptionType, Lang: d.Lang})
+	}
+	for _, g := range doc.GeoLocations {
+		location := &dataCiteGeoLocation{GeoLocationPlace: strings.TrimSpace(g.GeoLocationPlace)}
+		if p := g.GeoLocationPoint; p != nil {
+			location.GeoLocationPoint = &dataCitePoint{PointLongitude: dataCiteNumber(p.PointLongitude), PointLatitude: dataCiteNumber(p.PointLatitude)}
+		}
+		if b := g.GeoLocationBox; b != nil {
+			location.GeoLocationBox = &dataCiteBox{
+				WestBoundLongitude: dataCiteNumber(b.WestBoundLongitude),
+				EastBoundLongitude: dataCiteNumber(b.EastBoundLongitude),
+				SouthBoundLatitude: dataCiteNumber(b.SouthBoundLatitude),
+				NorthBoundLatitude: dataCiteNumber(b.NorthBoundLatitude),
+			}
+		}
+		dc.GeoLocations = append(dc.GeoLocations, location)
+	}
+	for _, f := range doc.FundingReferences {
+		ref := &dataCiteFundingReference{FunderName: strings.TrimSpace(f.FunderName), AwardTitle: strings.TrimSpace(f.AwardTitle)}
+		if f.FunderIdentifier != nil {
+			ref.FunderIdentifier, ref.FunderIdentifierType = strings.TrimSpace(f.FunderIdentifier.Value), f.FunderIdentifier.Type
+		}
+		if f.AwardNumber != nil {
+			ref.AwardNumber, ref.AwardURI = strings.TrimSpace(f.AwardNumber.Value), f.AwardNumber.AwardURI
+		}
+		dc.FundingReferences = append(dc.FundingReferences, ref)
+	}
+	return dc
+}
+
+// AsDataCiteXML renders the record as DataCite Metadata Schema 4.5 XML.
+//
+// ```
+//
+//	src, err := rec.AsDataCiteXML()
+//	// ... handle error ...
+//	os.WriteFile("datacite.xml", src, 0664)
+//
+// ```
+func (rec *Record) AsDataCiteXML() ([]byte, error) {
+	if rec == nil {
+		return nil, fmt.Errorf("no record")
+	}
+	src, err := xml.MarshalIndent(rec.asDataCiteJSON().asXML(), "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), src...), nil
+}
+
+// AsDataCiteJSON renders the record as DataCite JSON, the attributes
+// object used by the DataCite REST API.
+//
+// ```
+//
+//	src, err := rec.AsDataCiteJSON()
+//	// ... handle error ...
+//	fmt.Printf("%s\n", src)
+//
+// ```
+func (rec *Record) AsDataCiteJSON() ([]byte, error) {
+	if rec == nil {
+		return nil, fmt.Errorf("no record")
+	}
+	return json.MarshalIndent(rec.asDataCiteJSON(), "", "    ")
+}
+
+//
+// DataCite to Record
+//
+
+// creatorFromDataCite converts a DataCite name into a creator.
+func creatorFromDataCite(name *dataCiteName, role string) *Creator {
+	if name == nil {
+		return nil
+	}
+	var c *Creator
+	switch {
+	case name.FamilyName != "" || name.GivenName != "":
+		c = newPerson(name.FamilyName, name.GivenName, role)
+	case name.NameType == "Organizational" || (name.NameType == "" && !strings.Contains(name.Name, ",")):
+		c = newOrganization(name.Name, role)
+	default:
+		family, given, _ := strings.Cut(name.Name, ",")
+		c = newPerson(family, given, role)
+	}
+	for _, id := range name.NameIdentifiers {
+		scheme, value := dataCiteSchemeID(id.NameIdentifierScheme, id.NameIdentifier)
+		addPersonIdentifier(c, scheme, value)
+	}
+	for _, a := range name.Affiliation {
+		id := ""
+		if a.AffiliationIdentifier != "" {
+			_, id = dataCiteSchemeID(a.AffiliationIdentifierScheme, a.AffiliationIdentifier)
+		}
+		addAffiliation(c, a.Name, id)
+	}
+	return c
+}
+
+// asRecord maps the DataCite JSON structures to a record.
+func (dc *dataCiteJSON) asRecord() *Record {
+	rec := new(Record)
+	m := ensureMetadata(rec)
+	setRecordDOI(rec, dc.DOI)
+	for _, name := range dc.Creators {
+		if c := creatorFromDataCite(name, ""); c != nil {
+			m.Creators = append(m.Creators, c)
+		}
+	}
+	for _, name := range dc.Contributors {
+		role := "other"
+		if name.ContributorType != "" {
+			role = rdmTerm(name.ContributorType, false)
+		}
+		if c := creatorFromDataCite(name, role); c != nil {
+			m.Contributors = append(m.Contributors, c)
+		}
+	}
+	for _, t := range dc.Titles {
+		if t == nil || t.Title == "" {
+			continue
+		}
+		if t.TitleType == "" && m.Title == "" {
+			m.Title = t.Title
+			continue
+		}
+		title := &TitleDetail{Title: t.Title, Type: &Type{ID: rdmTerm(t.TitleType, true)}}
+		if t.TitleType == "" {
+			title.Type.ID = "other"
+		}
+		if t.Lang != "" {
+			title.Lang = &Type{ID: t.Lang}
+		}
+		m.AdditionalTitles = append(m.AdditionalTitles, title)
+	}
+	if dc.Publisher != nil {
+		m.Publisher = dc.Publisher.Name
+	}
+	for _, d := range dc.Dates {
+		if d == nil || d.Date == "" {
+			continue
+		}
+		if d.DateType == "Issued" && m.PublicationDate == "" {
+			m.PublicationDate = d.Date
+			continue
+		}
+		date := &DateType{Date: d.Date, Type: &Type{ID: rdmTerm(d.DateType, false)}, Description: d.DateInformation}
+		m.Dates = append(m.Dates, date)
+	}
+	if m.PublicationDate == "" {
+		m.PublicationDate = string(dc.PublicationYear)
+	}
+	if dc.Types != nil {
+		id, ok := dataCiteResourceTypeIDs[dc.Types.ResourceTypeGeneral]
+		if !ok {
+			id = "other"
+		}
+		// The resource type text may be a more specific InvenioRDM id.
+		if strings.HasPrefix(dc.Types.ResourceType, id+"-") {
+			id = dc.Types.ResourceType
+		}
+		setResourceType(rec, id)
+	}
+	for _, s := range dc.Subjects {
+		if s == nil || s.Subject == "" {
+			continue
+		}
+		subject := &Subject{Subject: s.Subject}
+		if s.ValueURI != "" {
+			subject.ID = s.ValueURI
+		}
+		m.Subjects = append(m.Subjects, subject)
+	}
+	if dc.Language != "" {
+		m.Languages = []map[string]interface{}{{"id": dc.Language}}
+	}
+	for _, id := range dc.Identifiers {
+		if id == nil || id.Identifier == "" || (id.IdentifierType == "DOI" && normalizeIdentifierValue("doi", id.Identifier) == recordDOI(rec)) {
+			continue
+		}
+		m.Identifiers = addIdentifier(m.Identifiers, strings.ToLower(id.IdentifierType), id.Identifier)
+	}
+	if dc.URL != "" && identifierValue(m.Identifiers, "url") == "" {
+		m.Identifiers = addIdentifier(m.Identifiers, "url", dc.URL)
+	}
+	for _, id := range dc.RelatedIdentifiers {
+		if id == nil || id.RelatedIdentifier == "" {
+			continue
+		}
+		related := &Identifier{
+			Scheme:       strings.ToLower(id.RelatedIdentifierType),
+			Identifier:   id.RelatedIdentifier,
+			RelationType: &TypeDetail{ID: rdmTerm(id.RelationType, false)},
+		}
+		if rt, ok := dataCiteResourceTypeIDs[id.ResourceTypeGeneral]; ok {
+			related.ResourceType = &TypeDetail{ID: rt}
+		}
+		m.RelatedIdentifiers = append(m.RelatedIdentifiers, related)
+	}
+	m.Version = string(dc.Version)
+	for _, r := range dc.RightsList {
+		if r == nil {
+			continue
+		}
+		right := &Right{ID: strings.ToLower(r.RightsIdentifier), Link: r.RightsURI}
+		if right.ID == "" {
+			// The DataCite REST API often only has the license URL.
+			if l := licenseRight(r.RightsURI); l != nil {
+				right.ID = l.ID
+			}
+		}
+		if r.Rights != "" {
+			lang := r.Lang
+			if lang == "" {
+				lang = "en"
+			}
+			right.Title = map[string]string{lang: r.Rights}
+		}
+		m.Rights = append(m.Rights, right)
+	}
+	for _, d := range dc.Descriptions {
+		if d == nil || d.Description == "" {
+			continue
+		}
+		if d.DescriptionType == "Abstract" && m.Description == "" {
+			m.Description = d.Description
+			continue
+		}
+		description := &Description{Description: d.Description, Type: &Type{ID: rdmTerm(d.DescriptionType, true)}}
+		if d.Lang != "" {
+			description.Lang = &Type{ID: d.Lang}
+		}
+		m.AdditionalDescriptions = append(m.AdditionalDescriptions, description)
+	}
+	features := []*Feature{}
+	for _, g := range dc.GeoLocations {
+		if g == nil {
+			continue
+		}
+		feature := &Feature{Place: g.GeoLocationPlace}
+		if p := g.GeoLocationPoint; p != nil {
+			feature.Geometry = &Geometry{Type: "Point", Coordinates: []float64{float64(p.PointLongitude), float64(p.PointLatitude)}}
+		} else if b := g.GeoLocationBox; b != nil {
+			// A Geometry only holds one position so a box is kept as its centre.
+			feature.Geometry = &Geometry{Type: "Point", Coordinates: []float64{
+				float64(b.WestBoundLongitude+b.EastBoundLongitude) / 2,
+				float64(b.SouthBoundLatitude+b.NorthBoundLatitude) / 2,
+			}}
+			feature.Description = fmt.Sprintf("bounding box %g %g %g %g", b.WestBoundLongitude, b.EastBoundLongitude, b.SouthBoundLatitude, b.NorthBoundLatitude)
+		}
+		if feature.Place != "" || feature.Geometry != nil {
+			features = append(features, feature)
+		}
+	}
+	if len(features) > 0 || len(dc.Formats) > 0 || len(dc.Sizes) > 0 {
+		rec.Files = &Files{Formats: dc.Formats, Sizes: dc.Sizes}
+		if len(features) > 0 {
+			rec.Files.Locations = &Location{Feature: features}
+		}
+	}
+	for _, f := range dc.FundingReferences {
+		if f == nil || (f.FunderName == "" && f.FunderIdentifier == "") {
+			continue
+		}
+		funder := &Funder{Funder: &FunderIdentifier{Name: f.FunderName}}
+		if f.FunderIdentifier != "" {
+			scheme, value := dataCiteSchemeID(f.FunderIdentifierType, f.FunderIdentifier)
+			funder.Funder.Identifier = value
+			if scheme == "doi" {
+				funder.Funder.Scheme = scheme
+			}
+		}
+		if f.AwardNumber != "" || f.AwardURI != "" || f.AwardTitle != "" {
+			funder.Award = &AwardIdentifier{Number: f.AwardNumber, Identifier: f.AwardURI}
+			if f.AwardTitle != "" {
+				funder.Award.Title = &TitleDetail{Title: f.AwardTitle}
+			}
+		}
+		m.Funding = append(m.Funding, funder)
+	}
+	return rec
+}
+
+// RecordFromDataCiteXML converts a DataCite Metadata Schema 4.x XML
+// document into a Record.
+//
+// ```
+//
+//	src, err := os.ReadFile("datacite.xml")
+//	// ... handle error ...
+//	rec, err := simplified.RecordFromDataCiteXML(src)
+//	// ... handle error ...
+//
+// ```
+func RecordFromDataCiteXML(src []byte) (*Record, error) {
+	doc := new(dataCiteXML)
+	if err := xml.Unmarshal(src, doc); err != nil {
+		return nil, err
+	}
+	return doc.asJSON().asRecord(), nil
+}
+
+// RecordFromDataCiteJSON converts DataCite JSON into a Record. The
+// attributes object may be given alone or inside a DataCite REST API
+// response, i.e. `{"data": {"attributes": {...}}}`. The "url"
+// attribute of a REST API response becomes the "url" identifier. See
+// RecordsFromDataCiteJSON for a list of DOIs.
+//
+// ```
+//
+//	src, err := os.ReadFile("datacite.json")
+//	// ... handle error ...
+//	rec, err := simplified.RecordFromDataCiteJSON(src)
+//	// ... handle error ...
+//
+// ```
+func RecordFromDataCiteJSON(src []byte) (*Record, error) {
+	records, err := RecordsFromDataCiteJSON(src)
+	if err != nil {
+		return nil, err
+	}
+	if len(records) != 1 {
+		return nil, fmt.Errorf("datacite, expected one DOI, found %d", len(records))
+	}
+	return records[0], nil
+}
+
+// dataCiteAPIItem is a DOI in a DataCite REST API response.
+type dataCiteAPIItem struct {
+	Attributes json.RawMessage `json:"attributes"`
+}
+
+// RecordsFromDataCiteJSON converts DataCite JSON into records. The
+// source may be a DataCite REST API response from the dois route, with
+// a single DOI (`{"data": {...}}`) or a list (`{"data": [...]}`), an
+// attributes object or an array of attributes objects.
+//
+// ```
+//
+//	src, err := io.ReadAll(os.Stdin)
+//	// ... handle error ...
+//	records, err := simplified.RecordsFromDataCiteJSON(src)
+//	// ... handle error ...
+//
+// ```
+func RecordsFromDataCiteJSON(src []byte) ([]*Record, error) {
+	attributes := []json.RawMessage{}
+	src = bytes.TrimSpace(src)
+	if bytes.HasPrefix(src, []byte("[")) {
+		if err := json.Unmarshal(src, &attributes); err != nil {
+			return nil, err
+		}
+	} else {
+		envelope := struct {
+			Data json.RawMessage `json:"data"`
+		}{}
+		if err := json.Unmarshal(src, &envelope); err != nil {
+			return nil, err
+		}
+		data := bytes.TrimSpace(envelope.Data)
+		switch {
+		case bytes.HasPrefix(data, []byte("[")):
+			items := []*dataCiteAPIItem{}
+			if err := json.Unmarshal(data, &items); err != nil {
+				return nil, err
+			}
+			for _, item := range items {
+				if item != nil && item.Attributes != nil {
+					attributes = append(attributes, item.Attributes)
+				}
+			}
+		case bytes.HasPrefix(data, []byte("{")):
+			item := new(dataCiteAPIItem)
+			if err := json.Unmarshal(data, item); err != nil {
+				return nil, err
+			}
+			if item.Attributes == nil {
+				return nil, fmt.Errorf("datacite, data has no attributes")
+			}
+			attributes = append(attributes, item.Attributes)
+		default:
+			attributes = append(attributes, src)
+		}
+	}
+	records := []*Record{}
+	for _, src := range attributes {
+		dc := new(dataCiteJSON)
+		if err := json.Unmarshal(src, dc); err != nil {
+			return nil, err
+		}
+		records = append(records, dc.asRecord())
+	}
+	return records, nil
+}
@@ -0,0 +1,323 @@
+package simplified
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+)
+
+// dataCiteTestRecord returns the test record with a location and
+// an editor added.
+func dataCiteTestRecord(t *testing.T) *Record {
+	rec := testRecord(t)
+	rec.Files.Locations = &Location{Feature: []*Feature{
+		{Place: "Pasadena, CA", Geometry: &Geometry{Type: "Point", Coordinates: []float64{-118.1253, 34.1377}}},
+	}}
+	rec.Metadata.Contributors = append(rec.Metadata.Contributors, newPerson("Doe", "Jane", "editor"))
+	return rec
+}
+
+func TestAsDataCiteXML(t *testing.T) {
+	rec := dataCiteTestRecord(t)
+	// Only SPDX licenses are given the SPDX scheme.
+	rec.Metadata.Rights = append(rec.Metadata.Rights, &Right{ID: "caltech-custom", Title: map[string]string{"en": "Caltech License"}, Link: "https://example.edu/license"})
+	src, err := rec.AsDataCiteXML()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{
+		`<resource xmlns="http://datacite.org/schema/kernel-4"`,
+		`xsi:schemaLocation="http://datacite.org/schema/kernel-4 http://schema.datacite.org/meta/kernel-4.5/metadata.xsd"`,
+		`<identifier identifierType="DOI">10.1073/pnas.2302156120</identifier>`,
+		`<creatorName nameType="Personal">Orphan, Victoria J.</creatorName>`,
+		`<nameIdentifier nameIdentifierScheme="ORCID" schemeURI="https://orcid.org/">0000-0002-5374-6178</nameIdentifier>`,
+		`<affiliation affiliationIdentifier="https://ror.org/05dxps055" affiliationIdentifierScheme="ROR" schemeURI="https://ror.org/">California Institute of Technology</affiliation>`,
+		`<title>Microbially induced precipitation of silica</title>`,
+		`<title titleType="Subtitle">a subtitle</title>`,
+		`<publisher>National Academy of Sciences</publisher>`,
+		`<publicationYear>2023</publicationYear>`,
+		`<resourceType resourceTypeGeneral="JournalArticle">Journal Article</resourceType>`,
+		`<contributor contributorType="HostingInstitution">`,
+		`<contributorName nameType="Organizational">Caltech Library</contributorName>`,
+		`<contributor contributorType="Editor">`,
+		`<date dateType="Accepted">2023-12-01</date>`,
+		`<date dateType="Issued">2023-12-19</date>`,
+		`<alternateIdentifier alternateIdentifierType="ISSN">1091-6490</alternateIdentifier>`,
+		`<relatedIdentifier relatedIdentifierType="URL" relationType="IsSupplementedBy">https://example.edu/supplement.pdf</relatedIdentifier>`,
+		`<format>application/pdf</format>`,
+		`<rights rightsURI="https://creativecommons.org/licenses/by/4.0/" rightsIdentifier="cc-by-4.0" rightsIdentifierScheme="SPDX">Creative Commons Attribution 4.0 International</rights>`,
+		`<rights rightsURI="https://example.edu/license" rightsIdentifier="caltech-custom">Caltech License</rights>`,
+		`<description descriptionType="Abstract">&lt;p&gt;An abstract.&lt;/p&gt;</description>`,
+		`<description descriptionType="Other">Thanks to all.</description>`,
+		`<geoLocationPlace>Pasadena, CA</geoLocationPlace>`,
+		`<pointLongitude>-118.1253</pointLongitude>`,
+		`<funderName>National Science Foundation</funderName>`,
+		`<funderIdentifier funderIdentifierType="ROR">https://ror.org/021nxhr62</funderIdentifier>`,
+		`<awardNumber>OCE-1634002</awardNumber>`,
+	} {
+		if !strings.Contains(string(src), expected) {
+			t.Errorf("expected %s in DataCite XML ->\n%s", expected, src)
+		}
+	}
+}
+
+func TestAsDataCiteJSON(t *testing.T) {
+	rec := dataCiteTestRecord(t)
+	src, err := rec.AsDataCiteJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	attributes := map[string]interface{}{}
+	if err := json.Unmarshal(src, &attributes); err != nil {
+		t.Fatal(err)
+	}
+	if attributes["doi"] != "10.1073/pnas.2302156120" || attributes["publicationYear"] != "2023" {
+		t.Errorf("expected doi and publicationYear, got %s", src)
+	}
+	for _, expected := range []string{
+		`"resourceTypeGeneral": "JournalArticle"`,
+		`"nameIdentifierScheme": "ORCID"`,
+		`"contributorType": "Editor"`,
+		`"relationType": "IsSupplementedBy"`,
+		`"geoLocationPoint": {`,
+		`"funderIdentifierType": "ROR"`,
+	} {
+		if !strings.Contains(string(src), expected) {
+			t.Errorf("expected %s in DataCite JSON ->\n%s", expected, src)
+		}
+	}
+}
+
+// sameCreatorNames compares creators ignoring the PersonOrOrg name
+// which crosswalks fill in from the family and given names.
+func sameCreatorNames(a *Creator, b *Creator) bool {
+	p1, p2 := *a.PersonOrOrg, *b.PersonOrOrg
+	if p1.Type == "personal" {
+		p1.Name, p2.Name = "", ""
+	}
+	return (&p1).IsSame(&p2) && creatorRole(a) == creatorRole(b) && sameSet(a.Affiliations, b.Affiliations, (*Affiliation).IsSame)
+}
+
+// checkDataCiteRoundTrip compares the fields DataCite can carry.
+func checkDataCiteRoundTrip(t *testing.T, format string, rec *Record, got *Record) {
+	m1, m2 := rec.Metadata, got.Metadata
+	if recordDOI(got) != recordDOI(rec) {
+		t.Errorf("%s: expected DOI to round trip, got %q", format, recordDOI(got))
+	}
+	if resourceTypeID(got) != resourceTypeID(rec) {
+		t.Errorf("%s: expected resource type %q, got %q", format, resourceTypeID(rec), resourceTypeID(got))
+	}
+	for _, field := range []struct {
+		name string
+		same bool
+	}{
+		{"title", sameString(m1.Title, m2.Title)},
+		{"publication_date", sameString(m1.PublicationDate, m2.PublicationDate)},
+		{"publisher", sameString(m1.Publisher, m2.Publisher)},
+		{"description", sameString(m1.Description, m2.Description)},
+		{"creators", sameList(m1.Creators, m2.Creators, sameCreatorNames)},
+		{"contributors", sameList(m1.Contributors, m2.Contributors, sameCreatorNames)},
+		{"additional_titles", sameList(m1.AdditionalTitles, m2.AdditionalTitles, func(a *TitleDetail, b *TitleDetail) bool {
+			return a.Title == b.Title && a.Type.ID == b.Type.ID
+		})},
+		{"dates", sameSet(m1.Dates, m2.Dates, (*DateType).IsSame)},
+		{"identifiers", sameSet(m1.Identifiers, m2.Identifiers, (*Identifier).IsSame)},
+		{"related_identifiers", sameSet(m1.RelatedIdentifiers, m2.RelatedIdentifiers, func(a *Identifier, b *Identifier) bool {
+			return a.Identifier == b.Identifier && a.RelationType.ID == b.RelationType.ID
+		})},
+		{"rights", sameSet(m1.Rights, m2.Rights, (*Right).IsSame)},
+		{"subjects", sameSet(m1.Subjects, m2.Subjects, (*Subject).IsSame)},
+		{"funding", sameSet(m1.Funding, m2.Funding, (*Funder).IsSame)},
+		{"locations", sameJSON(rec.Files.Locations, got.Files.Locations)},
+	} {
+		if !field.same {
+			t.Errorf("%s: expected %s to round trip ->\n%s", format, field.name, got.ToString())
+		}
+	}
+}
+
+func TestDataCiteRoundTrip(t *testing.T) {
+	rec := dataCiteTestRecord(t)
+	src, err := rec.AsDataCiteXML()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := RecordFromDataCiteXML(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkDataCiteRoundTrip(t, "XML", rec, got)
+
+	src, err = rec.AsDataCiteJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err = RecordFromDataCiteJSON(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkDataCiteRoundTrip(t, "JSON", rec, got)
+}
+
+func TestRecordFromDataCiteXMLFile(t *testing.T) {
+	// testdata/datacite.xml is a kernel-4 record as registered with
+	// DataCite.
+	src, err := os.ReadFile("testdata/datacite.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec, err := RecordFromDataCiteXML(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := rec.Metadata
+	if recordDOI(rec) != "10.22002/d1.20046" || resourceTypeID(rec) != "dataset" || m.PublicationDate != "2021-06-15" || m.Version != "1.0" {
+		t.Errorf("unexpected record %s", rec.ToString())
+	}
+	if m.Title != "Southern California seismic waveforms, 2021" || len(m.AdditionalTitles) != 1 || m.AdditionalTitles[0].Type.ID != "subtitle" {
+		t.Errorf("unexpected titles %s", rec.ToString())
+	}
+	if len(m.Creators) != 2 || personIdentifier(m.Creators[0].PersonOrOrg, "orcid") != "0000-0002-1825-0097" || m.Creators[0].Affiliations[0].ID != "05dxps055" || m.Creators[1].PersonOrOrg.Type != "organizational" {
+		t.Errorf("unexpected creators %s", rec.ToString())
+	}
+	if len(m.Contributors) != 1 || creatorRole(m.Contributors[0]) != "datacurator" {
+		t.Errorf("unexpected contributors %s", rec.ToString())
+	}
+	if len(m.Rights) != 1 || m.Rights[0].ID != "cc0-1.0" || len(m.RelatedIdentifiers) != 1 || len(m.Funding) != 1 {
+		t.Errorf("unexpected rights, related identifiers or funding %s", rec.ToString())
+	}
+	if rec.Files == nil || len(rec.Files.Locations.Feature) != 1 || rec.Files.Locations.Feature[0].Place != "Southern California" {
+		t.Errorf("expected a geo location %s", rec.ToString())
+	}
+}
+
+func TestRecordFromDataCiteJSON(t *testing.T) {
+	// A DataCite REST API response uses plain string affiliations,
+	// a string publisher and numeric years and coordinates as strings.
+	src := []byte(`{"data": {"id": "10.22002/d1.1234", "type": "dois", "attributes": {
+    "doi": "10.22002/D1.1234",
+    "creators": [
+        {"name": "Caltech Seismological Laboratory", "nameType": "Organizational"},
+        {"name": "Doe, Jane", "affiliation": ["Caltech"], "nameIdentifiers": [{"nameIdentifier": "https://orcid.org/0000-0002-1825-0097", "nameIdentifierScheme": "ORCID"}]}
+    ],
+    "titles": [{"title": "Seismic data"}],
+    "publisher": "CaltechDATA",
+    "publicationYear": 2021,
+    "types": {"resourceTypeGeneral": "Dataset", "resourceType": "Dataset"},
+    "geoLocations": [{"geoLocationBox": {"westBoundLongitude": "-120", "eastBoundLongitude": "-116", "southBoundLatitude": "32", "northBoundLatitude": "36"}}],
+    "fundingReferences": [{"funderName": "NSF", "funderIdentifier": "https://doi.org/10.13039/100000001", "funderIdentifierType": "Crossref Funder ID"}]
+}}}`)
+	rec, err := RecordFromDataCiteJSON(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := rec.Metadata
+	if recordDOI(rec) != "10.22002/d1.1234" || m.Title != "Seismic data" || m.Publisher != "CaltechDATA" || m.PublicationDate != "2021" {
+		t.Errorf("unexpected record %s", rec.ToString())
+	}
+	if resourceTypeID(rec) != "dataset" {
+		t.Errorf("expected dataset, got %q", resourceTypeID(rec))
+	}
+	if len(m.Creators) != 2 || m.Creators[0].PersonOrOrg.Type != "organizational" || m.Creators[1].PersonOrOrg.FamilyName != "Doe" {
+		t.Errorf("unexpected creators %s", rec.ToString())
+	}
+	if orcid := personIdentifier(m.Creators[1].PersonOrOrg, "orcid"); orcid != "0000-0002-1825-0097" {
+		t.Errorf("expected bare ORCID, got %q", orcid)
+	}
+	if len(m.Creators[1].Affiliations) != 1 || m.Creators[1].Affiliations[0].Name != "Caltech" {
+		t.Errorf("expected a Caltech affiliation, got %s", rec.ToString())
+	}
+	if f := m.Funding[0].Funder; f.Identifier != "10.13039/100000001" || f.Scheme != "doi" {
+		t.Errorf("expected a Crossref funder id, got %+v", f)
+	}
+	if g := rec.Files.Locations.Feature[0].Geometry; g.Coordinates[0] != -118 || g.Coordinates[1] != 34 {
+		t.Errorf("expected the centre of the bounding box, got %v", g.Coordinates)
+	}
+	if _, err := RecordFromDataCiteXML([]byte(`<resource><titles>`)); err == nil {
+		t.Errorf("expected an error for truncated XML")
+	}
+}
+
+func TestRecordFromDataCiteJSONFile(t *testing.T) {
+	// testdata/datacite.json is a full REST API response, requested with
+	// affiliation=true, for the record in testdata/datacite.xml. It
+	// includes the attributes which are not mapped.
+	src, err := os.ReadFile("testdata/datacite.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec, err := RecordFromDataCiteJSON(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	xmlSrc, err := os.ReadFile("testdata/datacite.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fromXML, err := RecordFromDataCiteXML(xmlSrc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, x := rec.Metadata, fromXML.Metadata
+	if recordDOI(rec) != recordDOI(fromXML) || m.Title != x.Title || m.PublicationDate != x.PublicationDate || m.Publisher != x.Publisher || m.Description != x.Description {
+		t.Errorf("expected the same record as the XML\n%s\n%s", rec.ToString(), fromXML.ToString())
+	}
+	if !sameList(m.Creators, x.Creators, sameCreatorNames) || !sameList(m.Contributors, x.Contributors, sameCreatorNames) {
+		t.Errorf("expected the same names as the XML\n%s", rec.ToString())
+	}
+	if !sameSet(m.RelatedIdentifiers, x.RelatedIdentifiers, (*Identifier).IsSame) || !sameSet(m.Rights, x.Rights, (*Right).IsSame) {
+		t.Errorf("expected the same related identifiers and rights as the XML\n%s", rec.ToString())
+	}
+	if identifierValue(m.Identifiers, "url") != "https://data.caltech.edu/records/20046" || len(m.Subjects) != 2 {
+		t.Errorf("expected the landing page and subjects %s", rec.ToString())
+	}
+}
+
+func TestRecordsFromDataCiteJSON(t *testing.T) {
+	// A DataCite REST API list response with affiliation=true.
+	src := []byte(`{"data": [{"id": "10.22002/d1.1", "type": "dois", "attributes": {
+    "doi": "10.22002/d1.1",
+    "url": "https://data.caltech.edu/records/1",
+    "creators": [{"name": "Doe, Jane", "nameType": "Personal", "givenName": "Jane", "familyName": "Doe",
+        "affiliation": [{"name": "California Institute of Technology", "affiliationIdentifier": "https://ror.org/05dxps055", "affiliationIdentifierScheme": "ROR"}]}],
+    "titles": [{"title": "First"}],
+    "publisher": {"name": "CaltechDATA"},
+    "publicationYear": "2023",
+    "types": {"resourceTypeGeneral": "Dataset"},
+    "rightsList": [{"rightsUri": "https://creativecommons.org/licenses/by/4.0/legalcode"}],
+    "relatedIdentifiers": [{"relatedIdentifier": "10.1073/pnas.2302156120", "relatedIdentifierType": "DOI", "relationType": "References"}],
+    "fundingReferences": [{"funderName": "NSF", "funderIdentifier": "https://ror.org/021nxhr62", "funderIdentifierType": "ROR", "awardNumber": "OCE-1634002", "awardTitle": "Methane seeps"}]
+}}, {"id": "10.22002/d1.2", "type": "dois", "attributes": {"doi": "10.22002/d1.2", "titles": [{"title": "Second"}]}}],
+"meta": {"total": 2}}`)
+	records, err := RecordsFromDataCiteJSON(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 2 || records[1].Metadata.Title != "Second" {
+		t.Fatalf("expected two records, got %d", len(records))
+	}
+	rec := records[0]
+	m := rec.Metadata
+	if identifierValue(m.Identifiers, "url") != "https://data.caltech.edu/records/1" {
+		t.Errorf("expected the url attribute as an identifier %s", rec.ToString())
+	}
+	if a := m.Creators[0].Affiliations; len(a) != 1 || a[0].ID != "05dxps055" {
+		t.Errorf("expected a ROR affiliation %s", rec.ToString())
+	}
+	if len(m.Rights) != 1 || m.Rights[0].ID != "cc-by-4.0" {
+		t.Errorf("expected the license id from its URL %s", rec.ToString())
+	}
+	if len(m.RelatedIdentifiers) != 1 || m.RelatedIdentifiers[0].RelationType.ID != "references" {
+		t.Errorf("expected a reference %s", rec.ToString())
+	}
+	if f := m.Funding[0]; f.Funder.Identifier != "021nxhr62" || f.Award == nil || f.Award.Number != "OCE-1634002" || f.Award.Title.Title != "Methane seeps" {
+		t.Errorf("unexpected funding %s", rec.ToString())
+	}
+	if _, err := RecordFromDataCiteJSON(src); err == nil {
+		t.Errorf("expected an error for a list given to RecordFromDataCiteJSON")
+	}
+	if _, err := RecordsFromDataCiteJSON([]byte(`{"data": {"id": "x"}}`)); err == nil {
+		t.Errorf("expected an error for data without attributes")
+	}
+}
@@ -0,0 +1,407 @@
+package simplified
+
+/**
+ * This file implements a crosswalk between the simplified Record and
+ * simple Dublin Core as used by the OAI-PMH oai_dc metadata format. See
+ * https://www.openarchives.org/OAI/openarchivesprotocol.html#dublincore
+ *
+ * Dublin Core is flat so the crosswalk is lossy, identifiers are written
+ * as URLs or "scheme:value" strings so they can be recognized when read
+ * back.
+ */
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const (
+	// OAIDCNamespace is the XML namespace of the OAI-PMH oai_dc format.
+	OAIDCNamespace = "http://www.openarchives.org/OAI/2.0/oai_dc/"
+	// OAIDCSchemaLocation is the location of the oai_dc XML schema.
+	OAIDCSchemaLocation = "http://www.openarchives.org/OAI/2.0/oai_dc/ http://www.openarchives.org/OAI/2.0/oai_dc.xsd"
+	// DublinCoreNamespace is the XML namespace of the Dublin Core elements.
+	DublinCoreNamespace = "http://purl.org/dc/elements/1.1/"
+)
+
+// dublinCoreTypes maps InvenioRDM resource type id prefixes to the DCMI
+// Type vocabulary.
+var dublinCoreTypes = map[string]string{
+	"publication":    "Text",
+	"poster":         "Text",
+	"presentation":   "Text",
+	"lesson":         "Text",
+	"dataset":        "Dataset",
+	"software":       "Software",
+	"image":          "StillImage",
+	"video":          "MovingImage",
+	"audio":          "Sound",
+	"event":          "Event",
+	"physicalobject": "PhysicalObject",
+	"model":          "InteractiveResource",
+	"workflow":       "Software",
+	"other":          "Other",
+}
+
+// dublinCoreResourceTypes maps the DCMI Type vocabulary to InvenioRDM
+// resource type ids. It is used when no resource type id is found in the
+// type elements.
+var dublinCoreResourceTypes = map[string]string{
+	"text":                "publication",
+	"dataset":             "dataset",
+	"software":            "software",
+	"image":               "image",
+	"stillimage":          "image",
+	"movingimage":         "video",
+	"sound":               "audio",
+	"event":               "event",
+	"physicalobject":      "physicalobject",
+	"interactiveresource": "model",
+	"collection":          "other",
+	"service":             "other",
+	"other":               "other",
+}
+
+// dublinCoreURLs holds URL prefixes used to write identifiers.
+var dublinCoreURLs = map[string]string{
+	"doi":   "https://doi.org/",
+	"orcid": "https://orcid.org/",
+	"ror":   "https://ror.org/",
+	"arxiv": "https://arxiv.org/abs/",
+}
+
+// dublinCoreXML is the "oai_dc:dc" element. Elements are written with
+// their prefix as encoding/xml does not manage namespace prefixes.
+type dublinCoreXML struct {
+	XMLName        xml.Name `xml:"oai_dc:dc"`
+	XmlnsOAIDC     string   `xml:"xmlns:oai_dc,attr"`
+	XmlnsDC        string   `xml:"xmlns:dc,attr"`
+	XmlnsXSI       string   `xml:"xmlns:xsi,attr"`
+	SchemaLocation string   `xml:"xsi:schemaLocation,attr"`
+	Title          []string `xml:"dc:title"`
+	Creator        []string `xml:"dc:creator"`
+	Subject        []string `xml:"dc:subject"`
+	Description    []string `xml:"dc:description"`
+	Publisher      []string `xml:"dc:publisher"`
+	Contributor    []string `xml:"dc:contributor"`
+	Date           []string `xml:"dc:date"`
+	Type           []string `xml:"dc:type"`
+	Format         []string `xml:"dc:format"`
+	Identifier     []string `xml:"dc:identifier"`
+	Source         []string `xml:"dc:source"`
+	Language       []string `xml:"dc:language"`
+	Relation       []string `xml:"dc:relation"`
+	Coverage       []string `xml:"dc:coverage"`
+	Rights         []string `xml:"dc:rights"`
+}
+
+// dublinCoreType returns the DCMI Type for a resource type id.
+func dublinCoreType(id string) string {
+	if dcType, ok := dublinCoreTypes[id]; ok {
+		return dcType
+	}
+	if prefix, _, ok := strings.Cut(id, "-"); ok {
+		if dcType, ok := dublinCoreTypes[prefix]; ok {
+			return dcType
+		}
+	}
+	return "Other"
+}
+
+// dublinCoreIdentifier formats an identifier as a URL when the scheme
+// has one, otherwise as "scheme:value".
+func dublinCoreIdentifier(scheme string, value string) string {
+	scheme = strings.ToLower(scheme)
+	switch {
+	case value == "":
+		return ""
+	case scheme == "url" || scheme == "":
+		return value
+	case strings.HasPrefix(value, "http://") || strings.HasPrefix(value, "https://"):
+		return value
+	}
+	if prefix, ok := dublinCoreURLs[scheme]; ok {
+		return prefix + value
+	}
+	return scheme + ":" + value
+}
+
+// dublinCoreSchemeID splits an identifier written by
+// dublinCoreIdentifier into its scheme and value.
+func dublinCoreSchemeID(s string) (string, string) {
+	s = strings.TrimSpace(s)
+	for scheme, prefix := range dublinCoreURLs {
+		for _, p := range []string{prefix, strings.Replace(prefix, "https:", "http:", 1)} {
+			if strings.HasPrefix(s, p) {
+				return scheme, strings.TrimPrefix(s, p)
+			}
+		}
+	}
+	if strings.HasPrefix(s, "http://") || strings.HasPrefix(s, "https://") {
+		return "url", s
+	}
+	if scheme, value, ok := strings.Cut(s, ":"); ok && scheme != "" && !strings.ContainsAny(scheme, " /") {
+		return strings.ToLower(scheme), strings.TrimSpace(value)
+	}
+	return "other", s
+}
+
+// AsDublinCore renders the record as an OAI-PMH "oai_dc:dc" element.
+// Creators and contributors are written as "Family, Given", the type
+// holds the DCMI Type followed by the resource type id, identifiers and
+// related identifiers are written as URLs or "scheme:value" strings.
+//
+// ```
+//
+//	src, err := rec.AsDublinCore()
+//	// ... handle error ...
+//	fmt.Printf("%s\n", src)
+//
+// ```
+func (rec *Record) AsDublinCore() ([]byte, error) {
+	if rec == nil {
+		return nil, fmt.Errorf("no record")
+	}
+	m := rec.Metadata
+	if m == nil {
+		m = new(Metadata)
+	}
+	dc := &dublinCoreXML{
+		XmlnsOAIDC:     OAIDCNamespace,
+		XmlnsDC:        DublinCoreNamespace,
+		XmlnsXSI:       xsiNamespace,
+		SchemaLocation: OAIDCSchemaLocation,
+	}
+	add := func(l []string, values ...string) []string {
+		for _, value := range values {
+			if value = strings.TrimSpace(value); value != "" {
+				l = append(l, value)
+			}
+		}
+		return l
+	}
+	dc.Title = add(dc.Title, m.Title)
+	for _, t := range m.AdditionalTitles {
+		if t != nil {
+			dc.Title = add(dc.Title, t.Title)
+		}
+	}
+	for _, c := range m.Creators {
+		if c != nil {
+			dc.Creator = add(dc.Creator, personOrOrgName(c.PersonOrOrg))
+		}
+	}
+	dc.Subject = add(dc.Subject, subjectLabels(m.Subjects)...)
+	dc.Description = add(dc.Description, stripHTML(m.Description))
+	for _, d := range m.AdditionalDescriptions {
+		if d != nil {
+			dc.Description = add(dc.Description, stripHTML(d.Description))
+		}
+	}
+	dc.Publisher = add(dc.Publisher, m.Publisher)
+	for _, c := range m.Contributors {
+		if c != nil {
+			dc.Contributor = add(dc.Contributor, personOrOrgName(c.PersonOrOrg))
+		}
+	}
+	dc.Date = add(dc.Date, m.PublicationDate)
+	if id := resourceTypeID(rec); id != "" {
+		dc.Type = add(dc.Type, dublinCoreType(id), id)
+	}
+	if rec.Files != nil {
+		for _, e := range sortedEntries(rec.Files.Entries) {
+			if e != nil && !contains(dc.Format, e.MimeType) {
+				dc.Format = add(dc.Format, e.MimeType)
+			}
+		}
+	}
+	dc.Identifier = add(dc.Identifier, dublinCoreIdentifier("doi", recordDOI(rec)))
+	for _, id := range m.Identifiers {
+		if id != nil && !strings.EqualFold(id.Scheme, "doi") {
+			dc.Identifier = add(dc.Identifier, dublinCoreIdentifier(id.Scheme, id.Identifier))
+		}
+	}
+	if journal := journalFields(rec); journal["title"] != "" {
+		dc.Source = add(dc.Source, journalLabel(rec))
+	}
+	for _, lang := range m.Languages {
+		if id, ok := lang["id"].(string); ok {
+			dc.Language = add(dc.Language, id)
+		}
+	}
+	for _, id := range m.RelatedIdentifiers {
+		if id != nil {
+			dc.Relation = add(dc.Relation, dublinCoreIdentifier(id.Scheme, id.Identifier))
+		}
+	}
+	for _, r := range m.Rights {
+		if r == nil {
+			continue
+		}
+		if r.Link != "" {
+			dc.Rights = add(dc.Rights, r.Link)
+		} else if label := localized(r.Title); label != "" {
+			dc.Rights = add(dc.Rights, label)
+		} else {
+			dc.Rights = add(dc.Rights, r.ID)
+		}
+	}
+	src, err := xml.MarshalIndent(dc, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), src...), nil
+}
+
+// contains reports if a string is in a list.
+func contains(l []string, s string) bool {
+	for _, v := range l {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// dublinCoreElements holds the fifteen Dublin Core element names.
+var dublinCoreElements = []string{
+	"title", "creator", "subject", "description", "publisher",
+	"contributor", "date", "type", "format", "identifier", "source",
+	"language", "relation", "coverage", "rights",
+}
+
+// isDublinCoreElement reports if an element is a Dublin Core element.
+// Elements without a namespace are matched on their local name.
+func isDublinCoreElement(name xml.Name) bool {
+	if name.Space != DublinCoreNamespace && name.Space != "" {
+		return false
+	}
+	return contains(dublinCoreElements, name.Local)
+}
+
+// dublinCoreName converts a creator or contributor element. Names
+// containing a comma are treated as "Family, Given", others as
+// organizations.
+func dublinCoreName(name string, role string) *Creator {
+	family, given, ok := strings.Cut(name, ",")
+	if !ok {
+		return newOrganization(name, role)
+	}
+	return newPerson(family, given, role)
+}
+
+// RecordFromDublinCore reads a simple Dublin Core record, e.g. an
+// "oai_dc:dc" element. Dublin Core elements are collected wherever they
+// are found so an OAI-PMH GetRecord response can be read directly.
+// Elements without a namespace are matched on their local name.
+//
+// ```
+//
+//	src, err := os.ReadFile("record.xml")
+//	// ... handle error ...
+//	rec, err := simplified.RecordFromDublinCore(src)
+//	// ... handle error ...
+//
+// ```
+func RecordFromDublinCore(src []byte) (*Record, error) {
+	elements := map[string][]string{}
+	decoder := xml.NewDecoder(bytes.NewReader(src))
+	for {
+		tok, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("dublin core, %s", err)
+		}
+		start, ok := tok.(xml.StartElement)
+		if !ok || !isDublinCoreElement(start.Name) {
+			continue
+		}
+		var value string
+		if err := decoder.DecodeElement(&value, &start); err != nil {
+			return nil, fmt.Errorf("dublin core %s, %s", start.Name.Local, err)
+		}
+		if value = strings.TrimSpace(value); value != "" {
+			elements[start.Name.Local] = append(elements[start.Name.Local], value)
+		}
+	}
+	if len(elements) == 0 {
+		return nil, fmt.Errorf("dublin core, no elements found")
+	}
+	rec := new(Record)
+	m := ensureMetadata(rec)
+	for i, title := range elements["title"] {
+		if i == 0 {
+			m.Title = title
+			continue
+		}
+		m.AdditionalTitles = append(m.AdditionalTitles, &TitleDetail{Title: title, Type: &Type{ID: "alternative-title"}})
+	}
+	for _, name := range elements["creator"] {
+		m.Creators = append(m.Creators, dublinCoreName(name, ""))
+	}
+	for _, name := range elements["contributor"] {
+		m.Contributors = append(m.Contributors, dublinCoreName(name, "other"))
+	}
+	for _, subject := range elements["subject"] {
+		m.Subjects = append(m.Subjects, &Subject{Subject: subject})
+	}
+	for i, description := range elements["description"] {
+		if i == 0 {
+			m.Description = description
+			continue
+		}
+		m.AdditionalDescriptions = append(m.AdditionalDescriptions, &Description{Description: description, Type: &Type{ID: "other"}})
+	}
+	if publishers := elements["publisher"]; len(publishers) > 0 {
+		m.Publisher = publishers[0]
+	}
+	if dates := elements["date"]; len(dates) > 0 {
+		m.PublicationDate = dates[0]
+	}
+	// A resource type id is preferred over the DCMI Type.
+	resourceType := ""
+	for _, t := range elements["type"] {
+		if id, ok := dublinCoreResourceTypes[strings.ToLower(t)]; ok {
+			if resourceType == "" {
+				resourceType = id
+			}
+		} else if dublinCoreType(t) != "Other" || t == "other" {
+			resourceType = t
+		}
+	}
+	if resourceType != "" {
+		setResourceType(rec, resourceType)
+	}
+	for _, identifier := range elements["identifier"] {
+		scheme, value := dublinCoreSchemeID(identifier)
+		if scheme == "doi" && recordDOI(rec) == "" {
+			setRecordDOI(rec, value)
+			continue
+		}
+		m.Identifiers = addIdentifier(m.Identifiers, scheme, value)
+	}
+	for _, lang := range elements["language"] {
+		m.Languages = append(m.Languages, map[string]interface{}{"id": lang})
+	}
+	for _, relation := range elements["relation"] {
+		scheme, value := dublinCoreSchemeID(relation)
+		m.RelatedIdentifiers = append(m.RelatedIdentifiers, &Identifier{
+			Scheme:       scheme,
+			Identifier:   value,
+			RelationType: &TypeDetail{ID: "references"},
+		})
+	}
+	for _, rights := range elements["rights"] {
+		if strings.HasPrefix(rights, "http://") || strings.HasPrefix(rights, "https://") {
+			m.Rights = append(m.Rights, &Right{Link: rights})
+		} else {
+			m.Rights = append(m.Rights, &Right{Title: map[string]string{"en": rights}})
+		}
+	}
+	return rec, nil
+}
@@ -0,0 +1,133 @@
+package simplified
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+)
+
+func TestAsDublinCore(t *testing.T) {
+	rec := testRecord(t)
+	rec.Metadata.Languages = []map[string]interface{}{{"id": "eng"}}
+	src, err := rec.AsDublinCore()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc struct {
+		XMLName xml.Name
+		Titles  []string `xml:"http://purl.org/dc/elements/1.1/ title"`
+	}
+	if err := xml.Unmarshal(src, &doc); err != nil {
+		t.Fatalf("expected well formed XML, %s\n%s", err, src)
+	}
+	if doc.XMLName.Space != OAIDCNamespace || doc.XMLName.Local != "dc" || len(doc.Titles) != 2 {
+		t.Errorf("expected an oai_dc:dc element with two titles, got %+v\n%s", doc, src)
+	}
+	for _, expected := range []string{
+		`xmlns:oai_dc="http://www.openarchives.org/OAI/2.0/oai_dc/"`,
+		`xmlns:dc="http://purl.org/dc/elements/1.1/"`,
+		"<dc:title>Microbially induced precipitation of silica</dc:title>",
+		"<dc:creator>Orphan, Victoria J.</dc:creator>",
+		"<dc:subject>Multidisciplinary</dc:subject>",
+		"<dc:description>An abstract.</dc:description>",
+		"<dc:publisher>National Academy of Sciences</dc:publisher>",
+		"<dc:contributor>Caltech Library</dc:contributor>",
+		"<dc:date>2023-12-19</dc:date>",
+		"<dc:type>Text</dc:type>",
+		"<dc:type>publication-article</dc:type>",
+		"<dc:format>application/pdf</dc:format>",
+		"<dc:identifier>https://doi.org/10.1073/pnas.2302156120</dc:identifier>",
+		"<dc:identifier>issn:1091-6490</dc:identifier>",
+		"<dc:source>PNAS, volume 120, issue 51</dc:source>",
+		"<dc:language>eng</dc:language>",
+		"<dc:relation>https://example.edu/supplement.pdf</dc:relation>",
+		"<dc:rights>https://creativecommons.org/licenses/by/4.0/</dc:rights>",
+	} {
+		if !strings.Contains(string(src), expected) {
+			t.Errorf("expected %q in Dublin Core ->\n%s", expected, src)
+		}
+	}
+}
+
+func TestRecordFromDublinCore(t *testing.T) {
+	src := []byte(`<?xml version="1.0" encoding="UTF-8"?>
+<OAI-PMH xmlns="http://www.openarchives.org/OAI/2.0/">
+  <GetRecord>
+    <record>
+      <header>
+        <identifier>oai:example.edu:1</identifier>
+        <datestamp>2023-12-20</datestamp>
+      </header>
+      <metadata>
+        <oai_dc:dc xmlns:oai_dc="http://www.openarchives.org/OAI/2.0/oai_dc/" xmlns:dc="http://purl.org/dc/elements/1.1/">
+          <dc:title>Seismic data</dc:title>
+          <dc:creator>Doe, Jane</dc:creator>
+          <dc:creator>Caltech Library</dc:creator>
+          <dc:contributor>Roe, Richard</dc:contributor>
+          <dc:subject>seismology</dc:subject>
+          <dc:description>Some data.</dc:description>
+          <dc:date>2021-03</dc:date>
+          <dc:type>Dataset</dc:type>
+          <dc:identifier>https://doi.org/10.22002/D1.1</dc:identifier>
+          <dc:identifier>isbn:978-3-16-148410-0</dc:identifier>
+          <dc:identifier>https://data.example.edu/1</dc:identifier>
+          <dc:language>en</dc:language>
+          <dc:relation>arxiv:2101.00001</dc:relation>
+          <dc:rights>CC0</dc:rights>
+        </oai_dc:dc>
+      </metadata>
+    </record>
+  </GetRecord>
+</OAI-PMH>`)
+	rec, err := RecordFromDublinCore(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := rec.Metadata
+	if m.Title != "Seismic data" || m.Description != "Some data." || m.PublicationDate != "2021-03" {
+		t.Errorf("unexpected title, description or date %s", rec.ToString())
+	}
+	if resourceTypeID(rec) != "dataset" {
+		t.Errorf("expected dataset, got %q", resourceTypeID(rec))
+	}
+	if len(m.Creators) != 2 || m.Creators[0].PersonOrOrg.FamilyName != "Doe" || !isOrganization(m.Creators[1].PersonOrOrg) {
+		t.Errorf("unexpected creators %s", rec.ToString())
+	}
+	if len(m.Contributors) != 1 || creatorRole(m.Contributors[0]) != "other" {
+		t.Errorf("unexpected contributors %s", rec.ToString())
+	}
+	if recordDOI(rec) != "10.22002/d1.1" || identifierValue(m.Identifiers, "isbn") != "978-3-16-148410-0" || identifierValue(m.Identifiers, "url") != "https://data.example.edu/1" {
+		t.Errorf("unexpected identifiers %s", rec.ToString())
+	}
+	if len(m.RelatedIdentifiers) != 1 || m.RelatedIdentifiers[0].Scheme != "arxiv" || m.RelatedIdentifiers[0].Identifier != "2101.00001" {
+		t.Errorf("unexpected related identifiers %s", rec.ToString())
+	}
+	if len(m.Rights) != 1 || m.Rights[0].Title["en"] != "CC0" {
+		t.Errorf("unexpected rights %s", rec.ToString())
+	}
+	if _, err := RecordFromDublinCore([]byte(`<record/>`)); err == nil {
+		t.Errorf("expected an error for a document without Dublin Core elements")
+	}
+}
+
+func TestDublinCoreRoundTrip(t *testing.T) {
+	rec1 := testRecord(t)
+	src, err := rec1.AsDublinCore()
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec2, err := RecordFromDublinCore(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m1, m2 := rec1.Metadata, rec2.Metadata
+	if m1.Title != m2.Title || m1.PublicationDate != m2.PublicationDate || m1.Publisher != m2.Publisher {
+		t.Errorf("expected title, date and publisher to round trip\n%s", rec2.ToString())
+	}
+	if resourceTypeID(rec2) != "publication-article" || recordDOI(rec2) != recordDOI(rec1) || recordISSN(rec2) != "1091-6490" {
+		t.Errorf("expected resource type, DOI and ISSN to round trip\n%s", rec2.ToString())
+	}
+	if len(m2.Creators) != 1 || m2.Creators[0].PersonOrOrg.FamilyName != "Orphan" || m2.Creators[0].PersonOrOrg.GivenName != "Victoria J." {
+		t.Errorf("expected creator to round trip\n%s", rec2.ToString())
+	}
+}
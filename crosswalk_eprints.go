@@ -0,0 +1,433 @@
+package simplified
+
+/**
+ * This file implements writing a Record as EPrints 3.3 XML for records
+ * that go back into an EPrints repository. The eprints package holds the
+ * reverse direction. The mappings here mirror those in eprints/record.go.
+ */
+
+import (
+	"encoding/xml"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// EPrintsNamespace is the XML namespace of EPrints 3.3 export XML.
+const EPrintsNamespace = "http://eprints.org/ep2/data/2.0"
+
+// eprintsTypes maps InvenioRDM resource type ids to an EPrints type and,
+// for monographs and conference items, the monograph or presentation type.
+var eprintsTypes = map[string][2]string{
+	"publication-article":         {"article", ""},
+	"publication-book":            {"book", ""},
+	"publication-section":         {"book_section", ""},
+	"publication-conferencepaper": {"conference_item", ""},
+	"poster":                      {"conference_item", "poster"},
+	"presentation":                {"conference_item", "speech"},
+	"publication-report":          {"monograph", ""},
+	"publication-technicalnote":   {"monograph", "technical_report"},
+	"publication-workingpaper":    {"monograph", "working_paper"},
+	"publication-thesis":          {"thesis", ""},
+	"publication-patent":          {"patent", ""},
+	"lesson":                      {"teaching_resource", ""},
+	"dataset":                     {"dataset", ""},
+	"software":                    {"software", ""},
+	"image":                       {"image", ""},
+	"video":                       {"video", ""},
+	"audio":                       {"audio", ""},
+}
+
+// eprintsDateTypes maps InvenioRDM date type ids to EPrints date types.
+var eprintsDateTypes = map[string]string{
+	"submitted": "submitted",
+	"accepted":  "inpress",
+	"created":   "completed",
+	"issued":    "degree",
+}
+
+// eprintsRelators maps InvenioRDM role ids to Library of Congress
+// relator codes used for the contributors type.
+var eprintsRelators = map[string]string{
+	"researcher":    "RTH",
+	"datacollector": "COL",
+	"datacurator":   "CUR",
+	"producer":      "PRO",
+	"sponsor":       "SPN",
+	"distributor":   "DST",
+	"rightsholder":  "CPH",
+}
+
+// eprintsRelatedURLTypes maps relation type ids to related_url types.
+var eprintsRelatedURLTypes = map[string]string{
+	"isversionof":      "pub",
+	"isdescribedby":    "author",
+	"issupplementedby": "supp",
+}
+
+type eprintsXMLName struct {
+	Family string `xml:"family,omitempty"`
+	Given  string `xml:"given,omitempty"`
+	Value  string `xml:",chardata"`
+}
+
+type eprintsXMLItem struct {
+	Name        *eprintsXMLName `xml:"name,omitempty"`
+	ID          string          `xml:"id,omitempty"`
+	ORCID       string          `xml:"orcid,omitempty"`
+	ROR         string          `xml:"ror,omitempty"`
+	Type        string          `xml:"type,omitempty"`
+	URL         string          `xml:"url,omitempty"`
+	Agency      string          `xml:"agency,omitempty"`
+	GrantNumber string          `xml:"grant_number,omitempty"`
+}
+
+type eprintsXMLFile struct {
+	DatasetID string `xml:"datasetid,omitempty"`
+	Filename  string `xml:"filename,omitempty"`
+	MimeType  string `xml:"mime_type,omitempty"`
+	Hash      string `xml:"hash,omitempty"`
+	HashType  string `xml:"hash_type,omitempty"`
+	FileSize  int    `xml:"filesize,omitempty"`
+	URL       string `xml:"url,omitempty"`
+}
+
+type eprintsXMLDocument struct {
+	Files       []*eprintsXMLFile `xml:"files>file,omitempty"`
+	EPrintID    int               `xml:"eprintid,omitempty"`
+	Pos         int               `xml:"pos,omitempty"`
+	Placement   int               `xml:"placement,omitempty"`
+	MimeType    string            `xml:"mime_type,omitempty"`
+	Format      string            `xml:"format,omitempty"`
+	Language    string            `xml:"language,omitempty"`
+	Security    string            `xml:"security,omitempty"`
+	License     string            `xml:"license,omitempty"`
+	Main        string            `xml:"main,omitempty"`
+	DateEmbargo string            `xml:"date_embargo,omitempty"`
+	Content     string            `xml:"content,omitempty"`
+}
+
+// eprintsXMLEPrint holds the fields written by AsEPrintsXML in the
+// order EPrints exports them.
+type eprintsXMLEPrint struct {
+	EPrintID           int                   `xml:"eprintid,omitempty"`
+	Documents          []*eprintsXMLDocument `xml:"documents>document,omitempty"`
+	EPrintStatus       string                `xml:"eprint_status,omitempty"`
+	Datestamp          string                `xml:"datestamp,omitempty"`
+	LastModified       string                `xml:"lastmod,omitempty"`
+	Type               string                `xml:"type,omitempty"`
+	MonographType      string                `xml:"monograph_type,omitempty"`
+	PresType           string                `xml:"pres_type,omitempty"`
+	MetadataVisibility string                `xml:"metadata_visibility,omitempty"`
+	Creators           []*eprintsXMLItem     `xml:"creators>item,omitempty"`
+	Editors            []*eprintsXMLItem     `xml:"editors>item,omitempty"`
+	Contributors       []*eprintsXMLItem     `xml:"contributors>item,omitempty"`
+	CorpCreators       []*eprintsXMLItem     `xml:"corp_creators>item,omitempty"`
+	ThesisAdvisor      []*eprintsXMLItem     `xml:"thesis_advisor>item,omitempty"`
+	ThesisCommittee    []*eprintsXMLItem     `xml:"thesis_committee>item,omitempty"`
+	Title              string                `xml:"title,omitempty"`
+	Keywords           string                `xml:"keywords,omitempty"`
+	Note               string                `xml:"note,omitempty"`
+	Abstract           string                `xml:"abstract,omitempty"`
+	Date               string                `xml:"date,omitempty"`
+	DateType           string                `xml:"date_type,omitempty"`
+	Publication        string                `xml:"publication,omitempty"`
+	Volume             string                `xml:"volume,omitempty"`
+	Number             string                `xml:"number,omitempty"`
+	Publisher          string                `xml:"publisher,omitempty"`
+	Edition            string                `xml:"edition,omitempty"`
+	PageRange          string                `xml:"pagerange,omitempty"`
+	ISSN               string                `xml:"issn,omitempty"`
+	ISBN               string                `xml:"isbn,omitempty"`
+	OfficialURL        string                `xml:"official_url,omitempty"`
+	RelatedURL         []*eprintsXMLItem     `xml:"related_url>item,omitempty"`
+	Funders            []*eprintsXMLItem     `xml:"funders>item,omitempty"`
+	DOI                string                `xml:"doi,omitempty"`
+	Subjects           []string              `xml:"subjects>item,omitempty"`
+	Language           string                `xml:"language,omitempty"`
+	Institution        string                `xml:"institution,omitempty"`
+}
+
+type eprintsXML struct {
+	XMLName xml.Name            `xml:"eprints"`
+	Xmlns   string              `xml:"xmlns,attr"`
+	EPrint  []*eprintsXMLEPrint `xml:"eprint"`
+}
+
+// eprintsPerson converts a creator into a creators, editors or
+// contributors item.
+func eprintsPerson(c *Creator) *eprintsXMLItem {
+	family, given := personNames(c.PersonOrOrg)
+	if isOrganization(c.PersonOrOrg) {
+		family, given = c.PersonOrOrg.Name, ""
+	}
+	return &eprintsXMLItem{
+		Name:  &eprintsXMLName{Family: family, Given: given},
+		ID:    personIdentifier(c.PersonOrOrg, "clpid"),
+		ORCID: personIdentifier(c.PersonOrOrg, "orcid"),
+	}
+}
+
+// entryInt returns an integer held in entry metadata.
+func entryInt(v interface{}) int {
+	switch v := v.(type) {
+	case int:
+		return v
+	case float64:
+		return int(v)
+	case string:
+		i, _ := strconv.Atoi(v)
+		return i
+	}
+	return 0
+}
+
+// eprintsDocuments converts Files.Entries into documents with one file
+// each, ordered by the "pos" entry metadata then key. Document fields
+// missing from the entry metadata come from the record access.
+func (rec *Record) eprintsDocuments(eprintID int) []*eprintsXMLDocument {
+	if rec.Files == nil || len(rec.Files.Entries) == 0 {
+		return nil
+	}
+	entries := []*Entry{}
+	for key, entry := range rec.Files.Entries {
+		if entry == nil {
+			continue
+		}
+		e := *entry
+		if e.Key == "" {
+			e.Key = key
+		}
+		entries = append(entries, &e)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		pi, pj := entryInt(entries[i].Metadata["pos"]), entryInt(entries[j].Metadata["pos"])
+		if pi != pj {
+			return pi < pj
+		}
+		return entries[i].Key < entries[j].Key
+	})
+	security, embargo := "public", ""
+	if access := rec.RecordAccess; access != nil {
+		if access.Files == "restricted" {
+			security = "staffonly"
+		}
+		if access.Embargo != nil {
+			embargo = access.Embargo.Until
+		}
+	}
+	docs := []*eprintsXMLDocument{}
+	for i, e := range entries {
+		meta := func(key string, defaultValue string) string {
+			if s, ok := e.Metadata[key].(string); ok && s != "" {
+				return s
+			}
+			return defaultValue
+		}
+		filename := e.Key
+		// Duplicate filenames are keyed as "pos/filename"
+		if _, name, ok := strings.Cut(filename, "/"); ok {
+			filename = name
+		}
+		pos := entryInt(e.Metadata["pos"])
+		if pos == 0 {
+			pos = i + 1
+		}
+		f := &eprintsXMLFile{DatasetID: "document", Filename: filename, MimeType: e.MimeType, FileSize: e.Size, URL: meta("url", "")}
+		if hashType, hash, ok := strings.Cut(e.CheckSum, ":"); ok {
+			f.HashType, f.Hash = strings.ToUpper(hashType), hash
+		}
+		docs = append(docs, &eprintsXMLDocument{
+			Files:       []*eprintsXMLFile{f},
+			EPrintID:    eprintID,
+			Pos:         pos,
+			Placement:   pos,
+			MimeType:    e.MimeType,
+			Format:      meta("format", e.MimeType),
+			Language:    meta("language", ""),
+			Security:    meta("security", security),
+			License:     meta("license", ""),
+			Main:        filename,
+			DateEmbargo: meta("date_embargo", embargo),
+			Content:     meta("content", ""),
+		})
+	}
+	return docs
+}
+
+// asEPrint maps the record to the EPrints XML structures.
+func (rec *Record) asEPrint() *eprintsXMLEPrint {
+	m := rec.Metadata
+	if m == nil {
+		m = new(Metadata)
+	}
+	eprint := new(eprintsXMLEPrint)
+	eprint.EPrintID, _ = strconv.Atoi(identifierValue(m.Identifiers, "eprintid"))
+	eprint.Documents = rec.eprintsDocuments(eprint.EPrintID)
+	switch {
+	case rec.Tombstone != nil:
+		eprint.EPrintStatus = "deletion"
+	case rec.RecordAccess != nil && rec.RecordAccess.Record == "restricted":
+		eprint.EPrintStatus, eprint.MetadataVisibility = "archive", "no_search"
+	default:
+		eprint.EPrintStatus, eprint.MetadataVisibility = "archive", "show"
+	}
+	if !rec.Created.IsZero() {
+		eprint.Datestamp = rec.Created.Format("2006-01-02 15:04:05")
+	}
+	if !rec.Updated.IsZero() {
+		eprint.LastModified = rec.Updated.Format("2006-01-02 15:04:05")
+	}
+	eprintType, ok := eprintsTypes[resourceTypeID(rec)]
+	if !ok {
+		eprintType = [2]string{"other", ""}
+	}
+	eprint.Type = eprintType[0]
+	switch eprint.Type {
+	case "monograph":
+		eprint.MonographType = eprintType[1]
+	case "conference_item":
+		eprint.PresType = eprintType[1]
+	}
+	for _, c := range m.Creators {
+		if c == nil || c.PersonOrOrg == nil {
+			continue
+		}
+		if isOrganization(c.PersonOrOrg) {
+			eprint.CorpCreators = append(eprint.CorpCreators, &eprintsXMLItem{
+				Name: &eprintsXMLName{Value: c.PersonOrOrg.Name},
+				ID:   personIdentifier(c.PersonOrOrg, "clpid"),
+				ROR:  personIdentifier(c.PersonOrOrg, "ror"),
+			})
+			continue
+		}
+		eprint.Creators = append(eprint.Creators, eprintsPerson(c))
+	}
+	for _, c := range m.Contributors {
+		if c == nil || c.PersonOrOrg == nil {
+			continue
+		}
+		item := eprintsPerson(c)
+		switch role := creatorRole(c); role {
+		case "editor":
+			eprint.Editors = append(eprint.Editors, item)
+		case "supervisor":
+			eprint.ThesisAdvisor = append(eprint.ThesisAdvisor, item)
+		case "committee":
+			eprint.ThesisCommittee = append(eprint.ThesisCommittee, item)
+		default:
+			code, ok := eprintsRelators[role]
+			if !ok {
+				code = "CTB"
+			}
+			item.Type = "http://www.loc.gov/loc.terms/relators/" + code
+			eprint.Contributors = append(eprint.Contributors, item)
+		}
+	}
+	eprint.Title = m.Title
+	keywords := []string{}
+	for _, s := range m.Subjects {
+		switch {
+		case s == nil:
+		case s.Subject != "":
+			keywords = append(keywords, s.Subject)
+		case s.ID != "":
+			eprint.Subjects = append(eprint.Subjects, s.ID)
+		}
+	}
+	eprint.Keywords = strings.Join(keywords, "; ")
+	for _, d := range m.AdditionalDescriptions {
+		if d != nil && d.Type != nil && d.Type.ID == "other" && eprint.Note == "" {
+			eprint.Note = stripHTML(d.Description)
+		}
+	}
+	eprint.Abstract = stripHTML(m.Description)
+	eprint.Date = m.PublicationDate
+	if eprint.Date != "" {
+		eprint.DateType = "published"
+		for _, d := range m.Dates {
+			if d == nil || d.Type == nil || d.Date != eprint.Date {
+				continue
+			}
+			if dateType, ok := eprintsDateTypes[d.Type.ID]; ok {
+				eprint.DateType = dateType
+				break
+			}
+		}
+	}
+	journal := journalFields(rec)
+	eprint.Publication = journal["title"]
+	eprint.Volume = journal["volume"]
+	eprint.Number = journal["issue"]
+	eprint.PageRange = journal["pages"]
+	eprint.ISSN = recordISSN(rec)
+	if eprint.Type == "thesis" {
+		eprint.Institution = m.Publisher
+	} else {
+		eprint.Publisher = m.Publisher
+	}
+	eprint.Edition = m.Version
+	eprint.ISBN = identifierValue(m.Identifiers, "isbn")
+	eprint.OfficialURL = identifierValue(m.Identifiers, "url")
+	for _, id := range m.RelatedIdentifiers {
+		if id == nil || id.Identifier == "" {
+			continue
+		}
+		item := &eprintsXMLItem{URL: id.Identifier}
+		if id.RelationType != nil {
+			item.Type = eprintsRelatedURLTypes[id.RelationType.ID]
+		}
+		if strings.EqualFold(id.Scheme, "doi") {
+			item.Type = "doi"
+		}
+		eprint.RelatedURL = append(eprint.RelatedURL, item)
+	}
+	for _, f := range m.Funding {
+		if f == nil || f.Funder == nil {
+			continue
+		}
+		item := &eprintsXMLItem{Agency: f.Funder.Name}
+		if f.Funder.Scheme == "" || strings.EqualFold(f.Funder.Scheme, "ror") {
+			item.ROR = f.Funder.Identifier
+		}
+		if f.Award != nil {
+			item.GrantNumber = f.Award.Number
+		}
+		eprint.Funders = append(eprint.Funders, item)
+	}
+	eprint.DOI = recordDOI(rec)
+	for _, lang := range m.Languages {
+		if id, ok := lang["id"].(string); ok && id != "" {
+			eprint.Language = id
+			break
+		}
+	}
+	return eprint
+}
+
+// AsEPrintsXML renders the record as EPrints 3.3 XML. Creators become
+// creators (or corp_creators for organizations), contributors are
+// sorted into editors, thesis_advisor (role "supervisor"),
+// thesis_committee (role "committee") and contributors by role. The
+// "journal:journal" custom field is mapped to publication, volume,
+// number and pagerange, Files.Entries become documents.
+//
+// ```
+//
+//	src, err := rec.AsEPrintsXML()
+//	// ... handle error ...
+//	os.WriteFile("eprint.xml", src, 0664)
+//
+// ```
+func (rec *Record) AsEPrintsXML() ([]byte, error) {
+	if rec == nil {
+		return nil, fmt.Errorf("no record")
+	}
+	doc := &eprintsXML{Xmlns: EPrintsNamespace, EPrint: []*eprintsXMLEPrint{rec.asEPrint()}}
+	src, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), src...), nil
+}
@@ -0,0 +1,84 @@
+package simplified
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+)
+
+func TestAsEPrintsXML(t *testing.T) {
+	rec := testRecord(t)
+	rec.Tombstone = nil
+	m := rec.Metadata
+	m.Identifiers = append(m.Identifiers, &Identifier{Scheme: "eprintid", Identifier: "12345"})
+	m.Contributors = append(m.Contributors,
+		newPerson("Doe", "Jane", "editor"),
+		newPerson("Roe", "Richard", "supervisor"),
+		newPerson("Poe", "Edgar", "researcher"))
+	setJournalField(rec, "pages", "15-23")
+	src, err := rec.AsEPrintsXML()
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc := new(eprintsXML)
+	if err := xml.Unmarshal(src, doc); err != nil {
+		t.Fatalf("expected well formed XML, %s\n%s", err, src)
+	}
+	if doc.Xmlns != EPrintsNamespace || len(doc.EPrint) != 1 {
+		t.Fatalf("expected one eprint in the EPrints namespace ->\n%s", src)
+	}
+	for _, expected := range []string{
+		"<eprintid>12345</eprintid>",
+		"<eprint_status>archive</eprint_status>",
+		"<type>article</type>",
+		"<metadata_visibility>show</metadata_visibility>",
+		"<family>Orphan</family>",
+		"<orcid>0000-0002-5374-6178</orcid>",
+		"<editors>",
+		"<thesis_advisor>",
+		"<type>http://www.loc.gov/loc.terms/relators/RTH</type>",
+		"<type>http://www.loc.gov/loc.terms/relators/CTB</type>",
+		"<date>2023-12-19</date>",
+		"<date_type>published</date_type>",
+		"<publication>PNAS</publication>",
+		"<volume>120</volume>",
+		"<number>51</number>",
+		"<pagerange>15-23</pagerange>",
+		"<issn>1091-6490</issn>",
+		"<agency>National Science Foundation</agency>",
+		"<grant_number>OCE-1634002</grant_number>",
+		"<ror>021nxhr62</ror>",
+		"<doi>10.1073/pnas.2302156120</doi>",
+		"<type>supp</type>",
+		"<keywords>Multidisciplinary</keywords>",
+		"<filename>paper|v1.pdf</filename>",
+		"<hash>abc</hash>",
+		"<hash_type>MD5</hash_type>",
+		"<security>staffonly</security>",
+		"<date_embargo>2131-01-01</date_embargo>",
+		"<abstract>An abstract.</abstract>",
+	} {
+		if !strings.Contains(string(src), expected) {
+			t.Errorf("expected %q in EPrints XML ->\n%s", expected, src)
+		}
+	}
+	if strings.Contains(string(src), "&lt;p&gt;") {
+		t.Errorf("expected the HTML removed from the abstract ->\n%s", src)
+	}
+
+	rec.Tombstone = &Tombstone{Reason: "withdrawn"}
+	setResourceType(rec, "publication-technicalnote")
+	src, err = rec.AsEPrintsXML()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{
+		"<eprint_status>deletion</eprint_status>",
+		"<type>monograph</type>",
+		"<monograph_type>technical_report</monograph_type>",
+	} {
+		if !strings.Contains(string(src), expected) {
+			t.Errorf("expected %q in EPrints XML ->\n%s", expected, src)
+		}
+	}
+}
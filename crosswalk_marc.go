@@ -0,0 +1,1011 @@
+package simplified
+
+/**
+ * This file implements a crosswalk between the simplified Record and
+ * MARC21 bibliographic records, both as MARCXML and as binary ISO 2709
+ * records. See https://www.loc.gov/marc/bibliographic/ and
+ * https://www.loc.gov/standards/marcxml/
+ *
+ * Both serializations share an internal model of leader, control fields
+ * and data fields. Roles are written as MARC relator terms and codes,
+ * name identifiers as URIs in subfield $0. The resource type id is kept
+ * in a 655 genre with the source "local" so it survives a round trip.
+ */
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// MARCXMLNamespace is the XML namespace of MARCXML.
+	MARCXMLNamespace = "http://www.loc.gov/MARC21/slim"
+	// MARCXMLSchemaLocation is the location of the MARCXML schema.
+	MARCXMLSchemaLocation = "http://www.loc.gov/MARC21/slim http://www.loc.gov/standards/marcxml/schema/MARC21slim.xsd"
+
+	// ISO 2709 delimiters
+	marcSubfieldDelimiter = 0x1f
+	marcFieldTerminator   = 0x1e
+	marcRecordTerminator  = 0x1d
+
+	// marcTimestamp is the layout of the 005 control field.
+	marcTimestamp = "20060102150405.0"
+)
+
+// marcRecordTypes maps resource type id prefixes to the type of record
+// in leader position 06.
+var marcRecordTypes = map[string]byte{
+	"publication":    'a',
+	"poster":         'a',
+	"presentation":   'a',
+	"lesson":         'a',
+	"dataset":        'm',
+	"software":       'm',
+	"image":          'k',
+	"video":          'g',
+	"audio":          'i',
+	"physicalobject": 'r',
+	"other":          'p',
+}
+
+// marcRecordTypeIDs maps leader position 06 to resource type ids. It
+// is used when there is no local 655 genre.
+var marcRecordTypeIDs = map[byte]string{
+	'a': "publication",
+	't': "publication",
+	'c': "publication",
+	'd': "publication",
+	'e': "image",
+	'f': "image",
+	'k': "image",
+	'g': "video",
+	'i': "audio",
+	'j': "audio",
+	'm': "software",
+	'r': "physicalobject",
+	'o': "other",
+	'p': "other",
+}
+
+// marcJournalPart parses the related parts subfield ($g) of a 773
+// host item entry, e.g. "Vol. 120, no. 51, p. 15-23".
+var marcJournalPart = regexp.MustCompile(`(?i)(vol|no|p)\.?\s*([^,;]+)`)
+
+type marcSubfield struct {
+	Code  string `xml:"code,attr"`
+	Value string `xml:",chardata"`
+}
+
+type marcControlField struct {
+	Tag   string `xml:"tag,attr"`
+	Value string `xml:",chardata"`
+}
+
+type marcDataField struct {
+	Tag       string          `xml:"tag,attr"`
+	Ind1      string          `xml:"ind1,attr"`
+	Ind2      string          `xml:"ind2,attr"`
+	Subfields []*marcSubfield `xml:"subfield"`
+}
+
+// marcRecord is a MARC21 record. It is the "record" element of
+// MARCXML and is also written and read as ISO 2709.
+type marcRecord struct {
+	XMLName        xml.Name            `xml:"record"`
+	Xmlns          string              `xml:"xmlns,attr,omitempty"`
+	XmlnsXSI       string              `xml:"xmlns:xsi,attr,omitempty"`
+	SchemaLocation string              `xml:"xsi:schemaLocation,attr,omitempty"`
+	Leader         string              `xml:"leader"`
+	ControlFields  []*marcControlField `xml:"controlfield"`
+	DataFields     []*marcDataField    `xml:"datafield"`
+}
+
+// addControl adds a control field if the value is not empty.
+func (r *marcRecord) addControl(tag string, value string) {
+	if value != "" {
+		r.ControlFields = append(r.ControlFields, &marcControlField{Tag: tag, Value: value})
+	}
+}
+
+// addField adds a data field. Subfields are given as code and value
+// pairs, subfields with empty values are skipped as is a field without
+// any subfields.
+func (r *marcRecord) addField(tag string, indicators string, subfields ...string) {
+	f := &marcDataField{Tag: tag, Ind1: indicators[0:1], Ind2: indicators[1:2]}
+	for i := 0; i+1 < len(subfields); i += 2 {
+		if value := strings.TrimSpace(subfields[i+1]); value != "" {
+			f.Subfields = append(f.Subfields, &marcSubfield{Code: subfields[i], Value: value})
+		}
+	}
+	if len(f.Subfields) > 0 {
+		r.DataFields = append(r.DataFields, f)
+	}
+}
+
+// control returns the value of the first control field with the tag.
+func (r *marcRecord) control(tag string) string {
+	for _, f := range r.ControlFields {
+		if f.Tag == tag {
+			return f.Value
+		}
+	}
+	return ""
+}
+
+// fields returns the data fields with one of the tags.
+func (r *marcRecord) fields(tags ...string) []*marcDataField {
+	l := []*marcDataField{}
+	for _, f := range r.DataFields {
+		for _, tag := range tags {
+			if f.Tag == tag {
+				l = append(l, f)
+				break
+			}
+		}
+	}
+	return l
+}
+
+// sub returns the first value of a subfield without trailing ISBD
+// punctuation.
+func (f *marcDataField) sub(code string) string {
+	for _, s := range f.Subfields {
+		if s.Code == code {
+			return marcValue(s.Value)
+		}
+	}
+	return ""
+}
+
+// subs returns all the values of a subfield without trailing ISBD
+// punctuation.
+func (f *marcDataField) subs(code string) []string {
+	l := []string{}
+	for _, s := range f.Subfields {
+		if s.Code == code {
+			if value := marcValue(s.Value); value != "" {
+				l = append(l, value)
+			}
+		}
+	}
+	return l
+}
+
+// marcValue trims spaces and the trailing ISBD punctuation used by
+// catalogers, e.g. "Seismic hazards :". URLs are only trimmed of spaces.
+func marcValue(s string) string {
+	if strings.Contains(s, "://") {
+		return strings.TrimSpace(s)
+	}
+	return strings.TrimSpace(strings.TrimRight(strings.TrimSpace(s), " /:;,="))
+}
+
+//
+// Record to MARC21
+//
+
+// marcRecordType returns leader position 06 for a resource type id.
+func marcRecordType(id string) byte {
+	if t, ok := marcRecordTypes[id]; ok {
+		return t
+	}
+	if prefix, _, ok := strings.Cut(id, "-"); ok {
+		if t, ok := marcRecordTypes[prefix]; ok {
+			return t
+		}
+	}
+	return 'a'
+}
+
+// addName adds a 1XX or 7XX field for a creator or contributor.
+// Persons use the tag ending in "00" and organizations "10".
+func (r *marcRecord) addName(tagPrefix string, c *Creator, role string) {
+	p := c.PersonOrOrg
+	tag, indicators, name := tagPrefix+"00", "1 ", personOrOrgName(p)
+	if isOrganization(p) {
+		tag, indicators = tagPrefix+"10", "2 "
+	} else if family, given := personNames(p); family != "" && given != "" {
+		name = family + ", " + given
+	}
+	subfields := []string{"a", name}
+	if term, ok := marcRelators[role]; ok {
+		subfields = append(subfields, "e", term[1], "4", term[0])
+	} else {
+		subfields = append(subfields, "e", role)
+	}
+	affiliations := []string{}
+	for _, a := range c.Affiliations {
+		if a != nil && a.Name != "" {
+			affiliations = append(affiliations, a.Name)
+		}
+	}
+	subfields = append(subfields, "u", strings.Join(affiliations, "; "))
+	for _, id := range p.Identifiers {
+		if id != nil && id.Identifier != "" {
+			subfields = append(subfields, "0", dublinCoreIdentifier(id.Scheme, id.Identifier))
+		}
+	}
+	r.addField(tag, indicators, subfields...)
+}
+
+// asMARC maps the record to a MARC21 record.
+func (rec *Record) asMARC() *marcRecord {
+	m := rec.Metadata
+	if m == nil {
+		m = new(Metadata)
+	}
+	r := new(marcRecord)
+	typeID := resourceTypeID(rec)
+	isThesis := typeID == "publication-thesis"
+	year, _, _ := dateParts(m.PublicationDate)
+
+	// Leader, record length and base address are set when the record
+	// is written as ISO 2709.
+	leader := []byte("00000nam a2200000 i 4500")
+	if rec.Tombstone != nil {
+		leader[5] = 'd'
+	}
+	if typeID != "" {
+		leader[6] = marcRecordType(typeID)
+	}
+	if typeID == "publication-article" || typeID == "publication-section" {
+		leader[7] = 'a'
+	}
+	r.Leader = string(leader)
+
+	r.addControl("001", rec.ID)
+	if !rec.Updated.IsZero() {
+		r.addControl("005", rec.Updated.UTC().Format(marcTimestamp))
+	}
+	fixed := []byte(strings.Repeat(" ", 40))
+	copy(fixed[0:6], "||||||")
+	if !rec.Created.IsZero() {
+		copy(fixed[0:6], rec.Created.UTC().Format("060102"))
+	}
+	if len(year) == 4 {
+		fixed[6] = 's'
+		copy(fixed[7:11], year)
+	} else {
+		fixed[6] = 'n'
+		copy(fixed[7:11], "uuuu")
+	}
+	copy(fixed[15:18], "xx ")
+	fixed[23] = 'o'
+	if isThesis {
+		fixed[24] = 'm'
+	}
+	copy(fixed[35:38], "und")
+	for _, lang := range m.Languages {
+		if id, ok := lang["id"].(string); ok && len(id) == 3 {
+			copy(fixed[35:38], id)
+			break
+		}
+	}
+	fixed[39] = 'd'
+	r.addControl("008", string(fixed))
+
+	for _, isbn := range identifierValues(m.Identifiers, "isbn") {
+		r.addField("020", "  ", "a", isbn)
+	}
+	for _, issn := range identifierValues(m.Identifiers, "issn") {
+		r.addField("022", "  ", "a", issn)
+	}
+	if doi := recordDOI(rec); doi != "" {
+		r.addField("024", "7 ", "a", doi, "2", "doi")
+	}
+	for _, id := range m.Identifiers {
+		if id == nil || id.Identifier == "" {
+			continue
+		}
+		switch scheme := strings.ToLower(id.Scheme); scheme {
+		case "doi", "isbn", "issn", "url":
+		default:
+			r.addField("024", "7 ", "a", id.Identifier, "2", scheme)
+		}
+	}
+	for _, lang := range m.Languages {
+		if id, ok := lang["id"].(string); ok {
+			r.addField("041", "  ", "a", id)
+		}
+	}
+	if m.PublicationDate != "" && m.PublicationDate != year {
+		r.addField("046", "  ", "k", m.PublicationDate, "2", "edtf")
+	}
+
+	// The first creator is the main entry, the others are added entries.
+	names := []*Creator{}
+	for _, c := range m.Creators {
+		if c != nil && c.PersonOrOrg != nil {
+			names = append(names, c)
+		}
+	}
+	if len(names) > 0 {
+		r.addName("1", names[0], creatorRole(names[0]))
+	}
+
+	subtitle, alternatives := "", []*TitleDetail{}
+	for _, t := range m.AdditionalTitles {
+		if t == nil || t.Title == "" {
+			continue
+		}
+		if t.Type != nil && t.Type.ID == "subtitle" && subtitle == "" {
+			subtitle = t.Title
+			continue
+		}
+		alternatives = append(alternatives, t)
+	}
+	indicators := "00"
+	if len(names) > 0 {
+		indicators = "10"
+	}
+	r.addField("245", indicators, "a", m.Title, "b", subtitle)
+	for _, t := range alternatives {
+		indicators = "3 "
+		if t.Type != nil && t.Type.ID == "translated-title" {
+			indicators = "31"
+		}
+		r.addField("246", indicators, "a", t.Title)
+	}
+	r.addField("250", "  ", "a", m.Version)
+	r.addField("264", " 1", "b", m.Publisher, "c", year)
+
+	for _, d := range m.AdditionalDescriptions {
+		if d != nil && (d.Type == nil || d.Type.ID != "abstract") {
+			r.addField("500", "  ", "a", stripHTML(d.Description))
+		}
+	}
+	if isThesis {
+		thesis := thesisFields(rec)
+		university := thesis["university"]
+		if university == "" {
+			university = m.Publisher
+		}
+		r.addField("502", "  ", "b", thesis["type"], "c", university, "d", year, "g", thesis["department"])
+	}
+	r.addField("520", "3 ", "a", stripHTML(m.Description))
+	for _, d := range m.AdditionalDescriptions {
+		if d != nil && d.Type != nil && d.Type.ID == "abstract" {
+			r.addField("520", "3 ", "a", stripHTML(d.Description))
+		}
+	}
+	for _, f := range m.Funding {
+		if f == nil || f.Funder == nil {
+			continue
+		}
+		award := ""
+		if f.Award != nil {
+			award = f.Award.Number
+		}
+		r.addField("536", "  ", "a", f.Funder.Name, "c", award)
+	}
+	for _, right := range m.Rights {
+		if right != nil {
+			r.addField("540", "  ", "a", localized(right.Title), "f", right.ID, "u", right.Link)
+		}
+	}
+	for _, s := range m.Subjects {
+		if s == nil {
+			continue
+		}
+		label := s.Subject
+		if label == "" {
+			label = s.ID
+		}
+		r.addField("650", " 4", "a", label, "0", s.ID)
+	}
+	if typeID != "" {
+		r.addField("655", " 7", "a", typeID, "2", "local")
+	}
+
+	for i, c := range names {
+		if i > 0 {
+			r.addName("7", c, creatorRole(c))
+		}
+	}
+	for _, c := range m.Contributors {
+		if c == nil || c.PersonOrOrg == nil {
+			continue
+		}
+		role := creatorRole(c)
+		if role == "" {
+			// Contributors always have a role, an author here would
+			// become a creator when read back.
+			role = "other"
+		}
+		r.addName("7", c, role)
+	}
+
+	if journal := journalFields(rec); journal["title"] != "" {
+		parts := []string{}
+		if journal["volume"] != "" {
+			parts = append(parts, "Vol. "+journal["volume"])
+		}
+		if journal["issue"] != "" {
+			parts = append(parts, "no. "+journal["issue"])
+		}
+		if journal["pages"] != "" {
+			parts = append(parts, "p. "+journal["pages"])
+		}
+		r.addField("773", "0 ", "t", journal["title"], "g", strings.Join(parts, ", "), "x", journal["issn"])
+	}
+	for _, id := range m.RelatedIdentifiers {
+		if id == nil || id.Identifier == "" {
+			continue
+		}
+		relation := ""
+		if id.RelationType != nil {
+			relation = id.RelationType.ID
+		}
+		r.addField("787", "08", "i", relation, "o", dublinCoreIdentifier(id.Scheme, id.Identifier))
+	}
+
+	for _, u := range identifierValues(m.Identifiers, "url") {
+		r.addField("856", "40", "u", u)
+	}
+	if rec.Files != nil {
+		for _, e := range sortedEntries(rec.Files.Entries) {
+			size := ""
+			if e.Size > 0 {
+				size = strconv.Itoa(e.Size)
+			}
+			// An 856 without a URL is no use to a catalog, when the entry
+			// has no link the URL is made from the record's landing page.
+			u := entryURL(e)
+			if landing, ok := rec.Links["self_html"].(string); ok && u == "" && landing != "" {
+				u = strings.TrimSuffix(landing, "/") + "/files/" + url.PathEscape(e.Key)
+			}
+			if u == "" {
+				continue
+			}
+			r.addField("856", "40", "u", u, "f", e.Key, "q", e.MimeType, "s", size)
+		}
+	}
+	return r
+}
+
+// AsMARCXML renders the record as a MARCXML "record" element. The
+// first creator is the main entry (100 or 110), the other creators and
+// the contributors are added entries (700 or 710) with relator terms,
+// ORCID or ROR URIs in $0 and affiliations in $u. The title is written
+// in 245, the publisher and year in 264, the abstract in 520, subjects
+// in 650, the DOI in 024, a thesis in 502, the "journal:journal"
+// custom field in 773 and Files.Entries in 856. A file without a link
+// is linked to the record's "self_html" landing page, or left out if
+// there is none.
+//
+// ```
+//
+//	src, err := rec.AsMARCXML()
+//	// ... handle error ...
+//	fmt.Printf("%s\n", src)
+//
+// ```
+func (rec *Record) AsMARCXML() ([]byte, error) {
+	if rec == nil {
+		return nil, fmt.Errorf("no record")
+	}
+	r := rec.asMARC()
+	r.Xmlns, r.XmlnsXSI, r.SchemaLocation = MARCXMLNamespace, xsiNamespace, MARCXMLSchemaLocation
+	src, err := xml.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), src...), nil
+}
+
+// MARCXMLCollection renders the records as a MARCXML "collection"
+// element. See AsMARCXML for the mapping of each record.
+//
+// ```
+//
+//	src, err := simplified.MARCXMLCollection(records...)
+//	// ... handle error ...
+//	os.WriteFile("records.xml", src, 0664)
+//
+// ```
+func MARCXMLCollection(records ...*Record) ([]byte, error) {
+	collection := struct {
+		XMLName        xml.Name      `xml:"collection"`
+		Xmlns          string        `xml:"xmlns,attr"`
+		XmlnsXSI       string        `xml:"xmlns:xsi,attr"`
+		SchemaLocation string        `xml:"xsi:schemaLocation,attr"`
+		Records        []*marcRecord `xml:"record"`
+	}{Xmlns: MARCXMLNamespace, XmlnsXSI: xsiNamespace, SchemaLocation: MARCXMLSchemaLocation}
+	for _, rec := range records {
+		if rec == nil {
+			return nil, fmt.Errorf("no record")
+		}
+		collection.Records = append(collection.Records, rec.asMARC())
+	}
+	src, err := xml.MarshalIndent(collection, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), src...), nil
+}
+
+// marcClean removes the ISO 2709 delimiters from a value.
+func marcClean(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r == marcSubfieldDelimiter || r == marcFieldTerminator || r == marcRecordTerminator {
+			return -1
+		}
+		return r
+	}, s)
+}
+
+// iso2709 encodes the record as ISO 2709. Lengths and offsets are
+// counted in bytes of UTF-8.
+func (r *marcRecord) iso2709() ([]byte, error) {
+	directory, data := new(bytes.Buffer), new(bytes.Buffer)
+	addEntry := func(tag string, field []byte) error {
+		if len(tag) != 3 {
+			return fmt.Errorf("marc, invalid tag %q", tag)
+		}
+		if len(field) > 9999 {
+			return fmt.Errorf("marc, field %s is too long", tag)
+		}
+		fmt.Fprintf(directory, "%s%04d%05d", tag, len(field), data.Len())
+		data.Write(field)
+		return nil
+	}
+	for _, f := range r.ControlFields {
+		if err := addEntry(f.Tag, append([]byte(marcClean(f.Value)), marcFieldTerminator)); err != nil {
+			return nil, err
+		}
+	}
+	for _, f := range r.DataFields {
+		field := new(bytes.Buffer)
+		for _, ind := range []string{f.Ind1, f.Ind2} {
+			if len(ind) != 1 {
+				ind = " "
+			}
+			field.WriteString(ind)
+		}
+		for _, s := range f.Subfields {
+			field.WriteByte(marcSubfieldDelimiter)
+			field.WriteString(marcClean(s.Code + s.Value))
+		}
+		field.WriteByte(marcFieldTerminator)
+		if err := addEntry(f.Tag, field.Bytes()); err != nil {
+			return nil, err
+		}
+	}
+	directory.WriteByte(marcFieldTerminator)
+	base := 24 + directory.Len()
+	length := base + data.Len() + 1
+	if length > 99999 {
+		return nil, fmt.Errorf("marc, record is too long")
+	}
+	leader := []byte(fmt.Sprintf("%-24s", r.Leader))[0:24]
+	copy(leader[0:5], fmt.Sprintf("%05d", length))
+	copy(leader[10:12], "22")
+	copy(leader[12:17], fmt.Sprintf("%05d", base))
+	copy(leader[20:24], "4500")
+	src := append(leader, directory.Bytes()...)
+	src = append(src, data.Bytes()...)
+	return append(src, marcRecordTerminator), nil
+}
+
+// AsMARC21 renders the record as a binary MARC21 (ISO 2709) record
+// using UTF-8. The fields are the same as written by AsMARCXML.
+//
+// ```
+//
+//	src, err := rec.AsMARC21()
+//	// ... handle error ...
+//	os.WriteFile("record.mrc", src, 0664)
+//
+// ```
+func (rec *Record) AsMARC21() ([]byte, error) {
+	if rec == nil {
+		return nil, fmt.Errorf("no record")
+	}
+	return rec.asMARC().iso2709()
+}
+
+//
+// MARC21 to Record
+//
+
+// marcSchemeID splits a $0 or $o identifier into its scheme and value.
+// Both URIs and the MARC "(scheme)value" form are understood.
+func marcSchemeID(s string) (string, string) {
+	s = strings.TrimSpace(s)
+	if strings.HasPrefix(s, "(") {
+		if scheme, value, ok := strings.Cut(s[1:], ")"); ok {
+			return strings.ToLower(strings.TrimSpace(scheme)), strings.TrimSpace(value)
+		}
+	}
+	return dublinCoreSchemeID(s)
+}
+
+// creator converts a 1XX or 7XX name field into a Creator.
+func (f *marcDataField) creator(role string) *Creator {
+	var c *Creator
+	name := f.sub("a")
+	if strings.HasSuffix(f.Tag, "00") {
+		if family, given, ok := strings.Cut(name, ","); ok && f.Ind1 != "0" {
+			c = newPerson(family, given, role)
+		} else {
+			c = newPerson(name, "", role)
+		}
+	} else {
+		c = newOrganization(name, role)
+	}
+	for _, id := range f.subs("0") {
+		if scheme, value := marcSchemeID(id); value != "" {
+			addPersonIdentifier(c, scheme, value)
+		}
+	}
+	for _, u := range f.subs("u") {
+		for _, a := range strings.Split(u, ";") {
+			addAffiliation(c, a, "")
+		}
+	}
+	return c
+}
+
+// role returns the InvenioRDM role id of a name field. Authors and
+// names without a relator return an empty string.
+func (f *marcDataField) role() string {
+	for _, code := range []string{"4", "e"} {
+		if value := f.sub(code); value != "" {
+			return relatorRole(value)
+		}
+	}
+	return ""
+}
+
+// asRecord maps a MARC21 record to a Record.
+func (r *marcRecord) asRecord() *Record {
+	rec := new(Record)
+	m := ensureMetadata(rec)
+	rec.ID = strings.TrimSpace(r.control("001"))
+	if t, err := time.Parse(marcTimestamp, strings.TrimSpace(r.control("005"))); err == nil {
+		rec.Updated = t
+	}
+	if len(r.Leader) > 5 && r.Leader[5] == 'd' {
+		rec.Tombstone = new(Tombstone)
+	}
+
+	for _, f := range r.fields("020") {
+		if isbn := f.sub("a"); isbn != "" {
+			// The ISBN may be followed by a qualifier, e.g. "(pbk.)".
+			m.Identifiers = addIdentifier(m.Identifiers, "isbn", strings.Fields(isbn)[0])
+		}
+	}
+	for _, f := range r.fields("022") {
+		m.Identifiers = addIdentifier(m.Identifiers, "issn", f.sub("a"))
+	}
+	for _, f := range r.fields("024") {
+		value, scheme := f.sub("a"), strings.ToLower(f.sub("2"))
+		switch {
+		case value == "":
+		case scheme == "doi" && recordDOI(rec) == "":
+			setRecordDOI(rec, value)
+		case scheme == "":
+			m.Identifiers = addIdentifier(m.Identifiers, "other", value)
+		default:
+			m.Identifiers = addIdentifier(m.Identifiers, scheme, value)
+		}
+	}
+	for _, f := range r.fields("041") {
+		for _, lang := range f.subs("a") {
+			m.Languages = append(m.Languages, map[string]interface{}{"id": lang})
+		}
+	}
+	if fixed := r.control("008"); len(m.Languages) == 0 && len(fixed) >= 38 {
+		if lang := strings.TrimSpace(fixed[35:38]); len(lang) == 3 && lang != "und" && !strings.Contains(lang, "|") {
+			m.Languages = append(m.Languages, map[string]interface{}{"id": lang})
+		}
+	}
+
+	for _, f := range r.fields("100", "110", "700", "710") {
+		if len(f.Subfields) == 0 || f.sub("a") == "" {
+			continue
+		}
+		role := f.role()
+		if role == "" || strings.HasPrefix(f.Tag, "1") {
+			m.Creators = append(m.Creators, f.creator(role))
+		} else {
+			m.Contributors = append(m.Contributors, f.creator(role))
+		}
+	}
+
+	for _, f := range r.fields("245") {
+		m.Title = f.sub("a")
+		if sub := f.sub("b"); sub != "" {
+			m.AdditionalTitles = append(m.AdditionalTitles, &TitleDetail{Title: sub, Type: &Type{ID: "subtitle"}})
+		}
+		break
+	}
+	for _, f := range r.fields("246") {
+		typeID := "alternative-title"
+		if f.Ind2 == "1" {
+			typeID = "translated-title"
+		}
+		if title := f.sub("a"); title != "" {
+			m.AdditionalTitles = append(m.AdditionalTitles, &TitleDetail{Title: title, Type: &Type{ID: typeID}})
+		}
+	}
+	for _, f := range r.fields("250") {
+		m.Version = f.sub("a")
+	}
+	for _, f := range r.fields("264", "260") {
+		if f.Tag == "264" && f.Ind2 != "1" {
+			continue
+		}
+		if m.Publisher == "" {
+			m.Publisher = f.sub("b")
+		}
+		if m.PublicationDate == "" {
+			m.PublicationDate = strings.Trim(f.sub("c"), "[]c©. ")
+		}
+	}
+	for _, f := range r.fields("046") {
+		if date := f.sub("k"); date != "" {
+			m.PublicationDate = date
+		}
+	}
+
+	for _, f := range r.fields("500") {
+		if note := f.sub("a"); note != "" {
+			m.AdditionalDescriptions = append(m.AdditionalDescriptions, &Description{Description: note, Type: &Type{ID: "other"}})
+		}
+	}
+	isThesis := false
+	for _, f := range r.fields("502") {
+		isThesis = true
+		setThesisField(rec, "type", f.sub("b"))
+		setThesisField(rec, "university", f.sub("c"))
+		setThesisField(rec, "department", f.sub("g"))
+		if m.Publisher == "" {
+			m.Publisher = f.sub("c")
+		}
+		if m.PublicationDate == "" {
+			m.PublicationDate = strings.Trim(f.sub("d"), "[]c©. ")
+		}
+		if note := f.sub("a"); note != "" {
+			m.AdditionalDescriptions = append(m.AdditionalDescriptions, &Description{Description: note, Type: &Type{ID: "other"}})
+		}
+	}
+	for _, f := range r.fields("520") {
+		value := strings.TrimSpace(f.sub("a"))
+		switch {
+		case value == "":
+		case m.Description == "":
+			m.Description = value
+		default:
+			m.AdditionalDescriptions = append(m.AdditionalDescriptions, &Description{Description: value, Type: &Type{ID: "abstract"}})
+		}
+	}
+	for _, f := range r.fields("536") {
+		if name := f.sub("a"); name != "" {
+			funder := &Funder{Funder: &FunderIdentifier{Name: name}}
+			if award := f.sub("c"); award != "" {
+				funder.Award = &AwardIdentifier{Number: award}
+			}
+			m.Funding = append(m.Funding, funder)
+		}
+	}
+	for _, f := range r.fields("540") {
+		right := &Right{ID: f.sub("f"), Link: f.sub("u")}
+		if title := f.sub("a"); title != "" {
+			right.Title = map[string]string{"en": title}
+		}
+		if right.ID != "" || right.Link != "" || right.Title != nil {
+			m.Rights = append(m.Rights, right)
+		}
+	}
+	for _, f := range r.fields("650", "653") {
+		label, id := f.sub("a"), strings.TrimSpace(f.sub("0"))
+		switch {
+		case label == "":
+		case label == id:
+			// Subjects with only an id are written with the id as label.
+			m.Subjects = append(m.Subjects, &Subject{ID: id})
+		default:
+			m.Subjects = append(m.Subjects, &Subject{Subject: label, ID: id})
+		}
+	}
+	resourceType := ""
+	for _, f := range r.fields("655") {
+		if f.sub("2") == "local" && f.sub("a") != "" {
+			resourceType = f.sub("a")
+			break
+		}
+	}
+	if resourceType == "" && isThesis {
+		resourceType = "publication-thesis"
+	}
+	if resourceType == "" && len(r.Leader) > 6 {
+		resourceType = marcRecordTypeIDs[r.Leader[6]]
+	}
+	if resourceType != "" {
+		setResourceType(rec, resourceType)
+	}
+
+	for _, f := range r.fields("773") {
+		if title := f.sub("t"); title != "" {
+			setJournalField(rec, "title", title)
+			for _, match := range marcJournalPart.FindAllStringSubmatch(f.sub("g"), -1) {
+				key := map[string]string{"vol": "volume", "no": "issue", "p": "pages"}[strings.ToLower(match[1])]
+				setJournalField(rec, key, strings.TrimSpace(match[2]))
+			}
+			setJournalField(rec, "issn", f.sub("x"))
+			break
+		}
+	}
+	for _, f := range r.fields("787") {
+		scheme, value := marcSchemeID(f.sub("o"))
+		if value == "" {
+			continue
+		}
+		relation := f.sub("i")
+		if relation == "" {
+			relation = "references"
+		}
+		m.RelatedIdentifiers = append(m.RelatedIdentifiers, &Identifier{Scheme: scheme, Identifier: value, RelationType: &TypeDetail{ID: relation}})
+	}
+	for _, f := range r.fields("856") {
+		u, key := f.sub("u"), f.sub("f")
+		if key == "" && f.sub("q") == "" && f.sub("s") == "" {
+			if u == "" {
+				continue
+			}
+			m.Identifiers = addIdentifier(m.Identifiers, "url", u)
+			continue
+		}
+		if key == "" {
+			key = u[strings.LastIndex(u, "/")+1:]
+		}
+		if key == "" {
+			continue
+		}
+		entry := &Entry{Key: key, MimeType: f.sub("q")}
+		entry.Size, _ = strconv.Atoi(f.sub("s"))
+		if u != "" {
+			entry.Links = map[string]interface{}{"content": u}
+		}
+		if rec.Files == nil {
+			rec.Files = &Files{Enabled: true, Entries: map[string]*Entry{}}
+		}
+		rec.Files.Entries[key] = entry
+		rec.Files.Count, rec.Files.TotalBytes = rec.Files.Count+1, rec.Files.TotalBytes+entry.Size
+	}
+	return rec
+}
+
+// RecordsFromMARCXML reads the MARC21 records of a MARCXML document.
+// Records may be inside a "collection" element or an OAI-PMH response.
+//
+// ```
+//
+//	src, err := os.ReadFile("records.xml")
+//	// ... handle error ...
+//	records, err := simplified.RecordsFromMARCXML(src)
+//	// ... handle error ...
+//
+// ```
+func RecordsFromMARCXML(src []byte) ([]*Record, error) {
+	records := []*Record{}
+	decoder := xml.NewDecoder(bytes.NewReader(src))
+	for {
+		tok, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("marcxml, %s", err)
+		}
+		start, ok := tok.(xml.StartElement)
+		// OAI-PMH responses also have "record" elements.
+		if !ok || start.Name.Local != "record" || (start.Name.Space != "" && start.Name.Space != MARCXMLNamespace) {
+			continue
+		}
+		r := new(marcRecord)
+		if err := decoder.DecodeElement(r, &start); err != nil {
+			return nil, fmt.Errorf("marcxml, %s", err)
+		}
+		records = append(records, r.asRecord())
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("marcxml, no record element found")
+	}
+	return records, nil
+}
+
+// parseISO2709 decodes a single ISO 2709 record without its record
+// terminator.
+// marcNumber parses a number of the leader or directory. Only ASCII
+// digits are allowed, strconv.Atoi would accept a sign.
+func marcNumber(b []byte) (int, bool) {
+	if len(b) == 0 {
+		return 0, false
+	}
+	n := 0
+	for _, c := range b {
+		if c < '0' || c > '9' {
+			return 0, false
+		}
+		n = n*10 + int(c-'0')
+	}
+	return n, true
+}
+
+func parseISO2709(src []byte) (*marcRecord, error) {
+	if len(src) < 25 {
+		return nil, fmt.Errorf("record is too short")
+	}
+	r := &marcRecord{Leader: string(src[0:24])}
+	base, ok := marcNumber(src[12:17])
+	if !ok || base < 25 || base > len(src) {
+		return nil, fmt.Errorf("invalid base address %q", src[12:17])
+	}
+	directory := src[24 : base-1]
+	if len(directory)%12 != 0 {
+		return nil, fmt.Errorf("invalid directory length %d", len(directory))
+	}
+	for i := 0; i < len(directory); i += 12 {
+		tag := string(directory[i : i+3])
+		length, ok1 := marcNumber(directory[i+3 : i+7])
+		start, ok2 := marcNumber(directory[i+7 : i+12])
+		if !ok1 || !ok2 || base+start+length > len(src) {
+			return nil, fmt.Errorf("invalid directory entry for %s", tag)
+		}
+		field := bytes.TrimSuffix(src[base+start:base+start+length], []byte{marcFieldTerminator})
+		if strings.HasPrefix(tag, "00") {
+			r.ControlFields = append(r.ControlFields, &marcControlField{Tag: tag, Value: string(field)})
+			continue
+		}
+		if len(field) < 2 {
+			return nil, fmt.Errorf("invalid field %s", tag)
+		}
+		f := &marcDataField{Tag: tag, Ind1: string(field[0:1]), Ind2: string(field[1:2])}
+		for _, s := range bytes.Split(field[2:], []byte{marcSubfieldDelimiter}) {
+			if len(s) > 0 {
+				f.Subfields = append(f.Subfields, &marcSubfield{Code: string(s[0:1]), Value: string(s[1:])})
+			}
+		}
+		r.DataFields = append(r.DataFields, f)
+	}
+	return r, nil
+}
+
+// RecordsFromMARC21 reads the binary MARC21 (ISO 2709) records in src,
+// e.g. the contents of a ".mrc" file. Records are expected to use UTF-8.
+//
+// ```
+//
+//	src, err := os.ReadFile("records.mrc")
+//	// ... handle error ...
+//	records, err := simplified.RecordsFromMARC21(src)
+//	// ... handle error ...
+//
+// ```
+func RecordsFromMARC21(src []byte) ([]*Record, error) {
+	records := []*Record{}
+	for i, chunk := range bytes.Split(src, []byte{marcRecordTerminator}) {
+		chunk = bytes.TrimLeft(chunk, "\r\n ")
+		if len(chunk) == 0 {
+			continue
+		}
+		r, err := parseISO2709(chunk)
+		if err != nil {
+			return nil, fmt.Errorf("marc21, record %d, %s", i+1, err)
+		}
+		records = append(records, r.asRecord())
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("marc21, no records found")
+	}
+	return records, nil
+}
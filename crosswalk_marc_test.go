@@ -0,0 +1,309 @@
+package simplified
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestAsMARCXML(t *testing.T) {
+	rec := testRecord(t)
+	rec.Metadata.Contributors = append(rec.Metadata.Contributors, newPerson("Doe", "Jane", "editor"))
+	rec.Files.Entries["paper|v1.pdf"].Links = map[string]interface{}{"content": "https://example.edu/files/paper.pdf"}
+	setJournalField(rec, "pages", "15-23")
+	src, err := rec.AsMARCXML()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{
+		`<record xmlns="http://www.loc.gov/MARC21/slim"`,
+		"<leader>00000daa a2200000 i 4500</leader>",
+		`<controlfield tag="001">rd9fg-k5282</controlfield>`,
+		`<datafield tag="024" ind1="7" ind2=" ">`,
+		`<subfield code="a">10.1073/pnas.2302156120</subfield>`,
+		`<subfield code="k">2023-12-19</subfield>`,
+		`<datafield tag="100" ind1="1" ind2=" ">`,
+		`<subfield code="a">Orphan, Victoria J.</subfield>`,
+		`<subfield code="4">aut</subfield>`,
+		`<subfield code="u">California Institute of Technology</subfield>`,
+		`<subfield code="0">https://orcid.org/0000-0002-5374-6178</subfield>`,
+		`<datafield tag="245" ind1="1" ind2="0">`,
+		`<subfield code="b">a subtitle</subfield>`,
+		`<datafield tag="264" ind1=" " ind2="1">`,
+		`<subfield code="b">National Academy of Sciences</subfield>`,
+		`<subfield code="a">An abstract.</subfield>`,
+		`<subfield code="c">OCE-1634002</subfield>`,
+		`<subfield code="a">Multidisciplinary</subfield>`,
+		`<datafield tag="655" ind1=" " ind2="7">`,
+		`<datafield tag="710" ind1="2" ind2=" ">`,
+		`<subfield code="4">his</subfield>`,
+		`<subfield code="e">editor</subfield>`,
+		`<subfield code="g">Vol. 120, no. 51, p. 15-23</subfield>`,
+		`<subfield code="u">https://example.edu/files/paper.pdf</subfield>`,
+		`<subfield code="q">application/pdf</subfield>`,
+		`<subfield code="s">1024</subfield>`,
+	} {
+		if !strings.Contains(string(src), expected) {
+			t.Errorf("expected %q in MARCXML ->\n%s", expected, src)
+		}
+	}
+	if strings.Contains(string(src), "&lt;p&gt;") {
+		t.Errorf("expected the HTML markup to be removed ->\n%s", src)
+	}
+
+	// A file without a link is linked from the landing page, or left
+	// out when there is none.
+	rec.Files.Entries["paper|v1.pdf"].Links = nil
+	rec.Links = map[string]interface{}{"self_html": "https://example.edu/records/rd9fg-k5282"}
+	if src, err = rec.AsMARCXML(); err != nil {
+		t.Fatal(err)
+	}
+	if expected := `<subfield code="u">https://example.edu/records/rd9fg-k5282/files/paper%7Cv1.pdf</subfield>`; !strings.Contains(string(src), expected) {
+		t.Errorf("expected %q in MARCXML ->\n%s", expected, src)
+	}
+	rec.Links = nil
+	if src, err = rec.AsMARCXML(); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(src), `tag="856"`) {
+		t.Errorf("expected no 856 for a file without a URL ->\n%s", src)
+	}
+}
+
+func TestAsMARC21Thesis(t *testing.T) {
+	rec := &Record{ID: "abc-123"}
+	setResourceType(rec, "publication-thesis")
+	m := rec.Metadata
+	m.Title = "Seismic hazards"
+	m.PublicationDate = "2020"
+	m.Publisher = "California Institute of Technology"
+	m.Creators = []*Creator{newPerson("Doe", "Jane", "")}
+	m.Contributors = []*Creator{newPerson("Roe", "Richard", "supervisor")}
+	m.Languages = []map[string]interface{}{{"id": "eng"}}
+	setThesisField(rec, "type", "phd")
+	setThesisField(rec, "department", "Geological and Planetary Sciences")
+	src, err := rec.AsMARC21()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(src[0:5]) != fmt.Sprintf("%05d", len(src)) {
+		t.Errorf("expected the record length %d in the leader, got %q", len(src), src[0:5])
+	}
+	if src[len(src)-1] != marcRecordTerminator {
+		t.Errorf("expected a record terminator")
+	}
+	for _, expected := range []string{
+		"\x1fbphd\x1fcCalifornia Institute of Technology\x1fd2020\x1fgGeological and Planetary Sciences\x1e",
+		"\x1faRoe, Richard\x1fethesis advisor\x1f4ths\x1e",
+		"s2020    xx      om          eng d\x1e",
+	} {
+		if !strings.Contains(string(src), expected) {
+			t.Errorf("expected %q in MARC21 ->\n%q", expected, src)
+		}
+	}
+	records, err := RecordsFromMARC21(append(src, src...))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("expected two records, got %d", len(records))
+	}
+	thesis := thesisFields(records[0])
+	if resourceTypeID(records[0]) != "publication-thesis" || thesis["type"] != "phd" || thesis["university"] != "California Institute of Technology" || thesis["department"] != "Geological and Planetary Sciences" {
+		t.Errorf("expected thesis fields to round trip %s", records[0].ToString())
+	}
+	if len(records[0].Metadata.Contributors) != 1 || creatorRole(records[0].Metadata.Contributors[0]) != "supervisor" {
+		t.Errorf("expected a thesis advisor %s", records[0].ToString())
+	}
+	if _, err := RecordsFromMARC21([]byte("00010nam")); err == nil {
+		t.Errorf("expected an error for a truncated record")
+	}
+	// Numbers with a sign are rejected rather than sliced with.
+	for _, bad := range []string{
+		"00050nam a2200037 i 4500245-00100000\x1e10\x1faTitle\x1e\x1d",
+		"00050nam a2200037 i 45002450010-0001\x1e10\x1faTitle\x1e\x1d",
+		"00050nam a22+0037 i 4500245001000000\x1e10\x1faTitle\x1e\x1d",
+	} {
+		if _, err := RecordsFromMARC21([]byte(bad)); err == nil {
+			t.Errorf("expected an error for %q", bad)
+		}
+	}
+}
+
+func TestRecordsFromMARCXML(t *testing.T) {
+	src := []byte(`<?xml version="1.0" encoding="UTF-8"?>
+<collection xmlns="http://www.loc.gov/MARC21/slim">
+  <record>
+    <leader>01234cam a2200289 i 4500</leader>
+    <controlfield tag="001">991234</controlfield>
+    <controlfield tag="005">20240102030405.0</controlfield>
+    <controlfield tag="008">200601s2020    cau     om    000 0 eng d</controlfield>
+    <datafield tag="020" ind1=" " ind2=" "><subfield code="a">9780306406157 (pbk.)</subfield></datafield>
+    <datafield tag="024" ind1="7" ind2=" "><subfield code="a">10.7907/ABC</subfield><subfield code="2">doi</subfield></datafield>
+    <datafield tag="100" ind1="1" ind2=" "><subfield code="a">van Beethoven, Ludwig,</subfield><subfield code="e">author.</subfield><subfield code="0">(orcid)0000-0001-2345-6789</subfield></datafield>
+    <datafield tag="245" ind1="1" ind2="0"><subfield code="a">Seismic hazards :</subfield><subfield code="b">a review /</subfield><subfield code="c">Ludwig van Beethoven.</subfield></datafield>
+    <datafield tag="260" ind1=" " ind2=" "><subfield code="a">Pasadena :</subfield><subfield code="b">Caltech,</subfield><subfield code="c">c2020.</subfield></datafield>
+    <datafield tag="502" ind1=" " ind2=" "><subfield code="a">Thesis (Ph.D.)--California Institute of Technology, 2020.</subfield></datafield>
+    <datafield tag="520" ind1=" " ind2=" "><subfield code="a">A review.</subfield></datafield>
+    <datafield tag="650" ind1=" " ind2="0"><subfield code="a">Earthquakes.</subfield><subfield code="0">http://id.loc.gov/authorities/subjects/sh85040469</subfield></datafield>
+    <datafield tag="700" ind1="1" ind2=" "><subfield code="a">Doe, Jane.</subfield><subfield code="4">ths</subfield><subfield code="u">Caltech; JPL</subfield></datafield>
+    <datafield tag="856" ind1="4" ind2="0"><subfield code="u">https://thesis.library.caltech.edu/1/</subfield></datafield>
+  </record>
+</collection>`)
+	records, err := RecordsFromMARCXML(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 1 {
+		t.Fatalf("expected one record, got %d", len(records))
+	}
+	rec := records[0]
+	m := rec.Metadata
+	if rec.ID != "991234" || rec.Updated.Year() != 2024 {
+		t.Errorf("unexpected control fields %s", rec.ToString())
+	}
+	if m.Title != "Seismic hazards" || len(m.AdditionalTitles) != 1 || m.AdditionalTitles[0].Title != "a review" {
+		t.Errorf("unexpected titles %s", rec.ToString())
+	}
+	if len(m.Creators) != 1 || m.Creators[0].PersonOrOrg.FamilyName != "van Beethoven" || personIdentifier(m.Creators[0].PersonOrOrg, "orcid") != "0000-0001-2345-6789" {
+		t.Errorf("unexpected creators %s", rec.ToString())
+	}
+	if len(m.Contributors) != 1 || creatorRole(m.Contributors[0]) != "supervisor" || len(m.Contributors[0].Affiliations) != 2 {
+		t.Errorf("unexpected contributors %s", rec.ToString())
+	}
+	if m.Publisher != "Caltech" || m.PublicationDate != "2020" || resourceTypeID(rec) != "publication-thesis" {
+		t.Errorf("unexpected publication %s", rec.ToString())
+	}
+	if identifierValue(m.Identifiers, "isbn") != "9780306406157" || recordDOI(rec) != "10.7907/abc" || identifierValue(m.Identifiers, "url") != "https://thesis.library.caltech.edu/1/" {
+		t.Errorf("unexpected identifiers %s", rec.ToString())
+	}
+	if len(m.Languages) != 1 || m.Languages[0]["id"] != "eng" {
+		t.Errorf("unexpected languages %s", rec.ToString())
+	}
+	if len(m.Subjects) != 1 || m.Subjects[0].Subject != "Earthquakes." || m.Subjects[0].ID == "" {
+		t.Errorf("unexpected subjects %s", rec.ToString())
+	}
+	if _, err := RecordsFromMARCXML([]byte(`<OAI-PMH><record/></OAI-PMH>`)); err != nil {
+		t.Errorf("expected an un-namespaced record to be read, %s", err)
+	}
+	if _, err := RecordsFromMARCXML([]byte(`<mods/>`)); err == nil {
+		t.Errorf("expected an error for a document without a record element")
+	}
+}
+
+func TestRecordsFromMARCFiles(t *testing.T) {
+	// testdata/catalog.marcxml and testdata/catalog.mrc hold the same
+	// catalog records as MARCXML, with a namespace prefix, and as ISO 2709.
+	xmlSrc, err := os.ReadFile("testdata/catalog.marcxml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	isoSrc, err := os.ReadFile("testdata/catalog.mrc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fromXML, err := RecordsFromMARCXML(xmlSrc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fromISO, err := RecordsFromMARC21(isoSrc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fromXML) != 2 || len(fromISO) != 2 {
+		t.Fatalf("expected two records from each file, got %d and %d", len(fromXML), len(fromISO))
+	}
+	for i := range fromXML {
+		if string(fromXML[i].ToString()) != string(fromISO[i].ToString()) {
+			t.Errorf("expected record %d to read the same\n%s\n%s", i, fromXML[i].ToString(), fromISO[i].ToString())
+		}
+	}
+	book := fromISO[0]
+	m := book.Metadata
+	if book.ID != "991000123" || m.Title != "Seismic hazards of southern California" || m.Publisher != "Caltech Library" || m.PublicationDate != "2019" {
+		t.Errorf("unexpected book %s", book.ToString())
+	}
+	if len(m.Creators) != 1 || m.Creators[0].PersonOrOrg.FamilyName != "Doe" || len(m.Contributors) != 1 || creatorRole(m.Contributors[0]) != "editor" {
+		t.Errorf("unexpected names %s", book.ToString())
+	}
+	if identifierValue(m.Identifiers, "isbn") != "9780306406157" || len(m.Subjects) != 1 || m.Description == "" {
+		t.Errorf("unexpected book fields %s", book.ToString())
+	}
+	thesis := fromISO[1]
+	if resourceTypeID(thesis) != "publication-thesis" || thesis.Metadata.PublicationDate != "2020" || recordDOI(thesis) != "10.7907/xyz1-ab23" {
+		t.Errorf("unexpected thesis %s", thesis.ToString())
+	}
+	if thesisFields(thesis)["university"] != "California Institute of Technology" {
+		t.Errorf("unexpected thesis fields %v", thesisFields(thesis))
+	}
+}
+
+func TestMARCRoundTrip(t *testing.T) {
+	rec1 := testRecord(t)
+	rec1.Metadata.Contributors = append(rec1.Metadata.Contributors, newPerson("Doe", "Jane", "editor"))
+	// MARC affiliations are text only.
+	rec1.Metadata.Creators[0].Affiliations[0].ID = ""
+	// Files are written with their URL.
+	rec1.Links = map[string]interface{}{"self_html": "https://example.edu/records/rd9fg-k5282"}
+	xmlSrc, err := rec1.AsMARCXML()
+	if err != nil {
+		t.Fatal(err)
+	}
+	isoSrc, err := rec1.AsMARC21()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fromXML, err := RecordsFromMARCXML(xmlSrc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fromISO, err := RecordsFromMARC21(isoSrc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(fromXML[0].ToString()) != string(fromISO[0].ToString()) {
+		t.Errorf("expected MARCXML and ISO 2709 to read the same\n%s\n%s", fromXML[0].ToString(), fromISO[0].ToString())
+	}
+	rec2 := fromISO[0]
+	m1, m2 := rec1.Metadata, rec2.Metadata
+	if rec2.ID != rec1.ID || m1.Title != m2.Title || stripHTML(m1.Description) != m2.Description || m1.PublicationDate != m2.PublicationDate || m1.Publisher != m2.Publisher {
+		t.Errorf("expected id, title, abstract, date and publisher to round trip\n%s", rec2.ToString())
+	}
+	if resourceTypeID(rec2) != "publication-article" || recordDOI(rec2) != recordDOI(rec1) || recordISSN(rec2) != "1091-6490" {
+		t.Errorf("expected resource type, DOI and ISSN to round trip\n%s", rec2.ToString())
+	}
+	if len(m2.Creators) != len(m1.Creators) || !sameCreatorNames(m1.Creators[0], m2.Creators[0]) {
+		t.Errorf("expected creators to round trip\n%s", rec2.ToString())
+	}
+	if len(m2.Contributors) != len(m1.Contributors) {
+		t.Fatalf("expected contributors to round trip\n%s", rec2.ToString())
+	}
+	for i, c := range m1.Contributors {
+		if !sameCreatorNames(c, m2.Contributors[i]) {
+			t.Errorf("expected contributor %d to round trip, got %+v", i, m2.Contributors[i].PersonOrOrg)
+		}
+	}
+	journal := journalFields(rec2)
+	if journal["title"] != "PNAS" || journal["volume"] != "120" || journal["issue"] != "51" {
+		t.Errorf("expected the journal to round trip %v", journal)
+	}
+	if len(m2.Funding) != 1 || m2.Funding[0].Funder.Name != "National Science Foundation" || m2.Funding[0].Award.Number != "OCE-1634002" {
+		t.Errorf("expected funding to round trip\n%s", rec2.ToString())
+	}
+	if len(m2.Subjects) != 1 || m2.Subjects[0].Subject != "Multidisciplinary" {
+		t.Errorf("expected subjects to round trip\n%s", rec2.ToString())
+	}
+	if len(m2.RelatedIdentifiers) != 1 || m2.RelatedIdentifiers[0].Identifier != m1.RelatedIdentifiers[0].Identifier || m2.RelatedIdentifiers[0].RelationType.ID != "issupplementedby" {
+		t.Errorf("expected related identifiers to round trip\n%s", rec2.ToString())
+	}
+	if len(m2.Rights) != 1 || m2.Rights[0].ID != "cc-by-4.0" || m2.Rights[0].Link != m1.Rights[0].Link {
+		t.Errorf("expected rights to round trip\n%s", rec2.ToString())
+	}
+	if rec2.Files == nil || rec2.Files.Entries["paper|v1.pdf"] == nil || rec2.Files.Entries["paper|v1.pdf"].Size != 1024 {
+		t.Errorf("expected files to round trip\n%s", rec2.ToString())
+	}
+	if rec2.Tombstone == nil {
+		t.Errorf("expected a deleted record")
+	}
+}
@@ -1,5 +1,460 @@
 package simplified
 
+/**
+ * This file implements a Markdown rendering of a simplified Record.
+ *
+ * The rendering is driven by a Go text/template so that the layout
+ * can be customized without changing the Go code. MarkdownTemplate
+ * holds the default template, use RenderMarkdown to supply your own.
+ */
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// MarkdownTemplate is the default text/template used by AsMarkdown
+// to render a Record. It can be used as a starting point for a custom
+// template passed to RenderMarkdown.
+const MarkdownTemplate = `{{- with .Tombstone -}}
+> **This record has been removed.**{{ with .Reason }} Reason: {{ . }}{{ end }}{{ with .Category }} ({{ . }}){{ end }}{{ if not .Timestamp.IsZero }} Removed {{ .Timestamp.Format "2006-01-02" }}{{ end }}{{ with .RemovedBy }}{{ with .DisplayName }} by {{ . }}{{ end }}{{ end }}
+
+{{ end -}}
+{{- with .Metadata -}}
+# {{ if .Title }}{{ .Title }}{{ else }}(untitled){{ end }}
+{{- range .AdditionalTitles }}
+
+## {{ .Title }}{{ with .Type }} ({{ typeLabel . }}){{ end }}
+{{- end }}
+{{ if .ResourceType }}
+- Resource type: {{ resourceType .ResourceType }}
+{{- end }}
+{{- with .PublicationDate }}
+- Publication date: {{ . }}
+{{- end }}
+{{- with .Publisher }}
+- Publisher: {{ . }}
+{{- end }}
+{{- with .Version }}
+- Version: {{ . }}
+{{- end }}
+{{- with .Languages }}
+- Languages: {{ languages . }}
+{{- end }}
+{{- end }}
+{{- with journal . }}
+- Journal: {{ . }}
+{{- end }}
+{{- range $scheme, $pid := .ExternalPIDs }}
+- {{ upper $scheme }}: {{ $pid.Identifier }}
+{{- end }}
+{{- with .ID }}
+- Record ID: {{ . }}
+{{- end }}
+{{- if not .Created.IsZero }}
+- Created: {{ .Created.Format "2006-01-02" }}
+{{- end }}
+{{- if not .Updated.IsZero }}
+- Updated: {{ .Updated.Format "2006-01-02" }}
+{{- end }}
+{{- with .Metadata }}
+{{- with .Creators }}
+
+## Creators
+{{ range . }}
+- {{ creator . }}
+{{- end }}
+{{- end }}
+{{- with .Contributors }}
+
+## Contributors
+{{ range . }}
+- {{ creator . }}{{ with .Role }} ({{ roleLabel . }}){{ end }}
+{{- end }}
+{{- end }}
+{{- with .Description }}
+
+## Abstract
+
+{{ . }}
+{{- end }}
+{{- range .AdditionalDescriptions }}
+
+## {{ with .Type }}{{ typeLabel . }}{{ else }}Description{{ end }}
+
+{{ .Description }}
+{{- end }}
+{{- with .Dates }}
+
+## Dates
+{{ range . }}
+- {{ .Date }}{{ with .Type }} ({{ typeLabel . }}){{ end }}{{ with .Description }}, {{ . }}{{ end }}
+{{- end }}
+{{- end }}
+{{- with .Subjects }}
+
+## Subjects
+{{ range . }}
+- {{ if .Subject }}{{ .Subject }}{{ else }}{{ .ID }}{{ end }}
+{{- end }}
+{{- end }}
+{{- with .Rights }}
+
+## Rights
+{{ range . }}
+- {{ right . }}
+{{- end }}
+{{- end }}
+{{- with .Funding }}
+
+## Funding
+{{ range . }}
+- {{ funder . }}
+{{- end }}
+{{- end }}
+{{- with .Identifiers }}
+
+## Identifiers
+{{ range . }}
+- {{ identifier . }}
+{{- end }}
+{{- end }}
+{{- with .RelatedIdentifiers }}
+
+## Related Identifiers
+{{ range . }}
+- {{ identifier . }}
+{{- end }}
+{{- end }}
+{{- end }}
+{{- with .RecordAccess }}
+
+## Access
+
+- Record: {{ if .Record }}{{ .Record }}{{ else }}public{{ end }}
+- Files: {{ if .Files }}{{ .Files }}{{ else }}public{{ end }}
+{{- with .Embargo }}{{ if .Active }}
+- Embargoed until {{ .Until }}{{ with .Reason }}, {{ . }}{{ end }}
+{{- end }}{{ end }}
+{{- end }}
+{{- with .Files }}{{ with .Entries }}
+
+## Files
+
+| Name | Mime Type | Size | Checksum |
+| ---- | --------- | ---- | -------- |
+{{- range entries . }}
+| {{ cell .Key }} | {{ cell .MimeType }} | {{ .Size }} | {{ cell .CheckSum }} |
+{{- end }}
+{{- end }}{{ end }}
+`
+
+// markdownFuncs holds the functions available to a Markdown template.
+var markdownFuncs = template.FuncMap{
+	"upper":        strings.ToUpper,
+	"cell":         markdownCell,
+	"typeLabel":    typeLabel,
+	"roleLabel":    roleLabel,
+	"resourceType": resourceTypeLabel,
+	"languages":    languageLabels,
+	"creator":      creatorLabel,
+	"right":        rightLabel,
+	"funder":       funderLabel,
+	"identifier":   identifierLabel,
+	"journal":      journalLabel,
+	"entries":      sortedEntries,
+}
+
+// localized picks the English value from a localized map if
+// available otherwise the first value by key order.
+func localized(m map[string]string) string {
+	if m == nil {
+		return ""
+	}
+	if s, ok := m["en"]; ok && strings.TrimSpace(s) != "" {
+		return s
+	}
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if s := strings.TrimSpace(m[k]); s != "" {
+			return s
+		}
+	}
+	return ""
+}
+
+// localizedInterface is the same as localized for maps with
+// interface{} values as found in TypeDetail and ResourceType.
+func localizedInterface(m map[string]interface{}) string {
+	if m == nil {
+		return ""
+	}
+	sm := map[string]string{}
+	for k, v := range m {
+		if s, ok := v.(string); ok {
+			sm[k] = s
+		}
+	}
+	return localized(sm)
+}
+
+func markdownCell(s string) string {
+	s = strings.ReplaceAll(s, "|", `\|`)
+	return strings.ReplaceAll(s, "\n", " ")
+}
+
+func typeLabel(t *Type) string {
+	if t == nil {
+		return ""
+	}
+	if s := localized(t.Title); s != "" {
+		return s
+	}
+	if t.Name != "" {
+		return t.Name
+	}
+	return t.ID
+}
+
+func roleLabel(r *Role) string {
+	if r == nil {
+		return ""
+	}
+	if s := localized(r.Title); s != "" {
+		return s
+	}
+	return r.ID
+}
+
+func resourceTypeLabel(m map[string]interface{}) string {
+	if m == nil {
+		return ""
+	}
+	if title, ok := m["title"].(map[string]interface{}); ok {
+		if s := localizedInterface(title); s != "" {
+			return s
+		}
+	}
+	if id, ok := m["id"].(string); ok {
+		return id
+	}
+	return ""
+}
+
+func languageLabels(languages []map[string]interface{}) string {
+	labels := []string{}
+	for _, lang := range languages {
+		if s := resourceTypeLabel(lang); s != "" {
+			labels = append(labels, s)
+		}
+	}
+	return strings.Join(labels, ", ")
+}
+
+// personOrOrgName returns a display name for a PersonOrOrg.
+func personOrOrgName(p *PersonOrOrg) string {
+	if p == nil {
+		return ""
+	}
+	if p.Name != "" {
+		return p.Name
+	}
+	if p.FamilyName != "" && p.GivenName != "" {
+		return fmt.Sprintf("%s, %s", p.FamilyName, p.GivenName)
+	}
+	return strings.TrimSpace(p.FamilyName + p.GivenName)
+}
+
+func creatorLabel(c *Creator) string {
+	if c == nil {
+		return ""
+	}
+	parts := []string{personOrOrgName(c.PersonOrOrg)}
+	if c.PersonOrOrg != nil {
+		for _, id := range c.PersonOrOrg.Identifiers {
+			if id == nil || id.Identifier == "" {
+				continue
+			}
+			switch strings.ToLower(id.Scheme) {
+			case "orcid":
+				parts = append(parts, fmt.Sprintf("[ORCID %s](https://orcid.org/%s)", id.Identifier, id.Identifier))
+			case "ror":
+				parts = append(parts, fmt.Sprintf("[ROR %s](https://ror.org/%s)", id.Identifier, id.Identifier))
+			}
+		}
+	}
+	affiliations := []string{}
+	for _, a := range c.Affiliations {
+		if a == nil {
+			continue
+		}
+		if a.Name != "" {
+			affiliations = append(affiliations, a.Name)
+		} else if a.ID != "" {
+			affiliations = append(affiliations, a.ID)
+		}
+	}
+	if len(affiliations) > 0 {
+		parts = append(parts, "("+strings.Join(affiliations, "; ")+")")
+	}
+	return strings.Join(parts, " ")
+}
+
+func rightLabel(r *Right) string {
+	if r == nil {
+		return ""
+	}
+	label := localized(r.Title)
+	if label == "" {
+		label = r.ID
+	}
+	if r.Link != "" {
+		label = fmt.Sprintf("[%s](%s)", label, r.Link)
+	}
+	if desc := localized(r.Description); desc != "" {
+		label = fmt.Sprintf("%s, %s", label, desc)
+	}
+	return label
+}
+
+func funderLabel(f *Funder) string {
+	if f == nil {
+		return ""
+	}
+	parts := []string{}
+	if f.Funder != nil {
+		if f.Funder.Name != "" {
+			parts = append(parts, f.Funder.Name)
+		} else if f.Funder.Identifier != "" {
+			parts = append(parts, f.Funder.Identifier)
+		}
+	}
+	if f.Award != nil {
+		award := []string{}
+		if f.Award.Number != "" {
+			award = append(award, f.Award.Number)
+		}
+		if f.Award.Title != nil && strings.TrimSpace(f.Award.Title.Title) != "" {
+			award = append(award, strings.TrimSpace(f.Award.Title.Title))
+		}
+		if len(award) == 0 && f.Award.Identifier != "" {
+			award = append(award, f.Award.Identifier)
+		}
+		if len(award) > 0 {
+			parts = append(parts, "award "+strings.Join(award, ", "))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+func identifierLabel(id *Identifier) string {
+	if id == nil {
+		return ""
+	}
+	value := id.Identifier
+	if value == "" {
+		value = id.ID
+	}
+	label := value
+	if id.Scheme != "" {
+		label = fmt.Sprintf("%s: %s", id.Scheme, value)
+	}
+	if id.RelationType != nil {
+		relation := localizedInterface(id.RelationType.Title)
+		if relation == "" {
+			relation = id.RelationType.ID
+		}
+		if relation != "" {
+			label = fmt.Sprintf("%s (%s)", label, relation)
+		}
+	}
+	return label
+}
+
+// journalLabel formats the "journal:journal" custom field of a record.
+func journalLabel(rec *Record) string {
+	if rec == nil || rec.CustomFields == nil {
+		return ""
+	}
+	journal, ok := rec.CustomFields["journal:journal"].(map[string]interface{})
+	if !ok {
+		return ""
+	}
+	parts := []string{}
+	if title, ok := journal["title"].(string); ok && title != "" {
+		parts = append(parts, title)
+	}
+	for _, key := range []string{"volume", "issue", "pages", "issn"} {
+		if val, ok := journal[key]; ok && val != nil && fmt.Sprintf("%v", val) != "" {
+			parts = append(parts, fmt.Sprintf("%s %v", key, val))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// sortedEntries returns the file entries ordered by key.
+func sortedEntries(entries map[string]*Entry) []*Entry {
+	keys := make([]string, 0, len(entries))
+	for k := range entries {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	l := []*Entry{}
+	for _, k := range keys {
+		if e := entries[k]; e != nil {
+			if e.Key == "" {
+				entry := *e
+				entry.Key = k
+				e = &entry
+			}
+			l = append(l, e)
+		}
+	}
+	return l
+}
+
+// RenderMarkdown renders the record as Markdown using the text/template
+// source provided. If tmplSrc is an empty string MarkdownTemplate is used.
+//
+// ```
+//
+//	src, err := os.ReadFile("my-template.tmpl")
+//	// ... handle error ...
+//	md, err := rec.RenderMarkdown(string(src))
+//	// ... handle error ...
+//	fmt.Printf("%s\n", md)
+//
+// ```
+func (rec *Record) RenderMarkdown(tmplSrc string) ([]byte, error) {
+	if tmplSrc == "" {
+		tmplSrc = MarkdownTemplate
+	}
+	tmpl, err := template.New("markdown").Funcs(markdownFuncs).Parse(tmplSrc)
+	if err != nil {
+		return nil, err
+	}
+	if rec == nil {
+		rec = new(Record)
+	}
+	buf := new(bytes.Buffer)
+	if err := tmpl.Execute(buf, rec); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// AsMarkdown renders the record as Markdown using MarkdownTemplate.
 func (rec *Record) AsMarkdown() []byte {
-	return []byte("rec.AsMarkdown() not implemented")
+	src, err := rec.RenderMarkdown(MarkdownTemplate)
+	if err != nil {
+		return []byte(fmt.Sprintf("rec.AsMarkdown() failed, %s", err))
+	}
+	return src
 }
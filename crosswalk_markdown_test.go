@@ -0,0 +1,58 @@
+package simplified
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAsMarkdown(t *testing.T) {
+	rec := testRecord(t)
+	src := string(rec.AsMarkdown())
+	for _, expected := range []string{
+		"> **This record has been removed.** Reason: duplicate record (spam)",
+		"# Microbially induced precipitation of silica",
+		"## a subtitle (Subtitle)",
+		"- Resource type: Journal Article",
+		"- Journal: PNAS, volume 120, issue 51",
+		"- DOI: 10.1073/pnas.2302156120",
+		"- Orphan, Victoria J. [ORCID 0000-0002-5374-6178](https://orcid.org/0000-0002-5374-6178) (California Institute of Technology)",
+		"- Caltech Library (hostinginstitution)",
+		"## Abstract\n\n<p>An abstract.</p>",
+		"## Acknowledgement\n\nThanks to all.",
+		"- 2023-12-01 (accepted)",
+		"- Multidisciplinary",
+		"- [Creative Commons Attribution 4.0 International](https://creativecommons.org/licenses/by/4.0/)",
+		"- National Science Foundation, award OCE-1634002",
+		"- issn: 1091-6490",
+		"- url: https://example.edu/supplement.pdf (Is supplemented by)",
+		"- Files: restricted",
+		"- Embargoed until 2131-01-01, publisher embargo",
+		`| paper\|v1.pdf | application/pdf | 1024 | md5:abc |`,
+	} {
+		if !strings.Contains(src, expected) {
+			t.Errorf("expected %q in Markdown ->\n%s", expected, src)
+		}
+	}
+	if strings.Contains(src, "not implemented") {
+		t.Errorf("expected a rendered record, got %s", src)
+	}
+}
+
+func TestRenderMarkdown(t *testing.T) {
+	rec := testRecord(t)
+	src, err := rec.RenderMarkdown(`{{ .Metadata.Title }} by {{ range .Metadata.Creators }}{{ creator . }}{{ end }}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "Microbially induced precipitation of silica by Orphan, Victoria J. [ORCID 0000-0002-5374-6178](https://orcid.org/0000-0002-5374-6178) (California Institute of Technology)"
+	if string(src) != expected {
+		t.Errorf("expected %q, got %q", expected, src)
+	}
+	if _, err := rec.RenderMarkdown(`{{ .NoSuchField }}`); err == nil {
+		t.Errorf("expected an error for a template referencing a missing field")
+	}
+	// An empty record should still render without error.
+	if _, err := new(Record).RenderMarkdown(""); err != nil {
+		t.Errorf("expected empty record to render, %s", err)
+	}
+}
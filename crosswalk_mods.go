@@ -0,0 +1,812 @@
+package simplified
+
+/**
+ * This file implements a crosswalk between the simplified Record and
+ * MODS 3.x XML as used by library catalogs and digital collections. See
+ * https://www.loc.gov/standards/mods/
+ *
+ * The same XML structures are used for reading and writing. Roles are
+ * written as MARC relator terms and codes. The resource type id is kept
+ * in a "genre" with the authority "local" so it survives a round trip.
+ */
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const (
+	// MODSNamespace is the XML namespace of MODS 3.x.
+	MODSNamespace = "http://www.loc.gov/mods/v3"
+	// MODSSchemaLocation is the location of the MODS 3.8 XML schema.
+	MODSSchemaLocation = "http://www.loc.gov/mods/v3 http://www.loc.gov/standards/mods/v3/mods-3-8.xsd"
+)
+
+// marcRelators maps InvenioRDM role ids to MARC relator codes and terms.
+// They are shared by the MODS and MARC21 crosswalks.
+// Creators without a role are authors.
+var marcRelators = map[string][2]string{
+	"":                   {"aut", "author"},
+	"editor":             {"edt", "editor"},
+	"supervisor":         {"ths", "thesis advisor"},
+	"committee":          {"dgc", "degree committee member"},
+	"researcher":         {"res", "researcher"},
+	"datacollector":      {"com", "compiler"},
+	"datacurator":        {"cur", "curator"},
+	"producer":           {"pro", "producer"},
+	"sponsor":            {"spn", "sponsor"},
+	"distributor":        {"dst", "distributor"},
+	"rightsholder":       {"cph", "copyright holder"},
+	"projectleader":      {"pdr", "project director"},
+	"hostinginstitution": {"his", "host institution"},
+	"contactperson":      {"", "contact person"},
+	"other":              {"oth", "other"},
+}
+
+// modsTitleTypes maps InvenioRDM title types to MODS titleInfo types.
+var modsTitleTypes = map[string]string{
+	"alternative-title": "alternative",
+	"translated-title":  "translated",
+	"other":             "alternative",
+}
+
+// modsTitleTypeIDs maps MODS titleInfo types to InvenioRDM title types.
+var modsTitleTypeIDs = map[string]string{
+	"alternative": "alternative-title",
+	"translated":  "translated-title",
+	"abbreviated": "other",
+	"uniform":     "other",
+}
+
+// modsDateFields lists the InvenioRDM date types with their own
+// originInfo element. Other date types are written as dateOther.
+var modsDateFields = map[string]string{
+	"created":     "dateCreated",
+	"updated":     "dateModified",
+	"valid":       "dateValid",
+	"collected":   "dateCaptured",
+	"copyrighted": "copyrightDate",
+}
+
+// modsRelations maps InvenioRDM relation types to relatedItem types.
+// Relations not listed are written with an otherType.
+var modsRelations = map[string]string{
+	"ispartof":            "host",
+	"haspart":             "constituent",
+	"isversionof":         "otherVersion",
+	"hasversion":          "otherVersion",
+	"isnewversionof":      "preceding",
+	"ispreviousversionof": "succeeding",
+	"isvariantformof":     "otherFormat",
+	"isoriginalformof":    "original",
+	"references":          "references",
+	"isreferencedby":      "isReferencedBy",
+	"reviews":             "reviewOf",
+}
+
+// modsRelationIDs maps relatedItem types to InvenioRDM relation types.
+var modsRelationIDs = map[string]string{
+	"host":           "ispartof",
+	"constituent":    "haspart",
+	"series":         "ispartof",
+	"otherVersion":   "isversionof",
+	"preceding":      "isnewversionof",
+	"succeeding":     "ispreviousversionof",
+	"otherFormat":    "isvariantformof",
+	"original":       "isoriginalformof",
+	"references":     "references",
+	"isReferencedBy": "isreferencedby",
+	"reviewOf":       "reviews",
+}
+
+// modsResourceTypes maps resource type id prefixes to typeOfResource.
+var modsResourceTypes = map[string]string{
+	"publication":    "text",
+	"poster":         "text",
+	"presentation":   "text",
+	"lesson":         "text",
+	"dataset":        "software, multimedia",
+	"software":       "software, multimedia",
+	"image":          "still image",
+	"video":          "moving image",
+	"audio":          "sound recording",
+	"physicalobject": "three dimensional object",
+	"other":          "mixed material",
+}
+
+// modsResourceTypeIDs maps typeOfResource to resource type ids. It is
+// used when there is no local genre.
+var modsResourceTypeIDs = map[string]string{
+	"text":                       "publication",
+	"software, multimedia":       "software",
+	"still image":                "image",
+	"moving image":               "video",
+	"sound recording":            "audio",
+	"sound recording-musical":    "audio",
+	"sound recording-nonmusical": "audio",
+	"three dimensional object":   "physicalobject",
+	"cartographic":               "image",
+	"mixed material":             "other",
+}
+
+// modsIdentifierTypes maps identifier schemes to MODS identifier types
+// where they differ.
+var modsIdentifierTypes = map[string]string{
+	"url": "uri",
+}
+
+type modsValue struct {
+	Type      string `xml:"type,attr,omitempty"`
+	Authority string `xml:"authority,attr,omitempty"`
+	Value     string `xml:",chardata"`
+}
+
+type modsTitleInfo struct {
+	Type     string `xml:"type,attr,omitempty"`
+	Lang     string `xml:"lang,attr,omitempty"`
+	Title    string `xml:"title"`
+	SubTitle string `xml:"subTitle,omitempty"`
+}
+
+type modsRole struct {
+	RoleTerm []*modsValue `xml:"roleTerm"`
+}
+
+type modsName struct {
+	Type           string       `xml:"type,attr,omitempty"`
+	NamePart       []*modsValue `xml:"namePart"`
+	DisplayForm    string       `xml:"displayForm,omitempty"`
+	Affiliation    []string     `xml:"affiliation,omitempty"`
+	Role           []*modsRole  `xml:"role,omitempty"`
+	NameIdentifier []*modsValue `xml:"nameIdentifier,omitempty"`
+}
+
+type modsDate struct {
+	Encoding string `xml:"encoding,attr,omitempty"`
+	KeyDate  string `xml:"keyDate,attr,omitempty"`
+	Type     string `xml:"type,attr,omitempty"`
+	Value    string `xml:",chardata"`
+}
+
+type modsOriginInfo struct {
+	Publisher     []string    `xml:"publisher,omitempty"`
+	DateIssued    []*modsDate `xml:"dateIssued,omitempty"`
+	DateCreated   []*modsDate `xml:"dateCreated,omitempty"`
+	DateCaptured  []*modsDate `xml:"dateCaptured,omitempty"`
+	DateValid     []*modsDate `xml:"dateValid,omitempty"`
+	DateModified  []*modsDate `xml:"dateModified,omitempty"`
+	CopyrightDate []*modsDate `xml:"copyrightDate,omitempty"`
+	DateOther     []*modsDate `xml:"dateOther,omitempty"`
+	Edition       string      `xml:"edition,omitempty"`
+}
+
+type modsLanguage struct {
+	LanguageTerm []*modsValue `xml:"languageTerm"`
+}
+
+type modsSubject struct {
+	Authority string   `xml:"authority,attr,omitempty"`
+	ValueURI  string   `xml:"valueURI,attr,omitempty"`
+	Topic     []string `xml:"topic"`
+}
+
+type modsExtent struct {
+	Unit  string `xml:"unit,attr,omitempty"`
+	Start string `xml:"start,omitempty"`
+	End   string `xml:"end,omitempty"`
+	List  string `xml:"list,omitempty"`
+}
+
+type modsDetail struct {
+	Type   string `xml:"type,attr,omitempty"`
+	Number string `xml:"number"`
+}
+
+type modsPart struct {
+	Detail []*modsDetail `xml:"detail,omitempty"`
+	Extent *modsExtent   `xml:"extent,omitempty"`
+}
+
+type modsRelatedItem struct {
+	Type       string           `xml:"type,attr,omitempty"`
+	OtherType  string           `xml:"otherType,attr,omitempty"`
+	TitleInfo  []*modsTitleInfo `xml:"titleInfo,omitempty"`
+	Identifier []*modsValue     `xml:"identifier,omitempty"`
+	Part       *modsPart        `xml:"part,omitempty"`
+}
+
+type modsAccessCondition struct {
+	Type  string `xml:"type,attr,omitempty"`
+	Href  string `xml:"http://www.w3.org/1999/xlink href,attr,omitempty"`
+	Value string `xml:",chardata"`
+}
+
+// modsXML is the root "mods" element of a MODS record.
+type modsXML struct {
+	XMLName         xml.Name               `xml:"mods"`
+	Xmlns           string                 `xml:"xmlns,attr,omitempty"`
+	XmlnsXSI        string                 `xml:"xmlns:xsi,attr,omitempty"`
+	SchemaLocation  string                 `xml:"xsi:schemaLocation,attr,omitempty"`
+	Version         string                 `xml:"version,attr,omitempty"`
+	TitleInfo       []*modsTitleInfo       `xml:"titleInfo"`
+	Name            []*modsName            `xml:"name,omitempty"`
+	TypeOfResource  string                 `xml:"typeOfResource,omitempty"`
+	Genre           []*modsValue           `xml:"genre,omitempty"`
+	OriginInfo      *modsOriginInfo        `xml:"originInfo,omitempty"`
+	Language        []*modsLanguage        `xml:"language,omitempty"`
+	Abstract        []*modsValue           `xml:"abstract,omitempty"`
+	Note            []*modsValue           `xml:"note,omitempty"`
+	Subject         []*modsSubject         `xml:"subject,omitempty"`
+	RelatedItem     []*modsRelatedItem     `xml:"relatedItem,omitempty"`
+	Identifier      []*modsValue           `xml:"identifier,omitempty"`
+	AccessCondition []*modsAccessCondition `xml:"accessCondition,omitempty"`
+}
+
+// modsNameOf converts a creator or contributor into a MODS name.
+func modsNameOf(c *Creator) *modsName {
+	p := c.PersonOrOrg
+	n := new(modsName)
+	if isOrganization(p) {
+		n.Type = "corporate"
+		n.NamePart = []*modsValue{{Value: p.Name}}
+	} else {
+		n.Type = "personal"
+		family, given := personNames(p)
+		if family != "" {
+			n.NamePart = append(n.NamePart, &modsValue{Type: "family", Value: family})
+		}
+		if given != "" {
+			n.NamePart = append(n.NamePart, &modsValue{Type: "given", Value: given})
+		}
+		n.DisplayForm = personOrOrgName(p)
+	}
+	for _, a := range c.Affiliations {
+		if a != nil && a.Name != "" {
+			n.Affiliation = append(n.Affiliation, a.Name)
+		}
+	}
+	role := creatorRole(c)
+	if term, ok := marcRelators[role]; ok {
+		r := &modsRole{RoleTerm: []*modsValue{{Type: "text", Authority: "marcrelator", Value: term[1]}}}
+		if term[0] != "" {
+			r.RoleTerm = append(r.RoleTerm, &modsValue{Type: "code", Authority: "marcrelator", Value: term[0]})
+		}
+		n.Role = append(n.Role, r)
+	} else {
+		n.Role = append(n.Role, &modsRole{RoleTerm: []*modsValue{{Type: "text", Value: role}}})
+	}
+	for _, id := range p.Identifiers {
+		if id != nil && id.Identifier != "" {
+			n.NameIdentifier = append(n.NameIdentifier, &modsValue{Type: strings.ToLower(id.Scheme), Value: id.Identifier})
+		}
+	}
+	return n
+}
+
+// modsResourceType returns the typeOfResource for a resource type id.
+func modsResourceType(id string) string {
+	if t, ok := modsResourceTypes[id]; ok {
+		return t
+	}
+	if prefix, _, ok := strings.Cut(id, "-"); ok {
+		if t, ok := modsResourceTypes[prefix]; ok {
+			return t
+		}
+	}
+	return "mixed material"
+}
+
+// asMODS maps the record to the MODS structures.
+func (rec *Record) asMODS() *modsXML {
+	m := rec.Metadata
+	if m == nil {
+		m = new(Metadata)
+	}
+	mods := &modsXML{
+		Xmlns:          MODSNamespace,
+		XmlnsXSI:       xsiNamespace,
+		SchemaLocation: MODSSchemaLocation,
+		Version:        "3.8",
+	}
+	title := &modsTitleInfo{Title: m.Title}
+	mods.TitleInfo = append(mods.TitleInfo, title)
+	for _, t := range m.AdditionalTitles {
+		if t == nil || t.Title == "" {
+			continue
+		}
+		typeID := ""
+		if t.Type != nil {
+			typeID = t.Type.ID
+		}
+		if typeID == "subtitle" && title.SubTitle == "" {
+			title.SubTitle = t.Title
+			continue
+		}
+		info := &modsTitleInfo{Type: modsTitleTypes[typeID], Title: t.Title}
+		if info.Type == "" {
+			info.Type = "alternative"
+		}
+		if t.Lang != nil {
+			info.Lang = t.Lang.ID
+		}
+		mods.TitleInfo = append(mods.TitleInfo, info)
+	}
+	for _, c := range m.Creators {
+		if c != nil && c.PersonOrOrg != nil {
+			mods.Name = append(mods.Name, modsNameOf(c))
+		}
+	}
+	for _, c := range m.Contributors {
+		if c == nil || c.PersonOrOrg == nil {
+			continue
+		}
+		if creatorRole(c) == "" {
+			// Contributors always have a role, an author here would
+			// become a creator when read back.
+			c = &Creator{PersonOrOrg: c.PersonOrOrg, Role: &Role{ID: "other"}, Affiliations: c.Affiliations}
+		}
+		mods.Name = append(mods.Name, modsNameOf(c))
+	}
+	if id := resourceTypeID(rec); id != "" {
+		mods.TypeOfResource = modsResourceType(id)
+		mods.Genre = append(mods.Genre, &modsValue{Authority: "local", Value: id})
+	}
+	origin := new(modsOriginInfo)
+	if m.Publisher != "" {
+		origin.Publisher = []string{m.Publisher}
+	}
+	if m.PublicationDate != "" {
+		origin.DateIssued = append(origin.DateIssued, &modsDate{Encoding: "w3cdtf", KeyDate: "yes", Value: m.PublicationDate})
+	}
+	for _, d := range m.Dates {
+		if d == nil || d.Date == "" {
+			continue
+		}
+		typeID := ""
+		if d.Type != nil {
+			typeID = d.Type.ID
+		}
+		date := &modsDate{Encoding: "w3cdtf", Value: d.Date}
+		switch modsDateFields[typeID] {
+		case "dateCreated":
+			origin.DateCreated = append(origin.DateCreated, date)
+		case "dateModified":
+			origin.DateModified = append(origin.DateModified, date)
+		case "dateValid":
+			origin.DateValid = append(origin.DateValid, date)
+		case "dateCaptured":
+			origin.DateCaptured = append(origin.DateCaptured, date)
+		case "copyrightDate":
+			origin.CopyrightDate = append(origin.CopyrightDate, date)
+		default:
+			date.Type = typeID
+			origin.DateOther = append(origin.DateOther, date)
+		}
+	}
+	origin.Edition = m.Version
+	if len(origin.Publisher) > 0 || len(origin.DateIssued) > 0 || len(m.Dates) > 0 || origin.Edition != "" {
+		mods.OriginInfo = origin
+	}
+	for _, lang := range m.Languages {
+		if id, ok := lang["id"].(string); ok && id != "" {
+			mods.Language = append(mods.Language, &modsLanguage{LanguageTerm: []*modsValue{{Type: "code", Authority: "iso639-2b", Value: id}}})
+		}
+	}
+	if m.Description != "" {
+		mods.Abstract = append(mods.Abstract, &modsValue{Value: m.Description})
+	}
+	for _, d := range m.AdditionalDescriptions {
+		if d == nil || d.Description == "" {
+			continue
+		}
+		typeID := ""
+		if d.Type != nil {
+			typeID = d.Type.ID
+		}
+		if typeID == "abstract" {
+			mods.Abstract = append(mods.Abstract, &modsValue{Value: d.Description})
+		} else {
+			mods.Note = append(mods.Note, &modsValue{Type: typeID, Value: d.Description})
+		}
+	}
+	for _, s := range m.Subjects {
+		if s == nil || (s.Subject == "" && s.ID == "") {
+			continue
+		}
+		subject := &modsSubject{Topic: []string{s.Subject}, ValueURI: s.ID}
+		if s.Subject == "" {
+			subject.Topic = []string{s.ID}
+		}
+		mods.Subject = append(mods.Subject, subject)
+	}
+	if journal := journalFields(rec); journal["title"] != "" {
+		host := &modsRelatedItem{Type: "host", TitleInfo: []*modsTitleInfo{{Title: journal["title"]}}}
+		part := new(modsPart)
+		for _, key := range []string{"volume", "issue"} {
+			if journal[key] != "" {
+				part.Detail = append(part.Detail, &modsDetail{Type: key, Number: journal[key]})
+			}
+		}
+		if pages := journal["pages"]; pages != "" {
+			start, end := splitPages(pages)
+			part.Extent = &modsExtent{Unit: "pages", Start: start, End: end}
+		}
+		if len(part.Detail) > 0 || part.Extent != nil {
+			host.Part = part
+		}
+		if issn := recordISSN(rec); issn != "" {
+			host.Identifier = append(host.Identifier, &modsValue{Type: "issn", Value: issn})
+		}
+		mods.RelatedItem = append(mods.RelatedItem, host)
+	}
+	for _, id := range m.RelatedIdentifiers {
+		if id == nil || id.Identifier == "" {
+			continue
+		}
+		item := &modsRelatedItem{}
+		if id.RelationType != nil {
+			if t, ok := modsRelations[id.RelationType.ID]; ok {
+				item.Type = t
+			} else {
+				item.OtherType = id.RelationType.ID
+			}
+		}
+		scheme := strings.ToLower(id.Scheme)
+		if t, ok := modsIdentifierTypes[scheme]; ok {
+			scheme = t
+		}
+		item.Identifier = append(item.Identifier, &modsValue{Type: scheme, Value: id.Identifier})
+		mods.RelatedItem = append(mods.RelatedItem, item)
+	}
+	if doi := recordDOI(rec); doi != "" {
+		mods.Identifier = append(mods.Identifier, &modsValue{Type: "doi", Value: doi})
+	}
+	for _, id := range m.Identifiers {
+		if id == nil || id.Identifier == "" || strings.EqualFold(id.Scheme, "doi") {
+			continue
+		}
+		scheme := strings.ToLower(id.Scheme)
+		if t, ok := modsIdentifierTypes[scheme]; ok {
+			scheme = t
+		}
+		mods.Identifier = append(mods.Identifier, &modsValue{Type: scheme, Value: id.Identifier})
+	}
+	for _, r := range m.Rights {
+		if r == nil {
+			continue
+		}
+		label := localized(r.Title)
+		if label == "" {
+			label = r.ID
+		}
+		mods.AccessCondition = append(mods.AccessCondition, &modsAccessCondition{Type: "use and reproduction", Href: r.Link, Value: label})
+	}
+	return mods
+}
+
+// AsMODS renders the record as a MODS 3.8 "mods" element. Additional
+// titles become titleInfo elements with their type, creators and
+// contributors become names with MARC relator roles, dates are written
+// in originInfo, related identifiers and the "journal:journal" custom
+// field become relatedItem elements and rights become accessCondition.
+//
+// ```
+//
+//	src, err := rec.AsMODS()
+//	// ... handle error ...
+//	fmt.Printf("%s\n", src)
+//
+// ```
+func (rec *Record) AsMODS() ([]byte, error) {
+	if rec == nil {
+		return nil, fmt.Errorf("no record")
+	}
+	src, err := xml.MarshalIndent(rec.asMODS(), "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), src...), nil
+}
+
+// relatorRole returns the InvenioRDM role id for a MARC relator code
+// or term. Authors and creators return an empty string, unknown values
+// return "other".
+func relatorRole(value string) string {
+	value = strings.ToLower(strings.TrimRight(strings.TrimSpace(value), ".,"))
+	for id, t := range marcRelators {
+		if (t[0] != "" && value == t[0]) || value == t[1] {
+			return id
+		}
+	}
+	if value == "cre" || value == "creator" {
+		return ""
+	}
+	return "other"
+}
+
+// modsRoleID returns the InvenioRDM role id for a MODS name. Authors
+// and names without a role return an empty string.
+func modsRoleID(n *modsName) string {
+	for _, r := range n.Role {
+		for _, term := range r.RoleTerm {
+			if strings.TrimSpace(term.Value) != "" {
+				return relatorRole(term.Value)
+			}
+		}
+	}
+	return ""
+}
+
+// creator converts a MODS name into a Creator.
+func (n *modsName) creator(role string) *Creator {
+	var c *Creator
+	family, given, name := "", "", ""
+	for _, part := range n.NamePart {
+		value := strings.TrimSpace(part.Value)
+		switch part.Type {
+		case "family":
+			family = value
+		case "given":
+			given = strings.TrimSpace(given + " " + value)
+		case "date", "termsOfAddress":
+		default:
+			name = strings.TrimSpace(name + " " + value)
+		}
+	}
+	if name == "" && family == "" && given == "" {
+		name = strings.TrimSpace(n.DisplayForm)
+	}
+	switch {
+	case n.Type == "corporate" || n.Type == "conference":
+		c = newOrganization(strings.TrimSpace(strings.Join([]string{name, family, given}, " ")), role)
+	case family != "" || given != "":
+		c = newPerson(family, given, role)
+	default:
+		// An untyped name part is often "Family, Given".
+		if f, g, ok := strings.Cut(name, ","); ok && n.Type == "personal" {
+			c = newPerson(f, g, role)
+		} else if n.Type == "personal" {
+			c = newPerson(name, "", role)
+		} else {
+			c = newOrganization(name, role)
+		}
+	}
+	for _, id := range n.NameIdentifier {
+		if value := strings.TrimSpace(id.Value); value != "" {
+			addPersonIdentifier(c, strings.ToLower(id.Type), value)
+		}
+	}
+	for _, a := range n.Affiliation {
+		if a = strings.TrimSpace(a); a != "" {
+			addAffiliation(c, a, "")
+		}
+	}
+	return c
+}
+
+// asRecord maps the MODS structures to a Record.
+func (mods *modsXML) asRecord() *Record {
+	rec := new(Record)
+	m := ensureMetadata(rec)
+	for _, info := range mods.TitleInfo {
+		if info == nil || strings.TrimSpace(info.Title) == "" {
+			continue
+		}
+		title := strings.TrimSpace(info.Title)
+		if info.Type == "" && m.Title == "" {
+			m.Title = title
+			if sub := strings.TrimSpace(info.SubTitle); sub != "" {
+				m.AdditionalTitles = append(m.AdditionalTitles, &TitleDetail{Title: sub, Type: &Type{ID: "subtitle"}})
+			}
+			continue
+		}
+		typeID, ok := modsTitleTypeIDs[info.Type]
+		if !ok {
+			typeID = "alternative-title"
+		}
+		t := &TitleDetail{Title: title, Type: &Type{ID: typeID}}
+		if info.Lang != "" {
+			t.Lang = &Type{ID: info.Lang}
+		}
+		m.AdditionalTitles = append(m.AdditionalTitles, t)
+	}
+	for _, n := range mods.Name {
+		role := modsRoleID(n)
+		if role == "" {
+			m.Creators = append(m.Creators, n.creator(""))
+		} else {
+			m.Contributors = append(m.Contributors, n.creator(role))
+		}
+	}
+	resourceType := ""
+	for _, g := range mods.Genre {
+		if g.Authority == "local" && strings.TrimSpace(g.Value) != "" {
+			resourceType = strings.TrimSpace(g.Value)
+			break
+		}
+	}
+	if resourceType == "" {
+		resourceType = modsResourceTypeIDs[strings.TrimSpace(mods.TypeOfResource)]
+	}
+	if resourceType != "" {
+		setResourceType(rec, resourceType)
+	}
+	if origin := mods.OriginInfo; origin != nil {
+		if len(origin.Publisher) > 0 {
+			m.Publisher = strings.TrimSpace(origin.Publisher[0])
+		}
+		for _, d := range origin.DateIssued {
+			if m.PublicationDate == "" || d.KeyDate == "yes" {
+				m.PublicationDate = strings.TrimSpace(d.Value)
+			}
+		}
+		for i, dates := range [][]*modsDate{origin.DateCreated, origin.DateModified, origin.DateValid, origin.DateCaptured, origin.CopyrightDate} {
+			typeID := []string{"created", "updated", "valid", "collected", "copyrighted"}[i]
+			for _, d := range dates {
+				m.Dates = append(m.Dates, &DateType{Date: strings.TrimSpace(d.Value), Type: &Type{ID: typeID}})
+			}
+		}
+		for _, d := range origin.DateOther {
+			typeID := d.Type
+			if typeID == "" {
+				typeID = "other"
+			}
+			m.Dates = append(m.Dates, &DateType{Date: strings.TrimSpace(d.Value), Type: &Type{ID: typeID}})
+		}
+		m.Version = strings.TrimSpace(origin.Edition)
+	}
+	for _, lang := range mods.Language {
+		for _, term := range lang.LanguageTerm {
+			if term.Type != "text" && strings.TrimSpace(term.Value) != "" {
+				m.Languages = append(m.Languages, map[string]interface{}{"id": strings.TrimSpace(term.Value)})
+			}
+		}
+	}
+	for _, a := range mods.Abstract {
+		value := strings.TrimSpace(a.Value)
+		switch {
+		case value == "":
+		case m.Description == "":
+			m.Description = value
+		default:
+			m.AdditionalDescriptions = append(m.AdditionalDescriptions, &Description{Description: value, Type: &Type{ID: "abstract"}})
+		}
+	}
+	for _, note := range mods.Note {
+		if value := strings.TrimSpace(note.Value); value != "" {
+			typeID := note.Type
+			if typeID == "" {
+				typeID = "other"
+			}
+			m.AdditionalDescriptions = append(m.AdditionalDescriptions, &Description{Description: value, Type: &Type{ID: typeID}})
+		}
+	}
+	for _, s := range mods.Subject {
+		for _, topic := range s.Topic {
+			if topic = strings.TrimSpace(topic); topic == "" {
+				continue
+			}
+			// Subjects with only an id are written with the id as topic.
+			if topic == s.ValueURI {
+				m.Subjects = append(m.Subjects, &Subject{ID: topic})
+			} else {
+				m.Subjects = append(m.Subjects, &Subject{Subject: topic, ID: s.ValueURI})
+			}
+		}
+	}
+	for _, item := range mods.RelatedItem {
+		if item.Type == "host" && len(item.TitleInfo) > 0 && item.TitleInfo[0].Title != "" {
+			setJournalField(rec, "title", strings.TrimSpace(item.TitleInfo[0].Title))
+			if item.Part != nil {
+				for _, d := range item.Part.Detail {
+					if d.Type == "volume" || d.Type == "issue" {
+						setJournalField(rec, d.Type, strings.TrimSpace(d.Number))
+					}
+				}
+				if e := item.Part.Extent; e != nil {
+					if e.List != "" {
+						setJournalField(rec, "pages", strings.TrimSpace(e.List))
+					} else {
+						setJournalField(rec, "pages", joinPages(strings.TrimSpace(e.Start), strings.TrimSpace(e.End)))
+					}
+				}
+			}
+			for _, id := range item.Identifier {
+				if id.Type == "issn" {
+					setJournalField(rec, "issn", strings.TrimSpace(id.Value))
+				}
+			}
+			continue
+		}
+		relation := item.OtherType
+		if relation == "" {
+			relation = modsRelationIDs[item.Type]
+		}
+		if relation == "" {
+			relation = "references"
+		}
+		for _, id := range item.Identifier {
+			value := strings.TrimSpace(id.Value)
+			if value == "" {
+				continue
+			}
+			scheme := modsScheme(id.Type)
+			m.RelatedIdentifiers = append(m.RelatedIdentifiers, &Identifier{Scheme: scheme, Identifier: value, RelationType: &TypeDetail{ID: relation}})
+		}
+	}
+	for _, id := range mods.Identifier {
+		value := strings.TrimSpace(id.Value)
+		if value == "" {
+			continue
+		}
+		scheme := modsScheme(id.Type)
+		if scheme == "doi" && recordDOI(rec) == "" {
+			setRecordDOI(rec, value)
+			continue
+		}
+		m.Identifiers = addIdentifier(m.Identifiers, scheme, value)
+	}
+	for _, a := range mods.AccessCondition {
+		value := strings.TrimSpace(a.Value)
+		if value == "" && a.Href == "" {
+			continue
+		}
+		r := &Right{Link: strings.TrimSpace(a.Href)}
+		if value != "" {
+			r.Title = map[string]string{"en": value}
+		}
+		m.Rights = append(m.Rights, r)
+	}
+	return rec
+}
+
+// modsScheme returns the identifier scheme for a MODS identifier type.
+func modsScheme(t string) string {
+	t = strings.ToLower(strings.TrimSpace(t))
+	for scheme, modsType := range modsIdentifierTypes {
+		if t == modsType {
+			return scheme
+		}
+	}
+	if t == "" {
+		return "other"
+	}
+	return t
+}
+
+// RecordFromMODS reads a MODS record. The first "mods" element found is
+// read so a record inside a modsCollection or an OAI-PMH response can be
+// read directly.
+//
+// ```
+//
+//	src, err := os.ReadFile("record.xml")
+//	// ... handle error ...
+//	rec, err := simplified.RecordFromMODS(src)
+//	// ... handle error ...
+//
+// ```
+func RecordFromMODS(src []byte) (*Record, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(src))
+	for {
+		tok, err := decoder.Token()
+		if err == io.EOF {
+			return nil, fmt.Errorf("mods, no mods element found")
+		}
+		if err != nil {
+			return nil, fmt.Errorf("mods, %s", err)
+		}
+		start, ok := tok.(xml.StartElement)
+		if !ok || start.Name.Local != "mods" {
+			continue
+		}
+		mods := new(modsXML)
+		if err := decoder.DecodeElement(mods, &start); err != nil {
+			return nil, fmt.Errorf("mods, %s", err)
+		}
+		return mods.asRecord(), nil
+	}
+}
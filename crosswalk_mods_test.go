@@ -0,0 +1,185 @@
+package simplified
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAsMODS(t *testing.T) {
+	rec := testRecord(t)
+	rec.Metadata.AdditionalTitles = append(rec.Metadata.AdditionalTitles, &TitleDetail{Title: "Silice", Type: &Type{ID: "translated-title"}, Lang: &Type{ID: "fra"}})
+	rec.Metadata.Contributors = append(rec.Metadata.Contributors, newPerson("Doe", "Jane", "editor"))
+	setJournalField(rec, "pages", "15-23")
+	src, err := rec.AsMODS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{
+		`<mods xmlns="http://www.loc.gov/mods/v3"`,
+		`version="3.8"`,
+		"<title>Microbially induced precipitation of silica</title>",
+		"<subTitle>a subtitle</subTitle>",
+		`<titleInfo type="translated" lang="fra">`,
+		`<name type="personal">`,
+		`<namePart type="family">Orphan</namePart>`,
+		`<namePart type="given">Victoria J.</namePart>`,
+		"<affiliation>California Institute of Technology</affiliation>",
+		`<roleTerm type="code" authority="marcrelator">aut</roleTerm>`,
+		`<roleTerm type="text" authority="marcrelator">editor</roleTerm>`,
+		`<roleTerm type="code" authority="marcrelator">his</roleTerm>`,
+		`<nameIdentifier type="orcid">0000-0002-5374-6178</nameIdentifier>`,
+		"<typeOfResource>text</typeOfResource>",
+		`<genre authority="local">publication-article</genre>`,
+		"<publisher>National Academy of Sciences</publisher>",
+		`<dateIssued encoding="w3cdtf" keyDate="yes">2023-12-19</dateIssued>`,
+		`<dateOther encoding="w3cdtf" type="accepted">2023-12-01</dateOther>`,
+		"<abstract>&lt;p&gt;An abstract.&lt;/p&gt;</abstract>",
+		`<note type="acknowledgement">Thanks to all.</note>`,
+		"<topic>Multidisciplinary</topic>",
+		`<relatedItem type="host">`,
+		`<detail type="volume">`,
+		"<start>15</start>",
+		`<identifier type="issn">1091-6490</identifier>`,
+		`<relatedItem otherType="issupplementedby">`,
+		`<identifier type="uri">https://example.edu/supplement.pdf</identifier>`,
+		`<identifier type="doi">10.1073/pnas.2302156120</identifier>`,
+		`type="use and reproduction"`,
+		`href="https://creativecommons.org/licenses/by/4.0/"`,
+	} {
+		if !strings.Contains(string(src), expected) {
+			t.Errorf("expected %q in MODS ->\n%s", expected, src)
+		}
+	}
+}
+
+func TestRecordFromMODS(t *testing.T) {
+	src := []byte(`<?xml version="1.0" encoding="UTF-8"?>
+<modsCollection xmlns="http://www.loc.gov/mods/v3" xmlns:xlink="http://www.w3.org/1999/xlink">
+  <mods version="3.7">
+    <titleInfo><title>Seismic hazards</title><subTitle>a review</subTitle></titleInfo>
+    <titleInfo type="abbreviated"><title>Seis. haz.</title></titleInfo>
+    <name type="personal">
+      <namePart>van Beethoven, Ludwig</namePart>
+      <role><roleTerm type="text">author</roleTerm></role>
+    </name>
+    <name type="personal">
+      <namePart type="family">Doe</namePart>
+      <namePart type="given">Jane</namePart>
+      <namePart type="date">1970-</namePart>
+      <role><roleTerm authority="marcrelator" type="code">ths</roleTerm></role>
+      <nameIdentifier type="orcid">0000-0001-2345-6789</nameIdentifier>
+    </name>
+    <name type="corporate"><namePart>Caltech Library</namePart></name>
+    <name type="personal"><namePart>Roe, Richard</namePart><role><roleTerm type="text">illustrator</roleTerm></role></name>
+    <typeOfResource>text</typeOfResource>
+    <genre authority="marcgt">thesis</genre>
+    <originInfo>
+      <publisher>California Institute of Technology</publisher>
+      <dateIssued>2020</dateIssued>
+      <dateIssued encoding="w3cdtf" keyDate="yes">2020-06-01</dateIssued>
+      <copyrightDate>2020</copyrightDate>
+    </originInfo>
+    <language><languageTerm type="code" authority="iso639-2b">eng</languageTerm><languageTerm type="text">English</languageTerm></language>
+    <abstract>A review.</abstract>
+    <subject authority="lcsh"><topic>Earthquakes</topic><topic>Risk</topic></subject>
+    <relatedItem type="host">
+      <titleInfo><title>Seismology Letters</title></titleInfo>
+      <part><detail type="volume"><number>9</number></detail><extent unit="pages"><start>1</start><end>12</end></extent></part>
+    </relatedItem>
+    <relatedItem type="otherVersion"><identifier type="arxiv">2001.00001</identifier></relatedItem>
+    <identifier type="doi">10.7907/ABC</identifier>
+    <identifier type="uri">https://thesis.library.caltech.edu/1/</identifier>
+    <accessCondition type="use and reproduction" xlink:href="https://rightsstatements.org/vocab/InC/1.0/">In Copyright</accessCondition>
+  </mods>
+</modsCollection>`)
+	rec, err := RecordFromMODS(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := rec.Metadata
+	if m.Title != "Seismic hazards" || len(m.AdditionalTitles) != 2 || m.AdditionalTitles[0].Type.ID != "subtitle" || m.AdditionalTitles[1].Type.ID != "other" {
+		t.Errorf("unexpected titles %s", rec.ToString())
+	}
+	if len(m.Creators) != 2 || m.Creators[0].PersonOrOrg.FamilyName != "van Beethoven" || !isOrganization(m.Creators[1].PersonOrOrg) {
+		t.Errorf("unexpected creators %s", rec.ToString())
+	}
+	if len(m.Contributors) != 2 || creatorRole(m.Contributors[0]) != "supervisor" || personIdentifier(m.Contributors[0].PersonOrOrg, "orcid") != "0000-0001-2345-6789" || creatorRole(m.Contributors[1]) != "other" {
+		t.Errorf("unexpected contributors %s", rec.ToString())
+	}
+	if resourceTypeID(rec) != "publication" || m.PublicationDate != "2020-06-01" || len(m.Dates) != 1 || m.Dates[0].Type.ID != "copyrighted" {
+		t.Errorf("unexpected resource type or dates %s", rec.ToString())
+	}
+	if len(m.Languages) != 1 || m.Languages[0]["id"] != "eng" {
+		t.Errorf("unexpected languages %s", rec.ToString())
+	}
+	if len(m.Subjects) != 2 || m.Subjects[1].Subject != "Risk" {
+		t.Errorf("unexpected subjects %s", rec.ToString())
+	}
+	journal := journalFields(rec)
+	if journal["title"] != "Seismology Letters" || journal["volume"] != "9" || journal["pages"] != "1-12" {
+		t.Errorf("unexpected journal %v", journal)
+	}
+	if len(m.RelatedIdentifiers) != 1 || m.RelatedIdentifiers[0].Scheme != "arxiv" || m.RelatedIdentifiers[0].RelationType.ID != "isversionof" {
+		t.Errorf("unexpected related identifiers %s", rec.ToString())
+	}
+	if recordDOI(rec) != "10.7907/abc" || identifierValue(m.Identifiers, "url") != "https://thesis.library.caltech.edu/1/" {
+		t.Errorf("unexpected identifiers %s", rec.ToString())
+	}
+	if len(m.Rights) != 1 || m.Rights[0].Link != "https://rightsstatements.org/vocab/InC/1.0/" || m.Rights[0].Title["en"] != "In Copyright" {
+		t.Errorf("unexpected rights %s", rec.ToString())
+	}
+	if _, err := RecordFromMODS([]byte(`<record/>`)); err == nil {
+		t.Errorf("expected an error for a document without a mods element")
+	}
+}
+
+func TestMODSRoundTrip(t *testing.T) {
+	rec1 := testRecord(t)
+	rec1.Metadata.Contributors = append(rec1.Metadata.Contributors, newPerson("Doe", "Jane", "editor"))
+	// MODS affiliations are text only.
+	rec1.Metadata.Creators[0].Affiliations[0].ID = ""
+	src, err := rec1.AsMODS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec2, err := RecordFromMODS(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m1, m2 := rec1.Metadata, rec2.Metadata
+	if m1.Title != m2.Title || m1.Description != m2.Description || m1.PublicationDate != m2.PublicationDate || m1.Publisher != m2.Publisher {
+		t.Errorf("expected title, abstract, date and publisher to round trip\n%s", rec2.ToString())
+	}
+	if resourceTypeID(rec2) != "publication-article" || recordDOI(rec2) != recordDOI(rec1) || recordISSN(rec2) != "1091-6490" {
+		t.Errorf("expected resource type, DOI and ISSN to round trip\n%s", rec2.ToString())
+	}
+	if len(m2.Creators) != len(m1.Creators) || !sameCreatorNames(m1.Creators[0], m2.Creators[0]) {
+		t.Errorf("expected creators to round trip\n%s", rec2.ToString())
+	}
+	if len(m2.Contributors) != len(m1.Contributors) {
+		t.Fatalf("expected contributors to round trip\n%s", rec2.ToString())
+	}
+	for i, c := range m1.Contributors {
+		if !sameCreatorNames(c, m2.Contributors[i]) {
+			t.Errorf("expected contributor %d to round trip, got %+v", i, m2.Contributors[i].PersonOrOrg)
+		}
+	}
+	if len(m2.AdditionalTitles) != 1 || m2.AdditionalTitles[0].Title != "a subtitle" || m2.AdditionalTitles[0].Type.ID != "subtitle" {
+		t.Errorf("expected the subtitle to round trip\n%s", rec2.ToString())
+	}
+	if len(m2.AdditionalDescriptions) != 1 || m2.AdditionalDescriptions[0].Type.ID != "acknowledgement" {
+		t.Errorf("expected additional descriptions to round trip\n%s", rec2.ToString())
+	}
+	if len(m2.Dates) != 1 || m2.Dates[0].Date != "2023-12-01" || m2.Dates[0].Type.ID != "accepted" {
+		t.Errorf("expected dates to round trip\n%s", rec2.ToString())
+	}
+	if len(m2.Subjects) != 1 || m2.Subjects[0].Subject != "Multidisciplinary" {
+		t.Errorf("expected subjects to round trip\n%s", rec2.ToString())
+	}
+	if len(m2.RelatedIdentifiers) != 1 || m2.RelatedIdentifiers[0].Identifier != m1.RelatedIdentifiers[0].Identifier || m2.RelatedIdentifiers[0].RelationType.ID != "issupplementedby" {
+		t.Errorf("expected related identifiers to round trip\n%s", rec2.ToString())
+	}
+	if len(m2.Rights) != 1 || m2.Rights[0].Link != m1.Rights[0].Link {
+		t.Errorf("expected rights to round trip\n%s", rec2.ToString())
+	}
+}
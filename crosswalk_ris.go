@@ -0,0 +1,362 @@
+package simplified
+
+/**
+ * This file implements a crosswalk between the simplified Record and the
+ * RIS tagged format used by reference managers. Records are written with
+ * AsRIS and read one at a time with a RISReader.
+ */
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// risTypes maps InvenioRDM resource type ids to RIS reference types.
+var risTypes = map[string]string{
+	"publication-article":              "JOUR",
+	"publication-preprint":             "UNPB",
+	"publication-book":                 "BOOK",
+	"publication-section":              "CHAP",
+	"publication-conferencepaper":      "CPAPER",
+	"publication-conferenceproceeding": "CONF",
+	"publication-report":               "RPRT",
+	"publication-technicalnote":        "RPRT",
+	"publication-workingpaper":         "RPRT",
+	"publication-thesis":               "THES",
+	"publication-patent":               "PAT",
+	"publication-standard":             "STAND",
+	"dataset":                          "DATA",
+	"software":                         "COMP",
+	"image":                            "FIGURE",
+	"image-figure":                     "FIGURE",
+	"video":                            "VIDEO",
+	"audio":                            "SOUND",
+}
+
+// risResourceTypes maps RIS reference types to InvenioRDM resource type ids.
+var risResourceTypes = map[string]string{
+	"JOUR":   "publication-article",
+	"JFULL":  "publication-article",
+	"MGZN":   "publication-article",
+	"NEWS":   "publication-article",
+	"EJOUR":  "publication-article",
+	"UNPB":   "publication-preprint",
+	"BOOK":   "publication-book",
+	"EBOOK":  "publication-book",
+	"EDBOOK": "publication-book",
+	"CHAP":   "publication-section",
+	"ECHAP":  "publication-section",
+	"CPAPER": "publication-conferencepaper",
+	"CONF":   "publication-conferenceproceeding",
+	"RPRT":   "publication-report",
+	"THES":   "publication-thesis",
+	"PAT":    "publication-patent",
+	"STAND":  "publication-standard",
+	"DATA":   "dataset",
+	"DBASE":  "dataset",
+	"COMP":   "software",
+	"FIGURE": "image-figure",
+	"VIDEO":  "video",
+	"SOUND":  "audio",
+	"GEN":    "other",
+}
+
+// risTag writes a RIS tag line, skipping empty values.
+func risTag(buf *bytes.Buffer, tag string, value string) {
+	value = strings.Join(strings.Fields(value), " ")
+	if value != "" {
+		fmt.Fprintf(buf, "%s  - %s\n", tag, value)
+	}
+}
+
+// risName formats a creator as "Family, Given". Organizations are
+// written as their name.
+func risName(c *Creator) string {
+	if c == nil || c.PersonOrOrg == nil {
+		return ""
+	}
+	if isOrganization(c.PersonOrOrg) {
+		return c.PersonOrOrg.Name
+	}
+	family, given := personNames(c.PersonOrOrg)
+	if given == "" {
+		return family
+	}
+	return family + ", " + given
+}
+
+// AsRIS renders the record as a RIS reference terminated by an "ER" tag.
+// Creators are written as AU, editors as ED, the journal custom fields
+// as JO, VL, IS, SP and EP.
+//
+// ```
+//
+//	fmt.Printf("%s", rec.AsRIS())
+//
+// ```
+func (rec *Record) AsRIS() []byte {
+	if rec == nil {
+		return nil
+	}
+	m := rec.Metadata
+	if m == nil {
+		m = new(Metadata)
+	}
+	buf := new(bytes.Buffer)
+	risType, ok := risTypes[resourceTypeID(rec)]
+	if !ok {
+		risType = "GEN"
+	}
+	risTag(buf, "TY", risType)
+	risTag(buf, "ID", rec.ID)
+	for _, c := range creatorsWithoutRole(m.Creators, "editor") {
+		risTag(buf, "AU", risName(c))
+	}
+	for _, c := range append(creatorsWithRole(m.Creators, "editor"), creatorsWithRole(m.Contributors, "editor")...) {
+		risTag(buf, "ED", risName(c))
+	}
+	risTag(buf, "TI", m.Title)
+	year, month, day := dateParts(m.PublicationDate)
+	risTag(buf, "PY", year)
+	if month != "" {
+		risTag(buf, "DA", strings.Join([]string{year, month, day}, "/"))
+	}
+	journal := journalFields(rec)
+	if risType == "JOUR" {
+		risTag(buf, "JO", journal["title"])
+	} else {
+		risTag(buf, "T2", journal["title"])
+	}
+	risTag(buf, "VL", journal["volume"])
+	risTag(buf, "IS", journal["issue"])
+	start, end := splitPages(journal["pages"])
+	risTag(buf, "SP", start)
+	risTag(buf, "EP", end)
+	risTag(buf, "PB", m.Publisher)
+	risTag(buf, "ET", m.Version)
+	if isbn := identifierValue(m.Identifiers, "isbn"); isbn != "" {
+		risTag(buf, "SN", isbn)
+	} else {
+		risTag(buf, "SN", recordISSN(rec))
+	}
+	risTag(buf, "DO", recordDOI(rec))
+	risTag(buf, "UR", identifierValue(m.Identifiers, "url"))
+	risTag(buf, "AB", stripHTML(m.Description))
+	for _, keyword := range subjectLabels(m.Subjects) {
+		risTag(buf, "KW", keyword)
+	}
+	buf.WriteString("ER  - \n")
+	return buf.Bytes()
+}
+
+// RISReader reads RIS references one at a time from an io.Reader
+// returning each as a Record. Text before the first "TY" tag is skipped.
+type RISReader struct {
+	scanner *bufio.Scanner
+	line    int
+	// pending holds a line read but not used by the previous Read,
+	// e.g. the TY of a reference following one missing its ER.
+	pending    string
+	hasPending bool
+}
+
+// NewRISReader creates a RISReader for r.
+//
+// ```
+//
+//	reader := simplified.NewRISReader(os.Stdin)
+//	for {
+//	    rec, err := reader.Read()
+//	    if err == io.EOF {
+//	        break
+//	    }
+//	    // ... handle error ...
+//	    fmt.Printf("%s\n", rec.Metadata.Title)
+//	}
+//
+// ```
+func NewRISReader(r io.Reader) *RISReader {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	return &RISReader{scanner: scanner}
+}
+
+var reRISTag = regexp.MustCompile(`^([A-Z][A-Z0-9])  -( (.*))?$`)
+
+// Read returns the next reference as a Record. It returns io.EOF when
+// there are no more references. A reference missing its ER is an
+// error, the following reference is returned by the next Read. The RIS
+// ID tag is a reference manager's own id and is not read.
+func (r *RISReader) Read() (*Record, error) {
+	var (
+		tags    [][2]string
+		inEntry bool
+	)
+	for {
+		text, ok := r.next()
+		if !ok {
+			break
+		}
+		m := reRISTag.FindStringSubmatch(text)
+		if m == nil {
+			// Lines without a tag continue the previous value.
+			if inEntry && len(tags) > 0 && strings.TrimSpace(text) != "" {
+				tags[len(tags)-1][1] += " " + strings.TrimSpace(text)
+			}
+			continue
+		}
+		tag, value := m[1], strings.TrimSpace(m[3])
+		switch {
+		case tag == "TY":
+			if inEntry {
+				// The next Read starts with this reference.
+				r.pending, r.hasPending = text, true
+				return nil, fmt.Errorf("ris line %d, TY found before ER", r.line)
+			}
+			inEntry = true
+		case !inEntry:
+			continue
+		case tag == "ER":
+			return recordFromRIS(tags), nil
+		}
+		tags = append(tags, [2]string{tag, value})
+	}
+	if err := r.scanner.Err(); err != nil {
+		return nil, err
+	}
+	if inEntry {
+		return nil, fmt.Errorf("ris line %d, missing ER at end of reference", r.line)
+	}
+	return nil, io.EOF
+}
+
+// next returns the next line, the pending line if there is one.
+func (r *RISReader) next() (string, bool) {
+	if r.hasPending {
+		r.hasPending = false
+		return r.pending, true
+	}
+	if !r.scanner.Scan() {
+		return "", false
+	}
+	r.line++
+	return strings.TrimRight(strings.TrimPrefix(r.scanner.Text(), "\uFEFF"), "\r"), true
+}
+
+// ReadAll reads the remaining references.
+func (r *RISReader) ReadAll() ([]*Record, error) {
+	records := []*Record{}
+	for {
+		rec, err := r.Read()
+		if err == io.EOF {
+			return records, nil
+		}
+		if err != nil {
+			return records, err
+		}
+		records = append(records, rec)
+	}
+}
+
+// risCreator converts a RIS name. Names without a comma are treated
+// as organizations.
+func risCreator(name string, role string) *Creator {
+	family, given, ok := strings.Cut(name, ",")
+	if !ok {
+		return newOrganization(name, role)
+	}
+	// Drop a trailing suffix, e.g. "Doe, Jane, Jr."
+	given, _, _ = strings.Cut(given, ",")
+	return newPerson(family, given, role)
+}
+
+// risDate converts a RIS date, e.g. "2023/12/19/" into an ISO date.
+func risDate(s string) string {
+	parts := strings.FieldsFunc(s, func(r rune) bool { return r == '/' || r == '-' })
+	for len(parts) < 3 {
+		parts = append(parts, "")
+	}
+	return joinDate(parts[0], parts[1], parts[2])
+}
+
+// recordFromRIS converts the tags of a reference into a Record.
+func recordFromRIS(tags [][2]string) *Record {
+	rec := new(Record)
+	m := ensureMetadata(rec)
+	var year, date, startPage, endPage string
+	for _, tv := range tags {
+		tag, value := tv[0], tv[1]
+		if value == "" {
+			continue
+		}
+		switch tag {
+		case "TY":
+			id, ok := risResourceTypes[value]
+			if !ok {
+				id = "other"
+			}
+			setResourceType(rec, id)
+		case "AU", "A1":
+			m.Creators = append(m.Creators, risCreator(value, ""))
+		case "ED", "A2":
+			m.Contributors = append(m.Contributors, risCreator(value, "editor"))
+		case "TI", "T1":
+			if m.Title == "" {
+				m.Title = value
+			}
+		case "PY", "Y1":
+			year = risDate(value)
+		case "DA":
+			date = risDate(value)
+		case "JO", "JF", "T2", "JA", "J2", "BT":
+			if _, ok := journalFields(rec)["title"]; !ok {
+				setJournalField(rec, "title", value)
+			}
+		case "VL":
+			setJournalField(rec, "volume", value)
+		case "IS":
+			setJournalField(rec, "issue", value)
+		case "SP":
+			startPage = value
+		case "EP":
+			endPage = value
+		case "PB":
+			m.Publisher = value
+		case "ET":
+			m.Version = value
+		case "SN":
+			digits := strings.Map(func(r rune) rune {
+				if (r >= '0' && r <= '9') || r == 'X' || r == 'x' {
+					return r
+				}
+				return -1
+			}, value)
+			if len(digits) == 8 {
+				m.Identifiers = addIdentifier(m.Identifiers, "issn", value)
+			} else {
+				m.Identifiers = addIdentifier(m.Identifiers, "isbn", value)
+			}
+		case "DO":
+			setRecordDOI(rec, value)
+		case "UR":
+			m.Identifiers = addIdentifier(m.Identifiers, "url", value)
+		case "AB", "N2":
+			if m.Description == "" {
+				m.Description = value
+			}
+		case "KW":
+			m.Subjects = append(m.Subjects, &Subject{Subject: value})
+		}
+	}
+	// DA holds the full date, PY may only hold the year.
+	m.PublicationDate = year
+	if len(date) > len(year) {
+		m.PublicationDate = date
+	}
+	setJournalField(rec, "pages", joinPages(startPage, endPage))
+	return rec
+}
@@ -0,0 +1,206 @@
+package simplified
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestAsRIS(t *testing.T) {
+	rec := testRecord(t)
+	setJournalField(rec, "pages", "15-23")
+	src := string(rec.AsRIS())
+	for _, expected := range []string{
+		"TY  - JOUR\n",
+		"AU  - Orphan, Victoria J.\n",
+		"TI  - Microbially induced precipitation of silica\n",
+		"PY  - 2023\n",
+		"DA  - 2023/12/19\n",
+		"JO  - PNAS\n",
+		"VL  - 120\n",
+		"IS  - 51\n",
+		"SP  - 15\n",
+		"EP  - 23\n",
+		"SN  - 1091-6490\n",
+		"DO  - 10.1073/pnas.2302156120\n",
+		"AB  - An abstract.\n",
+		"KW  - Multidisciplinary\n",
+	} {
+		if !strings.Contains(src, expected) {
+			t.Errorf("expected %q in RIS ->\n%s", expected, src)
+		}
+	}
+	if !strings.HasPrefix(src, "TY  - ") || !strings.HasSuffix(src, "ER  - \n") {
+		t.Errorf("expected reference to start with TY and end with ER ->\n%s", src)
+	}
+}
+
+func TestRISReader(t *testing.T) {
+	src := "\uFEFFSome header text\r\n" +
+		"TY  - CHAP\r\n" +
+		"AU  - van Beethoven, Ludwig\r\n" +
+		"AU  - Caltech Library\r\n" +
+		"ED  - Doe, Jane\r\n" +
+		"TI  - A chapter with a\r\n" +
+		"long title\r\n" +
+		"T2  - A Book\r\n" +
+		"PY  - 2021///\r\n" +
+		"SP  - 7\r\n" +
+		"SN  - 978-3-16-148410-0\r\n" +
+		"KW  - music\r\n" +
+		"KW  - history\r\n" +
+		"ER  - \r\n" +
+		"\r\n" +
+		"TY  - DATA\n" +
+		"TI  - Seismic data\n" +
+		"DA  - 2020/05/01/\n" +
+		"DO  - 10.22002/D1.1234\n" +
+		"ER  -\n"
+	reader := NewRISReader(strings.NewReader(src))
+	rec, err := reader.Read()
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := rec.Metadata
+	if id := resourceTypeID(rec); id != "publication-section" {
+		t.Errorf("expected publication-section, got %q", id)
+	}
+	if m.Title != "A chapter with a long title" {
+		t.Errorf("expected continued title, got %q", m.Title)
+	}
+	if len(m.Creators) != 2 || m.Creators[0].PersonOrOrg.FamilyName != "van Beethoven" || m.Creators[1].PersonOrOrg.Type != "organizational" {
+		t.Errorf("unexpected creators %s", rec.ToString())
+	}
+	if len(m.Contributors) != 1 || creatorRole(m.Contributors[0]) != "editor" {
+		t.Errorf("expected an editor, got %s", rec.ToString())
+	}
+	if m.PublicationDate != "2021" {
+		t.Errorf("expected 2021, got %q", m.PublicationDate)
+	}
+	if journal := journalFields(rec); journal["title"] != "A Book" || journal["pages"] != "7" {
+		t.Errorf("unexpected journal fields %v", journal)
+	}
+	if isbn := identifierValue(m.Identifiers, "isbn"); isbn != "978-3-16-148410-0" {
+		t.Errorf("expected ISBN, got %q", isbn)
+	}
+	if len(m.Subjects) != 2 {
+		t.Errorf("expected two keywords, got %d", len(m.Subjects))
+	}
+
+	rec, err = reader.Read()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resourceTypeID(rec) != "dataset" || rec.Metadata.PublicationDate != "2020-05-01" || recordDOI(rec) != "10.22002/d1.1234" {
+		t.Errorf("unexpected second record %s", rec.ToString())
+	}
+	if _, err := reader.Read(); err != io.EOF {
+		t.Errorf("expected io.EOF, got %v", err)
+	}
+
+	if _, err := NewRISReader(strings.NewReader("TY  - JOUR\nTI  - No end\n")).Read(); err == nil || err == io.EOF {
+		t.Errorf("expected an error for a missing ER, got %v", err)
+	}
+
+	// After a reference missing its ER the next reference is read.
+	reader = NewRISReader(strings.NewReader("TY  - JOUR\nTI  - No end\nTY  - BOOK\nTI  - A Book\nER  - \n"))
+	if _, err := reader.Read(); err == nil || !strings.Contains(err.Error(), "line 3") {
+		t.Errorf("expected an error for TY before ER on line 3, got %v", err)
+	}
+	rec, err = reader.Read()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resourceTypeID(rec) != "publication-book" || rec.Metadata.Title != "A Book" {
+		t.Errorf("expected the book after the error ->\n%s", rec.ToString())
+	}
+	if _, err := reader.Read(); err != io.EOF {
+		t.Errorf("expected io.EOF, got %v", err)
+	}
+}
+
+func TestRISReaderExport(t *testing.T) {
+	// testdata/export.ris is laid out as reference managers export RIS,
+	// CRLF line endings and a blank line between references.
+	f, err := os.Open("testdata/export.ris")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	records, err := NewRISReader(f).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 3 {
+		t.Fatalf("expected three records, got %d", len(records))
+	}
+	article := records[0]
+	m := article.Metadata
+	if resourceTypeID(article) != "publication-article" || m.Title != "Microbially induced precipitation of silica" || m.PublicationDate != "2023-12-19" {
+		t.Errorf("unexpected article ->\n%s", article.ToString())
+	}
+	if len(m.Creators) != 2 || m.Creators[1].PersonOrOrg.FamilyName != "Ruff" || m.Creators[1].PersonOrOrg.GivenName != "S. Emil" {
+		t.Errorf("unexpected creators ->\n%s", article.ToString())
+	}
+	if recordDOI(article) != "10.1073/pnas.2302156120" || identifierValue(m.Identifiers, "issn") != "0027-8424" || identifierValue(m.Identifiers, "url") == "" {
+		t.Errorf("unexpected identifiers ->\n%s", article.ToString())
+	}
+	journal := journalFields(article)
+	if journal["title"] != "Proceedings of the National Academy of Sciences" || journal["volume"] != "120" || journal["issue"] != "51" || journal["pages"] != "e2302156120" {
+		t.Errorf("unexpected journal fields %v", journal)
+	}
+	if len(m.Subjects) != 2 || m.Description == "" {
+		t.Errorf("expected the keywords and abstract ->\n%s", article.ToString())
+	}
+	book := records[1]
+	m = book.Metadata
+	if resourceTypeID(book) != "publication-book" || m.Publisher != "Caltech Library" || m.Version != "2nd" || identifierValue(m.Identifiers, "isbn") != "978-0-306-40615-7" {
+		t.Errorf("unexpected book ->\n%s", book.ToString())
+	}
+	if len(m.Contributors) != 1 || creatorRole(m.Contributors[0]) != "editor" {
+		t.Errorf("expected an editor ->\n%s", book.ToString())
+	}
+	if thesis := records[2]; resourceTypeID(thesis) != "publication-thesis" || thesis.Metadata.PublicationDate != "2020" {
+		t.Errorf("unexpected thesis ->\n%s", thesis.ToString())
+	}
+}
+
+func TestRISRoundTrip(t *testing.T) {
+	rec := testRecord(t)
+	setJournalField(rec, "pages", "15-23")
+	buf := new(bytes.Buffer)
+	buf.Write(rec.AsRIS())
+	buf.Write(rec.AsRIS())
+	records, err := NewRISReader(buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("expected two records, got %d", len(records))
+	}
+	got := records[1]
+	m1, m2 := rec.Metadata, got.Metadata
+	if m2.Title != m1.Title || m2.PublicationDate != m1.PublicationDate || m2.Publisher != m1.Publisher {
+		t.Errorf("expected title, date and publisher to round trip ->\n%s", got.ToString())
+	}
+	// The RIS ID is not a record id.
+	if got.ID != "" {
+		t.Errorf("did not expect the RIS ID as the record id, got %q", got.ID)
+	}
+	if resourceTypeID(got) != resourceTypeID(rec) || recordDOI(got) != recordDOI(rec) {
+		t.Errorf("expected resource type and DOI to round trip ->\n%s", got.ToString())
+	}
+	if !sameList(m1.Creators, m2.Creators, func(a *Creator, b *Creator) bool {
+		return a.PersonOrOrg.FamilyName == b.PersonOrOrg.FamilyName && a.PersonOrOrg.GivenName == b.PersonOrOrg.GivenName
+	}) {
+		t.Errorf("expected creators to round trip ->\n%s", got.ToString())
+	}
+	if !sameStringMap(journalFields(rec), journalFields(got)) {
+		t.Errorf("expected journal fields to round trip, %v != %v", journalFields(rec), journalFields(got))
+	}
+	if !sameSet(m1.Identifiers, m2.Identifiers, (*Identifier).IsSame) || !sameSet(m1.Subjects, m2.Subjects, (*Subject).IsSame) {
+		t.Errorf("expected identifiers and subjects to round trip ->\n%s", got.ToString())
+	}
+}
@@ -0,0 +1,325 @@
+package simplified
+
+/**
+ * This file renders the simplified Record as schema.org JSON-LD. It is
+ * intended to be embedded in a landing page for search engines and
+ * Google Dataset Search. See https://schema.org/ and
+ * https://developers.google.com/search/docs/appearance/structured-data/dataset
+ */
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// SchemaOrgContext is the JSON-LD context of schema.org.
+const SchemaOrgContext = "https://schema.org"
+
+// schemaOrgTypes maps InvenioRDM resource type ids, or their prefix, to
+// schema.org types.
+var schemaOrgTypes = map[string]string{
+	"publication-article":  "ScholarlyArticle",
+	"publication-preprint": "ScholarlyArticle",
+	"publication-thesis":   "Thesis",
+	"publication-book":     "Book",
+	"publication-section":  "Chapter",
+	"publication-report":   "Report",
+	"publication":          "ScholarlyArticle",
+	"dataset":              "Dataset",
+	"software":             "SoftwareSourceCode",
+	"image":                "ImageObject",
+	"video":                "VideoObject",
+	"audio":                "AudioObject",
+	"presentation":         "PresentationDigitalDocument",
+	"poster":               "Poster",
+	"lesson":               "LearningResource",
+}
+
+type schemaOrgAgent struct {
+	Type        string            `json:"@type"`
+	ID          string            `json:"@id,omitempty"`
+	Name        string            `json:"name,omitempty"`
+	GivenName   string            `json:"givenName,omitempty"`
+	FamilyName  string            `json:"familyName,omitempty"`
+	Affiliation []*schemaOrgAgent `json:"affiliation,omitempty"`
+}
+
+type schemaOrgGrant struct {
+	Type       string          `json:"@type"`
+	Identifier string          `json:"identifier,omitempty"`
+	Name       string          `json:"name,omitempty"`
+	Funder     *schemaOrgAgent `json:"funder,omitempty"`
+}
+
+type schemaOrgMedia struct {
+	Type           string `json:"@type"`
+	Name           string `json:"name,omitempty"`
+	EncodingFormat string `json:"encodingFormat,omitempty"`
+	ContentURL     string `json:"contentUrl,omitempty"`
+	ContentSize    string `json:"contentSize,omitempty"`
+}
+
+type schemaOrgGeo struct {
+	Type      string  `json:"@type"`
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+type schemaOrgPlace struct {
+	Type        string        `json:"@type"`
+	Name        string        `json:"name,omitempty"`
+	Description string        `json:"description,omitempty"`
+	Geo         *schemaOrgGeo `json:"geo,omitempty"`
+}
+
+type schemaOrgPeriodical struct {
+	Type string `json:"@type"`
+	Name string `json:"name"`
+	ISSN string `json:"issn,omitempty"`
+}
+
+// schemaOrg is a schema.org CreativeWork, or one of its sub types.
+type schemaOrg struct {
+	Context             string               `json:"@context"`
+	Type                string               `json:"@type"`
+	ID                  string               `json:"@id,omitempty"`
+	Identifier          []string             `json:"identifier,omitempty"`
+	URL                 string               `json:"url,omitempty"`
+	Name                string               `json:"name"`
+	AlternateName       []string             `json:"alternateName,omitempty"`
+	Description         string               `json:"description,omitempty"`
+	Author              []*schemaOrgAgent    `json:"author,omitempty"`
+	Contributor         []*schemaOrgAgent    `json:"contributor,omitempty"`
+	Publisher           *schemaOrgAgent      `json:"publisher,omitempty"`
+	DatePublished       string               `json:"datePublished,omitempty"`
+	DateCreated         string               `json:"dateCreated,omitempty"`
+	DateModified        string               `json:"dateModified,omitempty"`
+	Version             string               `json:"version,omitempty"`
+	InLanguage          []string             `json:"inLanguage,omitempty"`
+	Keywords            []string             `json:"keywords,omitempty"`
+	License             []string             `json:"license,omitempty"`
+	Funder              []*schemaOrgAgent    `json:"funder,omitempty"`
+	Funding             []*schemaOrgGrant    `json:"funding,omitempty"`
+	IsPartOf            *schemaOrgPeriodical `json:"isPartOf,omitempty"`
+	Pagination          string               `json:"pagination,omitempty"`
+	InSupportOf         string               `json:"inSupportOf,omitempty"`
+	SourceOrganization  *schemaOrgAgent      `json:"sourceOrganization,omitempty"`
+	Distribution        []*schemaOrgMedia    `json:"distribution,omitempty"`
+	Encoding            []*schemaOrgMedia    `json:"encoding,omitempty"`
+	SpatialCoverage     []*schemaOrgPlace    `json:"spatialCoverage,omitempty"`
+	CreativeWorkStatus  string               `json:"creativeWorkStatus,omitempty"`
+	ConditionsOfAccess  string               `json:"conditionsOfAccess,omitempty"`
+	IsAccessibleForFree *bool                `json:"isAccessibleForFree,omitempty"`
+}
+
+// schemaOrgType returns the schema.org type for a resource type id.
+func schemaOrgType(id string) string {
+	if t, ok := schemaOrgTypes[id]; ok {
+		return t
+	}
+	if prefix, _, ok := strings.Cut(id, "-"); ok {
+		if t, ok := schemaOrgTypes[prefix]; ok {
+			return t
+		}
+	}
+	return "CreativeWork"
+}
+
+// schemaOrgOrganization returns an Organization node identified by a
+// ROR id when there is one.
+func schemaOrgOrganization(name string, ror string) *schemaOrgAgent {
+	org := &schemaOrgAgent{Type: "Organization", Name: name}
+	if ror != "" {
+		org.ID = dublinCoreIdentifier("ror", ror)
+	}
+	return org
+}
+
+// schemaOrgAgentOf converts a creator or contributor into a Person or
+// Organization node. Persons are identified by their ORCID,
+// organizations by their ROR id.
+func schemaOrgAgentOf(c *Creator) *schemaOrgAgent {
+	p := c.PersonOrOrg
+	if isOrganization(p) {
+		return schemaOrgOrganization(p.Name, personIdentifier(p, "ror"))
+	}
+	family, given := personNames(p)
+	agent := &schemaOrgAgent{Type: "Person", GivenName: given, FamilyName: family}
+	agent.Name = strings.TrimSpace(given + " " + family)
+	if orcid := personIdentifier(p, "orcid"); orcid != "" {
+		agent.ID = dublinCoreIdentifier("orcid", orcid)
+	}
+	for _, a := range c.Affiliations {
+		if a != nil && (a.Name != "" || a.ID != "") {
+			agent.Affiliation = append(agent.Affiliation, schemaOrgOrganization(a.Name, a.ID))
+		}
+	}
+	return agent
+}
+
+// asSchemaOrg maps the record to the schema.org structures.
+func (rec *Record) asSchemaOrg() *schemaOrg {
+	m := rec.Metadata
+	if m == nil {
+		m = new(Metadata)
+	}
+	doc := &schemaOrg{Context: SchemaOrgContext, Name: m.Title}
+	typeID := resourceTypeID(rec)
+	doc.Type = schemaOrgType(typeID)
+	if doi := recordDOI(rec); doi != "" {
+		doc.ID = dublinCoreIdentifier("doi", doi)
+		doc.Identifier = append(doc.Identifier, doc.ID)
+	}
+	for _, id := range m.Identifiers {
+		if id == nil || id.Identifier == "" || strings.EqualFold(id.Scheme, "doi") {
+			continue
+		}
+		if strings.EqualFold(id.Scheme, "url") {
+			if doc.URL == "" {
+				doc.URL = id.Identifier
+			}
+			continue
+		}
+		doc.Identifier = append(doc.Identifier, dublinCoreIdentifier(id.Scheme, id.Identifier))
+	}
+	for _, t := range m.AdditionalTitles {
+		if t != nil && t.Title != "" {
+			doc.AlternateName = append(doc.AlternateName, t.Title)
+		}
+	}
+	doc.Description = stripHTML(m.Description)
+	for _, c := range m.Creators {
+		if c != nil && c.PersonOrOrg != nil {
+			doc.Author = append(doc.Author, schemaOrgAgentOf(c))
+		}
+	}
+	for _, c := range m.Contributors {
+		if c != nil && c.PersonOrOrg != nil {
+			doc.Contributor = append(doc.Contributor, schemaOrgAgentOf(c))
+		}
+	}
+	if m.Publisher != "" {
+		doc.Publisher = schemaOrgOrganization(m.Publisher, "")
+	}
+	doc.DatePublished = m.PublicationDate
+	for _, d := range m.Dates {
+		if d != nil && d.Type != nil && d.Type.ID == "created" && doc.DateCreated == "" {
+			doc.DateCreated = d.Date
+		}
+	}
+	if doc.DateCreated == "" && !rec.Created.IsZero() {
+		doc.DateCreated = rec.Created.UTC().Format("2006-01-02")
+	}
+	if !rec.Updated.IsZero() {
+		doc.DateModified = rec.Updated.UTC().Format("2006-01-02")
+	}
+	doc.Version = m.Version
+	for _, lang := range m.Languages {
+		if id, ok := lang["id"].(string); ok && id != "" {
+			doc.InLanguage = append(doc.InLanguage, id)
+		}
+	}
+	doc.Keywords = subjectLabels(m.Subjects)
+	for _, r := range m.Rights {
+		if r != nil && r.Link != "" {
+			doc.License = append(doc.License, r.Link)
+		}
+	}
+	for _, f := range m.Funding {
+		if f == nil || f.Funder == nil || (f.Funder.Name == "" && f.Funder.Identifier == "") {
+			continue
+		}
+		ror := ""
+		if f.Funder.Scheme == "" || strings.EqualFold(f.Funder.Scheme, "ror") {
+			ror = f.Funder.Identifier
+		}
+		funder := schemaOrgOrganization(f.Funder.Name, ror)
+		doc.Funder = append(doc.Funder, funder)
+		if a := f.Award; a != nil && (a.Number != "" || a.Title != nil) {
+			grant := &schemaOrgGrant{Type: "MonetaryGrant", Identifier: a.Number, Funder: funder}
+			if a.Title != nil {
+				grant.Name = a.Title.Title
+			}
+			doc.Funding = append(doc.Funding, grant)
+		}
+	}
+	if journal := journalFields(rec); journal["title"] != "" {
+		doc.IsPartOf = &schemaOrgPeriodical{Type: "Periodical", Name: journal["title"], ISSN: recordISSN(rec)}
+		doc.Pagination = journal["pages"]
+	}
+	if doc.Type == "Thesis" {
+		thesis := thesisFields(rec)
+		doc.InSupportOf = thesis["type"]
+		if university := thesis["university"]; university != "" {
+			doc.SourceOrganization = schemaOrgOrganization(university, "")
+		}
+	}
+	if rec.Files != nil {
+		mediaType := "MediaObject"
+		if doc.Type == "Dataset" {
+			mediaType = "DataDownload"
+		}
+		for _, e := range sortedEntries(rec.Files.Entries) {
+			media := &schemaOrgMedia{Type: mediaType, Name: e.Key, EncodingFormat: e.MimeType, ContentURL: entryURL(e)}
+			if e.Size > 0 {
+				media.ContentSize = fmt.Sprintf("%d B", e.Size)
+			}
+			if doc.Type == "Dataset" {
+				doc.Distribution = append(doc.Distribution, media)
+			} else {
+				doc.Encoding = append(doc.Encoding, media)
+			}
+		}
+		if rec.Files.Locations != nil {
+			for _, feature := range rec.Files.Locations.Feature {
+				if feature == nil {
+					continue
+				}
+				place := &schemaOrgPlace{Type: "Place", Name: feature.Place, Description: feature.Description}
+				if g := feature.Geometry; g != nil && strings.EqualFold(g.Type, "Point") && len(g.Coordinates) >= 2 {
+					// GeoJSON positions are longitude then latitude.
+					place.Geo = &schemaOrgGeo{Type: "GeoCoordinates", Longitude: g.Coordinates[0], Latitude: g.Coordinates[1]}
+				}
+				if place.Name != "" || place.Description != "" || place.Geo != nil {
+					doc.SpatialCoverage = append(doc.SpatialCoverage, place)
+				}
+			}
+		}
+	}
+	if access := rec.RecordAccess; access != nil {
+		free := access.Files != "restricted"
+		if access.Embargo != nil && access.Embargo.Active {
+			free = false
+			doc.ConditionsOfAccess = fmt.Sprintf("embargoed until %s", access.Embargo.Until)
+		}
+		doc.IsAccessibleForFree = &free
+	}
+	if rec.Tombstone != nil {
+		doc.CreativeWorkStatus = "Withdrawn"
+	}
+	return doc
+}
+
+// AsSchemaOrg renders the record as schema.org JSON-LD suitable for a
+// landing page "script" element of type "application/ld+json". The
+// type (e.g. ScholarlyArticle, Dataset, SoftwareSourceCode or Thesis)
+// is chosen from the resource type. Creators become "author" Person or
+// Organization nodes with ORCID or ROR URLs as their "@id", rights
+// links become "license", funding becomes "funder" and "funding", file
+// entries become "distribution" (for datasets) or "encoding", and the
+// file locations become "spatialCoverage".
+//
+// ```
+//
+//	src, err := rec.AsSchemaOrg()
+//	// ... handle error ...
+//	fmt.Printf("<script type=\"application/ld+json\">\n%s\n</script>\n", src)
+//
+// ```
+func (rec *Record) AsSchemaOrg() ([]byte, error) {
+	if rec == nil {
+		return nil, fmt.Errorf("no record")
+	}
+	return json.MarshalIndent(rec.asSchemaOrg(), "", "    ")
+}
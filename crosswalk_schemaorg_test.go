@@ -0,0 +1,92 @@
+package simplified
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestAsSchemaOrg(t *testing.T) {
+	rec := testRecord(t)
+	src, err := rec.AsSchemaOrg()
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc := map[string]interface{}{}
+	if err := json.Unmarshal(src, &doc); err != nil {
+		t.Fatal(err)
+	}
+	for key, expected := range map[string]interface{}{
+		"@context":            "https://schema.org",
+		"@type":               "ScholarlyArticle",
+		"@id":                 "https://doi.org/10.1073/pnas.2302156120",
+		"name":                "Microbially induced precipitation of silica",
+		"description":         "An abstract.",
+		"datePublished":       "2023-12-19",
+		"isAccessibleForFree": false,
+		"creativeWorkStatus":  "Withdrawn",
+	} {
+		if doc[key] != expected {
+			t.Errorf("expected %s %v, got %v", key, expected, doc[key])
+		}
+	}
+	author := doc["author"].([]interface{})[0].(map[string]interface{})
+	if author["@type"] != "Person" || author["@id"] != "https://orcid.org/0000-0002-5374-6178" || author["name"] != "Victoria J. Orphan" {
+		t.Errorf("unexpected author %v", author)
+	}
+	affiliation := author["affiliation"].([]interface{})[0].(map[string]interface{})
+	if affiliation["@type"] != "Organization" || affiliation["@id"] != "https://ror.org/05dxps055" {
+		t.Errorf("unexpected affiliation %v", affiliation)
+	}
+	if l := doc["license"].([]interface{}); len(l) != 1 || l[0] != "https://creativecommons.org/licenses/by/4.0/" {
+		t.Errorf("unexpected license %v", l)
+	}
+	if funder := doc["funder"].([]interface{})[0].(map[string]interface{}); funder["@id"] != "https://ror.org/021nxhr62" {
+		t.Errorf("unexpected funder %v", funder)
+	}
+	if grant := doc["funding"].([]interface{})[0].(map[string]interface{}); grant["identifier"] != "OCE-1634002" {
+		t.Errorf("unexpected funding %v", grant)
+	}
+	if periodical := doc["isPartOf"].(map[string]interface{}); periodical["name"] != "PNAS" || periodical["issn"] != "1091-6490" {
+		t.Errorf("unexpected isPartOf %v", periodical)
+	}
+	if _, ok := doc["distribution"]; ok {
+		t.Errorf("expected encoding rather than distribution for an article")
+	}
+
+	// A dataset has a distribution and spatial coverage.
+	setResourceType(rec, "dataset")
+	rec.Files.Locations = &Location{Feature: []*Feature{{Place: "Pasadena", Geometry: &Geometry{Type: "Point", Coordinates: []float64{-118.125, 34.138}}}}}
+	src, err = rec.AsSchemaOrg()
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc = map[string]interface{}{}
+	if err := json.Unmarshal(src, &doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc["@type"] != "Dataset" {
+		t.Errorf("expected a Dataset, got %v", doc["@type"])
+	}
+	download, ok := doc["distribution"].([]interface{})
+	if !ok || len(download) != 1 || download[0].(map[string]interface{})["@type"] != "DataDownload" || download[0].(map[string]interface{})["encodingFormat"] != "application/pdf" {
+		t.Errorf("unexpected distribution %v", doc["distribution"])
+	}
+	place, ok := doc["spatialCoverage"].([]interface{})
+	if !ok || len(place) != 1 {
+		t.Fatalf("unexpected spatialCoverage %v", doc["spatialCoverage"])
+	}
+	geo := place[0].(map[string]interface{})["geo"].(map[string]interface{})
+	if geo["latitude"] != 34.138 || geo["longitude"] != -118.125 {
+		t.Errorf("unexpected geo %v", geo)
+	}
+	for id, expected := range map[string]string{
+		"publication-thesis": "Thesis",
+		"software":           "SoftwareSourceCode",
+		"publication-other":  "ScholarlyArticle",
+		"":                   "CreativeWork",
+	} {
+		if got := schemaOrgType(id); got != expected {
+			t.Errorf("expected %s for %q, got %s", expected, id, got)
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package simplified
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+)
+
+// testRecord reads testdata/record.json, a small record exercising most
+// of the Record structure. Each call returns a new copy to change.
+func testRecord(t *testing.T) *Record {
+	t.Helper()
+	src, err := os.ReadFile("testdata/record.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec := new(Record)
+	if err := json.Unmarshal(src, &rec); err != nil {
+		t.Fatal(err)
+	}
+	return rec
+}
+
+func TestStripHTML(t *testing.T) {
+	for _, test := range []struct{ src, expected string }{
+		{"<p>a</p><p>b</p>", "a\n\nb"},
+		{"<p>First  line<br/>second\n line</p>\n<ul><li>one</li><li>two</li></ul>", "First line\nsecond line\n\none\n\ntwo"},
+		{"<jats:p>A <jats:italic>JATS</jats:italic> paragraph.</jats:p><jats:p>Another.</jats:p>", "A JATS paragraph.\n\nAnother."},
+		{"Fish &amp; <b>chips</b>", "Fish & chips"},
+		{"  plain\ttext  ", "plain text"},
+		{"Plain text\n\nin two   paragraphs", "Plain text\n\nin two paragraphs"},
+	} {
+		if got := stripHTML(test.src); got != test.expected {
+			t.Errorf("%q, expected %q, got %q", test.src, test.expected, got)
+		}
+	}
+}
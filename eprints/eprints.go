@@ -0,0 +1,206 @@
+// Package eprints implements a crosswalk from EPrints 3.3 export XML
+// to the simplified Record.
+//
+// @author R. S. Doiel, <rsdoiel@caltech.edu>
+//
+// Copyright (c) 2023, Caltech
+// All rights not granted herein are expressly reserved by Caltech.
+//
+// Redistribution and use in source and binary forms, with or without modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice, this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice, this list of conditions and the following disclaimer in the documentation and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors may be used to endorse or promote products derived from this software without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS" AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE) ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE POSSIBILITY OF SUCH DAMAGE.
+package eprints
+
+/**
+ * This file holds the EPrints 3.3 XML data model. Only the fields used
+ * in the crosswalk to the simplified Record are modeled, others are
+ * ignored when parsing.
+ */
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+)
+
+// Namespace is the XML namespace of EPrints 3.3 export XML.
+const Namespace = "http://eprints.org/ep2/data/2.0"
+
+// EPrints is the root element of an EPrints XML export.
+type EPrints struct {
+	XMLName xml.Name  `xml:"eprints"`
+	Xmlns   string    `xml:"xmlns,attr,omitempty"`
+	EPrint  []*EPrint `xml:"eprint"`
+}
+
+// EPrint holds a single EPrint record.
+type EPrint struct {
+	XMLName            xml.Name    `xml:"eprint"`
+	ID                 string      `xml:"id,attr,omitempty"`
+	EPrintID           int         `xml:"eprintid,omitempty"`
+	RevNumber          int         `xml:"rev_number,omitempty"`
+	Documents          []*Document `xml:"documents>document,omitempty"`
+	EPrintStatus       string      `xml:"eprint_status,omitempty"`
+	UserID             int         `xml:"userid,omitempty"`
+	Dir                string      `xml:"dir,omitempty"`
+	Datestamp          string      `xml:"datestamp,omitempty"`
+	LastModified       string      `xml:"lastmod,omitempty"`
+	StatusChanged      string      `xml:"status_changed,omitempty"`
+	Type               string      `xml:"type,omitempty"`
+	MonographType      string      `xml:"monograph_type,omitempty"`
+	PresType           string      `xml:"pres_type,omitempty"`
+	MetadataVisibility string      `xml:"metadata_visibility,omitempty"`
+	Creators           []*Item     `xml:"creators>item,omitempty"`
+	Editors            []*Item     `xml:"editors>item,omitempty"`
+	Contributors       []*Item     `xml:"contributors>item,omitempty"`
+	CorpCreators       []*Item     `xml:"corp_creators>item,omitempty"`
+	ThesisAdvisor      []*Item     `xml:"thesis_advisor>item,omitempty"`
+	ThesisCommittee    []*Item     `xml:"thesis_committee>item,omitempty"`
+	Title              string      `xml:"title,omitempty"`
+	IsPublished        string      `xml:"ispublished,omitempty"`
+	FullTextStatus     string      `xml:"full_text_status,omitempty"`
+	Keywords           string      `xml:"keywords,omitempty"`
+	Note               string      `xml:"note,omitempty"`
+	Abstract           string      `xml:"abstract,omitempty"`
+	Date               string      `xml:"date,omitempty"`
+	DateType           string      `xml:"date_type,omitempty"`
+	Series             string      `xml:"series,omitempty"`
+	Publication        string      `xml:"publication,omitempty"`
+	Volume             string      `xml:"volume,omitempty"`
+	Number             string      `xml:"number,omitempty"`
+	Publisher          string      `xml:"publisher,omitempty"`
+	PlaceOfPub         string      `xml:"place_of_pub,omitempty"`
+	Edition            string      `xml:"edition,omitempty"`
+	PageRange          string      `xml:"pagerange,omitempty"`
+	IDNumber           string      `xml:"id_number,omitempty"`
+	Refereed           string      `xml:"refereed,omitempty"`
+	ISSN               string      `xml:"issn,omitempty"`
+	ISBN               string      `xml:"isbn,omitempty"`
+	BookTitle          string      `xml:"book_title,omitempty"`
+	OfficialURL        string      `xml:"official_url,omitempty"`
+	RelatedURL         []*Item     `xml:"related_url>item,omitempty"`
+	Funders            []*Item     `xml:"funders>item,omitempty"`
+	Rights             string      `xml:"rights,omitempty"`
+	DOI                string      `xml:"doi,omitempty"`
+	Subjects           []string    `xml:"subjects>item,omitempty"`
+	Language           string      `xml:"language,omitempty"`
+	Institution        string      `xml:"institution,omitempty"`
+	Department         string      `xml:"department,omitempty"`
+	ThesisType         string      `xml:"thesis_type,omitempty"`
+	ThesisDegree       string      `xml:"thesis_degree,omitempty"`
+	EventTitle         string      `xml:"event_title,omitempty"`
+	EventLocation      string      `xml:"event_location,omitempty"`
+	EventDates         string      `xml:"event_dates,omitempty"`
+	Collection         string      `xml:"collection,omitempty"`
+	LocalGroup         []string    `xml:"local_group>item,omitempty"`
+}
+
+// Name holds a personal name (family and given) or, for corporate
+// creators, the organization name as character data.
+type Name struct {
+	Family     string `xml:"family,omitempty"`
+	Given      string `xml:"given,omitempty"`
+	Honourific string `xml:"honourific,omitempty"`
+	Lineage    string `xml:"lineage,omitempty"`
+	Value      string `xml:",chardata"`
+}
+
+// Item is an element of a compound field, e.g. creators, related_url
+// or funders. Only the fields used by the compound field are set.
+type Item struct {
+	Name        *Name  `xml:"name,omitempty"`
+	ID          string `xml:"id,omitempty"`
+	ORCID       string `xml:"orcid,omitempty"`
+	ROR         string `xml:"ror,omitempty"`
+	Type        string `xml:"type,omitempty"`
+	Role        string `xml:"role,omitempty"`
+	URL         string `xml:"url,omitempty"`
+	Description string `xml:"description,omitempty"`
+	Agency      string `xml:"agency,omitempty"`
+	GrantNumber string `xml:"grant_number,omitempty"`
+}
+
+// Document describes a document attached to an EPrint.
+type Document struct {
+	ID          string  `xml:"id,attr,omitempty"`
+	DocID       int     `xml:"docid,omitempty"`
+	RevNumber   int     `xml:"rev_number,omitempty"`
+	Files       []*File `xml:"files>file,omitempty"`
+	EPrintID    int     `xml:"eprintid,omitempty"`
+	Pos         int     `xml:"pos,omitempty"`
+	Placement   int     `xml:"placement,omitempty"`
+	MimeType    string  `xml:"mime_type,omitempty"`
+	Format      string  `xml:"format,omitempty"`
+	FormatDesc  string  `xml:"formatdesc,omitempty"`
+	Language    string  `xml:"language,omitempty"`
+	Security    string  `xml:"security,omitempty"`
+	License     string  `xml:"license,omitempty"`
+	Main        string  `xml:"main,omitempty"`
+	DateEmbargo string  `xml:"date_embargo,omitempty"`
+	Content     string  `xml:"content,omitempty"`
+}
+
+// File describes a file of a document.
+type File struct {
+	ID        string `xml:"id,attr,omitempty"`
+	FileID    int    `xml:"fileid,omitempty"`
+	DatasetID string `xml:"datasetid,omitempty"`
+	ObjectID  int    `xml:"objectid,omitempty"`
+	Filename  string `xml:"filename,omitempty"`
+	MimeType  string `xml:"mime_type,omitempty"`
+	Hash      string `xml:"hash,omitempty"`
+	HashType  string `xml:"hash_type,omitempty"`
+	FileSize  int    `xml:"filesize,omitempty"`
+	MTime     string `xml:"mtime,omitempty"`
+	URL       string `xml:"url,omitempty"`
+}
+
+// Parse parses EPrints 3.3 export XML. The document may hold an
+// `<eprints>` element or a single `<eprint>` element.
+//
+// ```
+//
+//	src, err := os.ReadFile("12345.xml")
+//	// ... handle error ...
+//	doc, err := eprints.Parse(src)
+//	// ... handle error ...
+//	for _, eprint := range doc.EPrint {
+//	    fmt.Printf("%d %s\n", eprint.EPrintID, eprint.Title)
+//	}
+//
+// ```
+func Parse(src []byte) (*EPrints, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(src))
+	for {
+		tok, err := decoder.Token()
+		if err != nil {
+			return nil, fmt.Errorf("expected an eprints or eprint element, %s", err)
+		}
+		start, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		switch start.Name.Local {
+		case "eprints":
+			doc := new(EPrints)
+			if err := decoder.DecodeElement(doc, &start); err != nil {
+				return nil, err
+			}
+			return doc, nil
+		case "eprint":
+			eprint := new(EPrint)
+			if err := decoder.DecodeElement(eprint, &start); err != nil {
+				return nil, err
+			}
+			return &EPrints{EPrint: []*EPrint{eprint}}, nil
+		default:
+			return nil, fmt.Errorf("expected an eprints or eprint element, found %s", start.Name.Local)
+		}
+	}
+}
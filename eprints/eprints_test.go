@@ -0,0 +1,217 @@
+package eprints
+
+import (
+	"testing"
+)
+
+// eprintsTestXML is a small EPrints 3.3 export exercising the fields
+// used by the crosswalk.
+var eprintsTestXML = []byte(`<?xml version='1.0' encoding='utf-8'?>
+<eprints xmlns='http://eprints.org/ep2/data/2.0'>
+  <eprint id='https://authors.library.caltech.edu/id/eprint/12345'>
+    <eprintid>12345</eprintid>
+    <rev_number>7</rev_number>
+    <documents>
+      <document id='https://authors.library.caltech.edu/id/document/1'>
+        <docid>1</docid>
+        <files>
+          <file id='https://authors.library.caltech.edu/id/file/1'>
+            <fileid>1</fileid>
+            <datasetid>document</datasetid>
+            <filename>paper.pdf</filename>
+            <mime_type>application/pdf</mime_type>
+            <hash>abc123</hash>
+            <hash_type>MD5</hash_type>
+            <filesize>1024</filesize>
+            <url>https://authors.library.caltech.edu/12345/1/paper.pdf</url>
+          </file>
+        </files>
+        <eprintid>12345</eprintid>
+        <pos>1</pos>
+        <placement>1</placement>
+        <mime_type>application/pdf</mime_type>
+        <format>application/pdf</format>
+        <language>en</language>
+        <security>staffonly</security>
+        <license>cc_by</license>
+        <main>paper.pdf</main>
+        <date_embargo>2131-01-01</date_embargo>
+        <content>published</content>
+      </document>
+    </documents>
+    <eprint_status>archive</eprint_status>
+    <datestamp>2023-12-20 08:30:00</datestamp>
+    <lastmod>2023-12-21 10:15:00</lastmod>
+    <type>article</type>
+    <metadata_visibility>show</metadata_visibility>
+    <creators>
+      <item>
+        <name><family>Orphan</family><given>Victoria J.</given></name>
+        <id>Orphan-V-J</id>
+        <orcid>0000-0002-5374-6178</orcid>
+      </item>
+    </creators>
+    <corp_creators>
+      <item><name>Caltech Seismological Laboratory</name><ror>05dxps055</ror></item>
+    </corp_creators>
+    <editors>
+      <item><name><family>Doe</family><given>Jane</given></name></item>
+    </editors>
+    <contributors>
+      <item>
+        <type>http://www.loc.gov/loc.terms/relators/RTH</type>
+        <name><family>Roe</family><given>Richard</given></name>
+        <id>Roe-R</id>
+      </item>
+    </contributors>
+    <title>Microbially induced precipitation of silica</title>
+    <ispublished>pub</ispublished>
+    <keywords>geobiology; silica</keywords>
+    <note>A note.</note>
+    <abstract>An abstract.</abstract>
+    <date>2023-12-19</date>
+    <date_type>published</date_type>
+    <publication>PNAS</publication>
+    <volume>120</volume>
+    <number>51</number>
+    <publisher>National Academy of Sciences</publisher>
+    <pagerange>e2302156120</pagerange>
+    <issn>0027-8424</issn>
+    <official_url>https://resolver.caltech.edu/CaltechAUTHORS:20231220-123456</official_url>
+    <related_url>
+      <item><url>https://doi.org/10.1073/pnas.2302156120</url><type>doi</type><description>Article</description></item>
+      <item><url>https://example.edu/data</url><type>data</type></item>
+    </related_url>
+    <funders>
+      <item><agency>National Science Foundation</agency><grant_number>OCE-1634002</grant_number><ror>021nxhr62</ror></item>
+    </funders>
+    <doi>10.1073/pnas.2302156120</doi>
+    <subjects><item>geology</item></subjects>
+    <language>en</language>
+  </eprint>
+  <eprint>
+    <eprintid>2</eprintid>
+    <eprint_status>deletion</eprint_status>
+    <type>monograph</type>
+    <monograph_type>technical_report</monograph_type>
+    <title>Withdrawn report</title>
+    <date>2020</date>
+    <date_type>completed</date_type>
+  </eprint>
+</eprints>`)
+
+func TestRecordsFromXML(t *testing.T) {
+	records, err := RecordsFromXML(eprintsTestXML)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("expected 2 records, got %d", len(records))
+	}
+	rec := records[0]
+	m := rec.Metadata
+	if id, _ := m.ResourceType["id"].(string); id != "publication-article" {
+		t.Errorf("expected publication-article, got %q", id)
+	}
+	if m.Title != "Microbially induced precipitation of silica" || m.PublicationDate != "2023-12-19" || len(m.Dates) != 0 {
+		t.Errorf("unexpected title or dates %s", rec.ToString())
+	}
+	if rec.Created.Format(DateFormat) != "2023-12-20 08:30:00" || rec.Updated.Format(DateFormat) != "2023-12-21 10:15:00" {
+		t.Errorf("unexpected created %s or updated %s", rec.Created, rec.Updated)
+	}
+	if len(m.Creators) != 2 {
+		t.Fatalf("expected 2 creators, got %d", len(m.Creators))
+	}
+	p := m.Creators[0].PersonOrOrg
+	if p.FamilyName != "Orphan" || p.GivenName != "Victoria J." || len(p.Identifiers) != 2 || p.Identifiers[0].Scheme != "clpid" || p.Identifiers[1].Identifier != "0000-0002-5374-6178" {
+		t.Errorf("unexpected creator %+v", p)
+	}
+	if p := m.Creators[1].PersonOrOrg; p.Type != "organizational" || p.Name != "Caltech Seismological Laboratory" || p.Identifiers[0].Scheme != "ror" {
+		t.Errorf("unexpected corporate creator %+v", p)
+	}
+	if len(m.Contributors) != 2 || m.Contributors[0].Role.ID != "editor" || m.Contributors[1].Role.ID != "researcher" {
+		t.Errorf("expected an editor and a researcher, got %s", rec.ToString())
+	}
+	journal := rec.CustomFields["journal:journal"].(map[string]interface{})
+	for k, v := range map[string]string{"title": "PNAS", "volume": "120", "issue": "51", "pages": "e2302156120", "issn": "0027-8424"} {
+		if journal[k] != v {
+			t.Errorf("expected journal %s %q, got %v", k, v, journal[k])
+		}
+	}
+	if pid := rec.ExternalPIDs["doi"]; pid == nil || pid.Identifier != "10.1073/pnas.2302156120" {
+		t.Errorf("expected DOI, got %+v", rec.ExternalPIDs)
+	}
+	if len(m.Identifiers) != 2 || m.Identifiers[0].Scheme != "eprintid" || m.Identifiers[1].Scheme != "url" {
+		t.Errorf("expected eprintid and official_url identifiers, got %s", rec.ToString())
+	}
+	if len(m.RelatedIdentifiers) != 2 || m.RelatedIdentifiers[0].Scheme != "doi" || m.RelatedIdentifiers[1].RelationType.ID != "issupplementedby" {
+		t.Errorf("unexpected related identifiers %s", rec.ToString())
+	}
+	if len(m.Funding) != 1 || m.Funding[0].Funder.Identifier != "021nxhr62" || m.Funding[0].Award.Number != "OCE-1634002" {
+		t.Errorf("unexpected funding %s", rec.ToString())
+	}
+	if len(m.Subjects) != 3 || m.Subjects[1].Subject != "silica" || m.Subjects[2].ID != "geology" {
+		t.Errorf("unexpected subjects %s", rec.ToString())
+	}
+	access := rec.RecordAccess
+	if access.Record != "public" || access.Files != "restricted" || access.Status != "embargoed" || access.Embargo == nil || access.Embargo.Until != "2131-01-01" {
+		t.Errorf("unexpected access %+v", access)
+	}
+	entry, ok := rec.Files.Entries["paper.pdf"]
+	if !ok {
+		t.Fatalf("expected a paper.pdf entry, got %s", rec.ToString())
+	}
+	if entry.MimeType != "application/pdf" || entry.Size != 1024 || entry.CheckSum != "md5:abc123" || entry.Metadata["security"] != "staffonly" || entry.Metadata["content"] != "published" {
+		t.Errorf("unexpected entry %+v", entry)
+	}
+
+	rec = records[1]
+	if id, _ := rec.Metadata.ResourceType["id"].(string); id != "publication-technicalnote" {
+		t.Errorf("expected publication-technicalnote, got %q", id)
+	}
+	if rec.Tombstone == nil || rec.RecordAccess.Record != "restricted" {
+		t.Errorf("expected a restricted record with a tombstone, got %s", rec.ToString())
+	}
+	if len(rec.Metadata.Dates) != 1 || rec.Metadata.Dates[0].Type.ID != "created" {
+		t.Errorf("expected a created date, got %s", rec.ToString())
+	}
+}
+
+func TestParse(t *testing.T) {
+	doc, err := Parse([]byte(`<eprint><eprintid>3</eprintid><title>Single</title></eprint>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(doc.EPrint) != 1 || doc.EPrint[0].EPrintID != 3 {
+		t.Errorf("expected a single eprint, got %+v", doc)
+	}
+	if _, err := Parse([]byte(`<record/>`)); err == nil {
+		t.Errorf("expected an error for a non-EPrints document")
+	}
+	if _, err := RecordsFromXML([]byte(`<eprints><eprint><datestamp>yesterday</datestamp></eprint></eprints>`)); err == nil {
+		t.Errorf("expected an error for an invalid datestamp")
+	}
+}
+
+func TestAsEPrintsXMLRoundTrip(t *testing.T) {
+	records, err := RecordsFromXML(eprintsTestXML)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, rec1 := range records {
+		src, err := rec1.AsEPrintsXML()
+		if err != nil {
+			t.Fatal(err)
+		}
+		l, err := RecordsFromXML(src)
+		if err != nil {
+			t.Fatalf("%s\n%s", err, src)
+		}
+		if len(l) != 1 {
+			t.Fatalf("expected one record, got %d", len(l))
+		}
+		if rec2 := l[0]; !rec1.IsSame(rec2) {
+			t.Errorf("expected round trip to preserve the record\n%s\n%s", rec1.ToString(), rec2.ToString())
+		}
+	}
+}
@@ -0,0 +1,435 @@
+package eprints
+
+/**
+ * This file implements the conversion of an EPrint into a simplified
+ * Record.
+ */
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	// Caltech Library Packages
+	"github.com/caltechlibrary/simplified"
+)
+
+// DateFormat is the layout of EPrints datestamps, e.g. "2023-12-20 10:15:00"
+const DateFormat = "2006-01-02 15:04:05"
+
+// resourceTypes maps EPrints types to InvenioRDM resource type ids.
+var resourceTypes = map[string]string{
+	"article":           "publication-article",
+	"book":              "publication-book",
+	"book_section":      "publication-section",
+	"conference_item":   "publication-conferencepaper",
+	"monograph":         "publication-report",
+	"thesis":            "publication-thesis",
+	"patent":            "publication-patent",
+	"teaching_resource": "lesson",
+	"dataset":           "dataset",
+	"software":          "software",
+	"image":             "image",
+	"video":             "video",
+	"audio":             "audio",
+	"experiment":        "other",
+	"collection":        "other",
+	"other":             "other",
+}
+
+// monographTypes refine the "monograph" type.
+var monographTypes = map[string]string{
+	"technical_report": "publication-technicalnote",
+	"documentation":    "publication-technicalnote",
+	"manual":           "publication-technicalnote",
+	"working_paper":    "publication-workingpaper",
+	"discussion_paper": "publication-workingpaper",
+}
+
+// presTypes refine the "conference_item" type.
+var presTypes = map[string]string{
+	"poster":  "poster",
+	"speech":  "presentation",
+	"lecture": "presentation",
+	"keynote": "presentation",
+	"other":   "presentation",
+}
+
+// dateTypes maps EPrints date types, other than "published", to
+// InvenioRDM date type ids.
+var dateTypes = map[string]string{
+	"submitted": "submitted",
+	"inpress":   "accepted",
+	"completed": "created",
+	"degree":    "issued",
+}
+
+// relatorRoles maps Library of Congress relator codes used for
+// contributor types to InvenioRDM role ids.
+var relatorRoles = map[string]string{
+	"EDT": "editor",
+	"THS": "supervisor",
+	"RTH": "researcher",
+	"COL": "datacollector",
+	"CUR": "datacurator",
+	"PRO": "producer",
+	"SPN": "sponsor",
+	"DST": "distributor",
+	"CPH": "rightsholder",
+}
+
+// relatedURLTypes maps related_url types to relation type ids.
+var relatedURLTypes = map[string]string{
+	"doi":    "isversionof",
+	"pub":    "isversionof",
+	"arxiv":  "isversionof",
+	"pmc":    "isversionof",
+	"author": "isdescribedby",
+	"data":   "issupplementedby",
+	"supp":   "issupplementedby",
+}
+
+// resourceType returns the InvenioRDM resource type id of an EPrint.
+func (eprint *EPrint) resourceType() string {
+	switch eprint.Type {
+	case "monograph":
+		if id, ok := monographTypes[eprint.MonographType]; ok {
+			return id
+		}
+	case "conference_item":
+		if id, ok := presTypes[eprint.PresType]; ok {
+			return id
+		}
+	}
+	if id, ok := resourceTypes[eprint.Type]; ok {
+		return id
+	}
+	return "other"
+}
+
+// creator converts a creators, editors, contributors or thesis item.
+func (item *Item) creator(role string) *simplified.Creator {
+	p := &simplified.PersonOrOrg{Type: "personal"}
+	if item.Name != nil {
+		p.FamilyName = strings.TrimSpace(item.Name.Family)
+		p.GivenName = strings.TrimSpace(item.Name.Given)
+		if lineage := strings.TrimSpace(item.Name.Lineage); lineage != "" {
+			p.FamilyName += ", " + lineage
+		}
+	}
+	p.Name = p.FamilyName
+	if p.GivenName != "" {
+		p.Name = fmt.Sprintf("%s, %s", p.FamilyName, p.GivenName)
+	}
+	if item.ID != "" {
+		p.Identifiers = append(p.Identifiers, &simplified.Identifier{Scheme: "clpid", Identifier: strings.TrimSpace(item.ID)})
+	}
+	if item.ORCID != "" {
+		p.Identifiers = append(p.Identifiers, &simplified.Identifier{Scheme: "orcid", Identifier: strings.TrimSpace(item.ORCID)})
+	}
+	c := &simplified.Creator{PersonOrOrg: p}
+	if role != "" {
+		c.Role = &simplified.Role{ID: role}
+	}
+	return c
+}
+
+// corpCreator converts a corp_creators item.
+func (item *Item) corpCreator() *simplified.Creator {
+	p := &simplified.PersonOrOrg{Type: "organizational"}
+	if item.Name != nil {
+		p.Name = strings.TrimSpace(item.Name.Value)
+	}
+	if item.ID != "" {
+		p.Identifiers = append(p.Identifiers, &simplified.Identifier{Scheme: "clpid", Identifier: strings.TrimSpace(item.ID)})
+	}
+	if item.ROR != "" {
+		p.Identifiers = append(p.Identifiers, &simplified.Identifier{Scheme: "ror", Identifier: strings.TrimSpace(item.ROR)})
+	}
+	return &simplified.Creator{PersonOrOrg: p}
+}
+
+// contributorRole returns the role id for a contributors item type,
+// e.g. "http://www.loc.gov/loc.terms/relators/EDT".
+func contributorRole(itemType string) string {
+	code := strings.ToUpper(itemType[strings.LastIndex(itemType, "/")+1:])
+	if role, ok := relatorRoles[code]; ok {
+		return role
+	}
+	return "other"
+}
+
+// splitKeywords splits the keywords field on semicolons, or if there
+// are none, on commas.
+func splitKeywords(keywords string) []string {
+	sep := ","
+	if strings.Contains(keywords, ";") {
+		sep = ";"
+	}
+	l := []string{}
+	for _, keyword := range strings.Split(keywords, sep) {
+		if keyword = strings.TrimSpace(keyword); keyword != "" {
+			l = append(l, keyword)
+		}
+	}
+	return l
+}
+
+// parseDatestamp parses an EPrints datestamp. Empty values return the
+// zero time.
+func parseDatestamp(name string, s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(DateFormat, s)
+	if err != nil {
+		return t, fmt.Errorf("%s, %s", name, err)
+	}
+	return t, nil
+}
+
+// access maps eprint_status, metadata_visibility and document security
+// to a RecordAccess. The record is public when it is in the live archive
+// and shown, files are public when all documents are public.
+func (eprint *EPrint) access() *simplified.RecordAccess {
+	access := &simplified.RecordAccess{Record: "restricted", Files: "public"}
+	if eprint.EPrintStatus == "archive" && (eprint.MetadataVisibility == "" || eprint.MetadataVisibility == "show") {
+		access.Record = "public"
+	}
+	for _, doc := range eprint.Documents {
+		if doc.Security != "" && doc.Security != "public" {
+			access.Files = "restricted"
+		}
+		if doc.DateEmbargo != "" && (access.Embargo == nil || doc.DateEmbargo > access.Embargo.Until) {
+			access.Embargo = &simplified.Embargo{
+				Active: doc.DateEmbargo > time.Now().Format("2006-01-02"),
+				Until:  doc.DateEmbargo,
+			}
+		}
+	}
+	switch {
+	case access.Embargo != nil && access.Embargo.Active:
+		access.Status = "embargoed"
+	case access.Record == "public" && len(eprint.Documents) == 0:
+		access.Status = "metadata-only"
+	case access.Record == "public" && access.Files == "public":
+		access.Status = "open"
+	default:
+		access.Status = "restricted"
+	}
+	return access
+}
+
+// files maps the documents to Files.Entries keyed by filename. The
+// document's format, security, content, license and URL are kept in
+// the entry metadata.
+func (eprint *EPrint) files() *simplified.Files {
+	if len(eprint.Documents) == 0 {
+		return nil
+	}
+	files := &simplified.Files{Enabled: true, Entries: map[string]*simplified.Entry{}}
+	for _, doc := range eprint.Documents {
+		for _, f := range doc.Files {
+			if f.Filename == "" {
+				continue
+			}
+			key := f.Filename
+			if _, exists := files.Entries[key]; exists {
+				key = fmt.Sprintf("%d/%s", doc.Pos, f.Filename)
+			}
+			entry := &simplified.Entry{
+				Key:      key,
+				MimeType: f.MimeType,
+				Size:     f.FileSize,
+				Metadata: map[string]interface{}{},
+			}
+			if entry.MimeType == "" {
+				entry.MimeType = doc.MimeType
+			}
+			if f.Hash != "" {
+				entry.CheckSum = strings.ToLower(f.HashType) + ":" + f.Hash
+			}
+			for k, v := range map[string]string{
+				"format":       doc.Format,
+				"security":     doc.Security,
+				"content":      doc.Content,
+				"license":      doc.License,
+				"language":     doc.Language,
+				"date_embargo": doc.DateEmbargo,
+				"url":          f.URL,
+			} {
+				if v != "" {
+					entry.Metadata[k] = v
+				}
+			}
+			if doc.Pos > 0 {
+				entry.Metadata["pos"] = doc.Pos
+			}
+			files.Entries[key] = entry
+			files.Count++
+			files.TotalBytes += f.FileSize
+		}
+	}
+	return files
+}
+
+// ToRecord converts the EPrint into a simplified Record.
+//
+// ```
+//
+//	rec, err := eprint.ToRecord()
+//	// ... handle error ...
+//	fmt.Printf("%s\n", rec.ToString())
+//
+// ```
+func (eprint *EPrint) ToRecord() (*simplified.Record, error) {
+	if eprint == nil {
+		return nil, fmt.Errorf("no eprint")
+	}
+	var err error
+	rec := new(simplified.Record)
+	if rec.Created, err = parseDatestamp("datestamp", eprint.Datestamp); err != nil {
+		return nil, err
+	}
+	if rec.Updated, err = parseDatestamp("lastmod", eprint.LastModified); err != nil {
+		return nil, err
+	}
+	m := new(simplified.Metadata)
+	rec.Metadata = m
+	m.ResourceType = map[string]interface{}{"id": eprint.resourceType()}
+	m.Title = strings.TrimSpace(eprint.Title)
+	for _, item := range eprint.Creators {
+		m.Creators = append(m.Creators, item.creator(""))
+	}
+	for _, item := range eprint.CorpCreators {
+		m.Creators = append(m.Creators, item.corpCreator())
+	}
+	for _, item := range eprint.Editors {
+		m.Contributors = append(m.Contributors, item.creator("editor"))
+	}
+	for _, item := range eprint.ThesisAdvisor {
+		m.Contributors = append(m.Contributors, item.creator("supervisor"))
+	}
+	for _, item := range eprint.ThesisCommittee {
+		m.Contributors = append(m.Contributors, item.creator("committee"))
+	}
+	for _, item := range eprint.Contributors {
+		m.Contributors = append(m.Contributors, item.creator(contributorRole(item.Type)))
+	}
+	m.Description = strings.TrimSpace(eprint.Abstract)
+	if note := strings.TrimSpace(eprint.Note); note != "" {
+		m.AdditionalDescriptions = append(m.AdditionalDescriptions, &simplified.Description{
+			Description: note,
+			Type:        &simplified.Type{ID: "other"},
+		})
+	}
+	m.PublicationDate = strings.TrimSpace(eprint.Date)
+	if dateType, ok := dateTypes[eprint.DateType]; ok && m.PublicationDate != "" {
+		m.Dates = append(m.Dates, &simplified.DateType{Date: m.PublicationDate, Type: &simplified.Type{ID: dateType}})
+	}
+	m.Publisher = strings.TrimSpace(eprint.Publisher)
+	if m.Publisher == "" && eprint.Type == "thesis" {
+		m.Publisher = strings.TrimSpace(eprint.Institution)
+	}
+	m.Version = strings.TrimSpace(eprint.Edition)
+	if lang := strings.TrimSpace(eprint.Language); lang != "" {
+		m.Languages = []map[string]interface{}{{"id": lang}}
+	}
+	for _, keyword := range splitKeywords(eprint.Keywords) {
+		m.Subjects = append(m.Subjects, &simplified.Subject{Subject: keyword})
+	}
+	for _, subject := range eprint.Subjects {
+		if subject = strings.TrimSpace(subject); subject != "" {
+			m.Subjects = append(m.Subjects, &simplified.Subject{ID: subject})
+		}
+	}
+	addIdentifier := func(scheme string, value string) {
+		if value = strings.TrimSpace(value); value != "" {
+			m.Identifiers = append(m.Identifiers, &simplified.Identifier{Scheme: scheme, Identifier: value})
+		}
+	}
+	if eprint.EPrintID > 0 {
+		addIdentifier("eprintid", fmt.Sprintf("%d", eprint.EPrintID))
+	}
+	addIdentifier("url", eprint.OfficialURL)
+	addIdentifier("isbn", eprint.ISBN)
+	for _, item := range eprint.RelatedURL {
+		if item.URL == "" {
+			continue
+		}
+		id := &simplified.Identifier{Scheme: "url", Identifier: strings.TrimSpace(item.URL)}
+		if item.Type == "doi" {
+			id.Scheme = "doi"
+		}
+		relation, ok := relatedURLTypes[item.Type]
+		if !ok {
+			relation = "references"
+		}
+		id.RelationType = &simplified.TypeDetail{ID: relation}
+		m.RelatedIdentifiers = append(m.RelatedIdentifiers, id)
+	}
+	for _, item := range eprint.Funders {
+		if item.Agency == "" && item.ROR == "" {
+			continue
+		}
+		funder := &simplified.Funder{Funder: &simplified.FunderIdentifier{Name: strings.TrimSpace(item.Agency), Identifier: strings.TrimSpace(item.ROR)}}
+		if item.GrantNumber != "" {
+			funder.Award = &simplified.AwardIdentifier{Number: strings.TrimSpace(item.GrantNumber)}
+		}
+		m.Funding = append(m.Funding, funder)
+	}
+	if doi := strings.TrimSpace(eprint.DOI); doi != "" {
+		rec.ExternalPIDs = map[string]*simplified.PersistentIdentifier{
+			"doi": {Identifier: doi, Provider: "external"},
+		}
+	}
+	journal := map[string]interface{}{}
+	for k, v := range map[string]string{
+		"title":  eprint.Publication,
+		"volume": eprint.Volume,
+		"issue":  eprint.Number,
+		"pages":  eprint.PageRange,
+		"issn":   eprint.ISSN,
+	} {
+		if v = strings.TrimSpace(v); v != "" {
+			journal[k] = v
+		}
+	}
+	if len(journal) > 0 {
+		rec.CustomFields = map[string]interface{}{"journal:journal": journal}
+	}
+	rec.RecordAccess = eprint.access()
+	rec.Files = eprint.files()
+	if eprint.EPrintStatus == "deletion" {
+		rec.Tombstone = &simplified.Tombstone{Reason: "removed from EPrints", Timestamp: rec.Updated}
+	}
+	return rec, nil
+}
+
+// RecordsFromXML parses EPrints 3.3 export XML and converts each EPrint
+// into a simplified Record.
+//
+// ```
+//
+//	src, err := os.ReadFile("12345.xml")
+//	// ... handle error ...
+//	records, err := eprints.RecordsFromXML(src)
+//	// ... handle error ...
+//
+// ```
+func RecordsFromXML(src []byte) ([]*simplified.Record, error) {
+	doc, err := Parse(src)
+	if err != nil {
+		return nil, err
+	}
+	records := []*simplified.Record{}
+	for _, eprint := range doc.EPrint {
+		rec, err := eprint.ToRecord()
+		if err != nil {
+			return nil, fmt.Errorf("eprintid %d, %s", eprint.EPrintID, err)
+		}
+		records = append(records, rec)
+	}
+	return records, nil
+}
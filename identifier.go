@@ -0,0 +1,449 @@
+package simplified
+
+/**
+ * This file implements normalization and validation of persistent
+ * identifiers, e.g. DOI, ORCID, ISNI, ROR, ISBN, ISSN, arXiv, PubMed,
+ * Handle and URL. Identifiers arrive in many forms, e.g.
+ * "https://doi.org/10.1/ABC", "doi:10.1/abc" or "orcid.org/0000-...",
+ * they are reduced to the canonical form InvenioRDM stores and checked
+ * including their check digits.
+ */
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+var (
+	// ErrEmptyIdentifier is returned for an identifier without a value.
+	ErrEmptyIdentifier = errors.New("identifier is empty")
+	// ErrMalformedIdentifier is returned for an identifier which does
+	// not have the form required by its scheme.
+	ErrMalformedIdentifier = errors.New("identifier is malformed")
+	// ErrIdentifierChecksum is returned for an identifier whose check
+	// digit does not match.
+	ErrIdentifierChecksum = errors.New("identifier check digit does not match")
+)
+
+// IdentifierError is a problem with an identifier. Err is one of
+// ErrEmptyIdentifier, ErrMalformedIdentifier or ErrIdentifierChecksum
+// so the kind of problem can be tested with errors.Is.
+type IdentifierError struct {
+	// Path is a JSON pointer to the identifier in a record, empty if
+	// the identifier was checked on its own.
+	Path string
+	// Scheme is the identifier scheme, e.g. "orcid".
+	Scheme string
+	// Value is the identifier as found.
+	Value string
+	// Err is the problem found.
+	Err error
+}
+
+// Error describes the identifier and the problem.
+func (e *IdentifierError) Error() string {
+	s := fmt.Sprintf("%s %q, %s", e.Scheme, e.Value, e.Err)
+	if e.Path != "" {
+		return fmt.Sprintf("%s: %s", e.Path, s)
+	}
+	return s
+}
+
+// Unwrap returns the problem found.
+func (e *IdentifierError) Unwrap() error {
+	return e.Err
+}
+
+// IdentifierErrors holds the problems found with the identifiers of a
+// record.
+type IdentifierErrors []*IdentifierError
+
+// Error implements the error interface for IdentifierErrors.
+func (ie IdentifierErrors) Error() string {
+	msgs := make([]string, 0, len(ie))
+	for _, e := range ie {
+		msgs = append(msgs, e.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+var (
+	doiRE                = regexp.MustCompile(`^10\.\d+(\.\d+)*/\S+$`)
+	orcidRE              = regexp.MustCompile(`^\d{15}[\dX]$`)
+	rorRE                = regexp.MustCompile(`^0[0-9a-hjkmnp-tv-z]{6}\d{2}$`)
+	isbnRE               = regexp.MustCompile(`^(\d{9}[\dX]|\d{13})$`)
+	issnRE               = regexp.MustCompile(`^\d{7}[\dX]$`)
+	arxivRE              = regexp.MustCompile(`^(\d{4}\.\d{4,5}|[a-z][a-z\-]*(\.[A-Z]{2})?/\d{7})(v\d+)?$`)
+	pmidRE               = regexp.MustCompile(`^\d{1,9}$`)
+	pmcidRE              = regexp.MustCompile(`^PMC\d+$`)
+	handleRE             = regexp.MustCompile(`^[^/\s]+/\S+$`)
+	identifierSeparators = strings.NewReplacer("-", "", " ", "", "‐", "", "–", "")
+)
+
+// trimPrefixFold removes the first prefix found ignoring case.
+func trimPrefixFold(value string, prefixes ...string) string {
+	for _, prefix := range prefixes {
+		if len(value) >= len(prefix) && strings.EqualFold(value[:len(prefix)], prefix) {
+			return value[len(prefix):]
+		}
+	}
+	return value
+}
+
+// mod11_2 computes the ISO 7064 mod 11-2 check character of the digits
+// used by ORCID and ISNI.
+func mod11_2(digits string) byte {
+	total := 0
+	for _, c := range digits {
+		total = (total + int(c-'0')) * 2
+	}
+	check := (12 - total%11) % 11
+	if check == 10 {
+		return 'X'
+	}
+	return byte('0' + check)
+}
+
+// rorChecksum computes the ISO 7064 mod 97-10 check digits of the
+// Crockford base 32 part of a ROR id.
+func rorChecksum(id string) string {
+	const digits = "0123456789abcdefghjkmnpqrstvwxyz"
+	n := 0
+	for _, c := range id {
+		n = n*32 + strings.IndexRune(digits, c)
+	}
+	return fmt.Sprintf("%02d", 98-(n*100)%97)
+}
+
+// isbnValid checks the check digit of an ISBN-10 or ISBN-13.
+func isbnValid(isbn string) bool {
+	total := 0
+	if len(isbn) == 10 {
+		for i, c := range isbn {
+			d := int(c - '0')
+			if c == 'X' {
+				d = 10
+			}
+			total += (10 - i) * d
+		}
+		return total%11 == 0
+	}
+	for i, c := range isbn {
+		d := int(c - '0')
+		if i%2 == 1 {
+			d *= 3
+		}
+		total += d
+	}
+	return total%10 == 0
+}
+
+// issnValid checks the check digit of an ISSN.
+func issnValid(issn string) bool {
+	total := 0
+	for i, c := range issn[:7] {
+		total += (8 - i) * int(c-'0')
+	}
+	check := (11 - total%11) % 11
+	if check == 10 {
+		return issn[7] == 'X'
+	}
+	return int(issn[7]-'0') == check
+}
+
+// NormalizeIdentifier returns the canonical form of an identifier for
+// the schemes "doi", "orcid", "isni", "ror", "isbn", "issn", "arxiv",
+// "pmid", "pmcid", "handle" and "url". Resolver URLs and prefixes
+// (e.g. "https://doi.org/", "doi:" or "orcid.org/") are removed and
+// check digits verified. Values of other schemes are returned trimmed
+// of white space. A value which can't be normalized is returned with
+// an IdentifierError.
+//
+// ```
+//
+//	doi, err := simplified.NormalizeIdentifier("doi", "https://doi.org/10.1/ABC")
+//	// doi is "10.1/abc"
+//	orcid, err := simplified.NormalizeIdentifier("orcid", "orcid.org/0000000218250097")
+//	// orcid is "0000-0002-1825-0097"
+//	if errors.Is(err, simplified.ErrIdentifierChecksum) {
+//	    // ... handle a mistyped identifier ...
+//	}
+//
+// ```
+func NormalizeIdentifier(scheme string, value string) (string, error) {
+	scheme = strings.ToLower(strings.TrimSpace(scheme))
+	value = strings.TrimSpace(value)
+	fail := func(err error) (string, error) {
+		return value, &IdentifierError{Scheme: scheme, Value: value, Err: err}
+	}
+	if value == "" {
+		return fail(ErrEmptyIdentifier)
+	}
+	switch scheme {
+	case "doi":
+		s := trimPrefixFold(value, "https://doi.org/", "http://doi.org/", "https://dx.doi.org/", "http://dx.doi.org/", "doi.org/", "doi:")
+		s = strings.ToLower(strings.TrimSpace(s))
+		if !doiRE.MatchString(s) {
+			return fail(ErrMalformedIdentifier)
+		}
+		return s, nil
+	case "orcid", "isni":
+		s := trimPrefixFold(value, "https://orcid.org/", "http://orcid.org/", "orcid.org/", "orcid:",
+			"https://isni.org/isni/", "http://isni.org/isni/", "isni.org/isni/", "https://isni.org/", "isni:")
+		s = strings.ToUpper(identifierSeparators.Replace(s))
+		if !orcidRE.MatchString(s) {
+			return fail(ErrMalformedIdentifier)
+		}
+		if mod11_2(s[:15]) != s[15] {
+			return fail(ErrIdentifierChecksum)
+		}
+		if scheme == "isni" {
+			return s, nil
+		}
+		return s[0:4] + "-" + s[4:8] + "-" + s[8:12] + "-" + s[12:16], nil
+	case "ror":
+		s := trimPrefixFold(value, "https://ror.org/", "http://ror.org/", "ror.org/", "ror:")
+		s = strings.ToLower(s)
+		if !rorRE.MatchString(s) {
+			return fail(ErrMalformedIdentifier)
+		}
+		if rorChecksum(s[:7]) != s[7:] {
+			return fail(ErrIdentifierChecksum)
+		}
+		return s, nil
+	case "isbn":
+		s := trimPrefixFold(value, "isbn-13:", "isbn-10:", "isbn:", "isbn")
+		s = strings.ToUpper(identifierSeparators.Replace(s))
+		if !isbnRE.MatchString(s) {
+			return fail(ErrMalformedIdentifier)
+		}
+		if !isbnValid(s) {
+			return fail(ErrIdentifierChecksum)
+		}
+		return s, nil
+	case "issn":
+		s := trimPrefixFold(value, "https://portal.issn.org/resource/issn/", "issn:", "issn")
+		s = strings.ToUpper(identifierSeparators.Replace(s))
+		if !issnRE.MatchString(s) {
+			return fail(ErrMalformedIdentifier)
+		}
+		if !issnValid(s) {
+			return fail(ErrIdentifierChecksum)
+		}
+		return s[:4] + "-" + s[4:], nil
+	case "arxiv":
+		s := trimPrefixFold(value, "https://arxiv.org/abs/", "http://arxiv.org/abs/", "arxiv.org/abs/", "arxiv:")
+		if !arxivRE.MatchString(s) {
+			return fail(ErrMalformedIdentifier)
+		}
+		return "arXiv:" + s, nil
+	case "pmid":
+		s := trimPrefixFold(value, "https://pubmed.ncbi.nlm.nih.gov/", "http://pubmed.ncbi.nlm.nih.gov/", "pubmed.ncbi.nlm.nih.gov/", "pmid:")
+		s = strings.TrimSpace(strings.TrimSuffix(s, "/"))
+		if !pmidRE.MatchString(s) {
+			return fail(ErrMalformedIdentifier)
+		}
+		if s = strings.TrimLeft(s, "0"); s == "" {
+			return fail(ErrMalformedIdentifier)
+		}
+		return s, nil
+	case "pmcid":
+		s := trimPrefixFold(value, "https://www.ncbi.nlm.nih.gov/pmc/articles/", "http://www.ncbi.nlm.nih.gov/pmc/articles/",
+			"https://pmc.ncbi.nlm.nih.gov/articles/", "pmc.ncbi.nlm.nih.gov/articles/", "pmcid:")
+		s = strings.ToUpper(strings.TrimSpace(strings.TrimSuffix(s, "/")))
+		if !pmcidRE.MatchString(s) {
+			return fail(ErrMalformedIdentifier)
+		}
+		return s, nil
+	case "handle":
+		s := trimPrefixFold(value, "https://hdl.handle.net/", "http://hdl.handle.net/", "hdl.handle.net/", "info:hdl/", "hdl:")
+		if !handleRE.MatchString(s) {
+			return fail(ErrMalformedIdentifier)
+		}
+		return s, nil
+	case "url":
+		u, err := url.Parse(value)
+		if err != nil || u.Host == "" || strings.ContainsAny(value, " \t\n") {
+			return fail(ErrMalformedIdentifier)
+		}
+		u.Scheme, u.Host = strings.ToLower(u.Scheme), strings.ToLower(u.Host)
+		switch u.Scheme {
+		case "http", "https", "ftp":
+		default:
+			return fail(ErrMalformedIdentifier)
+		}
+		return u.String(), nil
+	}
+	return value, nil
+}
+
+// ValidateIdentifier checks an identifier, see NormalizeIdentifier for
+// the schemes checked. It returns nil or an IdentifierError.
+//
+// ```
+//
+//	if err := simplified.ValidateIdentifier("issn", "0317-8471"); err != nil {
+//	    fmt.Printf("%s\n", err)
+//	}
+//
+// ```
+func ValidateIdentifier(scheme string, value string) error {
+	_, err := NormalizeIdentifier(scheme, value)
+	return err
+}
+
+// value returns the identifier value, held in Identifier or for
+// funders and affiliations in ID.
+func (identifier *Identifier) value() string {
+	if identifier.Identifier != "" {
+		return identifier.Identifier
+	}
+	return identifier.ID
+}
+
+// Normalize replaces the identifier value with its canonical form
+// and lowercases the scheme, see NormalizeIdentifier. The value is left
+// unchanged if it is not valid and an IdentifierError returned.
+//
+// ```
+//
+//	identifier := &simplified.Identifier{Scheme: "DOI", Identifier: "doi:10.1/ABC"}
+//	if err := identifier.Normalize(); err != nil {
+//	    // ... handle error ...
+//	}
+//	// identifier.Scheme is "doi", identifier.Identifier is "10.1/abc"
+//
+// ```
+func (identifier *Identifier) Normalize() error {
+	identifier.Scheme = strings.ToLower(strings.TrimSpace(identifier.Scheme))
+	s, err := NormalizeIdentifier(identifier.Scheme, identifier.value())
+	if err != nil {
+		return err
+	}
+	if identifier.Identifier != "" {
+		identifier.Identifier = s
+	} else {
+		identifier.ID = s
+	}
+	return nil
+}
+
+// Validate checks the identifier value against its scheme. It returns
+// nil or an IdentifierError.
+func (identifier *Identifier) Validate() error {
+	return ValidateIdentifier(identifier.Scheme, identifier.value())
+}
+
+// Normalize replaces the funder identifier with its canonical form, see
+// Identifier.Normalize. A funder without an identifier is left as is.
+func (funder *FunderIdentifier) Normalize() error {
+	if funder.Identifier == "" {
+		return nil
+	}
+	funder.Scheme = strings.ToLower(strings.TrimSpace(funder.Scheme))
+	s, err := NormalizeIdentifier(funder.Scheme, funder.Identifier)
+	if err != nil {
+		return err
+	}
+	funder.Identifier = s
+	return nil
+}
+
+// Validate checks the funder identifier against its scheme. A funder
+// without an identifier is valid.
+func (funder *FunderIdentifier) Validate() error {
+	if funder.Identifier == "" {
+		return nil
+	}
+	return ValidateIdentifier(funder.Scheme, funder.Identifier)
+}
+
+// eachIdentifier calls fn with the JSON pointer of each identifier of
+// the record, creators, contributors, related identifiers and funders
+// included.
+func (rec *Record) eachIdentifier(fn func(path string, identifier *Identifier), funderFn func(path string, funder *FunderIdentifier)) {
+	if rec == nil || rec.Metadata == nil {
+		return
+	}
+	m := rec.Metadata
+	people := func(field string, creators []*Creator) {
+		for i, creator := range creators {
+			if creator == nil || creator.PersonOrOrg == nil {
+				continue
+			}
+			for j, identifier := range creator.PersonOrOrg.Identifiers {
+				if identifier != nil {
+					fn(jsonPointer("metadata", field, i, "person_or_org", "identifiers", j), identifier)
+				}
+			}
+		}
+	}
+	people("creators", m.Creators)
+	people("contributors", m.Contributors)
+	for i, identifier := range m.Identifiers {
+		if identifier != nil {
+			fn(jsonPointer("metadata", "identifiers", i), identifier)
+		}
+	}
+	for i, identifier := range m.RelatedIdentifiers {
+		if identifier != nil {
+			fn(jsonPointer("metadata", "related_identifiers", i), identifier)
+		}
+	}
+	for i, funding := range m.Funding {
+		if funding != nil && funding.Funder != nil {
+			funderFn(jsonPointer("metadata", "funding", i, "funder"), funding.Funder)
+		}
+	}
+}
+
+// identifierErrors collects the errors of the identifiers of a record
+// setting the path of each.
+func (rec *Record) identifierErrors(check func(*Identifier) error, checkFunder func(*FunderIdentifier) error) error {
+	var errs IdentifierErrors
+	add := func(path string, err error) {
+		var e *IdentifierError
+		if errors.As(err, &e) {
+			e.Path = path
+			errs = append(errs, e)
+		}
+	}
+	rec.eachIdentifier(func(path string, identifier *Identifier) {
+		add(path, check(identifier))
+	}, func(path string, funder *FunderIdentifier) {
+		add(path, checkFunder(funder))
+	})
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// NormalizeIdentifiers normalizes the identifiers of the record, i.e.
+// those of the creators and contributors, the record's identifiers,
+// related identifiers and funders. Identifiers which are not valid are
+// left unchanged and returned as IdentifierErrors.
+//
+// ```
+//
+//	if err := rec.NormalizeIdentifiers(); err != nil {
+//	    if errs, ok := err.(simplified.IdentifierErrors); ok {
+//	        for _, e := range errs {
+//	            fmt.Printf("%s %s\n", e.Path, e.Err)
+//	        }
+//	    }
+//	}
+//
+// ```
+func (rec *Record) NormalizeIdentifiers() error {
+	return rec.identifierErrors((*Identifier).Normalize, (*FunderIdentifier).Normalize)
+}
+
+// ValidateIdentifiers checks the identifiers of the record, see
+// NormalizeIdentifiers. It returns nil or IdentifierErrors.
+func (rec *Record) ValidateIdentifiers() error {
+	return rec.identifierErrors((*Identifier).Validate, (*FunderIdentifier).Validate)
+}
@@ -0,0 +1,200 @@
+package simplified
+
+/**
+ * This file implements detection of the scheme of an identifier from
+ * its value, e.g. for records migrated from EPrints where identifiers
+ * often have no scheme. A value can fit more than one scheme (e.g. a
+ * sixteen digit ORCID or ISNI) so each candidate scheme is given a
+ * confidence and ambiguous values are reported rather than guessed.
+ */
+
+import (
+	"regexp"
+	"sort"
+)
+
+// Confidence is how sure a scheme detection is.
+type Confidence string
+
+const (
+	// HighConfidence is given when the value has a prefix or resolver
+	// URL naming the scheme, e.g. "doi:" or "https://orcid.org/", or a
+	// form only the scheme uses, e.g. "10.1000/xyz" or "PMC123".
+	HighConfidence Confidence = "high"
+	// MediumConfidence is given when the value has the form of the
+	// scheme and a valid check digit where it has one, e.g. "0317-8471"
+	// for an ISSN.
+	MediumConfidence Confidence = "medium"
+	// LowConfidence is given when the value fits the scheme but is
+	// common to other uses, e.g. a number as a PubMed id.
+	LowConfidence Confidence = "low"
+)
+
+// rank orders confidences, higher is more confident.
+func (c Confidence) rank() int {
+	switch c {
+	case HighConfidence:
+		return 3
+	case MediumConfidence:
+		return 2
+	case LowConfidence:
+		return 1
+	}
+	return 0
+}
+
+// SchemeMatch is a scheme an identifier value fits.
+type SchemeMatch struct {
+	// Scheme is the identifier scheme, e.g. "doi".
+	Scheme string `json:"scheme"`
+	// Identifier is the value in the scheme's canonical form.
+	Identifier string `json:"identifier"`
+	// Confidence is how sure the match is.
+	Confidence Confidence `json:"confidence"`
+}
+
+// schemePattern is a form of identifier value and the confidence it
+// gives the scheme.
+type schemePattern struct {
+	scheme     string
+	pattern    *regexp.Regexp
+	confidence Confidence
+}
+
+// schemePatterns are checked in order, the first pattern to match gives
+// the confidence for a scheme.
+var schemePatterns = []*schemePattern{
+	{"doi", regexp.MustCompile(`(?i)^(doi:|(https?://)?(dx\.)?doi\.org/)`), HighConfidence},
+	{"doi", regexp.MustCompile(`^10\.\d+(\.\d+)*/\S+$`), HighConfidence},
+	{"orcid", regexp.MustCompile(`(?i)^(orcid:|(https?://)?orcid\.org/)`), HighConfidence},
+	{"orcid", regexp.MustCompile(`^\d{4}-\d{4}-\d{4}-\d{3}[\dXx]$`), MediumConfidence},
+	{"orcid", regexp.MustCompile(`^\d{15}[\dXx]$`), MediumConfidence},
+	{"orcid", regexp.MustCompile(`^\d{4} \d{4} \d{4} \d{3}[\dXx]$`), LowConfidence},
+	{"isni", regexp.MustCompile(`(?i)^(isni:|(https?://)?isni\.org/)`), HighConfidence},
+	{"isni", regexp.MustCompile(`^\d{4} \d{4} \d{4} \d{3}[\dXx]$`), MediumConfidence},
+	{"isni", regexp.MustCompile(`^\d{15}[\dXx]$`), MediumConfidence},
+	{"isni", regexp.MustCompile(`^\d{4}-\d{4}-\d{4}-\d{3}[\dXx]$`), LowConfidence},
+	{"ror", regexp.MustCompile(`(?i)^(ror:|(https?://)?ror\.org/)`), HighConfidence},
+	{"ror", regexp.MustCompile(`^0[0-9a-hjkmnp-tv-z]{6}\d{2}$`), MediumConfidence},
+	{"isbn", regexp.MustCompile(`(?i)^isbn`), HighConfidence},
+	{"isbn", regexp.MustCompile(`^97[89][\d\- ]+$`), MediumConfidence},
+	{"isbn", regexp.MustCompile(`^\d{1,5}-\d{1,7}-\d{1,7}-[\dXx]$`), MediumConfidence},
+	{"isbn", regexp.MustCompile(`^\d{9}[\dXx]$`), LowConfidence},
+	{"issn", regexp.MustCompile(`(?i)^(issn|https?://portal\.issn\.org/)`), HighConfidence},
+	{"issn", regexp.MustCompile(`^\d{4}-\d{3}[\dXx]$`), MediumConfidence},
+	{"issn", regexp.MustCompile(`^\d{7}[\dXx]$`), LowConfidence},
+	{"arxiv", regexp.MustCompile(`(?i)^(arxiv:|(https?://)?arxiv\.org/abs/)`), HighConfidence},
+	{"arxiv", regexp.MustCompile(`^\d{4}\.\d{4,5}(v\d+)?$`), MediumConfidence},
+	{"arxiv", regexp.MustCompile(`^[a-z][a-z\-]*(\.[A-Z]{2})?/\d{7}(v\d+)?$`), MediumConfidence},
+	{"pmid", regexp.MustCompile(`(?i)^(pmid:|(https?://)?pubmed\.ncbi\.nlm\.nih\.gov/)`), HighConfidence},
+	{"pmid", regexp.MustCompile(`^\d{1,8}$`), LowConfidence},
+	{"pmcid", regexp.MustCompile(`(?i)^(pmcid:|pmc\d|(https?://)?(www\.ncbi\.nlm\.nih\.gov/pmc|pmc\.ncbi\.nlm\.nih\.gov)/articles/)`), HighConfidence},
+	{"handle", regexp.MustCompile(`(?i)^(hdl:|info:hdl/|(https?://)?hdl\.handle\.net/)`), HighConfidence},
+	{"handle", regexp.MustCompile(`^\d+(\.\d+)*/\S+$`), MediumConfidence},
+	{"handle", regexp.MustCompile(`^[^/\s:]+/\S+$`), LowConfidence},
+	{"urn", regexp.MustCompile(`(?i)^urn:[a-z0-9][a-z0-9\-]{0,31}:\S+$`), HighConfidence},
+	{"url", regexp.MustCompile(`(?i)^(https?|ftp)://`), MediumConfidence},
+}
+
+// DetectIdentifierSchemes returns the schemes an identifier value fits,
+// most confident first. The schemes detected are "doi", "orcid",
+// "isni", "ror", "isbn", "issn", "arxiv", "pmid", "pmcid", "handle",
+// "urn" and "url". A scheme is only returned if the value is valid for
+// it, including its check digit (see NormalizeIdentifier).
+//
+// ```
+//
+//	matches := simplified.DetectIdentifierSchemes("https://doi.org/10.1000/XYZ")
+//	// matches[0].Scheme is "doi", matches[0].Identifier is "10.1000/xyz"
+//	// and matches[0].Confidence is simplified.HighConfidence
+//
+// ```
+func DetectIdentifierSchemes(value string) []*SchemeMatch {
+	matches, found := []*SchemeMatch{}, map[string]bool{}
+	for _, p := range schemePatterns {
+		if found[p.scheme] || !p.pattern.MatchString(value) {
+			continue
+		}
+		s, err := NormalizeIdentifier(p.scheme, value)
+		if err != nil {
+			continue
+		}
+		found[p.scheme] = true
+		matches = append(matches, &SchemeMatch{Scheme: p.scheme, Identifier: s, Confidence: p.confidence})
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].Confidence.rank() > matches[j].Confidence.rank()
+	})
+	return matches
+}
+
+// SchemeInference reports the scheme inferred for an identifier
+// without one.
+type SchemeInference struct {
+	// Path is a JSON pointer to the identifier in the record.
+	Path string `json:"path"`
+	// Value is the identifier value.
+	Value string `json:"value"`
+	// Scheme is the scheme set, empty if none was set.
+	Scheme string `json:"scheme,omitempty"`
+	// Confidence is the confidence of the best match.
+	Confidence Confidence `json:"confidence,omitempty"`
+	// Ambiguous is true if more than one scheme has the best confidence.
+	Ambiguous bool `json:"ambiguous,omitempty"`
+	// Candidates are the schemes the value fits, most confident first.
+	Candidates []*SchemeMatch `json:"candidates,omitempty"`
+}
+
+// inferScheme detects the scheme of value. The scheme is returned if a
+// single scheme has the best confidence and it is high or medium.
+func inferScheme(path string, value string) *SchemeInference {
+	inference := &SchemeInference{Path: path, Value: value, Candidates: DetectIdentifierSchemes(value)}
+	if len(inference.Candidates) == 0 {
+		return inference
+	}
+	best := inference.Candidates[0]
+	inference.Confidence = best.Confidence
+	inference.Ambiguous = len(inference.Candidates) > 1 && inference.Candidates[1].Confidence == best.Confidence
+	if !inference.Ambiguous && best.Confidence != LowConfidence {
+		inference.Scheme = best.Scheme
+	}
+	return inference
+}
+
+// InferIdentifierSchemes sets the scheme of the record's identifiers
+// which have none, i.e. those of the creators and contributors, the
+// record's identifiers, related identifiers and funders. A scheme is
+// set when the value fits a single scheme with high or medium
+// confidence, the value itself is left unchanged (see
+// NormalizeIdentifiers). An inference is returned for each identifier
+// without a scheme, including those left unset because they are
+// ambiguous, of low confidence or fit no scheme.
+//
+// ```
+//
+//	for _, inference := range rec.InferIdentifierSchemes() {
+//	    if inference.Scheme == "" {
+//	        fmt.Printf("%s %q, no scheme, ambiguous %t\n", inference.Path, inference.Value, inference.Ambiguous)
+//	    }
+//	}
+//
+// ```
+func (rec *Record) InferIdentifierSchemes() []*SchemeInference {
+	inferences := []*SchemeInference{}
+	rec.eachIdentifier(func(path string, identifier *Identifier) {
+		if identifier.Scheme != "" || identifier.value() == "" {
+			return
+		}
+		inference := inferScheme(path, identifier.value())
+		identifier.Scheme = inference.Scheme
+		inferences = append(inferences, inference)
+	}, func(path string, funder *FunderIdentifier) {
+		if funder.Scheme != "" || funder.Identifier == "" {
+			return
+		}
+		inference := inferScheme(path, funder.Identifier)
+		funder.Scheme = inference.Scheme
+		inferences = append(inferences, inference)
+	})
+	return inferences
+}
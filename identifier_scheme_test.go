@@ -0,0 +1,125 @@
+package simplified
+
+import (
+	"testing"
+)
+
+func TestDetectIdentifierSchemes(t *testing.T) {
+	tests := []struct {
+		value      string
+		scheme     string
+		identifier string
+		confidence Confidence
+		candidates int
+	}{
+		{"https://doi.org/10.1000/XYZ", "doi", "10.1000/xyz", HighConfidence, 2},
+		{"10.1000/xyz", "doi", "10.1000/xyz", HighConfidence, 2},
+		{"10.1/ABC", "doi", "10.1/abc", HighConfidence, 2},
+		{"https://orcid.org/0000-0002-1825-0097", "orcid", "0000-0002-1825-0097", HighConfidence, 2},
+		{"0000-0002-1825-0097", "orcid", "0000-0002-1825-0097", MediumConfidence, 2},
+		{"0000 0001 2103 2683", "isni", "0000000121032683", MediumConfidence, 2},
+		{"05dxps055", "ror", "05dxps055", MediumConfidence, 1},
+		{"978-0-306-40615-7", "isbn", "9780306406157", MediumConfidence, 1},
+		{"0-8044-2957-X", "isbn", "080442957X", MediumConfidence, 1},
+		{"0317-8471", "issn", "0317-8471", MediumConfidence, 1},
+		{"2101.00001v2", "arxiv", "arXiv:2101.00001v2", MediumConfidence, 1},
+		{"hep-th/9901001", "arxiv", "arXiv:hep-th/9901001", MediumConfidence, 2},
+		{"PMID: 31452104", "pmid", "31452104", HighConfidence, 1},
+		{"PMC6760142", "pmcid", "PMC6760142", HighConfidence, 1},
+		{"2027/mdp.39015078707397", "handle", "2027/mdp.39015078707397", MediumConfidence, 1},
+		{"urn:nbn:de:101:1-201502241989", "urn", "urn:nbn:de:101:1-201502241989", HighConfidence, 1},
+		{"https://example.edu/report.pdf", "url", "https://example.edu/report.pdf", MediumConfidence, 1},
+		{"12345", "pmid", "12345", LowConfidence, 1},
+	}
+	for _, test := range tests {
+		matches := DetectIdentifierSchemes(test.value)
+		if len(matches) != test.candidates {
+			t.Errorf("%q, expected %d candidates, got %d", test.value, test.candidates, len(matches))
+		}
+		if len(matches) == 0 {
+			continue
+		}
+		m := matches[0]
+		if m.Scheme != test.scheme || m.Identifier != test.identifier || m.Confidence != test.confidence {
+			t.Errorf("%q, expected %s %q (%s), got %s %q (%s)", test.value, test.scheme, test.identifier, test.confidence, m.Scheme, m.Identifier, m.Confidence)
+		}
+	}
+	// A check digit which does not match rules out the scheme.
+	for _, value := range []string{"0000-0002-1825-0098", "05dxps056", "not an identifier"} {
+		if matches := DetectIdentifierSchemes(value); len(matches) != 0 {
+			t.Errorf("%q, expected no candidates, got %s", value, matches[0].Scheme)
+		}
+	}
+}
+
+func TestInferIdentifierSchemes(t *testing.T) {
+	rec := &Record{
+		Metadata: &Metadata{
+			Creators: []*Creator{
+				{PersonOrOrg: &PersonOrOrg{Type: "personal", FamilyName: "Carberry", Identifiers: []*Identifier{
+					{Identifier: "https://orcid.org/0000-0002-1825-0097"},
+					{Scheme: "clpid", Identifier: "Carberry-J"},
+				}}},
+			},
+			Identifiers: []*Identifier{
+				{Identifier: "10.1000/xyz"},
+				{Identifier: "0000000218250097"},
+				{Identifier: "03178471"},
+				{Identifier: "eprints-123"},
+			},
+			RelatedIdentifiers: []*Identifier{
+				{Identifier: "978-0-306-40615-7", RelationType: &TypeDetail{ID: "ispartof"}},
+			},
+			Funding: []*Funder{
+				{Funder: &FunderIdentifier{Name: "National Science Foundation", Identifier: "021nxhr62"}},
+			},
+		},
+	}
+	inferences := rec.InferIdentifierSchemes()
+	if len(inferences) != 7 {
+		t.Fatalf("expected 7 inferences, got %d", len(inferences))
+	}
+	expected := []struct {
+		path      string
+		scheme    string
+		ambiguous bool
+	}{
+		{"/metadata/creators/0/person_or_org/identifiers/0", "orcid", false},
+		{"/metadata/identifiers/0", "doi", false},
+		// A sixteen digit ORCID or ISNI.
+		{"/metadata/identifiers/1", "", true},
+		// An ISSN or PubMed id, both of low confidence.
+		{"/metadata/identifiers/2", "", true},
+		{"/metadata/identifiers/3", "", false},
+		{"/metadata/related_identifiers/0", "isbn", false},
+		{"/metadata/funding/0/funder", "ror", false},
+	}
+	for i, e := range expected {
+		inference := inferences[i]
+		if inference.Path != e.path || inference.Scheme != e.scheme || inference.Ambiguous != e.ambiguous {
+			t.Errorf("expected %s %q ambiguous %t, got %s %q ambiguous %t", e.path, e.scheme, e.ambiguous, inference.Path, inference.Scheme, inference.Ambiguous)
+		}
+	}
+	m := rec.Metadata
+	for _, test := range []struct{ got, expected string }{
+		{m.Creators[0].PersonOrOrg.Identifiers[0].Scheme, "orcid"},
+		{m.Creators[0].PersonOrOrg.Identifiers[1].Scheme, "clpid"},
+		{m.Identifiers[0].Scheme, "doi"},
+		{m.Identifiers[1].Scheme, ""},
+		{m.Identifiers[3].Scheme, ""},
+		{m.RelatedIdentifiers[0].Scheme, "isbn"},
+		{m.RelatedIdentifiers[0].Identifier, "978-0-306-40615-7"},
+		{m.Funding[0].Funder.Scheme, "ror"},
+	} {
+		if test.got != test.expected {
+			t.Errorf("expected %q, got %q", test.expected, test.got)
+		}
+	}
+	if len(inferences[2].Candidates) != 2 || inferences[3].Confidence != LowConfidence {
+		t.Errorf("expected the candidates to be reported, got %+v", inferences[2])
+	}
+	// Inferring again finds nothing new to report for the schemes set.
+	if inferences := rec.InferIdentifierSchemes(); len(inferences) != 3 {
+		t.Errorf("expected 3 identifiers still without a scheme, got %d", len(inferences))
+	}
+}
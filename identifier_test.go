@@ -0,0 +1,168 @@
+package simplified
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNormalizeIdentifier(t *testing.T) {
+	valid := []struct {
+		scheme, value, expected string
+	}{
+		{"doi", "https://doi.org/10.1000/ABC.123", "10.1000/abc.123"},
+		{"DOI", " doi:10.1000/abc.123 ", "10.1000/abc.123"},
+		{"doi", "http://dx.doi.org/10.1000/abc.123", "10.1000/abc.123"},
+		{"orcid", "0000-0002-1825-0097", "0000-0002-1825-0097"},
+		{"orcid", "https://orcid.org/0000-0002-1694-233X", "0000-0002-1694-233X"},
+		{"orcid", "orcid.org/000000021694233x", "0000-0002-1694-233X"},
+		{"isni", "0000 0001 2103 2683", "0000000121032683"},
+		{"isni", "https://isni.org/isni/0000000121032683", "0000000121032683"},
+		{"ror", "https://ror.org/05dxps055", "05dxps055"},
+		{"ror", "01GGX4157", "01ggx4157"},
+		{"isbn", "978-0-306-40615-7", "9780306406157"},
+		{"isbn", "ISBN 0-8044-2957-x", "080442957X"},
+		{"issn", "0317-8471", "0317-8471"},
+		{"issn", "issn 1050124x", "1050-124X"},
+		{"arxiv", "arXiv:2101.00001v2", "arXiv:2101.00001v2"},
+		{"arxiv", "https://arxiv.org/abs/hep-th/9901001", "arXiv:hep-th/9901001"},
+		{"pmid", "https://pubmed.ncbi.nlm.nih.gov/31452104/", "31452104"},
+		{"pmcid", "pmc6760142", "PMC6760142"},
+		{"handle", "https://hdl.handle.net/2027/mdp.39015078707397", "2027/mdp.39015078707397"},
+		{"handle", "hdl:10013/epic.10033", "10013/epic.10033"},
+		{"url", "HTTPS://Example.EDU/Path?q=A", "https://example.edu/Path?q=A"},
+		{"eprintid", " 12345 ", "12345"},
+	}
+	for _, test := range valid {
+		got, err := NormalizeIdentifier(test.scheme, test.value)
+		if err != nil {
+			t.Errorf("%s %q, unexpected error %s", test.scheme, test.value, err)
+			continue
+		}
+		if got != test.expected {
+			t.Errorf("%s %q, expected %q, got %q", test.scheme, test.value, test.expected, got)
+		}
+	}
+
+	invalid := []struct {
+		scheme, value string
+		expected      error
+	}{
+		{"doi", "10.1000", ErrMalformedIdentifier},
+		{"doi", "", ErrEmptyIdentifier},
+		{"orcid", "0000-0002-1825-0098", ErrIdentifierChecksum},
+		{"orcid", "0000-0002-1825", ErrMalformedIdentifier},
+		{"isni", "0000000121032684", ErrIdentifierChecksum},
+		{"ror", "05dxps056", ErrIdentifierChecksum},
+		{"ror", "15dxps055", ErrMalformedIdentifier},
+		{"isbn", "978-0-306-40615-8", ErrIdentifierChecksum},
+		{"isbn", "0-306-40615-X1", ErrMalformedIdentifier},
+		{"issn", "0317-8472", ErrIdentifierChecksum},
+		{"arxiv", "2101.1", ErrMalformedIdentifier},
+		{"pmid", "PMC6760142", ErrMalformedIdentifier},
+		{"pmcid", "6760142", ErrMalformedIdentifier},
+		{"handle", "no-slash", ErrMalformedIdentifier},
+		{"url", "example.edu/path", ErrMalformedIdentifier},
+		{"url", "mailto:jane@example.edu", ErrMalformedIdentifier},
+	}
+	for _, test := range invalid {
+		got, err := NormalizeIdentifier(test.scheme, test.value)
+		if !errors.Is(err, test.expected) {
+			t.Errorf("%s %q, expected %q, got %v", test.scheme, test.value, test.expected, err)
+		}
+		var e *IdentifierError
+		if !errors.As(err, &e) || e.Scheme != test.scheme || got != test.value {
+			t.Errorf("%s %q, expected an IdentifierError and the value unchanged, got %q, %#v", test.scheme, test.value, got, err)
+		}
+	}
+}
+
+func TestIdentifierDocExamples(t *testing.T) {
+	doi, err := NormalizeIdentifier("doi", "https://doi.org/10.1/ABC")
+	if err != nil || doi != "10.1/abc" {
+		t.Errorf("expected \"10.1/abc\", got %q, %v", doi, err)
+	}
+	orcid, err := NormalizeIdentifier("orcid", "orcid.org/0000000218250097")
+	if err != nil || orcid != "0000-0002-1825-0097" {
+		t.Errorf("expected \"0000-0002-1825-0097\", got %q, %v", orcid, err)
+	}
+	if err := ValidateIdentifier("issn", "0317-8471"); err != nil {
+		t.Errorf("expected a valid ISSN, got %s", err)
+	}
+	identifier := &Identifier{Scheme: "DOI", Identifier: "doi:10.1/ABC"}
+	if err := identifier.Normalize(); err != nil {
+		t.Fatal(err)
+	}
+	if identifier.Scheme != "doi" || identifier.Identifier != "10.1/abc" {
+		t.Errorf("expected doi \"10.1/abc\", got %s %q", identifier.Scheme, identifier.Identifier)
+	}
+	// Registrant codes may be short or have subdivisions.
+	for _, value := range []string{"10.1/x", "10.1000.10/abc", "10.123456789012/x"} {
+		if err := ValidateIdentifier("doi", value); err != nil {
+			t.Errorf("expected %q to be a valid DOI, got %s", value, err)
+		}
+	}
+}
+
+func TestRecordNormalizeIdentifiers(t *testing.T) {
+	rec := &Record{
+		Metadata: &Metadata{
+			Title:           "Identifiers",
+			ResourceType:    map[string]interface{}{"id": "publication-article"},
+			PublicationDate: "2024",
+			Creators: []*Creator{
+				{PersonOrOrg: &PersonOrOrg{Type: "personal", FamilyName: "Carberry", Identifiers: []*Identifier{
+					{Scheme: "ORCID", Identifier: "https://orcid.org/0000-0002-1825-0097"},
+				}}},
+			},
+			Contributors: []*Creator{
+				{PersonOrOrg: &PersonOrOrg{Type: "personal", FamilyName: "Doe", Identifiers: []*Identifier{
+					{Scheme: "orcid", Identifier: "0000-0002-1825-0098"},
+				}}},
+			},
+			Identifiers: []*Identifier{
+				{Scheme: "doi", Identifier: "doi:10.1000/ABC"},
+				{Scheme: "eprintid", Identifier: "123"},
+			},
+			RelatedIdentifiers: []*Identifier{
+				{Scheme: "isbn", Identifier: "978-0-306-40615-7", RelationType: &TypeDetail{ID: "ispartof"}},
+			},
+			Funding: []*Funder{
+				{Funder: &FunderIdentifier{Name: "National Science Foundation", Scheme: "ror", Identifier: "https://ror.org/021nxhr62"}},
+				{Funder: &FunderIdentifier{Name: "Unknown"}},
+			},
+		},
+	}
+	if err := rec.Validate(); err == nil {
+		t.Errorf("expected the contributor ORCID to fail validation")
+	} else if violations, ok := err.(ValidationError); !ok || len(violations) != 1 || violations[0].Kind != InvalidIdentifier {
+		t.Errorf("expected a single invalid identifier violation, got %s", err)
+	}
+
+	err := rec.NormalizeIdentifiers()
+	errs, ok := err.(IdentifierErrors)
+	if !ok || len(errs) != 1 {
+		t.Fatalf("expected a single identifier error, got %v", err)
+	}
+	if errs[0].Path != "/metadata/contributors/0/person_or_org/identifiers/0" || !errors.Is(errs[0], ErrIdentifierChecksum) {
+		t.Errorf("unexpected identifier error, %s", errs[0])
+	}
+	m := rec.Metadata
+	for _, test := range []struct{ got, expected string }{
+		{m.Creators[0].PersonOrOrg.Identifiers[0].Scheme, "orcid"},
+		{m.Creators[0].PersonOrOrg.Identifiers[0].Identifier, "0000-0002-1825-0097"},
+		{m.Contributors[0].PersonOrOrg.Identifiers[0].Identifier, "0000-0002-1825-0098"},
+		{m.Identifiers[0].Identifier, "10.1000/abc"},
+		{m.Identifiers[1].Identifier, "123"},
+		{m.RelatedIdentifiers[0].Identifier, "9780306406157"},
+		{m.Funding[0].Funder.Identifier, "021nxhr62"},
+	} {
+		if test.got != test.expected {
+			t.Errorf("expected %q, got %q", test.expected, test.got)
+		}
+	}
+
+	m.Contributors = nil
+	if err := rec.ValidateIdentifiers(); err != nil {
+		t.Errorf("expected the identifiers to be valid, got %s", err)
+	}
+}
@@ -0,0 +1,304 @@
+package simplified
+
+/**
+ * This file implements streaming of records as JSON Lines (also known
+ * as NDJSON, one record per line) and as a JSON array. Records are read
+ * and written one at a time so large batches are processed with bounded
+ * memory.
+ *
+ * A record which can't be read is returned as a RecordError, the
+ * reader can continue with the next record.
+ */
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// RecordError is a problem with a single record in a batch. Reading or
+// writing can continue with the next record.
+type RecordError struct {
+	// Index is the position of the record in the batch, starting at 1.
+	Index int
+	// Line is the line the record started on, zero if not known.
+	Line int
+	// ID is the record id if known.
+	ID string
+	// Err is the problem found.
+	Err error
+}
+
+// Error describes the record and the problem.
+func (e *RecordError) Error() string {
+	s := fmt.Sprintf("record %d", e.Index)
+	if e.Line > 0 {
+		s += fmt.Sprintf(" (line %d)", e.Line)
+	}
+	if e.ID != "" {
+		s += fmt.Sprintf(" %q", e.ID)
+	}
+	return fmt.Sprintf("%s, %s", s, e.Err)
+}
+
+// Unwrap returns the problem found.
+func (e *RecordError) Unwrap() error {
+	return e.Err
+}
+
+// IsRecordError reports if err is about a single record, i.e. a batch
+// can continue with the next record.
+func IsRecordError(err error) bool {
+	var e *RecordError
+	return errors.As(err, &e)
+}
+
+// JSONLReader reads records one at a time from JSON Lines, one record
+// per line, or from a JSON array of records. Blank lines are skipped.
+type JSONLReader struct {
+	in      *bufio.Reader
+	dec     *json.Decoder
+	started bool
+	array   bool
+	done    bool
+	index   int
+	line    int
+}
+
+// NewJSONLReader creates a JSONLReader for r. If the first character of
+// the input is "[" the input is read as a JSON array.
+//
+// ```
+//
+//	reader := simplified.NewJSONLReader(os.Stdin)
+//	for {
+//	    rec, err := reader.Read()
+//	    if err == io.EOF {
+//	        break
+//	    }
+//	    if simplified.IsRecordError(err) {
+//	        fmt.Fprintf(os.Stderr, "%s\n", err)
+//	        continue
+//	    }
+//	    // ... handle error ...
+//	    fmt.Printf("%s\n", rec.Metadata.Title)
+//	}
+//
+// ```
+func NewJSONLReader(r io.Reader) *JSONLReader {
+	return &JSONLReader{in: bufio.NewReaderSize(r, 64*1024)}
+}
+
+// start skips a byte order mark and leading white space then decides
+// if the input is an array.
+func (r *JSONLReader) start() error {
+	r.started, r.line = true, 1
+	if bom, _ := r.in.Peek(3); bytes.Equal(bom, []byte("\xef\xbb\xbf")) {
+		r.in.Discard(3)
+	}
+	for {
+		c, err := r.in.ReadByte()
+		if err != nil {
+			return err
+		}
+		switch c {
+		case '\n':
+			r.line++
+		case ' ', '\t', '\r':
+		case '[':
+			if err := r.in.UnreadByte(); err != nil {
+				return err
+			}
+			r.array = true
+			r.dec = json.NewDecoder(r.in)
+			// Consume the opening bracket.
+			_, err := r.dec.Token()
+			return err
+		default:
+			return r.in.UnreadByte()
+		}
+	}
+}
+
+// record converts the JSON of a single record.
+func (r *JSONLReader) record(src []byte, line int) (*Record, error) {
+	rec := new(Record)
+	if err := json.Unmarshal(src, &rec); err != nil {
+		e := &RecordError{Index: r.index, Line: line, Err: err}
+		// Report the id when the JSON is valid but does not fit a record.
+		obj := struct {
+			ID string `json:"id"`
+		}{}
+		if json.Unmarshal(src, &obj) == nil {
+			e.ID = obj.ID
+		}
+		return nil, e
+	}
+	return rec, nil
+}
+
+// Read returns the next record. It returns io.EOF when there are no
+// more records and a RecordError for a record which could not be read.
+// Other errors, e.g. a JSON array which is not well formed, end the
+// input.
+func (r *JSONLReader) Read() (*Record, error) {
+	if r.done {
+		return nil, io.EOF
+	}
+	if !r.started {
+		if err := r.start(); err != nil {
+			r.done = true
+			return nil, err
+		}
+	}
+	if r.array {
+		if !r.dec.More() {
+			r.done = true
+			// Consume the closing bracket.
+			if _, err := r.dec.Token(); err != nil {
+				return nil, err
+			}
+			return nil, io.EOF
+		}
+		var src json.RawMessage
+		if err := r.dec.Decode(&src); err != nil {
+			r.done = true
+			return nil, err
+		}
+		r.index++
+		return r.record(src, 0)
+	}
+	for {
+		src, err := r.in.ReadBytes('\n')
+		line := r.line
+		r.line++
+		if len(bytes.TrimSpace(src)) > 0 {
+			r.index++
+			return r.record(src, line)
+		}
+		if err == io.EOF {
+			r.done = true
+			return nil, io.EOF
+		}
+		if err != nil {
+			r.done = true
+			return nil, err
+		}
+	}
+}
+
+// JSONLWriter writes records as JSON Lines, one record per line.
+type JSONLWriter struct {
+	out io.Writer
+}
+
+// NewJSONLWriter creates a JSONLWriter for w.
+//
+// ```
+//
+//	writer := simplified.NewJSONLWriter(os.Stdout)
+//	for _, rec := range records {
+//	    if err := writer.Write(rec); err != nil {
+//	        // ... handle error ...
+//	    }
+//	}
+//
+// ```
+func NewJSONLWriter(w io.Writer) *JSONLWriter {
+	return &JSONLWriter{out: w}
+}
+
+// EncodeRecord returns the record as a line of JSON.
+func (w *JSONLWriter) EncodeRecord(rec *Record) ([]byte, error) {
+	src, err := json.Marshal(rec)
+	if err != nil {
+		return nil, err
+	}
+	return append(src, '\n'), nil
+}
+
+// WriteEncoded writes a line returned by EncodeRecord.
+func (w *JSONLWriter) WriteEncoded(src []byte) error {
+	_, err := w.out.Write(src)
+	return err
+}
+
+// Write writes a record on a line of its own.
+func (w *JSONLWriter) Write(rec *Record) error {
+	src, err := w.EncodeRecord(rec)
+	if err != nil {
+		return err
+	}
+	return w.WriteEncoded(src)
+}
+
+// Close does nothing, it is present so JSONLWriter and JSONArrayWriter
+// can be used in the same way.
+func (w *JSONLWriter) Close() error {
+	return nil
+}
+
+// JSONArrayWriter writes records as an indented JSON array one record
+// at a time. Close must be called to end the array.
+type JSONArrayWriter struct {
+	out   io.Writer
+	count int
+}
+
+// NewJSONArrayWriter creates a JSONArrayWriter for w.
+//
+// ```
+//
+//	writer := simplified.NewJSONArrayWriter(os.Stdout)
+//	defer writer.Close()
+//	for _, rec := range records {
+//	    if err := writer.Write(rec); err != nil {
+//	        // ... handle error ...
+//	    }
+//	}
+//
+// ```
+func NewJSONArrayWriter(w io.Writer) *JSONArrayWriter {
+	return &JSONArrayWriter{out: w}
+}
+
+// EncodeRecord returns the record indented as an element of the array.
+func (w *JSONArrayWriter) EncodeRecord(rec *Record) ([]byte, error) {
+	return json.MarshalIndent(rec, "    ", "    ")
+}
+
+// WriteEncoded writes an element returned by EncodeRecord.
+func (w *JSONArrayWriter) WriteEncoded(src []byte) error {
+	prefix := ",\n    "
+	if w.count == 0 {
+		prefix = "[\n    "
+	}
+	if _, err := io.WriteString(w.out, prefix); err != nil {
+		return err
+	}
+	w.count++
+	_, err := w.out.Write(src)
+	return err
+}
+
+// Write writes a record as the next element of the array.
+func (w *JSONArrayWriter) Write(rec *Record) error {
+	src, err := w.EncodeRecord(rec)
+	if err != nil {
+		return err
+	}
+	return w.WriteEncoded(src)
+}
+
+// Close ends the array.
+func (w *JSONArrayWriter) Close() error {
+	if w.count == 0 {
+		_, err := io.WriteString(w.out, "[]\n")
+		return err
+	}
+	_, err := io.WriteString(w.out, "\n]\n")
+	return err
+}
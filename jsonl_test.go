@@ -0,0 +1,140 @@
+package simplified
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+)
+
+// readAll reads the records from a RecordReader, collecting the
+// record errors.
+func readAll(t *testing.T, reader RecordReader) ([]*Record, []error) {
+	t.Helper()
+	records, errs := []*Record{}, []error{}
+	for {
+		rec, err := reader.Read()
+		if err == io.EOF {
+			return records, errs
+		}
+		if err != nil {
+			if !IsRecordError(err) {
+				return records, append(errs, err)
+			}
+			errs = append(errs, err)
+			continue
+		}
+		records = append(records, rec)
+	}
+}
+
+func TestJSONLReader(t *testing.T) {
+	src := "\xef\xbb\xbf\n" + `{"id": "a", "metadata": {"title": "A"}}
+
+{"id": "b", "metadata": {"title": 2}}
+{"id": "c", "metadata":
+{"id": "d", "metadata": {"title": "D"}}`
+	records, errs := readAll(t, NewJSONLReader(strings.NewReader(src)))
+	if len(records) != 2 || records[0].ID != "a" || records[1].ID != "d" {
+		t.Errorf("expected records a and d, got %d", len(records))
+	}
+	if len(errs) != 2 {
+		t.Fatalf("expected two record errors, got %v", errs)
+	}
+	if e := errs[0].(*RecordError); e.Index != 2 || e.Line != 4 || e.ID != "b" {
+		t.Errorf("unexpected record error %+v", e)
+	}
+	if e := errs[1].(*RecordError); e.Index != 3 || e.Line != 5 || !strings.HasPrefix(e.Error(), "record 3 (line 5), ") {
+		t.Errorf("unexpected record error %q", e)
+	}
+
+	// A JSON array is read one element at a time.
+	src = ` [{"id": "a", "metadata": {"title": "A"}}, {"id": "b", "metadata": {"title": ["B"]}}, {"id": "c"}]`
+	records, errs = readAll(t, NewJSONLReader(strings.NewReader(src)))
+	if len(records) != 2 || records[1].ID != "c" || len(errs) != 1 || errs[0].(*RecordError).Index != 2 {
+		t.Errorf("unexpected records %d or errors %v", len(records), errs)
+	}
+	records, errs = readAll(t, NewJSONLReader(strings.NewReader(`[{"id": "a"}, {"id": `)))
+	if len(records) != 1 || len(errs) != 1 || IsRecordError(errs[0]) {
+		t.Errorf("expected a truncated array to end the input, got %v", errs)
+	}
+	records, errs = readAll(t, NewJSONLReader(strings.NewReader("  \n")))
+	if len(records) != 0 || len(errs) != 0 {
+		t.Errorf("expected no records from blank input")
+	}
+}
+
+func TestJSONLWriters(t *testing.T) {
+	records := []*Record{}
+	for _, title := range []string{"A", "B"} {
+		rec := new(Record)
+		ensureMetadata(rec).Title = title
+		records = append(records, rec)
+	}
+	buf := new(bytes.Buffer)
+	w := NewJSONLWriter(buf)
+	for _, rec := range records {
+		if err := w.Write(rec); err != nil {
+			t.Fatal(err)
+		}
+	}
+	w.Close()
+	if lines := strings.Split(strings.TrimSpace(buf.String()), "\n"); len(lines) != 2 {
+		t.Errorf("expected a line for each record\n%s", buf)
+	}
+	read, errs := readAll(t, NewJSONLReader(buf))
+	if len(read) != 2 || read[1].Metadata.Title != "B" || len(errs) != 0 {
+		t.Errorf("expected the JSON Lines to be read back, %v", errs)
+	}
+
+	buf.Reset()
+	a := NewJSONArrayWriter(buf)
+	for _, rec := range records {
+		if err := a.Write(rec); err != nil {
+			t.Fatal(err)
+		}
+	}
+	a.Close()
+	l := []*Record{}
+	if err := json.Unmarshal(buf.Bytes(), &l); err != nil || len(l) != 2 {
+		t.Errorf("expected a JSON array of two records, %v\n%s", err, buf)
+	}
+	buf.Reset()
+	NewJSONArrayWriter(buf).Close()
+	if buf.String() != "[]\n" {
+		t.Errorf("expected an empty array, got %q", buf)
+	}
+}
+
+func TestRecordReaderWriter(t *testing.T) {
+	cw, _ := LookupCrosswalk("ris")
+	records, errs := readAll(t, NewRecordReader(cw, strings.NewReader("TY  - JOUR\nTI  - A\nER  - \n\nTY  - BOOK\nTI  - B\nER  - \n")))
+	if len(records) != 2 || len(errs) != 0 {
+		t.Fatalf("expected two RIS records, %v", errs)
+	}
+	cw, _ = LookupCrosswalk("json")
+	records, _ = readAll(t, NewRecordReader(cw, strings.NewReader("\n  {\n    \"id\": \"a\"\n}\n")))
+	if len(records) != 1 || records[0].ID != "a" {
+		t.Errorf("expected a single pretty printed record")
+	}
+	cw, _ = LookupCrosswalk("jsonl")
+	buf := new(bytes.Buffer)
+	w := NewRecordWriter(cw, buf)
+	for _, rec := range []*Record{{ID: "a"}, {ID: "b"}} {
+		if err := w.Write(rec); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil || strings.Count(buf.String(), "\n") != 2 {
+		t.Errorf("expected two lines, %v\n%s", err, buf)
+	}
+	cw, _ = LookupCrosswalk("datacite-xml")
+	w = NewRecordWriter(cw, io.Discard)
+	if err := w.Write(&Record{ID: "a"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Write(&Record{ID: "b"}); err == nil {
+		t.Errorf("expected an error writing a second DataCite XML record")
+	}
+}
@@ -0,0 +1,392 @@
+package simplified
+
+/**
+ * This file implements a three-way merge of simplified records. It is
+ * used to reconcile a record edited in two places, e.g. EPrints and
+ * InvenioRDM, given the common version both edits started from.
+ */
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// MergeStrategy describes how conflicting changes are resolved by
+// MergeWithStrategy.
+type MergeStrategy string
+
+const (
+	// PreferOurs resolves conflicts using our version.
+	PreferOurs MergeStrategy = "prefer-ours"
+	// PreferTheirs resolves conflicts using their version.
+	PreferTheirs MergeStrategy = "prefer-theirs"
+	// UnionLists keeps the elements from both versions when lists
+	// conflict, e.g. an element modified on one side and removed on
+	// the other is kept. Other conflicts are resolved using our version.
+	UnionLists MergeStrategy = "union-lists"
+)
+
+// Conflict describes a field (or list element) changed differently
+// in our and their versions of a record. Path is a JSON pointer to the
+// field, for list elements Key identifies the element (see Changes).
+// Resolution is the version used in the merged record, "ours",
+// "theirs" or "union".
+type Conflict struct {
+	Path       string      `json:"path"`
+	Key        string      `json:"key,omitempty"`
+	Base       interface{} `json:"base,omitempty"`
+	Ours       interface{} `json:"ours,omitempty"`
+	Theirs     interface{} `json:"theirs,omitempty"`
+	Resolution string      `json:"resolution"`
+}
+
+// Error implements the error interface for a Conflict.
+func (c Conflict) Error() string {
+	if c.Key != "" {
+		return fmt.Sprintf("%s (%s) changed in both versions, using %s", c.Path, c.Key, c.Resolution)
+	}
+	return fmt.Sprintf("%s changed in both versions, using %s", c.Path, c.Resolution)
+}
+
+// merger holds the state of a three-way merge.
+type merger struct {
+	strategy  MergeStrategy
+	conflicts []Conflict
+}
+
+// resolve picks the version to use for a conflict and records it.
+func (mg *merger) resolve(path string, key string, base interface{}, ours interface{}, theirs interface{}, union bool) string {
+	resolution := "ours"
+	switch {
+	case mg.strategy == PreferTheirs:
+		resolution = "theirs"
+	case mg.strategy == UnionLists && union:
+		resolution = "union"
+	}
+	mg.conflicts = append(mg.conflicts, Conflict{
+		Path:       path,
+		Key:        key,
+		Base:       base,
+		Ours:       ours,
+		Theirs:     theirs,
+		Resolution: resolution,
+	})
+	return resolution
+}
+
+// mergeField merges a value treated as a whole. If both sides changed
+// it differently the conflict is resolved by the merge strategy.
+// Values are compared like IsSame, so changes to whitespace or from
+// nil to empty are not conflicts. When using UnionLists slices are
+// combined keeping ours followed by the elements only found in theirs.
+func mergeField[T any](mg *merger, path string, base T, ours T, theirs T) T {
+	switch {
+	case sameValue(ours, theirs), sameValue(base, theirs):
+		return ours
+	case sameValue(base, ours):
+		return theirs
+	}
+	oVal, tVal := reflect.ValueOf(ours), reflect.ValueOf(theirs)
+	isSlice := oVal.Kind() == reflect.Slice
+	switch mg.resolve(path, "", base, ours, theirs, isSlice) {
+	case "theirs":
+		return theirs
+	case "union":
+		union := oVal
+		for i := 0; i < tVal.Len(); i++ {
+			found := false
+			for j := 0; j < oVal.Len(); j++ {
+				if sameValue(tVal.Index(i).Interface(), oVal.Index(j).Interface()) {
+					found = true
+					break
+				}
+			}
+			if !found {
+				union = reflect.Append(union, tVal.Index(i))
+			}
+		}
+		return union.Interface().(T)
+	}
+	return ours
+}
+
+// mergeMap merges maps key by key. A missing key and a key with an
+// empty value are the same.
+func mergeMap[V any](mg *merger, path string, base map[string]V, ours map[string]V, theirs map[string]V) map[string]V {
+	if sameValue(ours, theirs) || sameValue(base, theirs) {
+		return ours
+	}
+	if sameValue(base, ours) {
+		return theirs
+	}
+	seen := map[string]bool{}
+	keys := []string{}
+	for _, m := range []map[string]V{base, ours, theirs} {
+		for k := range m {
+			if !seen[k] {
+				seen[k] = true
+				keys = append(keys, k)
+			}
+		}
+	}
+	sort.Strings(keys)
+	merged := map[string]V{}
+	for _, k := range keys {
+		b, inBase := base[k]
+		o, inOurs := ours[k]
+		t, inTheirs := theirs[k]
+		oursChanged := !sameValue(b, o)
+		theirsChanged := !sameValue(b, t)
+		keep, val := inOurs, o
+		switch {
+		case !theirsChanged, sameValue(o, t):
+			// use ours
+		case !oursChanged:
+			keep, val = inTheirs, t
+		default:
+			var bVal, oVal, tVal interface{}
+			if inBase {
+				bVal = b
+			}
+			if inOurs {
+				oVal = o
+			}
+			if inTheirs {
+				tVal = t
+			}
+			if mg.resolve(path+jsonPointer(k), "", bVal, oVal, tVal, false) == "theirs" {
+				keep, val = inTheirs, t
+			}
+		}
+		if keep {
+			merged[k] = val
+		}
+	}
+	if len(merged) == 0 {
+		return nil
+	}
+	return merged
+}
+
+// occurrenceKeys returns the key of each element, repeated keys are
+// numbered so every element has a unique key.
+func occurrenceKeys[T any](l []T, key func(T) string) []string {
+	seen := map[string]int{}
+	keys := make([]string, len(l))
+	for i, elem := range l {
+		k := key(elem)
+		seen[k]++
+		if seen[k] > 1 {
+			k = fmt.Sprintf("%s#%d", k, seen[k])
+		}
+		keys[i] = k
+	}
+	return keys
+}
+
+// mergeList merges a list matching elements by key. Our order is
+// kept, elements inserted in theirs are placed after the element
+// which precedes them in their version.
+func mergeList[T any](mg *merger, path string, base []T, ours []T, theirs []T, key func(T) string) []T {
+	if sameValue(ours, theirs) || sameValue(base, theirs) {
+		return ours
+	}
+	if sameValue(base, ours) {
+		return theirs
+	}
+	toMap := func(l []T) ([]string, map[string]T) {
+		keys := occurrenceKeys(l, key)
+		m := map[string]T{}
+		for i, k := range keys {
+			m[k] = l[i]
+		}
+		return keys, m
+	}
+	_, baseMap := toMap(base)
+	resultKeys, oursMap := toMap(ours)
+	theirKeys, theirsMap := toMap(theirs)
+	result := map[string]T{}
+	for k, v := range oursMap {
+		result[k] = v
+	}
+	indexOf := func(k string) int {
+		for i, rk := range resultKeys {
+			if rk == k {
+				return i
+			}
+		}
+		return -1
+	}
+	remove := func(k string) {
+		if i := indexOf(k); i >= 0 {
+			resultKeys = append(resultKeys[:i], resultKeys[i+1:]...)
+			delete(result, k)
+		}
+	}
+	insertAfter := func(prev string, k string, v T) {
+		i := indexOf(prev) + 1
+		resultKeys = append(resultKeys, "")
+		copy(resultKeys[i+1:], resultKeys[i:])
+		resultKeys[i] = k
+		result[k] = v
+	}
+	prev := ""
+	for _, k := range theirKeys {
+		b, inBase := baseMap[k]
+		o, inOurs := oursMap[k]
+		t := theirsMap[k]
+		switch {
+		case !inBase && !inOurs:
+			// inserted by them
+			insertAfter(prev, k, t)
+		case !inBase:
+			// inserted by both
+			if !sameValue(o, t) && mg.resolve(path, k, nil, o, t, false) == "theirs" {
+				result[k] = t
+			}
+		case !inOurs:
+			// removed by us
+			if !sameValue(b, t) && mg.resolve(path, k, b, nil, t, true) != "ours" {
+				insertAfter(prev, k, t)
+			}
+		case sameValue(b, t):
+			// unchanged by them
+		case sameValue(b, o):
+			result[k] = t
+		case !sameValue(o, t):
+			if mg.resolve(path, k, b, o, t, false) == "theirs" {
+				result[k] = t
+			}
+		}
+		if _, ok := result[k]; ok {
+			prev = k
+		}
+	}
+	// Elements removed by them
+	for _, k := range occurrenceKeys(base, key) {
+		if _, ok := theirsMap[k]; ok {
+			continue
+		}
+		o, inOurs := oursMap[k]
+		if !inOurs {
+			continue
+		}
+		b := baseMap[k]
+		if sameValue(b, o) || mg.resolve(path, k, b, o, nil, true) == "theirs" {
+			remove(k)
+		}
+	}
+	l := make([]T, 0, len(resultKeys))
+	for _, k := range resultKeys {
+		l = append(l, result[k])
+	}
+	if len(l) == 0 {
+		return nil
+	}
+	return l
+}
+
+func (mg *merger) mergeMetadata(base *Metadata, ours *Metadata, theirs *Metadata) *Metadata {
+	if ours == nil && theirs == nil {
+		return nil
+	}
+	if base == nil {
+		base = new(Metadata)
+	}
+	if ours == nil {
+		ours = new(Metadata)
+	}
+	if theirs == nil {
+		theirs = new(Metadata)
+	}
+	path := func(field string) string {
+		return jsonPointer("metadata", field)
+	}
+	m := new(Metadata)
+	m.ResourceType = mergeMap(mg, path("resource_type"), base.ResourceType, ours.ResourceType, theirs.ResourceType)
+	m.Creators = mergeList(mg, path("creators"), base.Creators, ours.Creators, theirs.Creators, creatorKey)
+	m.Title = mergeField(mg, path("title"), base.Title, ours.Title, theirs.Title)
+	m.PublicationDate = mergeField(mg, path("publication_date"), base.PublicationDate, ours.PublicationDate, theirs.PublicationDate)
+	m.AdditionalTitles = mergeField(mg, path("additional_titles"), base.AdditionalTitles, ours.AdditionalTitles, theirs.AdditionalTitles)
+	m.Description = mergeField(mg, path("description"), base.Description, ours.Description, theirs.Description)
+	m.AdditionalDescriptions = mergeField(mg, path("additional_descriptions"), base.AdditionalDescriptions, ours.AdditionalDescriptions, theirs.AdditionalDescriptions)
+	m.Rights = mergeList(mg, path("rights"), base.Rights, ours.Rights, theirs.Rights, rightKey)
+	m.Contributors = mergeList(mg, path("contributors"), base.Contributors, ours.Contributors, theirs.Contributors, creatorKey)
+	m.Subjects = mergeList(mg, path("subjects"), base.Subjects, ours.Subjects, theirs.Subjects, subjectKey)
+	m.Languages = mergeField(mg, path("languages"), base.Languages, ours.Languages, theirs.Languages)
+	m.Dates = mergeList(mg, path("dates"), base.Dates, ours.Dates, theirs.Dates, dateKey)
+	m.Version = mergeField(mg, path("version"), base.Version, ours.Version, theirs.Version)
+	m.Publisher = mergeField(mg, path("publisher"), base.Publisher, ours.Publisher, theirs.Publisher)
+	m.Identifiers = mergeList(mg, path("identifiers"), base.Identifiers, ours.Identifiers, theirs.Identifiers, identifierKey)
+	m.RelatedIdentifiers = mergeList(mg, path("related_identifiers"), base.RelatedIdentifiers, ours.RelatedIdentifiers, theirs.RelatedIdentifiers, identifierKey)
+	m.Funding = mergeList(mg, path("funding"), base.Funding, ours.Funding, theirs.Funding, funderKey)
+	return m
+}
+
+// Merge performs a three-way merge of two versions of a record, ours
+// and theirs, edited from a common version, base. Changes made on only
+// one side are applied. Changes made on both sides are returned as
+// conflicts and resolved using our version. See MergeWithStrategy.
+//
+// ```
+//
+//	merged, conflicts := simplified.Merge(base, ours, theirs)
+//	for _, conflict := range conflicts {
+//	    fmt.Printf("%s\n", conflict)
+//	}
+//
+// ```
+func Merge(base *Record, ours *Record, theirs *Record) (*Record, []Conflict) {
+	return MergeWithStrategy(base, ours, theirs, PreferOurs)
+}
+
+// MergeWithStrategy performs a three-way merge like Merge resolving
+// conflicts with the given strategy. Lists of creators, contributors,
+// subjects, identifiers, related identifiers, dates, funding and rights
+// are merged element by element using the same keys as Changes. Updated
+// is set to the later of the two versions. The merged record does not
+// share data with the records merged.
+func MergeWithStrategy(base *Record, ours *Record, theirs *Record, strategy MergeStrategy) (*Record, []Conflict) {
+	mg := &merger{strategy: strategy, conflicts: []Conflict{}}
+	if ours == nil && theirs == nil {
+		return nil, mg.conflicts
+	}
+	if base == nil {
+		base = new(Record)
+	}
+	if ours == nil {
+		ours = new(Record)
+	}
+	if theirs == nil {
+		theirs = new(Record)
+	}
+	rec := new(Record)
+	rec.Schema = mergeField(mg, jsonPointer("$schema"), base.Schema, ours.Schema, theirs.Schema)
+	rec.ID = mergeField(mg, jsonPointer("id"), base.ID, ours.ID, theirs.ID)
+	rec.Parent = mergeField(mg, jsonPointer("parent"), base.Parent, ours.Parent, theirs.Parent)
+	rec.ExternalPIDs = mergeMap(mg, jsonPointer("pids"), base.ExternalPIDs, ours.ExternalPIDs, theirs.ExternalPIDs)
+	rec.Metadata = mg.mergeMetadata(base.Metadata, ours.Metadata, theirs.Metadata)
+	rec.Files = mergeField(mg, jsonPointer("files"), base.Files, ours.Files, theirs.Files)
+	rec.RecordAccess = mergeField(mg, jsonPointer("access"), base.RecordAccess, ours.RecordAccess, theirs.RecordAccess)
+	rec.CustomFields = mergeMap(mg, jsonPointer("custom_fields"), base.CustomFields, ours.CustomFields, theirs.CustomFields)
+	rec.Tombstone = mergeField(mg, jsonPointer("tombstone"), base.Tombstone, ours.Tombstone, theirs.Tombstone)
+	rec.Created = mergeField(mg, jsonPointer("created"), base.Created, ours.Created, theirs.Created)
+	rec.Updated = ours.Updated
+	if theirs.Updated.After(ours.Updated) {
+		rec.Updated = theirs.Updated
+	}
+	rec.Versions = mergeField(mg, jsonPointer("versions"), base.Versions, ours.Versions, theirs.Versions)
+	rec.Links = mergeMap(mg, jsonPointer("links"), base.Links, ours.Links, theirs.Links)
+
+	// Copy the merged record so it doesn't share pointers with the
+	// records it was merged from.
+	if src, err := json.Marshal(rec); err == nil {
+		merged := new(Record)
+		if err := json.Unmarshal(src, &merged); err == nil {
+			rec = merged
+		}
+	}
+	return rec, mg.conflicts
+}
@@ -0,0 +1,138 @@
+package simplified
+
+import (
+	"testing"
+	"time"
+)
+
+// mergeTestRecords returns three copies of the test record
+// to use as base, ours and theirs.
+func mergeTestRecords(t *testing.T) (*Record, *Record, *Record) {
+	records := []*Record{}
+	for i := 0; i < 3; i++ {
+		rec := testRecord(t)
+		rec.Metadata.Creators = makeCreators(5)
+		records = append(records, rec)
+	}
+	return records[0], records[1], records[2]
+}
+
+func TestMerge(t *testing.T) {
+	base, ours, theirs := mergeTestRecords(t)
+
+	// Non-conflicting changes on both sides.
+	ours.Metadata.Title = "Our title"
+	ours.Metadata.Creators[1].Affiliations = nil
+	ours.CustomFields["caltech:groups"] = []interface{}{"GALCIT"}
+	ours.Updated = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	theirs.Metadata.Version = "v2"
+	theirs.Metadata.Creators[3].PersonOrOrg.GivenName = "B."
+	theirs.Metadata.Creators = append(theirs.Metadata.Creators, makeCreators(6)[5])
+	theirs.Metadata.Subjects = append(theirs.Metadata.Subjects, &Subject{Subject: "Geology"})
+	theirs.Updated = time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+	theirs.Links = map[string]interface{}{"self_html": "https://example.edu/records/rd9fg-k5282"}
+
+	merged, conflicts := Merge(base, ours, theirs)
+	if len(conflicts) != 0 {
+		t.Fatalf("expected no conflicts, got %+v", conflicts)
+	}
+	m := merged.Metadata
+	if m.Title != "Our title" || m.Version != "v2" {
+		t.Errorf("expected title and version from both sides, got %q, %q", m.Title, m.Version)
+	}
+	if len(m.Creators) != 6 {
+		t.Fatalf("expected 6 creators, got %d", len(m.Creators))
+	}
+	if m.Creators[1].Affiliations != nil || m.Creators[3].PersonOrOrg.GivenName != "B." {
+		t.Errorf("expected creator changes from both sides")
+	}
+	if len(m.Subjects) != 2 {
+		t.Errorf("expected two subjects, got %d", len(m.Subjects))
+	}
+	if _, ok := merged.CustomFields["caltech:groups"]; !ok {
+		t.Errorf("expected caltech:groups custom field")
+	}
+	if !merged.Updated.Equal(theirs.Updated) {
+		t.Errorf("expected the later updated time, got %s", merged.Updated)
+	}
+	if merged.Links["self_html"] != theirs.Links["self_html"] {
+		t.Errorf("expected the links from theirs, got %v", merged.Links)
+	}
+	// The merged record doesn't share data with ours
+	merged.Metadata.Creators[0].PersonOrOrg.FamilyName = "Changed"
+	if ours.Metadata.Creators[0].PersonOrOrg.FamilyName == "Changed" {
+		t.Errorf("expected merged record to be a copy")
+	}
+}
+
+func TestMergeConflicts(t *testing.T) {
+	for _, test := range []struct {
+		strategy MergeStrategy
+		title    string
+		creators int
+		family   string
+	}{
+		{PreferOurs, "Our title", 4, "Ours"},
+		{PreferTheirs, "Their title", 5, "Theirs"},
+		{UnionLists, "Our title", 5, "Ours"},
+	} {
+		base, ours, theirs := mergeTestRecords(t)
+		ours.Metadata.Title = "Our title"
+		theirs.Metadata.Title = "Their title"
+		// Both modify the same creator.
+		ours.Metadata.Creators[0].PersonOrOrg.FamilyName = "Ours"
+		theirs.Metadata.Creators[0].PersonOrOrg.FamilyName = "Theirs"
+		// We remove a creator they modified.
+		ours.Metadata.Creators = append(ours.Metadata.Creators[:2], ours.Metadata.Creators[3:]...)
+		theirs.Metadata.Creators[2].Affiliations = nil
+
+		merged, conflicts := MergeWithStrategy(base, ours, theirs, test.strategy)
+		if len(conflicts) != 3 {
+			t.Errorf("%s: expected 3 conflicts, got %d -> %+v", test.strategy, len(conflicts), conflicts)
+		}
+		if merged.Metadata.Title != test.title {
+			t.Errorf("%s: expected title %q, got %q", test.strategy, test.title, merged.Metadata.Title)
+		}
+		if len(merged.Metadata.Creators) != test.creators {
+			t.Errorf("%s: expected %d creators, got %d", test.strategy, test.creators, len(merged.Metadata.Creators))
+		}
+		if family := merged.Metadata.Creators[0].PersonOrOrg.FamilyName; family != test.family {
+			t.Errorf("%s: expected first creator %q, got %q", test.strategy, test.family, family)
+		}
+		for _, c := range conflicts {
+			if c.Path == "/metadata/creators" && c.Key == "" {
+				t.Errorf("%s: expected creator conflicts to have a key", test.strategy)
+			}
+		}
+	}
+}
+
+func TestMergeSame(t *testing.T) {
+	base, ours, theirs := mergeTestRecords(t)
+	// Both sides make the same change, differing only in whitespace,
+	// case or nil versus empty values.
+	ours.Metadata.Title = "A new title"
+	theirs.Metadata.Title = " A  new title\n"
+	ours.Metadata.Creators[1].Affiliations = nil
+	theirs.Metadata.Creators[1].Affiliations = []*Affiliation{}
+	ours.Metadata.Creators[2].PersonOrOrg.FamilyName = "Smith"
+	theirs.Metadata.Creators[2].PersonOrOrg.FamilyName = "Smith "
+	ours.Metadata.Identifiers = addIdentifier(ours.Metadata.Identifiers, "orcid", "0000-0002-1694-233X")
+	theirs.Metadata.Identifiers = addIdentifier(theirs.Metadata.Identifiers, "orcid", "0000-0002-1694-233x")
+	for _, rec := range []*Record{base, ours, theirs} {
+		rec.CustomFields["caltech:groups"] = []interface{}{"GALCIT"}
+	}
+	ours.CustomFields["caltech:groups"] = []interface{}{}
+	delete(theirs.CustomFields, "caltech:groups")
+
+	merged, conflicts := Merge(base, ours, theirs)
+	if len(conflicts) != 0 {
+		t.Fatalf("expected no conflicts, got %+v", conflicts)
+	}
+	if merged.Metadata.Title != ours.Metadata.Title {
+		t.Errorf("expected our title, got %q", merged.Metadata.Title)
+	}
+	if merged.Metadata.Creators[2].PersonOrOrg.FamilyName != "Smith" {
+		t.Errorf("expected our family name, got %q", merged.Metadata.Creators[2].PersonOrOrg.FamilyName)
+	}
+}
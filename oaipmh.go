@@ -0,0 +1,654 @@
+package simplified
+
+/**
+ * This file implements an OAI-PMH 2.0 data provider over a list of
+ * records held in memory. See
+ * https://www.openarchives.org/OAI/openarchivesprotocol.html
+ *
+ * Records are identified by their "oai" external PID or, if there is
+ * none, "oai:REPOSITORY_IDENTIFIER:RECORD_ID". The datestamp is the
+ * record's Updated time. Sets are the community ids found in
+ * Parent.Communities. Records with a Tombstone are reported as deleted.
+ * Resumption tokens are stateless, they encode the list request and the
+ * cursor.
+ */
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"time"
+)
+
+const (
+	// OAIPMHNamespace is the XML namespace of OAI-PMH 2.0 responses.
+	OAIPMHNamespace = "http://www.openarchives.org/OAI/2.0/"
+	// OAIPMHSchemaLocation is the location of the OAI-PMH 2.0 XML schema.
+	OAIPMHSchemaLocation = "http://www.openarchives.org/OAI/2.0/ http://www.openarchives.org/OAI/2.0/OAI-PMH.xsd"
+
+	// oaiDatestamp is the datestamp format for seconds granularity.
+	oaiDatestamp = "2006-01-02T15:04:05Z"
+	// oaiDay is the datestamp format for day granularity.
+	oaiDay = "2006-01-02"
+)
+
+// OAIMetadataFormat describes a metadata format the provider can
+// disseminate.
+type OAIMetadataFormat struct {
+	Prefix    string
+	Schema    string
+	Namespace string
+	// Encode renders a record as an XML document in the format.
+	Encode func(*Record) ([]byte, error)
+}
+
+// OAIMetadataFormats are the default formats of an OAIProvider.
+var OAIMetadataFormats = []*OAIMetadataFormat{
+	{
+		Prefix:    "oai_dc",
+		Schema:    "http://www.openarchives.org/OAI/2.0/oai_dc.xsd",
+		Namespace: OAIDCNamespace,
+		Encode:    (*Record).AsDublinCore,
+	},
+	{
+		Prefix:    "oai_datacite",
+		Schema:    "http://schema.datacite.org/meta/kernel-4.5/metadata.xsd",
+		Namespace: DataCiteNamespace,
+		Encode:    (*Record).AsDataCiteXML,
+	},
+}
+
+// oaiArguments lists the required and optional arguments of each verb.
+var oaiArguments = map[string][2][]string{
+	"Identify":            {nil, nil},
+	"ListMetadataFormats": {nil, {"identifier"}},
+	"ListSets":            {nil, {"resumptionToken"}},
+	"ListIdentifiers":     {{"metadataPrefix"}, {"from", "until", "set", "resumptionToken"}},
+	"ListRecords":         {{"metadataPrefix"}, {"from", "until", "set", "resumptionToken"}},
+	"GetRecord":           {{"identifier", "metadataPrefix"}, nil},
+}
+
+// OAIProvider is an OAI-PMH 2.0 data provider. It implements
+// http.Handler.
+type OAIProvider struct {
+	// RepositoryName is reported by Identify.
+	RepositoryName string
+	// BaseURL is the URL of the provider, reported by Identify and in
+	// the request element of each response.
+	BaseURL string
+	// AdminEmail holds the administrator addresses reported by Identify.
+	AdminEmail []string
+	// RepositoryIdentifier is used to form OAI identifiers for records
+	// without an "oai" external PID. It defaults to the host of BaseURL.
+	RepositoryIdentifier string
+	// PageSize is the number of headers or records returned before a
+	// resumption token is issued.
+	PageSize int
+	// Formats holds the metadata formats disseminated.
+	Formats []*OAIMetadataFormat
+
+	records []*Record
+}
+
+// NewOAIProvider creates a provider serving records. Records are sorted
+// by datestamp. Each record needs an ID or an "oai" external PID and
+// identifiers must be unique.
+//
+// ```
+//
+//	provider, err := simplified.NewOAIProvider("http://localhost:8000/oai", records)
+//	// ... handle error ...
+//	provider.RepositoryName = "My Repository"
+//	http.ListenAndServe("localhost:8000", provider)
+//
+// ```
+func NewOAIProvider(baseURL string, records []*Record) (*OAIProvider, error) {
+	p := &OAIProvider{
+		RepositoryName: "simplified",
+		BaseURL:        baseURL,
+		AdminEmail:     []string{"admin@localhost"},
+		PageSize:       100,
+		Formats:        OAIMetadataFormats,
+	}
+	if u, err := url.Parse(baseURL); err == nil && u.Hostname() != "" {
+		p.RepositoryIdentifier = u.Hostname()
+	} else {
+		p.RepositoryIdentifier = "localhost"
+	}
+	p.records = []*Record{}
+	for i, rec := range records {
+		if rec == nil {
+			continue
+		}
+		if rec.ID == "" && rec.ExternalPIDs["oai"] == nil {
+			return nil, fmt.Errorf("record %d has no id", i)
+		}
+		p.records = append(p.records, rec)
+	}
+	sort.SliceStable(p.records, func(i, j int) bool {
+		return oaiRecordDatestamp(p.records[i]).Before(oaiRecordDatestamp(p.records[j]))
+	})
+	seen := map[string]bool{}
+	for _, rec := range p.records {
+		identifier := p.Identifier(rec)
+		if seen[identifier] {
+			return nil, fmt.Errorf("duplicate identifier %q", identifier)
+		}
+		seen[identifier] = true
+	}
+	return p, nil
+}
+
+// lookup returns the record with the OAI identifier.
+func (p *OAIProvider) lookup(identifier string) (*Record, bool) {
+	for _, rec := range p.records {
+		if p.Identifier(rec) == identifier {
+			return rec, true
+		}
+	}
+	return nil, false
+}
+
+// Identifier returns the OAI identifier of a record.
+func (p *OAIProvider) Identifier(rec *Record) string {
+	if pid := rec.ExternalPIDs["oai"]; pid != nil && pid.Identifier != "" {
+		return pid.Identifier
+	}
+	return fmt.Sprintf("oai:%s:%s", p.RepositoryIdentifier, rec.ID)
+}
+
+// oaiRecordDatestamp returns the datestamp of a record, Updated falling
+// back to Created, then the tombstone timestamp. It is truncated to the
+// seconds granularity of the repository so the from and until
+// arguments match the datestamps harvesters see.
+func oaiRecordDatestamp(rec *Record) time.Time {
+	switch {
+	case !rec.Updated.IsZero():
+		return rec.Updated.UTC().Truncate(time.Second)
+	case !rec.Created.IsZero():
+		return rec.Created.UTC().Truncate(time.Second)
+	case rec.Tombstone != nil:
+		return rec.Tombstone.Timestamp.UTC().Truncate(time.Second)
+	}
+	return time.Time{}
+}
+
+// oaiSets returns the setSpecs of a record.
+func oaiSets(rec *Record) []string {
+	sets := []string{}
+	if rec.Parent == nil || rec.Parent.Communities == nil {
+		return sets
+	}
+	ids := append([]string{rec.Parent.Communities.Default}, rec.Parent.Communities.IDs...)
+	for _, id := range ids {
+		if id != "" && !contains(sets, id) {
+			sets = append(sets, id)
+		}
+	}
+	return sets
+}
+
+type oaiError struct {
+	Code    string `xml:"code,attr"`
+	Message string `xml:",chardata"`
+}
+
+func (e *oaiError) Error() string {
+	return e.Code + ", " + e.Message
+}
+
+func oaiErrorf(code string, format string, args ...interface{}) *oaiError {
+	return &oaiError{Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+type oaiRequest struct {
+	Verb            string `xml:"verb,attr,omitempty"`
+	Identifier      string `xml:"identifier,attr,omitempty"`
+	MetadataPrefix  string `xml:"metadataPrefix,attr,omitempty"`
+	From            string `xml:"from,attr,omitempty"`
+	Until           string `xml:"until,attr,omitempty"`
+	Set             string `xml:"set,attr,omitempty"`
+	ResumptionToken string `xml:"resumptionToken,attr,omitempty"`
+	URL             string `xml:",chardata"`
+}
+
+type oaiIdentify struct {
+	RepositoryName    string   `xml:"repositoryName"`
+	BaseURL           string   `xml:"baseURL"`
+	ProtocolVersion   string   `xml:"protocolVersion"`
+	AdminEmail        []string `xml:"adminEmail"`
+	EarliestDatestamp string   `xml:"earliestDatestamp"`
+	DeletedRecord     string   `xml:"deletedRecord"`
+	Granularity       string   `xml:"granularity"`
+}
+
+type oaiMetadataFormat struct {
+	MetadataPrefix    string `xml:"metadataPrefix"`
+	Schema            string `xml:"schema"`
+	MetadataNamespace string `xml:"metadataNamespace"`
+}
+
+type oaiSet struct {
+	SetSpec string `xml:"setSpec"`
+	SetName string `xml:"setName"`
+}
+
+type oaiHeader struct {
+	Status     string   `xml:"status,attr,omitempty"`
+	Identifier string   `xml:"identifier"`
+	Datestamp  string   `xml:"datestamp"`
+	SetSpec    []string `xml:"setSpec,omitempty"`
+}
+
+type oaiMetadata struct {
+	Inner []byte `xml:",innerxml"`
+}
+
+type oaiRecord struct {
+	Header   *oaiHeader   `xml:"header"`
+	Metadata *oaiMetadata `xml:"metadata,omitempty"`
+}
+
+type oaiResumptionToken struct {
+	CompleteListSize int    `xml:"completeListSize,attr"`
+	Cursor           int    `xml:"cursor,attr"`
+	Value            string `xml:",chardata"`
+}
+
+type oaiList struct {
+	Formats []*oaiMetadataFormat `xml:"metadataFormat,omitempty"`
+	Sets    []*oaiSet            `xml:"set,omitempty"`
+	Headers []*oaiHeader         `xml:"header,omitempty"`
+	Records []*oaiRecord         `xml:"record,omitempty"`
+	Token   *oaiResumptionToken  `xml:"resumptionToken,omitempty"`
+}
+
+type oaiPMH struct {
+	XMLName             xml.Name     `xml:"OAI-PMH"`
+	Xmlns               string       `xml:"xmlns,attr"`
+	XmlnsXSI            string       `xml:"xmlns:xsi,attr"`
+	SchemaLocation      string       `xml:"xsi:schemaLocation,attr"`
+	ResponseDate        string       `xml:"responseDate"`
+	Request             *oaiRequest  `xml:"request"`
+	Errors              []*oaiError  `xml:"error,omitempty"`
+	Identify            *oaiIdentify `xml:"Identify,omitempty"`
+	ListMetadataFormats *oaiList     `xml:"ListMetadataFormats,omitempty"`
+	ListSets            *oaiList     `xml:"ListSets,omitempty"`
+	ListIdentifiers     *oaiList     `xml:"ListIdentifiers,omitempty"`
+	ListRecords         *oaiList     `xml:"ListRecords,omitempty"`
+	GetRecord           *oaiList     `xml:"GetRecord,omitempty"`
+}
+
+// oaiListRequest holds the arguments of a ListIdentifiers or
+// ListRecords request, it is encoded in resumption tokens.
+type oaiListRequest struct {
+	MetadataPrefix string
+	From           string
+	Until          string
+	Set            string
+	Cursor         int
+}
+
+func (req *oaiListRequest) token() string {
+	v := url.Values{}
+	v.Set("metadataPrefix", req.MetadataPrefix)
+	v.Set("from", req.From)
+	v.Set("until", req.Until)
+	v.Set("set", req.Set)
+	v.Set("cursor", strconv.Itoa(req.Cursor))
+	return base64.RawURLEncoding.EncodeToString([]byte(v.Encode()))
+}
+
+func parseOAIToken(token string) (*oaiListRequest, *oaiError) {
+	src, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, oaiErrorf("badResumptionToken", "the resumption token is invalid")
+	}
+	v, err := url.ParseQuery(string(src))
+	if err != nil || v.Get("metadataPrefix") == "" {
+		return nil, oaiErrorf("badResumptionToken", "the resumption token is invalid")
+	}
+	cursor, err := strconv.Atoi(v.Get("cursor"))
+	if err != nil || cursor < 0 {
+		return nil, oaiErrorf("badResumptionToken", "the resumption token is invalid")
+	}
+	return &oaiListRequest{
+		MetadataPrefix: v.Get("metadataPrefix"),
+		From:           v.Get("from"),
+		Until:          v.Get("until"),
+		Set:            v.Get("set"),
+		Cursor:         cursor,
+	}, nil
+}
+
+// parseOAIDate parses a from or until argument. A day granularity
+// until covers the whole day.
+func parseOAIDate(s string, until bool) (time.Time, error) {
+	if len(s) == len(oaiDay) {
+		t, err := time.Parse(oaiDay, s)
+		if err == nil && until {
+			t = t.Add(24*time.Hour - time.Second)
+		}
+		return t, err
+	}
+	return time.Parse(oaiDatestamp, s)
+}
+
+// checkArguments verifies the arguments of a request against the verb.
+func checkArguments(verb string, args url.Values) *oaiError {
+	allowed, ok := oaiArguments[verb]
+	if !ok {
+		if verb == "" {
+			return oaiErrorf("badVerb", "missing verb")
+		}
+		return oaiErrorf("badVerb", "illegal verb %q", verb)
+	}
+	for key, values := range args {
+		if key == "verb" {
+			if len(values) > 1 {
+				return oaiErrorf("badVerb", "verb is repeated")
+			}
+			continue
+		}
+		if !contains(allowed[0], key) && !contains(allowed[1], key) {
+			return oaiErrorf("badArgument", "illegal argument %q", key)
+		}
+		if len(values) > 1 {
+			return oaiErrorf("badArgument", "argument %q is repeated", key)
+		}
+	}
+	if args.Get("resumptionToken") != "" {
+		if len(args) > 2 {
+			return oaiErrorf("badArgument", "resumptionToken is an exclusive argument")
+		}
+		return nil
+	}
+	for _, key := range allowed[0] {
+		if args.Get(key) == "" {
+			return oaiErrorf("badArgument", "missing required argument %q", key)
+		}
+	}
+	return nil
+}
+
+// format returns the metadata format for a prefix.
+func (p *OAIProvider) format(prefix string) (*OAIMetadataFormat, *oaiError) {
+	for _, f := range p.Formats {
+		if f.Prefix == prefix {
+			return f, nil
+		}
+	}
+	return nil, oaiErrorf("cannotDisseminateFormat", "%q is not supported", prefix)
+}
+
+// header returns the OAI header of a record.
+func (p *OAIProvider) header(rec *Record) *oaiHeader {
+	h := &oaiHeader{
+		Identifier: p.Identifier(rec),
+		Datestamp:  oaiRecordDatestamp(rec).Format(oaiDatestamp),
+		SetSpec:    oaiSets(rec),
+	}
+	if rec.Tombstone != nil {
+		h.Status = "deleted"
+	}
+	return h
+}
+
+// record returns the OAI record, deleted records have no metadata.
+func (p *OAIProvider) record(rec *Record, format *OAIMetadataFormat) (*oaiRecord, error) {
+	r := &oaiRecord{Header: p.header(rec)}
+	if rec.Tombstone != nil {
+		return r, nil
+	}
+	src, err := format.Encode(rec)
+	if err != nil {
+		return nil, err
+	}
+	src = bytes.TrimSpace(bytes.TrimPrefix(src, []byte(xml.Header)))
+	r.Metadata = &oaiMetadata{Inner: src}
+	return r, nil
+}
+
+func (p *OAIProvider) identify() *oaiIdentify {
+	earliest := time.Time{}
+	if len(p.records) > 0 {
+		earliest = oaiRecordDatestamp(p.records[0])
+	}
+	return &oaiIdentify{
+		RepositoryName:    p.RepositoryName,
+		BaseURL:           p.BaseURL,
+		ProtocolVersion:   "2.0",
+		AdminEmail:        p.AdminEmail,
+		EarliestDatestamp: earliest.Format(oaiDatestamp),
+		DeletedRecord:     "persistent",
+		Granularity:       "YYYY-MM-DDThh:mm:ssZ",
+	}
+}
+
+func (p *OAIProvider) listMetadataFormats(identifier string) (*oaiList, *oaiError) {
+	if identifier != "" {
+		if _, ok := p.lookup(identifier); !ok {
+			return nil, oaiErrorf("idDoesNotExist", "%q is unknown", identifier)
+		}
+	}
+	list := &oaiList{}
+	for _, f := range p.Formats {
+		list.Formats = append(list.Formats, &oaiMetadataFormat{MetadataPrefix: f.Prefix, Schema: f.Schema, MetadataNamespace: f.Namespace})
+	}
+	if len(list.Formats) == 0 {
+		return nil, oaiErrorf("noMetadataFormats", "no metadata formats are available")
+	}
+	return list, nil
+}
+
+func (p *OAIProvider) listSets(token string) (*oaiList, *oaiError) {
+	if token != "" {
+		return nil, oaiErrorf("badResumptionToken", "ListSets does not issue resumption tokens")
+	}
+	list := &oaiList{}
+	seen := []string{}
+	for _, rec := range p.records {
+		for _, spec := range oaiSets(rec) {
+			if !contains(seen, spec) {
+				seen = append(seen, spec)
+			}
+		}
+	}
+	if len(seen) == 0 {
+		return nil, oaiErrorf("noSetHierarchy", "this repository does not support sets")
+	}
+	sort.Strings(seen)
+	for _, spec := range seen {
+		list.Sets = append(list.Sets, &oaiSet{SetSpec: spec, SetName: spec})
+	}
+	return list, nil
+}
+
+// list answers ListIdentifiers and ListRecords.
+func (p *OAIProvider) list(args url.Values, withMetadata bool) (*oaiList, *oaiError) {
+	var (
+		req    *oaiListRequest
+		oaiErr *oaiError
+		err    error
+	)
+	if token := args.Get("resumptionToken"); token != "" {
+		if req, oaiErr = parseOAIToken(token); oaiErr != nil {
+			return nil, oaiErr
+		}
+	} else {
+		req = &oaiListRequest{
+			MetadataPrefix: args.Get("metadataPrefix"),
+			From:           args.Get("from"),
+			Until:          args.Get("until"),
+			Set:            args.Get("set"),
+		}
+	}
+	format, oaiErr := p.format(req.MetadataPrefix)
+	if oaiErr != nil {
+		return nil, oaiErr
+	}
+	var from, until time.Time
+	if req.From != "" {
+		if from, err = parseOAIDate(req.From, false); err != nil {
+			return nil, oaiErrorf("badArgument", "from %q is not a valid datestamp", req.From)
+		}
+	}
+	if req.Until != "" {
+		if until, err = parseOAIDate(req.Until, true); err != nil {
+			return nil, oaiErrorf("badArgument", "until %q is not a valid datestamp", req.Until)
+		}
+	}
+	if req.From != "" && req.Until != "" && len(req.From) != len(req.Until) {
+		return nil, oaiErrorf("badArgument", "from and until have different granularities")
+	}
+	if req.Set != "" {
+		if _, err := p.listSets(""); err != nil {
+			return nil, err
+		}
+	}
+	matches := []*Record{}
+	for _, rec := range p.records {
+		datestamp := oaiRecordDatestamp(rec)
+		if (req.From != "" && datestamp.Before(from)) || (req.Until != "" && datestamp.After(until)) {
+			continue
+		}
+		if req.Set != "" && !contains(oaiSets(rec), req.Set) {
+			continue
+		}
+		matches = append(matches, rec)
+	}
+	if len(matches) == 0 {
+		return nil, oaiErrorf("noRecordsMatch", "no records match the request")
+	}
+	if req.Cursor >= len(matches) {
+		return nil, oaiErrorf("badResumptionToken", "the resumption token is past the end of the list")
+	}
+	pageSize := p.PageSize
+	if pageSize <= 0 {
+		pageSize = len(matches)
+	}
+	end := req.Cursor + pageSize
+	if end > len(matches) {
+		end = len(matches)
+	}
+	list := &oaiList{}
+	for _, rec := range matches[req.Cursor:end] {
+		if !withMetadata {
+			list.Headers = append(list.Headers, p.header(rec))
+			continue
+		}
+		r, err := p.record(rec, format)
+		if err != nil {
+			return nil, oaiErrorf("cannotDisseminateFormat", "%s, %s", p.Identifier(rec), err)
+		}
+		list.Records = append(list.Records, r)
+	}
+	// An empty token marks the last page of an incomplete list.
+	if end < len(matches) || req.Cursor > 0 {
+		list.Token = &oaiResumptionToken{CompleteListSize: len(matches), Cursor: req.Cursor}
+		if end < len(matches) {
+			next := *req
+			next.Cursor = end
+			list.Token.Value = next.token()
+		}
+	}
+	return list, nil
+}
+
+func (p *OAIProvider) getRecord(identifier string, prefix string) (*oaiList, *oaiError) {
+	format, oaiErr := p.format(prefix)
+	if oaiErr != nil {
+		return nil, oaiErr
+	}
+	rec, ok := p.lookup(identifier)
+	if !ok {
+		return nil, oaiErrorf("idDoesNotExist", "%q is unknown", identifier)
+	}
+	r, err := p.record(rec, format)
+	if err != nil {
+		return nil, oaiErrorf("cannotDisseminateFormat", "%s, %s", identifier, err)
+	}
+	return &oaiList{Records: []*oaiRecord{r}}, nil
+}
+
+// Response answers an OAI-PMH request and returns the XML response.
+// Protocol errors are reported in the response as OAI-PMH requires.
+//
+// ```
+//
+//	args, _ := url.ParseQuery("verb=ListRecords&metadataPrefix=oai_dc")
+//	src, err := provider.Response(args)
+//	// ... handle error ...
+//	fmt.Printf("%s\n", src)
+//
+// ```
+func (p *OAIProvider) Response(args url.Values) ([]byte, error) {
+	verb := args.Get("verb")
+	resp := &oaiPMH{
+		Xmlns:          OAIPMHNamespace,
+		XmlnsXSI:       xsiNamespace,
+		SchemaLocation: OAIPMHSchemaLocation,
+		ResponseDate:   time.Now().UTC().Format(oaiDatestamp),
+		Request:        &oaiRequest{URL: p.BaseURL},
+	}
+	oaiErr := checkArguments(verb, args)
+	if oaiErr == nil {
+		// The request attributes are only echoed for valid requests.
+		resp.Request = &oaiRequest{
+			Verb:            verb,
+			Identifier:      args.Get("identifier"),
+			MetadataPrefix:  args.Get("metadataPrefix"),
+			From:            args.Get("from"),
+			Until:           args.Get("until"),
+			Set:             args.Get("set"),
+			ResumptionToken: args.Get("resumptionToken"),
+			URL:             p.BaseURL,
+		}
+		switch verb {
+		case "Identify":
+			resp.Identify = p.identify()
+		case "ListMetadataFormats":
+			resp.ListMetadataFormats, oaiErr = p.listMetadataFormats(args.Get("identifier"))
+		case "ListSets":
+			resp.ListSets, oaiErr = p.listSets(args.Get("resumptionToken"))
+		case "ListIdentifiers":
+			resp.ListIdentifiers, oaiErr = p.list(args, false)
+		case "ListRecords":
+			resp.ListRecords, oaiErr = p.list(args, true)
+		case "GetRecord":
+			resp.GetRecord, oaiErr = p.getRecord(args.Get("identifier"), args.Get("metadataPrefix"))
+		}
+	}
+	if oaiErr != nil {
+		resp.Errors = append(resp.Errors, oaiErr)
+	}
+	src, err := xml.MarshalIndent(resp, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), src...), nil
+}
+
+// ServeHTTP answers OAI-PMH requests sent by GET or POST.
+func (p *OAIProvider) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	src, err := p.Response(r.Form)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/xml; charset=utf-8")
+	w.Write(src)
+}
@@ -0,0 +1,183 @@
+package simplified
+
+import (
+	"encoding/xml"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// oaiTestResponse holds the parts of an OAI-PMH response checked by
+// the tests.
+type oaiTestResponse struct {
+	Request struct {
+		Verb string `xml:"verb,attr"`
+	} `xml:"request"`
+	Errors []struct {
+		Code string `xml:"code,attr"`
+	} `xml:"error"`
+	Identify struct {
+		EarliestDatestamp string `xml:"earliestDatestamp"`
+		DeletedRecord     string `xml:"deletedRecord"`
+	} `xml:"Identify"`
+	Sets    []string `xml:"ListSets>set>setSpec"`
+	Formats []string `xml:"ListMetadataFormats>metadataFormat>metadataPrefix"`
+	Headers []struct {
+		Status     string `xml:"status,attr"`
+		Identifier string `xml:"identifier"`
+		Datestamp  string `xml:"datestamp"`
+	} `xml:"ListIdentifiers>header"`
+	Records []struct {
+		Header struct {
+			Status     string `xml:"status,attr"`
+			Identifier string `xml:"identifier"`
+		} `xml:"header"`
+		Title string `xml:"metadata>dc>title"`
+	} `xml:"ListRecords>record"`
+	GetRecord struct {
+		Identifier string `xml:"header>identifier"`
+		Title      string `xml:"metadata>dc>title"`
+	} `xml:"GetRecord>record"`
+	Token struct {
+		CompleteListSize int    `xml:"completeListSize,attr"`
+		Cursor           int    `xml:"cursor,attr"`
+		Value            string `xml:",chardata"`
+	} `xml:"ListIdentifiers>resumptionToken"`
+}
+
+func oaiTestProvider(t *testing.T) *OAIProvider {
+	t.Helper()
+	records := []*Record{}
+	for i, title := range []string{"First", "Second", "Third"} {
+		rec := &Record{
+			ID:       strings.ToLower(title),
+			Metadata: &Metadata{Title: title, ResourceType: map[string]interface{}{"id": "dataset"}},
+			Updated:  time.Date(2023, 12, 19+i, 10, 0, 0, 0, time.UTC),
+		}
+		records = append(records, rec)
+	}
+	records[0].Parent = &RecordIdentifier{Communities: &Community{IDs: []string{"geology"}, Default: "geology"}}
+	records[1].Parent = &RecordIdentifier{Communities: &Community{IDs: []string{"biology", "geology"}}}
+	records[2].Tombstone = &Tombstone{Reason: "withdrawn"}
+	// Records are sorted by datestamp.
+	records[0], records[2] = records[2], records[0]
+	p, err := NewOAIProvider("http://localhost:8000/oai", records)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.PageSize = 2
+	return p
+}
+
+func oaiTestQuery(t *testing.T, p *OAIProvider, query string) *oaiTestResponse {
+	t.Helper()
+	args, err := url.ParseQuery(query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	src, err := p.Response(args)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp := new(oaiTestResponse)
+	if err := xml.Unmarshal(src, resp); err != nil {
+		t.Fatalf("%s\n%s", err, src)
+	}
+	return resp
+}
+
+func TestOAIProvider(t *testing.T) {
+	p := oaiTestProvider(t)
+	resp := oaiTestQuery(t, p, "verb=Identify")
+	if len(resp.Errors) != 0 || resp.Identify.EarliestDatestamp != "2023-12-19T10:00:00Z" || resp.Identify.DeletedRecord != "persistent" {
+		t.Errorf("unexpected Identify %+v", resp)
+	}
+	resp = oaiTestQuery(t, p, "verb=ListMetadataFormats&identifier=oai:localhost:first")
+	if len(resp.Formats) != 2 || resp.Formats[0] != "oai_dc" {
+		t.Errorf("unexpected ListMetadataFormats %+v", resp)
+	}
+	resp = oaiTestQuery(t, p, "verb=ListSets")
+	if strings.Join(resp.Sets, " ") != "biology geology" {
+		t.Errorf("unexpected ListSets %+v", resp)
+	}
+
+	// Page through the identifiers with resumption tokens.
+	resp = oaiTestQuery(t, p, "verb=ListIdentifiers&metadataPrefix=oai_dc")
+	if len(resp.Headers) != 2 || resp.Headers[0].Identifier != "oai:localhost:first" || resp.Token.Value == "" || resp.Token.CompleteListSize != 3 {
+		t.Fatalf("unexpected first page %+v", resp)
+	}
+	resp = oaiTestQuery(t, p, "verb=ListIdentifiers&resumptionToken="+resp.Token.Value)
+	if len(resp.Headers) != 1 || resp.Headers[0].Status != "deleted" || resp.Token.Value != "" || resp.Token.Cursor != 2 {
+		t.Errorf("unexpected last page %+v", resp)
+	}
+
+	resp = oaiTestQuery(t, p, "verb=ListRecords&metadataPrefix=oai_dc&from=2023-12-20&until=2023-12-21T10:00:00Z")
+	if len(resp.Errors) == 0 || resp.Errors[0].Code != "badArgument" {
+		t.Errorf("expected badArgument for mixed granularities, got %+v", resp)
+	}
+	resp = oaiTestQuery(t, p, "verb=ListRecords&metadataPrefix=oai_dc&from=2023-12-20&until=2023-12-21")
+	if len(resp.Records) != 2 || resp.Records[0].Title != "Second" || resp.Records[1].Header.Status != "deleted" || resp.Records[1].Title != "" {
+		t.Errorf("unexpected ListRecords %+v", resp)
+	}
+	// Datestamps are in seconds, a record updated within the second
+	// matches an until of the datestamp it is shown with.
+	p.records[1].Updated = p.records[1].Updated.Add(500 * time.Millisecond)
+	resp = oaiTestQuery(t, p, "verb=ListIdentifiers&metadataPrefix=oai_dc&from=2023-12-20T10:00:00Z&until=2023-12-20T10:00:00Z")
+	if len(resp.Headers) != 1 || resp.Headers[0].Datestamp != "2023-12-20T10:00:00Z" {
+		t.Errorf("expected the record at the until datestamp, got %+v", resp)
+	}
+	resp = oaiTestQuery(t, p, "verb=ListRecords&metadataPrefix=oai_dc&set=biology")
+	if len(resp.Records) != 1 || resp.Records[0].Header.Identifier != "oai:localhost:second" {
+		t.Errorf("unexpected ListRecords for a set %+v", resp)
+	}
+	resp = oaiTestQuery(t, p, "verb=GetRecord&metadataPrefix=oai_dc&identifier=oai:localhost:first")
+	if resp.GetRecord.Title != "First" {
+		t.Errorf("unexpected GetRecord %+v", resp)
+	}
+
+	for query, code := range map[string]string{
+		"":                                  "badVerb",
+		"verb=Delete":                       "badVerb",
+		"verb=Identify&set=geology":         "badArgument",
+		"verb=GetRecord&identifier=oai:x:y": "badArgument",
+		"verb=GetRecord&metadataPrefix=oai_dc&identifier=oai:x:y":  "idDoesNotExist",
+		"verb=ListRecords&metadataPrefix=marc":                     "cannotDisseminateFormat",
+		"verb=ListRecords&metadataPrefix=oai_dc&from=2030-01-01":   "noRecordsMatch",
+		"verb=ListRecords&resumptionToken=nonsense":                "badResumptionToken",
+		"verb=ListRecords&metadataPrefix=oai_dc&resumptionToken=x": "badArgument",
+	} {
+		resp = oaiTestQuery(t, p, query)
+		if len(resp.Errors) != 1 || resp.Errors[0].Code != code {
+			t.Errorf("expected %s for %q, got %+v", code, query, resp.Errors)
+		}
+		if (code == "badVerb" || code == "badArgument") && resp.Request.Verb != "" {
+			t.Errorf("expected no request attributes for %q", query)
+		}
+	}
+	if _, err := NewOAIProvider("http://localhost/oai", []*Record{{ID: "a"}, {ID: "a"}}); err == nil {
+		t.Errorf("expected an error for duplicate identifiers")
+	}
+}
+
+func TestOAIProviderServeHTTP(t *testing.T) {
+	p := oaiTestProvider(t)
+	server := httptest.NewServer(p)
+	defer server.Close()
+	res, err := server.Client().PostForm(server.URL, url.Values{"verb": {"Identify"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	if ct := res.Header.Get("Content-Type"); !strings.HasPrefix(ct, "text/xml") {
+		t.Errorf("expected text/xml, got %q", ct)
+	}
+	resp := new(oaiTestResponse)
+	if err := xml.NewDecoder(res.Body).Decode(resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Request.Verb != "Identify" {
+		t.Errorf("expected an Identify response, got %+v", resp)
+	}
+}
@@ -0,0 +1,533 @@
+package simplified
+
+/**
+ * This file implements JSON Patch (RFC 6902) support for simplified
+ * records. A patch is computed against the JSON representation of
+ * a Record so it can be applied to the same record held by another
+ * system, e.g. an InvenioRDM instance.
+ *
+ * See https://www.rfc-editor.org/rfc/rfc6902
+ */
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// PatchOperation is a single RFC 6902 JSON Patch operation.
+type PatchOperation struct {
+	// Op is one of "add", "remove", "replace", "move", "copy" or "test".
+	Op string `json:"op"`
+	// Path is a JSON pointer to the target location.
+	Path string `json:"path"`
+	// From is a JSON pointer used by "move" and "copy".
+	From string `json:"from,omitempty"`
+	// Value is used by "add", "replace" and "test".
+	Value interface{} `json:"value,omitempty"`
+}
+
+// MarshalJSON renders the operation making sure a value is included
+// for the operations that require one even when it is null.
+func (op *PatchOperation) MarshalJSON() ([]byte, error) {
+	switch op.Op {
+	case "add", "replace", "test":
+		return json.Marshal(struct {
+			Op    string      `json:"op"`
+			Path  string      `json:"path"`
+			Value interface{} `json:"value"`
+		}{op.Op, op.Path, op.Value})
+	case "move", "copy":
+		return json.Marshal(struct {
+			Op   string `json:"op"`
+			From string `json:"from"`
+			Path string `json:"path"`
+		}{op.Op, op.From, op.Path})
+	}
+	return json.Marshal(struct {
+		Op   string `json:"op"`
+		Path string `json:"path"`
+	}{op.Op, op.Path})
+}
+
+// Patch is an RFC 6902 JSON Patch document.
+type Patch []*PatchOperation
+
+// toJSONValue converts a value to its generic JSON form, i.e. maps,
+// slices, strings, json.Number, booleans and nil.
+func toJSONValue(v interface{}) (interface{}, error) {
+	src, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return decodeJSONValue(src)
+}
+
+func decodeJSONValue(src []byte) (interface{}, error) {
+	var val interface{}
+	decoder := json.NewDecoder(bytes.NewReader(src))
+	decoder.UseNumber()
+	if err := decoder.Decode(&val); err != nil {
+		return nil, err
+	}
+	return val, nil
+}
+
+// DiffAsPatch compares the record with a new version, t, and returns
+// the JSON Patch which transforms the JSON of rec into the JSON of t.
+// Lists are compared element by element so a change to a single
+// affiliation results in an operation like
+// `{"op": "replace", "path": "/metadata/creators/3/affiliations/0/name", ...}`.
+//
+// ```
+//
+//	patch, err := oldRecord.DiffAsPatch(newRecord)
+//	// ... handle error ...
+//	src, err := json.MarshalIndent(patch, "", "    ")
+//	// ... handle error ...
+//	fmt.Printf("%s\n", src)
+//
+// ```
+func (rec *Record) DiffAsPatch(t *Record) (Patch, error) {
+	a, err := toJSONValue(rec)
+	if err != nil {
+		return nil, err
+	}
+	b, err := toJSONValue(t)
+	if err != nil {
+		return nil, err
+	}
+	patch := Patch{}
+	diffJSONValue(&patch, "", a, b)
+	return patch, nil
+}
+
+// diffJSONValue appends the operations transforming a into b.
+func diffJSONValue(patch *Patch, path string, a interface{}, b interface{}) {
+	if reflect.DeepEqual(a, b) {
+		return
+	}
+	switch aVal := a.(type) {
+	case map[string]interface{}:
+		if bVal, ok := b.(map[string]interface{}); ok {
+			diffJSONObject(patch, path, aVal, bVal)
+			return
+		}
+	case []interface{}:
+		if bVal, ok := b.([]interface{}); ok {
+			diffJSONArray(patch, path, aVal, bVal)
+			return
+		}
+	}
+	*patch = append(*patch, &PatchOperation{Op: "replace", Path: path, Value: b})
+}
+
+func diffJSONObject(patch *Patch, path string, a map[string]interface{}, b map[string]interface{}) {
+	for _, key := range sortedKeys(a) {
+		if _, ok := b[key]; !ok {
+			*patch = append(*patch, &PatchOperation{Op: "remove", Path: path + jsonPointer(key)})
+		}
+	}
+	for _, key := range sortedKeys(b) {
+		if aVal, ok := a[key]; ok {
+			diffJSONValue(patch, path+jsonPointer(key), aVal, b[key])
+		} else {
+			*patch = append(*patch, &PatchOperation{Op: "add", Path: path + jsonPointer(key), Value: b[key]})
+		}
+	}
+}
+
+// diffJSONArray aligns the two arrays on their longest common
+// subsequence. Elements in between matches are paired up and
+// diffed, left over elements are removed or added.
+func diffJSONArray(patch *Patch, path string, a []interface{}, b []interface{}) {
+	n, m := len(a), len(b)
+	lcs := make([][]int, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if reflect.DeepEqual(a[i], b[j]) {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+	// idx tracks the position in the array as it is being patched.
+	i, j, idx := 0, 0, 0
+	for i < n || j < m {
+		if i < n && j < m && reflect.DeepEqual(a[i], b[j]) {
+			i, j, idx = i+1, j+1, idx+1
+			continue
+		}
+		// Collect the run of removed and inserted elements up to
+		// the next match.
+		dels, ins := []interface{}{}, []interface{}{}
+		for i < n || j < m {
+			if i < n && j < m && reflect.DeepEqual(a[i], b[j]) {
+				break
+			}
+			if j >= m || (i < n && lcs[i+1][j] >= lcs[i][j+1]) {
+				dels = append(dels, a[i])
+				i++
+			} else {
+				ins = append(ins, b[j])
+				j++
+			}
+		}
+		idx = diffJSONRun(patch, path, idx, dels, ins)
+	}
+}
+
+// pairThreshold is the minimum similarity for a removed and an inserted
+// element to be treated as the same element modified.
+const pairThreshold = 0.5
+
+// diffJSONRun handles a run of removed and inserted array elements
+// starting at idx. Removed elements are paired with similar inserted
+// elements, in order, and diffed. The rest are removed or added.
+// It returns the index following the run.
+func diffJSONRun(patch *Patch, path string, idx int, dels []interface{}, ins []interface{}) int {
+	n, m := len(dels), len(ins)
+	// score[i][j] holds the best total similarity pairing dels[i:] with ins[j:]
+	score := make([][]float64, n+1)
+	for i := range score {
+		score[i] = make([]float64, m+1)
+	}
+	sim := make([][]float64, n)
+	for i := n - 1; i >= 0; i-- {
+		sim[i] = make([]float64, m)
+		for j := m - 1; j >= 0; j-- {
+			sim[i][j] = jsonSimilarity(dels[i], ins[j])
+			best := score[i+1][j]
+			if score[i][j+1] > best {
+				best = score[i][j+1]
+			}
+			if sim[i][j] >= pairThreshold && score[i+1][j+1]+sim[i][j] > best {
+				best = score[i+1][j+1] + sim[i][j]
+			}
+			score[i][j] = best
+		}
+	}
+	i, j := 0, 0
+	for i < n || j < m {
+		switch {
+		case i < n && j < m && sim[i][j] >= pairThreshold && score[i][j] == score[i+1][j+1]+sim[i][j]:
+			diffJSONValue(patch, path+jsonPointer(idx), dels[i], ins[j])
+			i, j, idx = i+1, j+1, idx+1
+		case j >= m || (i < n && score[i][j] == score[i+1][j]):
+			*patch = append(*patch, &PatchOperation{Op: "remove", Path: path + jsonPointer(idx)})
+			i++
+		default:
+			*patch = append(*patch, &PatchOperation{Op: "add", Path: path + jsonPointer(idx), Value: ins[j]})
+			j, idx = j+1, idx+1
+		}
+	}
+	return idx
+}
+
+// jsonSimilarity scores how alike two generic JSON values are, from
+// 0 (nothing in common) to 1 (equal). Objects are scored on the
+// share of their attributes that are alike.
+func jsonSimilarity(a interface{}, b interface{}) float64 {
+	if reflect.DeepEqual(a, b) {
+		return 1
+	}
+	switch aVal := a.(type) {
+	case map[string]interface{}:
+		bVal, ok := b.(map[string]interface{})
+		if !ok {
+			return 0
+		}
+		keys := map[string]bool{}
+		for k := range aVal {
+			keys[k] = true
+		}
+		for k := range bVal {
+			keys[k] = true
+		}
+		total := 0.0
+		for k := range keys {
+			if _, ok := aVal[k]; ok {
+				if _, ok := bVal[k]; ok {
+					total += jsonSimilarity(aVal[k], bVal[k])
+				}
+			}
+		}
+		return total / float64(len(keys))
+	case []interface{}:
+		bVal, ok := b.([]interface{})
+		if !ok {
+			return 0
+		}
+		l := len(aVal)
+		if len(bVal) > l {
+			l = len(bVal)
+		}
+		total := 0.0
+		for i := 0; i < len(aVal) && i < len(bVal); i++ {
+			total += jsonSimilarity(aVal[i], bVal[i])
+		}
+		return total / float64(l)
+	}
+	return 0
+}
+
+// ApplyPatch applies a JSON Patch to the record. The patch is applied
+// as a whole, if any operation fails the record is left unchanged.
+//
+// ```
+//
+//	src, err := os.ReadFile("changes.json")
+//	// ... handle error ...
+//	patch := simplified.Patch{}
+//	err = json.Unmarshal(src, &patch)
+//	// ... handle error ...
+//	err = rec.ApplyPatch(patch)
+//	// ... handle error ...
+//
+// ```
+func (rec *Record) ApplyPatch(patch Patch) error {
+	if rec == nil {
+		return fmt.Errorf("cannot apply patch to a nil record")
+	}
+	doc, err := toJSONValue(rec)
+	if err != nil {
+		return err
+	}
+	for i, op := range patch {
+		if op == nil {
+			return fmt.Errorf("patch operation %d is empty", i)
+		}
+		if doc, err = applyPatchOperation(doc, op); err != nil {
+			return fmt.Errorf("patch operation %d (%s %s), %s", i, op.Op, op.Path, err)
+		}
+	}
+	src, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	patched := new(Record)
+	if err := json.Unmarshal(src, &patched); err != nil {
+		return err
+	}
+	*rec = *patched
+	return nil
+}
+
+func applyPatchOperation(doc interface{}, op *PatchOperation) (interface{}, error) {
+	// Values are normalized so numbers and structs compare as JSON.
+	value, err := toJSONValue(op.Value)
+	if err != nil {
+		return nil, err
+	}
+	switch op.Op {
+	case "add":
+		return jsonPointerSet(doc, op.Path, value, true)
+	case "remove":
+		doc, _, err := jsonPointerRemove(doc, op.Path)
+		return doc, err
+	case "replace":
+		if _, err := jsonPointerGet(doc, op.Path); err != nil {
+			return nil, err
+		}
+		return jsonPointerSet(doc, op.Path, value, false)
+	case "move":
+		if op.Path != op.From && strings.HasPrefix(op.Path, op.From+"/") {
+			return nil, fmt.Errorf("cannot move %q into one of its children", op.From)
+		}
+		doc, val, err := jsonPointerRemove(doc, op.From)
+		if err != nil {
+			return nil, err
+		}
+		return jsonPointerSet(doc, op.Path, val, true)
+	case "copy":
+		val, err := jsonPointerGet(doc, op.From)
+		if err != nil {
+			return nil, err
+		}
+		// Copy the value so the two locations don't share maps or slices.
+		if val, err = toJSONValue(val); err != nil {
+			return nil, err
+		}
+		return jsonPointerSet(doc, op.Path, val, true)
+	case "test":
+		val, err := jsonPointerGet(doc, op.Path)
+		if err != nil {
+			return nil, err
+		}
+		if !jsonValueEqual(val, value) {
+			return nil, fmt.Errorf("test failed, value at %q differs", op.Path)
+		}
+		return doc, nil
+	}
+	return nil, fmt.Errorf("unsupported operation %q", op.Op)
+}
+
+// jsonValueEqual compares two generic JSON values treating numbers
+// by value rather than representation.
+func jsonValueEqual(a interface{}, b interface{}) bool {
+	if aNum, ok := a.(json.Number); ok {
+		if bNum, ok := b.(json.Number); ok {
+			aF, aErr := aNum.Float64()
+			bF, bErr := bNum.Float64()
+			if aErr == nil && bErr == nil {
+				return aF == bF
+			}
+		}
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// parseJSONPointer splits a JSON pointer into unescaped reference tokens.
+func parseJSONPointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return []string{}, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("invalid JSON pointer %q", pointer)
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+	}
+	return tokens, nil
+}
+
+// arrayIndex converts a reference token into an array index. If
+// allowEnd is true then "-" or len(a) refer to the end of the array.
+func arrayIndex(token string, a []interface{}, allowEnd bool) (int, error) {
+	if allowEnd && token == "-" {
+		return len(a), nil
+	}
+	if token == "" || (len(token) > 1 && token[0] == '0') {
+		return -1, fmt.Errorf("invalid array index %q", token)
+	}
+	i, err := strconv.Atoi(token)
+	if err != nil || i < 0 {
+		return -1, fmt.Errorf("invalid array index %q", token)
+	}
+	if i > len(a) || (i == len(a) && !allowEnd) {
+		return -1, fmt.Errorf("array index %d out of range", i)
+	}
+	return i, nil
+}
+
+func jsonPointerGet(doc interface{}, pointer string) (interface{}, error) {
+	tokens, err := parseJSONPointer(pointer)
+	if err != nil {
+		return nil, err
+	}
+	cur := doc
+	for _, token := range tokens {
+		switch node := cur.(type) {
+		case map[string]interface{}:
+			val, ok := node[token]
+			if !ok {
+				return nil, fmt.Errorf("%q not found", pointer)
+			}
+			cur = val
+		case []interface{}:
+			i, err := arrayIndex(token, node, false)
+			if err != nil {
+				return nil, err
+			}
+			cur = node[i]
+		default:
+			return nil, fmt.Errorf("%q not found", pointer)
+		}
+	}
+	return cur, nil
+}
+
+// jsonPointerSet sets the value at pointer returning the updated
+// document. If insert is true values are inserted into arrays
+// (the "add" semantics) otherwise the existing element is replaced.
+func jsonPointerSet(doc interface{}, pointer string, value interface{}, insert bool) (interface{}, error) {
+	tokens, err := parseJSONPointer(pointer)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return value, nil
+	}
+	parentPointer := pointer[:strings.LastIndex(pointer, "/")]
+	parent, err := jsonPointerGet(doc, parentPointer)
+	if err != nil {
+		return nil, err
+	}
+	last := tokens[len(tokens)-1]
+	switch node := parent.(type) {
+	case map[string]interface{}:
+		node[last] = value
+		return doc, nil
+	case []interface{}:
+		i, err := arrayIndex(last, node, insert)
+		if err != nil {
+			return nil, err
+		}
+		if insert {
+			node = append(node, nil)
+			copy(node[i+1:], node[i:])
+		}
+		node[i] = value
+		return jsonPointerSet(doc, parentPointer, node, false)
+	}
+	return nil, fmt.Errorf("cannot set %q, parent is not an object or array", pointer)
+}
+
+// jsonPointerRemove removes the value at pointer returning the updated
+// document and the removed value.
+func jsonPointerRemove(doc interface{}, pointer string) (interface{}, interface{}, error) {
+	tokens, err := parseJSONPointer(pointer)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, doc, nil
+	}
+	parentPointer := pointer[:strings.LastIndex(pointer, "/")]
+	parent, err := jsonPointerGet(doc, parentPointer)
+	if err != nil {
+		return nil, nil, err
+	}
+	last := tokens[len(tokens)-1]
+	switch node := parent.(type) {
+	case map[string]interface{}:
+		val, ok := node[last]
+		if !ok {
+			return nil, nil, fmt.Errorf("%q not found", pointer)
+		}
+		delete(node, last)
+		return doc, val, nil
+	case []interface{}:
+		i, err := arrayIndex(last, node, false)
+		if err != nil {
+			return nil, nil, err
+		}
+		val := node[i]
+		node = append(node[:i:i], node[i+1:]...)
+		doc, err = jsonPointerSet(doc, parentPointer, node, false)
+		return doc, val, err
+	}
+	return nil, nil, fmt.Errorf("%q not found", pointer)
+}
+
+// sortedKeys returns the keys of a map in sorted order so
+// patches are generated in a stable order.
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,130 @@
+package simplified
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestDiffAsPatch(t *testing.T) {
+	oldRecord, newRecord := testRecord(t), testRecord(t)
+	patch, err := oldRecord.DiffAsPatch(newRecord)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(patch) != 0 {
+		t.Errorf("expected an empty patch for identical records, got %d operations", len(patch))
+	}
+
+	// Change an affiliation, insert a new first creator, drop a subject
+	// and add a version.
+	newRecord.Metadata.Creators[0].Affiliations[0].Name = "Caltech"
+	newRecord.Metadata.Creators = append([]*Creator{
+		{PersonOrOrg: &PersonOrOrg{Type: "personal", FamilyName: "Doe", GivenName: "Jane"}},
+	}, newRecord.Metadata.Creators...)
+	newRecord.Metadata.Subjects = nil
+	newRecord.Metadata.Version = "v2"
+
+	patch, err = oldRecord.DiffAsPatch(newRecord)
+	if err != nil {
+		t.Fatal(err)
+	}
+	src, _ := json.MarshalIndent(patch, "", "    ")
+	expected := map[string]string{
+		"/metadata/creators/0":                     "add",
+		"/metadata/creators/1/affiliations/0/name": "replace",
+		"/metadata/subjects":                       "remove",
+		"/metadata/version":                        "add",
+	}
+	if len(patch) != len(expected) {
+		t.Errorf("expected %d operations, got %d ->\n%s", len(expected), len(patch), src)
+	}
+	for _, op := range patch {
+		if expected[op.Path] != op.Op {
+			t.Errorf("unexpected operation %s %s ->\n%s", op.Op, op.Path, src)
+		}
+	}
+
+	// Round trip the patch through JSON and apply it.
+	replay := Patch{}
+	if err := json.Unmarshal(src, &replay); err != nil {
+		t.Fatal(err)
+	}
+	if err := oldRecord.ApplyPatch(replay); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(oldRecord, newRecord) {
+		src1, _ := json.MarshalIndent(oldRecord, "", "    ")
+		src2, _ := json.MarshalIndent(newRecord, "", "    ")
+		t.Errorf("expected patched record to match ->\n%s\n=============\n%s", src1, src2)
+	}
+}
+
+func TestApplyPatch(t *testing.T) {
+	rec := testRecord(t)
+	patch := Patch{
+		{Op: "test", Path: "/metadata/title", Value: "Microbially induced precipitation of silica"},
+		{Op: "copy", From: "/metadata/creators/0", Path: "/metadata/creators/-"},
+		{Op: "replace", Path: "/metadata/creators/1/person_or_org/family_name", Value: "Smith"},
+		{Op: "move", From: "/metadata/creators/1", Path: "/metadata/creators/0"},
+		{Op: "add", Path: "/custom_fields/journal:journal/pages", Value: "1-10"},
+		{Op: "remove", Path: "/tombstone"},
+	}
+	if err := rec.ApplyPatch(patch); err != nil {
+		t.Fatal(err)
+	}
+	if len(rec.Metadata.Creators) != 2 {
+		t.Fatalf("expected two creators, got %d", len(rec.Metadata.Creators))
+	}
+	if name := rec.Metadata.Creators[0].PersonOrOrg.FamilyName; name != "Smith" {
+		t.Errorf("expected first creator to be Smith, got %q", name)
+	}
+	if name := rec.Metadata.Creators[1].PersonOrOrg.FamilyName; name != "Orphan" {
+		t.Errorf("expected second creator to be Orphan, got %q", name)
+	}
+	if rec.Tombstone != nil {
+		t.Errorf("expected tombstone to be removed")
+	}
+	journal := rec.CustomFields["journal:journal"].(map[string]interface{})
+	if journal["pages"] != "1-10" {
+		t.Errorf("expected pages to be 1-10, got %v", journal["pages"])
+	}
+
+	// A failing operation leaves the record unchanged.
+	before := rec.ToString()
+	patch = Patch{
+		{Op: "replace", Path: "/metadata/title", Value: "Changed"},
+		{Op: "test", Path: "/metadata/version", Value: "v1"},
+	}
+	if err := rec.ApplyPatch(patch); err == nil {
+		t.Errorf("expected failed test operation to return an error")
+	}
+	if after := rec.ToString(); string(before) != string(after) {
+		t.Errorf("expected record to be unchanged after a failed patch")
+	}
+	for _, op := range []*PatchOperation{
+		{Op: "remove", Path: "/metadata/creators/5"},
+		{Op: "replace", Path: "/metadata/no_such_field", Value: 1},
+		{Op: "add", Path: "/metadata/creators/01", Value: nil},
+		{Op: "move", From: "/metadata", Path: "/metadata/title"},
+		{Op: "frobnicate", Path: "/metadata"},
+	} {
+		if err := rec.ApplyPatch(Patch{op}); err == nil {
+			t.Errorf("expected an error for %s %s", op.Op, op.Path)
+		}
+	}
+}
+
+func TestJSONPointer(t *testing.T) {
+	pointer := jsonPointer("custom_fields", "a/b~c", 3)
+	if pointer != "/custom_fields/a~1b~0c/3" {
+		t.Errorf("unexpected pointer %q", pointer)
+	}
+	tokens, err := parseJSONPointer(pointer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(tokens, []string{"custom_fields", "a/b~c", "3"}) {
+		t.Errorf("unexpected tokens %v", tokens)
+	}
+}
@@ -0,0 +1,746 @@
+package simplified
+
+/**
+ * This file holds the Crosswalk interface and the registry of crosswalks
+ * used to convert records between formats by name. Each of the formats
+ * supported by this package is registered when the package is loaded,
+ * other formats are added by calling RegisterCrosswalk.
+ *
+ * A crosswalk may only read (e.g. the Crossref REST API JSON) or only
+ * write (e.g. Markdown) a format, the other method returns an error.
+ */
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"regexp"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// Crosswalk converts records to and from a metadata format.
+type Crosswalk interface {
+	// Name is the short name of the format, e.g. "bibtex".
+	Name() string
+	// MIMEType is the media type of the format.
+	MIMEType() string
+	// Extensions are the file name extensions used by the format,
+	// including the leading dot, e.g. ".bib".
+	Extensions() []string
+	// Decode reads one or more records.
+	Decode(r io.Reader) ([]*Record, error)
+	// Encode writes a record.
+	Encode(w io.Writer, rec *Record) error
+}
+
+// CrosswalkDetector is implemented by crosswalks which recognize their
+// format from the start of a document.
+type CrosswalkDetector interface {
+	Detect(src []byte) bool
+}
+
+// CrosswalkBatchEncoder is implemented by crosswalks which write a list
+// of records as a single document, e.g. a JSON array or a MARCXML
+// collection.
+type CrosswalkBatchEncoder interface {
+	EncodeAll(w io.Writer, records []*Record) error
+}
+
+// crosswalk implements Crosswalk with functions. A nil decode or
+// encode function means the direction is not supported.
+type crosswalk struct {
+	name       string
+	mimeType   string
+	extensions []string
+	decode     func(src []byte) ([]*Record, error)
+	encode     func(rec *Record) ([]byte, error)
+	encodeAll  func(records []*Record) ([]byte, error)
+	detect     func(src []byte) bool
+	// newReader and newWriter stream records one at a time.
+	newReader func(r io.Reader) RecordReader
+	newWriter func(w io.Writer) RecordWriter
+	// separator is written between records, if empty each record is
+	// written as a document of its own.
+	separator string
+	binary    bool
+}
+
+func (cw *crosswalk) Name() string         { return cw.name }
+func (cw *crosswalk) MIMEType() string     { return cw.mimeType }
+func (cw *crosswalk) Extensions() []string { return append([]string{}, cw.extensions...) }
+
+// Decode reads one or more records.
+func (cw *crosswalk) Decode(r io.Reader) ([]*Record, error) {
+	if cw.decode == nil {
+		return nil, fmt.Errorf("%s, reading is not supported", cw.name)
+	}
+	src, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return cw.decode(src)
+}
+
+// Encode writes a record. Text formats end with a newline.
+func (cw *crosswalk) Encode(w io.Writer, rec *Record) error {
+	if cw.encode == nil {
+		return fmt.Errorf("%s, writing is not supported", cw.name)
+	}
+	if rec == nil {
+		return fmt.Errorf("no record")
+	}
+	src, err := cw.encode(rec)
+	if err != nil {
+		return err
+	}
+	return cw.write(w, src)
+}
+
+// EncodeAll writes the records. Formats without a list form and no
+// separator between records can only hold a single record.
+func (cw *crosswalk) EncodeAll(w io.Writer, records []*Record) error {
+	switch {
+	case cw.encodeAll != nil:
+		src, err := cw.encodeAll(records)
+		if err != nil {
+			return err
+		}
+		return cw.write(w, src)
+	case len(records) == 1:
+		return cw.Encode(w, records[0])
+	case cw.separator == "" && !cw.binary:
+		return fmt.Errorf("%s, can only write a single record, found %d", cw.name, len(records))
+	}
+	for i, rec := range records {
+		if i > 0 {
+			if _, err := io.WriteString(w, cw.separator); err != nil {
+				return err
+			}
+		}
+		if err := cw.Encode(w, rec); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (cw *crosswalk) write(w io.Writer, src []byte) error {
+	if !cw.binary && !bytes.HasSuffix(src, []byte("\n")) {
+		src = append(src, '\n')
+	}
+	_, err := w.Write(src)
+	return err
+}
+
+// Detect reports if the document is in the crosswalk's format.
+func (cw *crosswalk) Detect(src []byte) bool {
+	return cw.decode != nil && cw.detect != nil && cw.detect(src)
+}
+
+var (
+	crosswalksMu sync.RWMutex
+	crosswalks   = []Crosswalk{}
+)
+
+// RegisterCrosswalk adds a crosswalk to the registry. A crosswalk with
+// the same name replaces the one registered before it.
+//
+// ```
+//
+//	simplified.RegisterCrosswalk(myFormat)
+//	cw, ok := simplified.LookupCrosswalk("myformat")
+//
+// ```
+func RegisterCrosswalk(cw Crosswalk) {
+	crosswalksMu.Lock()
+	defer crosswalksMu.Unlock()
+	for i, c := range crosswalks {
+		if strings.EqualFold(c.Name(), cw.Name()) {
+			crosswalks[i] = cw
+			return
+		}
+	}
+	crosswalks = append(crosswalks, cw)
+}
+
+// Crosswalks returns the registered crosswalks in the order they were
+// registered.
+func Crosswalks() []Crosswalk {
+	crosswalksMu.RLock()
+	defer crosswalksMu.RUnlock()
+	return append([]Crosswalk{}, crosswalks...)
+}
+
+// LookupCrosswalk returns the crosswalk registered with the name or
+// MIME type, ignoring case.
+//
+// ```
+//
+//	cw, ok := simplified.LookupCrosswalk("ris")
+//	if ! ok {
+//	    // ... handle unknown format ...
+//	}
+//	records, err := cw.Decode(os.Stdin)
+//
+// ```
+func LookupCrosswalk(name string) (Crosswalk, bool) {
+	for _, cw := range Crosswalks() {
+		if strings.EqualFold(cw.Name(), name) {
+			return cw, true
+		}
+	}
+	for _, cw := range Crosswalks() {
+		if strings.EqualFold(cw.MIMEType(), name) {
+			return cw, true
+		}
+	}
+	return nil, false
+}
+
+// detects reports if the crosswalk can read and recognizes the document.
+func detects(cw Crosswalk, src []byte) bool {
+	d, ok := cw.(CrosswalkDetector)
+	return ok && d.Detect(src)
+}
+
+// DetectCrosswalk returns the crosswalk to read a document with. The
+// crosswalks whose extension matches the end of fName are tried first,
+// the longest extension winning, e.g. ".csl.json" before ".json". The
+// content is then used to choose between them or, when it clearly
+// belongs to another format, over the extension. Use an empty fName
+// (or "-" for standard input) to detect from the content alone.
+//
+// ```
+//
+//	src, err := os.ReadFile("work.json")
+//	// ... handle error ...
+//	cw, err := simplified.DetectCrosswalk("work.json", src)
+//	// ... handle error ...
+//	records, err := cw.Decode(bytes.NewReader(src))
+//
+// ```
+func DetectCrosswalk(fName string, src []byte) (Crosswalk, error) {
+	all := Crosswalks()
+	candidates, longest := []Crosswalk{}, 0
+	if base := strings.ToLower(path.Base(fName)); fName != "" && fName != "-" {
+		for _, cw := range all {
+			for _, ext := range cw.Extensions() {
+				ext = strings.ToLower(ext)
+				if !strings.HasSuffix("."+base, ext) || len(ext) < longest {
+					continue
+				}
+				if len(ext) > longest {
+					candidates, longest = []Crosswalk{}, len(ext)
+				}
+				candidates = append(candidates, cw)
+				break
+			}
+		}
+	}
+	for _, l := range [][]Crosswalk{candidates, all} {
+		for _, cw := range l {
+			if detects(cw, src) {
+				return cw, nil
+			}
+		}
+	}
+	if len(candidates) > 0 {
+		return candidates[0], nil
+	}
+	if fName == "" || fName == "-" {
+		return nil, fmt.Errorf("could not detect the input format")
+	}
+	return nil, fmt.Errorf("%s, could not detect the input format", fName)
+}
+
+// EncodeRecords writes the records with the crosswalk. Crosswalks
+// implementing CrosswalkBatchEncoder write the records as one
+// document, otherwise each record is encoded in turn.
+func EncodeRecords(cw Crosswalk, w io.Writer, records []*Record) error {
+	if b, ok := cw.(CrosswalkBatchEncoder); ok {
+		return b.EncodeAll(w, records)
+	}
+	for _, rec := range records {
+		if err := cw.Encode(w, rec); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RecordReader reads records one at a time, e.g. RISReader or
+// JSONLReader. Read returns io.EOF when there are no more records.
+type RecordReader interface {
+	Read() (*Record, error)
+}
+
+// RecordWriter writes records one at a time, e.g. JSONLWriter. Close
+// ends the output.
+type RecordWriter interface {
+	Write(rec *Record) error
+	Close() error
+}
+
+// RecordEncoder is implemented by RecordWriters which encode each
+// record on its own. EncodeRecord is safe for concurrent use so records
+// can be encoded in parallel then written in order with WriteEncoded.
+type RecordEncoder interface {
+	EncodeRecord(rec *Record) ([]byte, error)
+	WriteEncoded(src []byte) error
+}
+
+// sliceReader returns decoded records one at a time. The input is
+// decoded on the first call to Read.
+type sliceReader struct {
+	decode  func() ([]*Record, error)
+	records []*Record
+	done    bool
+}
+
+func (r *sliceReader) Read() (*Record, error) {
+	if !r.done {
+		r.done = true
+		records, err := r.decode()
+		if err != nil {
+			return nil, err
+		}
+		r.records = records
+	}
+	if len(r.records) == 0 {
+		return nil, io.EOF
+	}
+	rec := r.records[0]
+	r.records = r.records[1:]
+	return rec, nil
+}
+
+// NewRecordReader returns a RecordReader for the crosswalk. JSON
+// arrays, JSON Lines and RIS are read one record at a time, other
+// formats are decoded in full then returned one record at a time.
+//
+// ```
+//
+//	cw, _ := simplified.LookupCrosswalk("jsonl")
+//	reader := simplified.NewRecordReader(cw, os.Stdin)
+//	for {
+//	    rec, err := reader.Read()
+//	    if err == io.EOF {
+//	        break
+//	    }
+//	    // ... handle error ...
+//	}
+//
+// ```
+func NewRecordReader(cw Crosswalk, r io.Reader) RecordReader {
+	if c, ok := cw.(*crosswalk); ok && c.newReader != nil {
+		return c.newReader(r)
+	}
+	return &sliceReader{decode: func() ([]*Record, error) {
+		return cw.Decode(r)
+	}}
+}
+
+// batchWriter holds the records until Close for formats written as a
+// single document, e.g. a MARCXML collection.
+type batchWriter struct {
+	cw      CrosswalkBatchEncoder
+	out     io.Writer
+	records []*Record
+}
+
+func (w *batchWriter) Write(rec *Record) error {
+	w.records = append(w.records, rec)
+	return nil
+}
+
+func (w *batchWriter) Close() error {
+	if len(w.records) == 0 {
+		return nil
+	}
+	return w.cw.EncodeAll(w.out, w.records)
+}
+
+// encodeWriter encodes each record in turn.
+type encodeWriter struct {
+	cw    Crosswalk
+	out   io.Writer
+	count int
+}
+
+// EncodeRecord encodes the record with the crosswalk.
+func (w *encodeWriter) EncodeRecord(rec *Record) ([]byte, error) {
+	buf := new(bytes.Buffer)
+	if err := w.cw.Encode(buf, rec); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// WriteEncoded writes an encoded record, separated from the record
+// before it.
+func (w *encodeWriter) WriteEncoded(src []byte) error {
+	if c, ok := w.cw.(*crosswalk); ok && w.count > 0 {
+		if c.separator == "" && !c.binary {
+			return fmt.Errorf("%s, can only write a single record", c.name)
+		}
+		if _, err := io.WriteString(w.out, c.separator); err != nil {
+			return err
+		}
+	}
+	if _, err := w.out.Write(src); err != nil {
+		return err
+	}
+	w.count++
+	return nil
+}
+
+func (w *encodeWriter) Write(rec *Record) error {
+	src, err := w.EncodeRecord(rec)
+	if err != nil {
+		return err
+	}
+	return w.WriteEncoded(src)
+}
+
+func (w *encodeWriter) Close() error {
+	return nil
+}
+
+// NewRecordWriter returns a RecordWriter for the crosswalk. JSON is
+// written as an array, JSON Lines, BibTeX, RIS, Markdown and MARC21 are
+// written a record at a time. Formats which hold a list of records in
+// one document, e.g. MARCXML, are written when the writer is closed.
+// Other formats can only write a single record.
+//
+// ```
+//
+//	cw, _ := simplified.LookupCrosswalk("bibtex")
+//	writer := simplified.NewRecordWriter(cw, os.Stdout)
+//	for _, rec := range records {
+//	    // ... handle error ...
+//	    writer.Write(rec)
+//	}
+//	err := writer.Close()
+//
+// ```
+func NewRecordWriter(cw Crosswalk, w io.Writer) RecordWriter {
+	if c, ok := cw.(*crosswalk); ok {
+		switch {
+		case c.newWriter != nil:
+			return c.newWriter(w)
+		case c.encodeAll != nil && c.separator == "" && !c.binary:
+			return &batchWriter{cw: c, out: w}
+		}
+	}
+	return &encodeWriter{cw: cw, out: w}
+}
+
+//
+// The crosswalks of this package
+//
+
+// one adapts a function reading a single record.
+func one(fn func([]byte) (*Record, error)) func([]byte) ([]*Record, error) {
+	return func(src []byte) ([]*Record, error) {
+		rec, err := fn(src)
+		if err != nil {
+			return nil, err
+		}
+		return []*Record{rec}, nil
+	}
+}
+
+// oneOrArray adapts a function reading a single JSON object so it also
+// reads a JSON array of objects.
+func oneOrArray(fn func([]byte) (*Record, error)) func([]byte) ([]*Record, error) {
+	return func(src []byte) ([]*Record, error) {
+		src = bytes.TrimSpace(src)
+		if !bytes.HasPrefix(src, []byte("[")) {
+			return one(fn)(src)
+		}
+		l := []json.RawMessage{}
+		if err := json.Unmarshal(src, &l); err != nil {
+			return nil, err
+		}
+		records := []*Record{}
+		for _, item := range l {
+			rec, err := fn(item)
+			if err != nil {
+				return nil, err
+			}
+			records = append(records, rec)
+		}
+		return records, nil
+	}
+}
+
+// text adapts a method returning the text of a record.
+func text(fn func(*Record) []byte) func(*Record) ([]byte, error) {
+	return func(rec *Record) ([]byte, error) {
+		return fn(rec), nil
+	}
+}
+
+// jsonKeys returns the members of a JSON object, or of the first object
+// of a JSON array. It returns nil if src is neither.
+func jsonKeys(src []byte) map[string]json.RawMessage {
+	src = bytes.TrimSpace(src)
+	if bytes.HasPrefix(src, []byte("[")) {
+		l := []json.RawMessage{}
+		if err := json.Unmarshal(src, &l); err != nil || len(l) == 0 {
+			return nil
+		}
+		src = l[0]
+	}
+	keys := map[string]json.RawMessage{}
+	if err := json.Unmarshal(src, &keys); err != nil {
+		return nil
+	}
+	return keys
+}
+
+// hasKeys reports if the JSON document has all the keys.
+func hasKeys(src []byte, names ...string) bool {
+	keys := jsonKeys(src)
+	if keys == nil {
+		return false
+	}
+	for _, name := range names {
+		if _, ok := keys[name]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// containsXML reports if the XML document uses the namespace.
+func containsXML(namespace string) func([]byte) bool {
+	return func(src []byte) bool {
+		return bytes.HasPrefix(bytes.TrimSpace(src), []byte("<")) && bytes.Contains(src, []byte(namespace))
+	}
+}
+
+var (
+	reDetectBibTeX = regexp.MustCompile(`(?m)^\s*@[A-Za-z]+\s*[{(]`)
+	reDetectRIS    = regexp.MustCompile(`(?m)^TY  - `)
+	reDetectCFF    = regexp.MustCompile(`(?m)^cff-version:`)
+	reDetectMARC21 = regexp.MustCompile(`^[0-9]{5}[a-z ][a-z][a-z ]`)
+)
+
+func init() {
+	for _, cw := range []*crosswalk{
+		{
+			name: "json", mimeType: "application/json", extensions: []string{".json"},
+			decode: func(src []byte) ([]*Record, error) {
+				records := []*Record{}
+				if bytes.HasPrefix(bytes.TrimSpace(src), []byte("[")) {
+					err := json.Unmarshal(src, &records)
+					return records, err
+				}
+				rec := new(Record)
+				if err := json.Unmarshal(src, &rec); err != nil {
+					return nil, err
+				}
+				return append(records, rec), nil
+			},
+			encode: text((*Record).ToString),
+			encodeAll: func(records []*Record) ([]byte, error) {
+				return json.MarshalIndent(records, "", "    ")
+			},
+			detect: func(src []byte) bool {
+				if hasKeys(src, "metadata") || hasKeys(src, "pids") || hasKeys(src, "access") {
+					return true
+				}
+				// The start of a large array may be all that is available.
+				src = bytes.TrimSpace(src)
+				return bytes.HasPrefix(src, []byte("[")) && jsonKeys(src) == nil && bytes.Contains(src, []byte(`"metadata":`))
+			},
+			newReader: func(r io.Reader) RecordReader {
+				in := bufio.NewReader(r)
+				for {
+					c, err := in.Peek(1)
+					if err != nil || !unicode.IsSpace(rune(c[0])) {
+						break
+					}
+					in.Discard(1)
+				}
+				if c, err := in.Peek(1); err == nil && c[0] == '[' {
+					return NewJSONLReader(in)
+				}
+				return &sliceReader{decode: func() ([]*Record, error) {
+					rec := new(Record)
+					if err := json.NewDecoder(in).Decode(&rec); err != nil {
+						return nil, err
+					}
+					return []*Record{rec}, nil
+				}}
+			},
+			newWriter: func(w io.Writer) RecordWriter {
+				return NewJSONArrayWriter(w)
+			},
+		},
+		{
+			name: "jsonl", mimeType: "application/x-ndjson", extensions: []string{".jsonl", ".ndjson"},
+			decode: func(src []byte) ([]*Record, error) {
+				records := []*Record{}
+				reader := NewJSONLReader(bytes.NewReader(src))
+				for {
+					rec, err := reader.Read()
+					if err == io.EOF {
+						return records, nil
+					}
+					if err != nil {
+						return nil, err
+					}
+					records = append(records, rec)
+				}
+			},
+			encode: func(rec *Record) ([]byte, error) {
+				return json.Marshal(rec)
+			},
+			encodeAll: func(records []*Record) ([]byte, error) {
+				buf := new(bytes.Buffer)
+				w := NewJSONLWriter(buf)
+				for _, rec := range records {
+					if err := w.Write(rec); err != nil {
+						return nil, err
+					}
+				}
+				return buf.Bytes(), nil
+			},
+			detect: func(src []byte) bool {
+				first, rest, _ := bytes.Cut(bytes.TrimSpace(src), []byte("\n"))
+				return len(bytes.TrimSpace(rest)) > 0 && (hasKeys(first, "metadata") || hasKeys(first, "pids") || hasKeys(first, "access"))
+			},
+			newReader: func(r io.Reader) RecordReader {
+				return NewJSONLReader(r)
+			},
+			newWriter: func(w io.Writer) RecordWriter {
+				return NewJSONLWriter(w)
+			},
+		},
+		{
+			name: "bibtex", mimeType: "application/x-bibtex", extensions: []string{".bib", ".bibtex"},
+			decode: ParseBibTeX, encode: text((*Record).AsBibTeX), separator: "\n",
+			detect: reDetectBibTeX.Match,
+		},
+		{
+			name: "ris", mimeType: "application/x-research-info-systems", extensions: []string{".ris"},
+			decode: func(src []byte) ([]*Record, error) {
+				return NewRISReader(bytes.NewReader(src)).ReadAll()
+			},
+			newReader: func(r io.Reader) RecordReader {
+				return NewRISReader(r)
+			},
+			encode: text((*Record).AsRIS), separator: "\n",
+			detect: reDetectRIS.Match,
+		},
+		{
+			name: "csl", mimeType: "application/vnd.citationstyles.csl+json", extensions: []string{".csl.json", ".csl"},
+			decode: oneOrArray(RecordFromCSLJSON), encode: (*Record).AsCSLJSON,
+			encodeAll: func(records []*Record) ([]byte, error) {
+				items := []json.RawMessage{}
+				for _, rec := range records {
+					src, err := rec.AsCSLJSON()
+					if err != nil {
+						return nil, err
+					}
+					items = append(items, src)
+				}
+				return json.MarshalIndent(items, "", "    ")
+			},
+			detect: func(src []byte) bool {
+				keys := jsonKeys(src)
+				title := bytes.TrimSpace(keys["title"])
+				return keys != nil && keys["type"] != nil && keys["id"] != nil && (len(title) == 0 || title[0] == '"')
+			},
+		},
+		{
+			name: "datacite", mimeType: "application/vnd.datacite.datacite+json", extensions: []string{".datacite.json"},
+			decode: RecordsFromDataCiteJSON, encode: (*Record).AsDataCiteJSON,
+			detect: func(src []byte) bool {
+				keys := jsonKeys(src)
+				return keys != nil && ((keys["data"] != nil && bytes.Contains(keys["data"], []byte(`"attributes"`))) ||
+					(keys["titles"] != nil && keys["creators"] != nil))
+			},
+		},
+		{
+			name: "datacite-xml", mimeType: "application/vnd.datacite.datacite+xml", extensions: []string{".datacite.xml"},
+			decode: one(RecordFromDataCiteXML), encode: (*Record).AsDataCiteXML,
+			detect: containsXML(DataCiteNamespace),
+		},
+		{
+			name: "dublincore", mimeType: "application/oai_dc+xml", extensions: []string{".dc.xml"},
+			decode: one(RecordFromDublinCore), encode: (*Record).AsDublinCore,
+			detect: containsXML(OAIDCNamespace),
+		},
+		{
+			name: "mods", mimeType: "application/mods+xml", extensions: []string{".mods", ".mods.xml"},
+			decode: one(RecordFromMODS), encode: (*Record).AsMODS,
+			detect: containsXML(MODSNamespace),
+		},
+		{
+			name: "marcxml", mimeType: "application/marcxml+xml", extensions: []string{".marcxml", ".marc.xml"},
+			decode: RecordsFromMARCXML, encode: (*Record).AsMARCXML,
+			encodeAll: func(records []*Record) ([]byte, error) {
+				return MARCXMLCollection(records...)
+			},
+			detect: containsXML(MARCXMLNamespace),
+		},
+		{
+			name: "marc21", mimeType: "application/marc", extensions: []string{".mrc", ".marc"},
+			decode: RecordsFromMARC21, encode: (*Record).AsMARC21, binary: true,
+			detect: func(src []byte) bool {
+				return reDetectMARC21.Match(src) && bytes.IndexByte(src, marcRecordTerminator) > 0
+			},
+		},
+		{
+			name: "codemeta", mimeType: "application/vnd.codemeta+ld+json", extensions: []string{".codemeta.json"},
+			decode: one(RecordFromCodeMeta), encode: (*Record).AsCodeMeta,
+			detect: func(src []byte) bool {
+				keys := jsonKeys(src)
+				return keys != nil && bytes.Contains(keys["@context"], []byte("codemeta"))
+			},
+		},
+		{
+			name: "cff", mimeType: "application/x-yaml", extensions: []string{".cff"},
+			decode: one(RecordFromCFF), encode: (*Record).AsCFF,
+			detect: reDetectCFF.Match,
+		},
+		{
+			name: "crossref", mimeType: "application/vnd.crossref-api-message+json", extensions: []string{".crossref.json"},
+			decode: RecordsFromCrossrefJSON,
+			detect: func(src []byte) bool {
+				keys := jsonKeys(src)
+				title := bytes.TrimSpace(keys["title"])
+				return keys != nil && (keys["message-type"] != nil || (keys["DOI"] != nil && len(title) > 0 && title[0] == '['))
+			},
+		},
+		{
+			name: "crossref-xml", mimeType: "application/vnd.crossref.deposit+xml", extensions: []string{".crossref.xml"},
+			encode: (*Record).AsCrossrefDeposit,
+			encodeAll: func(records []*Record) ([]byte, error) {
+				return CrossrefDeposit(nil, records...)
+			},
+		},
+		{
+			name: "schemaorg", mimeType: "application/ld+json", extensions: []string{".jsonld"},
+			encode: (*Record).AsSchemaOrg,
+		},
+		{
+			name: "markdown", mimeType: "text/markdown", extensions: []string{".md"},
+			encode: text((*Record).AsMarkdown), separator: "\n",
+		},
+		{
+			name: "eprints", mimeType: "application/xml", extensions: []string{".eprints.xml"},
+			encode: (*Record).AsEPrintsXML,
+		},
+	} {
+		RegisterCrosswalk(cw)
+	}
+}
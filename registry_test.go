@@ -0,0 +1,118 @@
+package simplified
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestDetectCrosswalk(t *testing.T) {
+	rec := testRecord(t)
+	// Each readable format is recognized from the content it writes.
+	for _, name := range []string{"json", "bibtex", "ris", "csl", "datacite", "datacite-xml", "dublincore", "mods", "marcxml", "marc21", "codemeta", "cff"} {
+		cw, ok := LookupCrosswalk(name)
+		if !ok {
+			t.Errorf("expected %q to be registered", name)
+			continue
+		}
+		buf := new(bytes.Buffer)
+		if err := cw.Encode(buf, rec); err != nil {
+			t.Errorf("%s, %s", name, err)
+			continue
+		}
+		detected, err := DetectCrosswalk("-", buf.Bytes())
+		if err != nil {
+			t.Errorf("%s, %s", name, err)
+			continue
+		}
+		if detected.Name() != name {
+			t.Errorf("expected %q to be detected, got %q", name, detected.Name())
+		}
+		records, err := detected.Decode(bytes.NewReader(buf.Bytes()))
+		if err != nil || len(records) != 1 || records[0].Metadata.Title != rec.Metadata.Title {
+			t.Errorf("%s, expected the record to be read back, %v", name, err)
+		}
+	}
+	cw, err := DetectCrosswalk("work.json", crossrefTestWork)
+	if err != nil || cw.Name() != "crossref" {
+		t.Errorf("expected the content to win over a generic extension, got %v %v", cw, err)
+	}
+	cw, err = DetectCrosswalk("references.csl.json", []byte(`[]`))
+	if err != nil || cw.Name() != "csl" {
+		t.Errorf("expected the longest extension to win, got %v %v", cw, err)
+	}
+	if _, err := DetectCrosswalk("notes.txt", []byte("plain text")); err == nil {
+		t.Errorf("expected an error for an unknown format")
+	}
+}
+
+func TestEncodeRecords(t *testing.T) {
+	records, err := ParseBibTeX([]byte(`@article{a, title={First}, year={2020}}
+@article{b, title={Second}, year={2021}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cw, _ := LookupCrosswalk("application/json")
+	buf := new(bytes.Buffer)
+	if err := EncodeRecords(cw, buf, records); err != nil {
+		t.Fatal(err)
+	}
+	l := []*Record{}
+	if err := json.Unmarshal(buf.Bytes(), &l); err != nil || len(l) != 2 {
+		t.Errorf("expected a JSON array of two records, %v\n%s", err, buf.Bytes())
+	}
+	cw, _ = LookupCrosswalk("MARCXML")
+	buf.Reset()
+	if err := EncodeRecords(cw, buf, records); err != nil {
+		t.Fatal(err)
+	}
+	if read, err := RecordsFromMARCXML(buf.Bytes()); err != nil || len(read) != 2 || read[1].Metadata.Title != "Second" {
+		t.Errorf("expected a MARCXML collection, %v\n%s", err, buf.Bytes())
+	}
+	cw, _ = LookupCrosswalk("mods")
+	if err := EncodeRecords(cw, io.Discard, records); err == nil {
+		t.Errorf("expected an error writing two records as MODS")
+	}
+	// Formats sharing a JSON-LD syntax are told apart by MIME type.
+	for mimeType, name := range map[string]string{"application/ld+json": "schemaorg", "application/vnd.codemeta+ld+json": "codemeta"} {
+		if cw, ok := LookupCrosswalk(mimeType); !ok || cw.Name() != name {
+			t.Errorf("expected %s for %q, got %v", name, mimeType, cw)
+		}
+	}
+	cw, _ = LookupCrosswalk("markdown")
+	if _, err := cw.Decode(strings.NewReader("# Title")); err == nil {
+		t.Errorf("expected an error reading Markdown")
+	}
+}
+
+func TestRegisterCrosswalk(t *testing.T) {
+	n := len(Crosswalks())
+	upper := &crosswalk{
+		name: "title", mimeType: "text/plain", extensions: []string{".title"},
+		encode: func(rec *Record) ([]byte, error) {
+			return []byte(strings.ToUpper(rec.Metadata.Title)), nil
+		},
+	}
+	RegisterCrosswalk(upper)
+	defer func() {
+		crosswalksMu.Lock()
+		crosswalks = crosswalks[:n]
+		crosswalksMu.Unlock()
+	}()
+	RegisterCrosswalk(upper)
+	if len(Crosswalks()) != n+1 {
+		t.Errorf("expected registering the same name to replace the crosswalk")
+	}
+	cw, ok := LookupCrosswalk("Title")
+	if !ok {
+		t.Fatal("expected the crosswalk to be found")
+	}
+	rec := new(Record)
+	ensureMetadata(rec).Title = "a title"
+	buf := new(bytes.Buffer)
+	if err := cw.Encode(buf, rec); err != nil || buf.String() != "A TITLE\n" {
+		t.Errorf("unexpected output %q, %v", buf.String(), err)
+	}
+}
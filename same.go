@@ -0,0 +1,454 @@
+package simplified
+
+/**
+ * This file implements IsSame() for the simplified structs. IsSame
+ * checks if two values are semantically the same, e.g. ignoring
+ * whitespace differences, the order of lists where the order isn't
+ * meaningful (subjects, identifiers), the case of identifiers like
+ * DOI and treating nil and empty lists as the same.
+ */
+
+import (
+	"reflect"
+	"strings"
+)
+
+// sameString compares two strings ignoring leading, trailing and
+// repeated whitespace.
+func sameString(a string, b string) bool {
+	return strings.Join(strings.Fields(a), " ") == strings.Join(strings.Fields(b), " ")
+}
+
+// sameFold compares two strings ignoring whitespace and case.
+func sameFold(a string, b string) bool {
+	return strings.EqualFold(strings.Join(strings.Fields(a), " "), strings.Join(strings.Fields(b), " "))
+}
+
+// sameStringMap compares localized strings, e.g. titles, ignoring
+// whitespace. Empty values are treated as missing.
+func sameStringMap(a map[string]string, b map[string]string) bool {
+	for k, v := range a {
+		if !sameString(v, b[k]) {
+			return false
+		}
+	}
+	for k, v := range b {
+		if !sameString(v, a[k]) {
+			return false
+		}
+	}
+	return true
+}
+
+// sameJSON compares two values by their JSON representation. Nil and
+// empty lists, maps and structs are the same as they are both omitted.
+func sameJSON(a interface{}, b interface{}) bool {
+	if reflect.DeepEqual(a, b) {
+		return true
+	}
+	aVal, err := toJSONValue(a)
+	if err != nil {
+		return false
+	}
+	bVal, err := toJSONValue(b)
+	if err != nil {
+		return false
+	}
+	return reflect.DeepEqual(pruneJSONValue(aVal), pruneJSONValue(bVal))
+}
+
+// pruneJSONValue replaces empty objects and arrays in a decoded JSON
+// value with nil and removes the object members left nil, e.g. a
+// struct with only empty fields becomes nil.
+func pruneJSONValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		m := map[string]interface{}{}
+		for k, val := range v {
+			if val = pruneJSONValue(val); val != nil {
+				m[k] = val
+			}
+		}
+		if len(m) == 0 {
+			return nil
+		}
+		return m
+	case []interface{}:
+		if len(v) == 0 {
+			return nil
+		}
+		l := make([]interface{}, len(v))
+		for i, val := range v {
+			l[i] = pruneJSONValue(val)
+		}
+		return l
+	}
+	return v
+}
+
+// samePtr handles the nil cases when comparing two pointers. If done
+// is true then same holds the result.
+func samePtr[T any](a *T, b *T) (same bool, done bool) {
+	if a == nil || b == nil {
+		return a == nil && b == nil, true
+	}
+	if a == b {
+		return true, true
+	}
+	return false, false
+}
+
+// sameList compares two lists element by element in order.
+func sameList[T any](a []T, b []T, same func(T, T) bool) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !same(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// sameSet compares two lists ignoring the order of the elements.
+func sameSet[T any](a []T, b []T, same func(T, T) bool) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	used := make([]bool, len(b))
+	for _, elem := range a {
+		found := false
+		for j := range b {
+			if !used[j] && same(elem, b[j]) {
+				used[j], found = true, true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// sameValue compares two values of the same type the way IsSame does.
+// Strings are compared with sameString, values with an IsSame method
+// use it, lists and maps are compared element by element and anything
+// else is compared as JSON, so nil and empty values are the same.
+func sameValue(a interface{}, b interface{}) bool {
+	if s, ok := a.(string); ok {
+		if t, ok := b.(string); ok {
+			return sameString(s, t)
+		}
+	}
+	aVal, bVal := reflect.ValueOf(a), reflect.ValueOf(b)
+	if !aVal.IsValid() || !bVal.IsValid() || aVal.Type() != bVal.Type() {
+		return sameJSON(a, b)
+	}
+	if isSame := aVal.MethodByName("IsSame"); isSame.IsValid() && isSame.Type().NumIn() == 1 && isSame.Type().In(0) == bVal.Type() && isSame.Type().NumOut() == 1 && isSame.Type().Out(0).Kind() == reflect.Bool {
+		return isSame.Call([]reflect.Value{bVal})[0].Bool()
+	}
+	switch aVal.Kind() {
+	case reflect.Slice:
+		if aVal.Len() != bVal.Len() {
+			return false
+		}
+		for i := 0; i < aVal.Len(); i++ {
+			if !sameValue(aVal.Index(i).Interface(), bVal.Index(i).Interface()) {
+				return false
+			}
+		}
+		return true
+	case reflect.Map:
+		zero := reflect.Zero(aVal.Type().Elem())
+		for _, m := range []reflect.Value{aVal, bVal} {
+			for _, k := range m.MapKeys() {
+				aElem, bElem := aVal.MapIndex(k), bVal.MapIndex(k)
+				if !aElem.IsValid() {
+					aElem = zero
+				}
+				if !bElem.IsValid() {
+					bElem = zero
+				}
+				if !sameValue(aElem.Interface(), bElem.Interface()) {
+					return false
+				}
+			}
+		}
+		return true
+	}
+	return sameJSON(a, b)
+}
+
+// normalizeIdentifierValue prepares an identifier value for comparison
+// based on its scheme. DOI, ORCID, ISNI, ROR and similar identifiers
+// are case insensitive, valid identifiers are first reduced to their
+// canonical form (see NormalizeIdentifier). Only the scheme and host of
+// a URL and the prefix of a handle are case insensitive, arXiv ids are
+// case sensitive.
+func normalizeIdentifierValue(scheme string, value string) string {
+	if s, err := NormalizeIdentifier(scheme, value); err == nil {
+		value = s
+	}
+	value = strings.TrimSpace(value)
+	switch strings.ToLower(strings.TrimSpace(scheme)) {
+	case "doi":
+		value = strings.ToLower(value)
+		for _, prefix := range []string{"https://doi.org/", "http://doi.org/", "https://dx.doi.org/", "http://dx.doi.org/", "doi:"} {
+			value = strings.TrimPrefix(value, prefix)
+		}
+	case "orcid", "isni", "ror", "issn", "isbn", "pmcid":
+		value = strings.ToLower(value)
+	case "handle":
+		if prefix, suffix, ok := strings.Cut(value, "/"); ok {
+			value = strings.ToLower(prefix) + "/" + suffix
+		}
+	}
+	return value
+}
+
+// IsSame checks if two records are the same.
+func (rec *Record) IsSame(t *Record) bool {
+	if same, done := samePtr(rec, t); done {
+		return same
+	}
+	return sameString(rec.Schema, t.Schema) &&
+		sameString(rec.ID, t.ID) &&
+		sameJSON(rec.Parent, t.Parent) &&
+		samePIDs(rec.ExternalPIDs, t.ExternalPIDs) &&
+		rec.Metadata.IsSame(t.Metadata) &&
+		sameJSON(rec.Files, t.Files) &&
+		sameJSON(rec.RecordAccess, t.RecordAccess) &&
+		sameJSON(rec.CustomFields, t.CustomFields) &&
+		sameJSON(rec.Tombstone, t.Tombstone) &&
+		rec.Created.Equal(t.Created) &&
+		rec.Updated.Equal(t.Updated) &&
+		sameJSON(rec.Versions, t.Versions) &&
+		sameJSON(rec.Links, t.Links)
+}
+
+// samePIDs compares the external persistent identifiers of two records.
+func samePIDs(a map[string]*PersistentIdentifier, b map[string]*PersistentIdentifier) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for scheme, pid := range a {
+		other, ok := b[scheme]
+		if !ok {
+			return false
+		}
+		if !pid.IsSame(other) {
+			return false
+		}
+	}
+	return true
+}
+
+// IsSame checks if two Metadata are the same. The order of creators
+// and contributors is significant, the order of subjects, identifiers,
+// related identifiers, rights, dates, languages and funding is not.
+func (m *Metadata) IsSame(t *Metadata) bool {
+	if same, done := samePtr(m, t); done {
+		return same
+	}
+	return sameJSON(m.ResourceType, t.ResourceType) &&
+		sameList(m.Creators, t.Creators, (*Creator).IsSame) &&
+		sameString(m.Title, t.Title) &&
+		sameString(m.PublicationDate, t.PublicationDate) &&
+		sameList(m.AdditionalTitles, t.AdditionalTitles, (*TitleDetail).IsSame) &&
+		sameString(m.Description, t.Description) &&
+		sameList(m.AdditionalDescriptions, t.AdditionalDescriptions, (*Description).IsSame) &&
+		sameSet(m.Rights, t.Rights, (*Right).IsSame) &&
+		sameList(m.Contributors, t.Contributors, (*Creator).IsSame) &&
+		sameSet(m.Subjects, t.Subjects, (*Subject).IsSame) &&
+		sameSet(m.Languages, t.Languages, func(a map[string]interface{}, b map[string]interface{}) bool { return sameJSON(a, b) }) &&
+		sameSet(m.Dates, t.Dates, (*DateType).IsSame) &&
+		sameString(m.Version, t.Version) &&
+		sameString(m.Publisher, t.Publisher) &&
+		sameSet(m.Identifiers, t.Identifiers, (*Identifier).IsSame) &&
+		sameSet(m.RelatedIdentifiers, t.RelatedIdentifiers, (*Identifier).IsSame) &&
+		sameSet(m.Funding, t.Funding, (*Funder).IsSame)
+}
+
+// IsSame checks if two creators are the same, the order of affiliations
+// is not significant.
+func (c *Creator) IsSame(t *Creator) bool {
+	if same, done := samePtr(c, t); done {
+		return same
+	}
+	return c.PersonOrOrg.IsSame(t.PersonOrOrg) &&
+		c.Role.IsSame(t.Role) &&
+		sameSet(c.Affiliations, t.Affiliations, (*Affiliation).IsSame)
+}
+
+// IsSame checks if two roles are the same.
+func (r *Role) IsSame(t *Role) bool {
+	if same, done := samePtr(r, t); done {
+		return same
+	}
+	return sameFold(r.ID, t.ID) && sameStringMap(r.Title, t.Title) && sameStringMap(r.Props, t.Props)
+}
+
+// IsSame checks if two PersonOrOrg are the same, the order of identifiers
+// is not significant.
+func (p *PersonOrOrg) IsSame(t *PersonOrOrg) bool {
+	if same, done := samePtr(p, t); done {
+		return same
+	}
+	return sameString(p.ID, t.ID) &&
+		sameFold(p.Type, t.Type) &&
+		sameString(p.GivenName, t.GivenName) &&
+		sameString(p.FamilyName, t.FamilyName) &&
+		sameString(p.Name, t.Name) &&
+		sameSet(p.Identifiers, t.Identifiers, (*Identifier).IsSame) &&
+		p.Role.IsSame(t.Role)
+}
+
+// IsSame checks if two affiliations are the same.
+func (a *Affiliation) IsSame(t *Affiliation) bool {
+	if same, done := samePtr(a, t); done {
+		return same
+	}
+	return sameFold(a.ID, t.ID) && sameString(a.Name, t.Name) && normalizeIdentifierValue("ror", a.ROR) == normalizeIdentifierValue("ror", t.ROR)
+}
+
+// IsSame checks if two identifiers are the same. The scheme is case
+// insensitive, values are compared as appropriate for the scheme, e.g.
+// DOI are case insensitive.
+func (id *Identifier) IsSame(t *Identifier) bool {
+	if same, done := samePtr(id, t); done {
+		return same
+	}
+	return sameFold(id.Scheme, t.Scheme) &&
+		normalizeIdentifierValue(id.Scheme, id.Identifier) == normalizeIdentifierValue(t.Scheme, t.Identifier) &&
+		normalizeIdentifierValue(id.Scheme, id.ID) == normalizeIdentifierValue(t.Scheme, t.ID) &&
+		sameString(id.Name, t.Name) &&
+		sameString(id.Title, t.Title) &&
+		sameString(id.Number, t.Number) &&
+		id.RelationType.IsSame(t.RelationType) &&
+		id.ResourceType.IsSame(t.ResourceType)
+}
+
+// IsSame checks if two types are the same.
+func (td *Type) IsSame(t *Type) bool {
+	if same, done := samePtr(td, t); done {
+		return same
+	}
+	return sameFold(td.ID, t.ID) && sameString(td.Name, t.Name) && sameStringMap(td.Title, t.Title)
+}
+
+// IsSame checks if two type details are the same.
+func (td *TypeDetail) IsSame(t *TypeDetail) bool {
+	if same, done := samePtr(td, t); done {
+		return same
+	}
+	return sameFold(td.ID, t.ID) && sameString(td.Name, t.Name) && sameJSON(td.Title, t.Title)
+}
+
+// IsSame checks if two title details are the same.
+func (td *TitleDetail) IsSame(t *TitleDetail) bool {
+	if same, done := samePtr(td, t); done {
+		return same
+	}
+	return sameString(td.Title, t.Title) && sameString(td.Encoding, t.Encoding) && td.Type.IsSame(t.Type) && td.Lang.IsSame(t.Lang)
+}
+
+// IsSame checks if two descriptions are the same.
+func (d *Description) IsSame(t *Description) bool {
+	if same, done := samePtr(d, t); done {
+		return same
+	}
+	return sameString(d.Description, t.Description) && d.Type.IsSame(t.Type) && d.Lang.IsSame(t.Lang)
+}
+
+// IsSame checks if two rights are the same.
+func (r *Right) IsSame(t *Right) bool {
+	if same, done := samePtr(r, t); done {
+		return same
+	}
+	return sameFold(r.ID, t.ID) && sameStringMap(r.Title, t.Title) && sameStringMap(r.Description, t.Description) && sameString(r.Link, t.Link)
+}
+
+// IsSame checks if two subjects are the same.
+func (s *Subject) IsSame(t *Subject) bool {
+	if same, done := samePtr(s, t); done {
+		return same
+	}
+	return sameString(s.Subject, t.Subject) && sameString(s.ID, t.ID)
+}
+
+// IsSame checks if two dates are the same.
+func (d *DateType) IsSame(t *DateType) bool {
+	if same, done := samePtr(d, t); done {
+		return same
+	}
+	return sameString(d.Date, t.Date) && d.Type.IsSame(t.Type) && sameString(d.Description, t.Description)
+}
+
+// IsSame checks if two funders are the same, the order of references
+// is not significant.
+func (f *Funder) IsSame(t *Funder) bool {
+	if same, done := samePtr(f, t); done {
+		return same
+	}
+	return f.Funder.IsSame(t.Funder) && f.Award.IsSame(t.Award) && sameSet(f.Reference, t.Reference, (*Identifier).IsSame)
+}
+
+// IsSame checks if two funder identifiers are the same.
+func (fi *FunderIdentifier) IsSame(t *FunderIdentifier) bool {
+	if same, done := samePtr(fi, t); done {
+		return same
+	}
+	return sameFold(fi.Scheme, t.Scheme) &&
+		sameString(fi.Name, t.Name) &&
+		sameString(fi.Title, t.Title) &&
+		sameString(fi.Number, t.Number) &&
+		normalizeIdentifierValue(fi.Scheme, fi.Identifier) == normalizeIdentifierValue(t.Scheme, t.Identifier) &&
+		fi.RelationType.IsSame(t.RelationType) &&
+		fi.ResourceType.IsSame(t.ResourceType)
+}
+
+// IsSame checks if two award identifiers are the same.
+func (ai *AwardIdentifier) IsSame(t *AwardIdentifier) bool {
+	if same, done := samePtr(ai, t); done {
+		return same
+	}
+	return sameFold(ai.Scheme, t.Scheme) &&
+		sameString(ai.Name, t.Name) &&
+		ai.Title.IsSame(t.Title) &&
+		sameString(ai.Number, t.Number) &&
+		normalizeIdentifierValue(ai.Scheme, ai.Identifier) == normalizeIdentifierValue(t.Scheme, t.Identifier) &&
+		ai.RelationType.IsSame(t.RelationType) &&
+		ai.ResourceType.IsSame(t.ResourceType)
+}
+
+// IsSame checks if two entries are the same.
+func (e *Entry) IsSame(t *Entry) bool {
+	if same, done := samePtr(e, t); done {
+		return same
+	}
+	return sameJSON(e, t)
+}
+
+// IsSame checks if two persistent identifiers are the same. The
+// identifiers are compared in their canonical form, e.g. a DOI with or
+// without its resolver, as they are when comparing records.
+func (pid *PersistentIdentifier) IsSame(t *PersistentIdentifier) bool {
+	if same, done := samePtr(pid, t); done {
+		return same
+	}
+	scheme := pidScheme(pid.Identifier)
+	return normalizeIdentifierValue(scheme, pid.Identifier) == normalizeIdentifierValue(scheme, t.Identifier) &&
+		sameString(pid.Provider, t.Provider) && sameString(pid.Client, t.Client)
+}
+
+// pidScheme infers the scheme of a persistent identifier, it has none
+// of its own, so values written in different forms compare as the same,
+// e.g. "https://doi.org/10.1/ABC" and "10.1/abc".
+func pidScheme(value string) string {
+	if matches := DetectIdentifierSchemes(strings.TrimSpace(value)); len(matches) > 0 && matches[0].Confidence == HighConfidence {
+		return matches[0].Scheme
+	}
+	return ""
+}
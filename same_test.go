@@ -0,0 +1,167 @@
+package simplified
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestIsSame(t *testing.T) {
+	a, b := testRecord(t), testRecord(t)
+	if !a.IsSame(b) {
+		t.Fatalf("expected records to be the same")
+	}
+
+	// Noise changes should not count.
+	b.Metadata.Title = "  Microbially induced\nprecipitation of   silica "
+	b.Metadata.Subjects = append([]*Subject{{Subject: "Geology"}}, b.Metadata.Subjects...)
+	a.Metadata.Subjects = append(a.Metadata.Subjects, &Subject{Subject: "Geology"})
+	b.Metadata.Identifiers = []*Identifier{{Scheme: "ISSN", Identifier: " 1091-6490 "}}
+	a.Metadata.Contributors[0].Affiliations = []*Affiliation{}
+	b.Metadata.Languages = nil
+	a.Metadata.Languages = []map[string]interface{}{}
+	a.Metadata.RelatedIdentifiers = append(a.Metadata.RelatedIdentifiers, &Identifier{Scheme: "doi", Identifier: "10.1073/PNAS.2302156120"})
+	b.Metadata.RelatedIdentifiers = append([]*Identifier{{Scheme: "doi", Identifier: "https://doi.org/10.1073/pnas.2302156120"}}, b.Metadata.RelatedIdentifiers...)
+	if !a.IsSame(b) {
+		t.Errorf("expected records to be the same ignoring whitespace, case, order and empty lists")
+	}
+	o, n := a.Diff(b)
+	if o.Metadata != nil || n.Metadata != nil {
+		src, _ := json.MarshalIndent([]*Record{o, n}, "", "    ")
+		t.Errorf("expected no metadata differences, got %s", src)
+	}
+
+	// Creator order is significant
+	a.Metadata.Creators = makeCreators(2)
+	b.Metadata.Creators = []*Creator{a.Metadata.Creators[1], a.Metadata.Creators[0]}
+	if a.IsSame(b) {
+		t.Errorf("expected creator order to be significant")
+	}
+	b.Metadata.Creators = makeCreators(2)
+	b.Metadata.Creators[1].Affiliations[0].Name = "Princeton"
+	if a.Metadata.IsSame(b.Metadata) {
+		t.Errorf("expected a changed affiliation to be different")
+	}
+}
+
+func TestIsSameEmpty(t *testing.T) {
+	a, b := testRecord(t), testRecord(t)
+	a.CustomFields, b.CustomFields = nil, map[string]interface{}{}
+	a.Metadata.ResourceType, b.Metadata.ResourceType = nil, map[string]interface{}{}
+	a.Links, b.Links = nil, map[string]interface{}{"self": map[string]interface{}{}}
+	a.Files.Entries = nil
+	b.Files.Entries = map[string]*Entry{}
+	if !a.IsSame(b) || !b.IsSame(a) {
+		t.Errorf("expected nil and empty maps to be the same")
+	}
+	if o, n := a.Diff(b); o.CustomFields != nil || n.CustomFields != nil || o.Metadata != nil || n.Metadata != nil {
+		t.Errorf("expected no differences, got %s and %s", o.ToString(), n.ToString())
+	}
+	b.CustomFields["caltech:groups"] = []interface{}{"GALCIT"}
+	if a.IsSame(b) {
+		t.Errorf("expected a custom field to be a difference")
+	}
+}
+
+func TestRecordLinks(t *testing.T) {
+	src := []byte(`{"id": "abc-123", "links": {"self": "https://example.edu/api/records/abc-123", "self_html": "https://example.edu/records/abc-123"}}`)
+	a, b := new(Record), new(Record)
+	if err := json.Unmarshal(src, &a); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(src, &b); err != nil {
+		t.Fatal(err)
+	}
+	if a.Links["self_html"] != "https://example.edu/records/abc-123" {
+		t.Errorf("expected the links to be read, got %v", a.Links)
+	}
+	if !a.IsSame(b) {
+		t.Errorf("expected records with the same links to be the same")
+	}
+	b.Links["self_html"] = "https://example.edu/records/def-456"
+	if a.IsSame(b) {
+		t.Errorf("expected a changed link to be different")
+	}
+}
+
+func TestIsSameStructs(t *testing.T) {
+	var nilCreator *Creator
+	if !nilCreator.IsSame(nil) {
+		t.Errorf("expected nil creators to be the same")
+	}
+	if nilCreator.IsSame(&Creator{}) {
+		t.Errorf("expected nil and non-nil creator to differ")
+	}
+	p1 := &PersonOrOrg{Type: "personal", FamilyName: "Doe", GivenName: "Jane", Identifiers: []*Identifier{
+		{Scheme: "orcid", Identifier: "0000-0002-1825-009x"},
+		{Scheme: "clpid", Identifier: "Doe-J"},
+	}}
+	p2 := &PersonOrOrg{Type: "personal", FamilyName: "Doe ", GivenName: "Jane", Identifiers: []*Identifier{
+		{Scheme: "clpid", Identifier: "Doe-J"},
+		{Scheme: "ORCID", Identifier: "0000-0002-1825-009X"},
+	}}
+	if !p1.IsSame(p2) {
+		t.Errorf("expected PersonOrOrg to be the same")
+	}
+	if (&Identifier{Scheme: "clpid", Identifier: "Doe-J"}).IsSame(&Identifier{Scheme: "clpid", Identifier: "doe-j"}) {
+		t.Errorf("expected clpid to be case sensitive")
+	}
+	f1 := &Funder{Funder: &FunderIdentifier{Identifier: "021nxhr62", Name: "National Science Foundation"}, Award: &AwardIdentifier{Number: "OCE-1634002"}}
+	f2 := &Funder{Funder: &FunderIdentifier{Identifier: "021nxhr62", Name: "National Science  Foundation"}, Award: &AwardIdentifier{Number: "OCE-1634002"}}
+	if !f1.IsSame(f2) {
+		t.Errorf("expected funders to be the same")
+	}
+	f2.Award.Number = "OCE-1634003"
+	if f1.IsSame(f2) {
+		t.Errorf("expected funders with different awards to differ")
+	}
+	r1 := &Right{ID: "cc-by-4.0", Title: map[string]string{"en": "CC BY 4.0"}}
+	r2 := &Right{ID: "CC-BY-4.0", Title: map[string]string{"en": "CC BY 4.0", "de": ""}}
+	if !r1.IsSame(r2) {
+		t.Errorf("expected rights to be the same")
+	}
+	d1 := &DateType{Date: "2023-12-01", Type: &Type{ID: "accepted"}}
+	d2 := &DateType{Date: "2023-12-01", Type: &Type{ID: "issued"}}
+	if d1.IsSame(d2) {
+		t.Errorf("expected dates with different types to differ")
+	}
+}
+
+func TestIdentifierIsSameCase(t *testing.T) {
+	for _, test := range []struct {
+		scheme, a, b string
+		same         bool
+	}{
+		{"url", "HTTPS://Example.EDU/Paper.PDF", "https://example.edu/Paper.PDF", true},
+		{"url", "https://example.edu/Paper.PDF", "https://example.edu/paper.pdf", false},
+		{"handle", "HDL:2027/MDP.39015078707397", "2027/MDP.39015078707397", true},
+		{"handle", "2027/mdp.39015078707397", "2027/MDP.39015078707397", false},
+		{"arxiv", "arXiv:hep-th/9901001", "arXiv:HEP-TH/9901001", false},
+		{"doi", "10.1000/ABC", "https://doi.org/10.1000/abc", true},
+	} {
+		a, b := &Identifier{Scheme: test.scheme, Identifier: test.a}, &Identifier{Scheme: test.scheme, Identifier: test.b}
+		if a.IsSame(b) != test.same {
+			t.Errorf("%s %q and %q, expected same %t", test.scheme, test.a, test.b, test.same)
+		}
+	}
+}
+
+func TestPersistentIdentifierIsSame(t *testing.T) {
+	a := &PersistentIdentifier{Identifier: "https://doi.org/10.1073/PNAS.2302156120", Provider: "external"}
+	b := &PersistentIdentifier{Identifier: "10.1073/pnas.2302156120", Provider: "external"}
+	if !a.IsSame(b) || !b.IsSame(a) {
+		t.Errorf("expected the DOIs to be the same")
+	}
+	// Comparing records gives the same answer as comparing the identifiers.
+	if !samePIDs(map[string]*PersistentIdentifier{"doi": a}, map[string]*PersistentIdentifier{"doi": b}) {
+		t.Errorf("expected the records' DOIs to be the same")
+	}
+	c := &PersistentIdentifier{Identifier: "oai:authors.library.caltech.edu:rd9fg-k5282", Provider: "oai"}
+	d := &PersistentIdentifier{Identifier: "oai:authors.library.caltech.edu:rd9fg-k5283", Provider: "oai"}
+	if c.IsSame(d) || samePIDs(map[string]*PersistentIdentifier{"oai": c}, map[string]*PersistentIdentifier{"oai": d}) {
+		t.Errorf("expected the OAI identifiers to differ")
+	}
+	b.Provider = "datacite"
+	if a.IsSame(b) {
+		t.Errorf("expected a different provider to differ")
+	}
+}
@@ -30,7 +30,6 @@ package simplified
 import (
 	"encoding/json"
 	"fmt"
-	"reflect"
 	"strings"
 	"time"
 )
@@ -80,6 +79,9 @@ type Record struct {
 	//Created string `json:"created,omitempty"`
 	//Updated string `json:"updated,omitempty"`
 	Versions *RecordVersions `json:"versions,omitempty"`
+	// Links returned by the InvenioRDM REST API, e.g. "self_html" is the
+	// landing page of the record.
+	Links map[string]interface{} `json:"links,omitempty"`
 }
 
 //
@@ -416,7 +418,11 @@ func (rec *Record) ToString() []byte {
 
 // Diff takes a new Metadata struct and compares it with
 // and existing Metadata struct. It rturns two Metadata
-// structs with only the different attributes sets.
+// structs with only the different attributes sets. For list
+// attributes like Creators only the elements which changed are
+// included. Use Changes for a structured description of the changes.
+// Attributes are compared with IsSame so whitespace, identifier case
+// and the order of unordered lists (e.g. subjects) are ignored.
 //
 // ```
 //
@@ -451,70 +457,53 @@ func (m *Metadata) Diff(t *Metadata) (*Metadata, *Metadata) {
 		return m, t
 	}
 	oM, nM := new(Metadata), new(Metadata)
-	if !reflect.DeepEqual(m.ResourceType, t.ResourceType) {
+	// List fields only hold the elements which were inserted, removed,
+	// moved or modified, see Changes.
+	changes := m.Changes(t)
+	if !sameJSON(m.ResourceType, t.ResourceType) {
 		oM.ResourceType = m.ResourceType
 		nM.ResourceType = t.ResourceType
 	}
-	if !reflect.DeepEqual(m.Creators, t.Creators) {
-		oM.Creators = m.Creators
-		nM.Creators = t.Creators
-	}
-	if strings.Compare(m.Title, t.Title) != 0 {
+	oM.Creators, nM.Creators = changedElements(changes.Field("creators"), m.Creators, t.Creators)
+	if !sameString(m.Title, t.Title) {
 		oM.Title = m.Title
 		nM.Title = t.Title
 	}
-	if strings.Compare(m.PublicationDate, t.PublicationDate) != 0 {
+	if !sameString(m.PublicationDate, t.PublicationDate) {
 		oM.PublicationDate = m.PublicationDate
 		nM.PublicationDate = t.PublicationDate
 	}
-	if !reflect.DeepEqual(m.AdditionalTitles, t.AdditionalTitles) {
+	if !sameList(m.AdditionalTitles, t.AdditionalTitles, (*TitleDetail).IsSame) {
 		oM.AdditionalTitles = m.AdditionalTitles
 		nM.AdditionalTitles = t.AdditionalTitles
 	}
-	if strings.Compare(strings.TrimSpace(m.Description), strings.TrimSpace(t.Description)) != 0 {
+	if !sameString(m.Description, t.Description) {
 		oM.Description = m.Description
 		nM.Description = t.Description
 	}
-	if !reflect.DeepEqual(m.AdditionalDescriptions, t.AdditionalDescriptions) {
+	if !sameList(m.AdditionalDescriptions, t.AdditionalDescriptions, (*Description).IsSame) {
 		oM.AdditionalDescriptions = m.AdditionalDescriptions
 		nM.AdditionalDescriptions = t.AdditionalDescriptions
 	}
-	if !reflect.DeepEqual(m.Rights, t.Rights) {
-		oM.Rights = m.Rights
-		nM.Rights = t.Rights
-	}
-	if !reflect.DeepEqual(m.Contributors, t.Contributors) {
-		oM.Contributors = m.Contributors
-		nM.Contributors = t.Contributors
-	}
-	if !reflect.DeepEqual(m.Subjects, t.Subjects) {
-		oM.Subjects = m.Subjects
-		nM.Subjects = t.Subjects
-	}
-	if !reflect.DeepEqual(m.Languages, t.Languages) {
+	oM.Rights, nM.Rights = changedElements(changes.Field("rights"), m.Rights, t.Rights)
+	oM.Contributors, nM.Contributors = changedElements(changes.Field("contributors"), m.Contributors, t.Contributors)
+	oM.Subjects, nM.Subjects = changedElements(changes.Field("subjects"), m.Subjects, t.Subjects)
+	if len(changes.Field("languages")) > 0 {
 		oM.Languages = m.Languages
 		nM.Languages = t.Languages
 	}
-	if !reflect.DeepEqual(m.Dates, t.Dates) {
-		oM.Dates = m.Dates
-		nM.Dates = t.Dates
-	}
-	if strings.Compare(m.Version, t.Version) != 0 {
+	oM.Dates, nM.Dates = changedElements(changes.Field("dates"), m.Dates, t.Dates)
+	if !sameString(m.Version, t.Version) {
 		oM.Version = m.Version
 		nM.Version = t.Version
 	}
-	if strings.Compare(m.Publisher, t.Publisher) != 0 {
+	if !sameString(m.Publisher, t.Publisher) {
 		oM.Publisher = m.Publisher
 		nM.Publisher = t.Publisher
 	}
-	if !reflect.DeepEqual(m.Identifiers, t.Identifiers) {
-		oM.Identifiers = m.Identifiers
-		nM.Identifiers = t.Identifiers
-	}
-	if !reflect.DeepEqual(m.Funding, t.Funding) {
-		oM.Funding = m.Funding
-		nM.Funding = t.Funding
-	}
+	oM.Identifiers, nM.Identifiers = changedElements(changes.Field("identifiers"), m.Identifiers, t.Identifiers)
+	oM.RelatedIdentifiers, nM.RelatedIdentifiers = changedElements(changes.Field("related_identifiers"), m.RelatedIdentifiers, t.RelatedIdentifiers)
+	oM.Funding, nM.Funding = changedElements(changes.Field("funding"), m.Funding, t.Funding)
 	return oM, nM
 }
 
@@ -595,40 +584,40 @@ func (rec *Record) Diff(t *Record) (*Record, *Record) {
 		oR.ID = rec.ID
 		nR.ID = t.ID
 	}
-	if !reflect.DeepEqual(rec.Parent, t.Parent) {
+	if !sameJSON(rec.Parent, t.Parent) {
 		oR.Parent = rec.Parent
 		nR.Parent = t.Parent
 	}
-	if !reflect.DeepEqual(rec.ExternalPIDs, t.ExternalPIDs) {
+	if !samePIDs(rec.ExternalPIDs, t.ExternalPIDs) {
 		oR.ExternalPIDs = rec.ExternalPIDs
 		nR.ExternalPIDs = t.ExternalPIDs
 	}
-	if !reflect.DeepEqual(rec.RecordAccess, t.RecordAccess) {
+	if !sameJSON(rec.RecordAccess, t.RecordAccess) {
 		oR.RecordAccess = rec.RecordAccess
 		nR.RecordAccess = t.RecordAccess
 	}
-	if !reflect.DeepEqual(rec.Metadata, t.Metadata) {
+	if !rec.Metadata.IsSame(t.Metadata) {
 		oR.Metadata, nR.Metadata = rec.Metadata.Diff(t.Metadata)
 	}
-	if !reflect.DeepEqual(rec.Files, t.Files) {
+	if !sameJSON(rec.Files, t.Files) {
 		oR.Files = rec.Files
 		nR.Files = t.Files
 	}
 	// NOTE: The simplified Record contains the RDM CustomFields
 	// map. This needs to be diffed with a map comparison function.
-	if !reflect.DeepEqual(rec.CustomFields, t.CustomFields) {
+	if !sameJSON(rec.CustomFields, t.CustomFields) {
 		oR.CustomFields = rec.CustomFields
 		nR.CustomFields = t.CustomFields
 	}
-	if !reflect.DeepEqual(rec.Tombstone, t.Tombstone) {
+	if !sameJSON(rec.Tombstone, t.Tombstone) {
 		oR.Tombstone = rec.Tombstone
 		nR.Tombstone = t.Tombstone
 	}
-	if rec.Created.Compare(t.Created) != 0 {
+	if !rec.Created.Equal(t.Created) {
 		oR.Created = rec.Created
 		nR.Created = t.Created
 	}
-	if rec.Updated.Compare(t.Updated) != 0 {
+	if !rec.Updated.Equal(t.Updated) {
 		oR.Updated = rec.Updated
 		nR.Updated = t.Updated
 	}
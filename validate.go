@@ -0,0 +1,304 @@
+package simplified
+
+/**
+ * This file implements validation of a simplified Record against
+ * the rules InvenioRDM applies when a record is deposited.
+ *
+ * See https://inveniordm.docs.cern.ch/reference/metadata/
+ */
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ViolationKind identifies the type of rule a Violation reports.
+type ViolationKind string
+
+const (
+	// Required indicates a required element is missing or empty.
+	Required ViolationKind = "required"
+	// InvalidValue indicates a value outside the allowed vocabulary.
+	InvalidValue ViolationKind = "invalid_value"
+	// InvalidDate indicates a date which is not a valid EDTF or ISO 8601 date.
+	InvalidDate ViolationKind = "invalid_date"
+	// InvalidIdentifier indicates an identifier which is malformed or
+	// whose check digit does not match its scheme.
+	InvalidIdentifier ViolationKind = "invalid_identifier"
+)
+
+// Violation describes a single validation rule failure. Path is a
+// JSON pointer (RFC 6901) into the JSON representation of the Record.
+type Violation struct {
+	Path    string        `json:"path"`
+	Kind    ViolationKind `json:"kind"`
+	Message string        `json:"message"`
+}
+
+// Error implements the error interface for a Violation.
+func (v *Violation) Error() string {
+	return fmt.Sprintf("%s: %s", v.Path, v.Message)
+}
+
+// ValidationError holds the violations found validating a Record.
+type ValidationError []*Violation
+
+// Error implements the error interface for ValidationError.
+func (ve ValidationError) Error() string {
+	msgs := make([]string, 0, len(ve))
+	for _, v := range ve {
+		msgs = append(msgs, v.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// jsonPointerEscape escapes a reference token for use in a JSON pointer
+// per RFC 6901.
+func jsonPointerEscape(s string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(s, "~", "~0"), "/", "~1")
+}
+
+// jsonPointer joins reference tokens into a JSON pointer.
+func jsonPointer(tokens ...interface{}) string {
+	var sb strings.Builder
+	for _, token := range tokens {
+		sb.WriteString("/")
+		switch t := token.(type) {
+		case string:
+			sb.WriteString(jsonPointerEscape(t))
+		default:
+			sb.WriteString(fmt.Sprintf("%v", t))
+		}
+	}
+	return sb.String()
+}
+
+// validator accumulates violations as a record is checked.
+type validator struct {
+	violations ValidationError
+}
+
+func (v *validator) add(kind ViolationKind, path string, msg string, args ...interface{}) {
+	v.violations = append(v.violations, &Violation{
+		Path:    path,
+		Kind:    kind,
+		Message: fmt.Sprintf(msg, args...),
+	})
+}
+
+// Validate checks the record against the InvenioRDM metadata rules.
+// It returns nil if the record is valid otherwise a ValidationError
+// listing each violation found.
+//
+// ```
+//
+//	if err := rec.Validate(); err != nil {
+//	    if violations, ok := err.(simplified.ValidationError); ok {
+//	        for _, v := range violations {
+//	            fmt.Printf("%s (%s) %s\n", v.Path, v.Kind, v.Message)
+//	        }
+//	    }
+//	}
+//
+// ```
+func (rec *Record) Validate() error {
+	v := new(validator)
+	if rec == nil {
+		v.add(Required, "", "record is empty")
+		return v.violations
+	}
+	if rec.Metadata == nil {
+		v.add(Required, jsonPointer("metadata"), "metadata is required")
+	} else {
+		rec.Metadata.validate(v)
+	}
+	if rec.RecordAccess != nil {
+		rec.RecordAccess.validate(v)
+	}
+	if errs, ok := rec.ValidateIdentifiers().(IdentifierErrors); ok {
+		for _, e := range errs {
+			v.add(InvalidIdentifier, e.Path, "%s %q, %s", e.Scheme, e.Value, e.Err)
+		}
+	}
+	if len(v.violations) > 0 {
+		return v.violations
+	}
+	return nil
+}
+
+func (m *Metadata) validate(v *validator) {
+	if strings.TrimSpace(m.Title) == "" {
+		v.add(Required, jsonPointer("metadata", "title"), "title is required")
+	}
+	if m.ResourceType == nil {
+		v.add(Required, jsonPointer("metadata", "resource_type"), "resource type is required")
+	} else if id, ok := m.ResourceType["id"].(string); !ok || strings.TrimSpace(id) == "" {
+		v.add(Required, jsonPointer("metadata", "resource_type", "id"), "resource type id is required")
+	}
+	if len(m.Creators) == 0 {
+		v.add(Required, jsonPointer("metadata", "creators"), "at least one creator is required")
+	}
+	for i, creator := range m.Creators {
+		creator.validate(v, jsonPointer("metadata", "creators", i))
+	}
+	for i, contributor := range m.Contributors {
+		contributor.validate(v, jsonPointer("metadata", "contributors", i))
+	}
+	if strings.TrimSpace(m.PublicationDate) == "" {
+		v.add(Required, jsonPointer("metadata", "publication_date"), "publication date is required")
+	} else if !IsEDTF(m.PublicationDate) {
+		v.add(InvalidDate, jsonPointer("metadata", "publication_date"), "%q is not a valid EDTF date", m.PublicationDate)
+	}
+	for i, dt := range m.Dates {
+		if dt == nil {
+			continue
+		}
+		if !IsEDTF(dt.Date) {
+			v.add(InvalidDate, jsonPointer("metadata", "dates", i, "date"), "%q is not a valid EDTF date", dt.Date)
+		}
+	}
+}
+
+func (c *Creator) validate(v *validator, path string) {
+	if c == nil || c.PersonOrOrg == nil {
+		v.add(Required, path+jsonPointer("person_or_org"), "person or organization is required")
+		return
+	}
+	p := c.PersonOrOrg
+	path = path + jsonPointer("person_or_org")
+	switch p.Type {
+	case "personal":
+		if strings.TrimSpace(p.FamilyName) == "" {
+			v.add(Required, path+jsonPointer("family_name"), "family name is required for a personal name")
+		}
+	case "organizational":
+		if strings.TrimSpace(p.Name) == "" {
+			v.add(Required, path+jsonPointer("name"), "name is required for an organizational name")
+		}
+	case "":
+		v.add(Required, path+jsonPointer("type"), "type is required, either \"personal\" or \"organizational\"")
+	default:
+		v.add(InvalidValue, path+jsonPointer("type"), "%q is not a valid type, expected \"personal\" or \"organizational\"", p.Type)
+	}
+}
+
+func (ra *RecordAccess) validate(v *validator) {
+	if ra.Record != "" && ra.Record != "public" && ra.Record != "restricted" {
+		v.add(InvalidValue, jsonPointer("access", "record"), "%q is not a valid value, expected \"public\" or \"restricted\"", ra.Record)
+	}
+	if ra.Files != "" && ra.Files != "public" && ra.Files != "restricted" {
+		v.add(InvalidValue, jsonPointer("access", "files"), "%q is not a valid value, expected \"public\" or \"restricted\"", ra.Files)
+	}
+	if ra.Embargo != nil && ra.Embargo.Active {
+		if strings.TrimSpace(ra.Embargo.Until) == "" {
+			v.add(Required, jsonPointer("access", "embargo", "until"), "until is required for an active embargo")
+		} else if !isISODate(ra.Embargo.Until) {
+			v.add(InvalidDate, jsonPointer("access", "embargo", "until"), "%q is not a valid date, expected YYYY-MM-DD", ra.Embargo.Until)
+		}
+	}
+}
+
+//
+// EDTF support
+//
+
+// IsEDTF checks if a string is a valid Extended Date Time Format
+// (EDTF) date as accepted by InvenioRDM. It supports level 0 dates
+// (YYYY, YYYY-MM, YYYY-MM-DD) and intervals (e.g. "2018/2020-09")
+// along with the level 1 qualifiers "?", "~" and "%" and open
+// ("..") or unknown (empty) interval ends.
+func IsEDTF(s string) bool {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return false
+	}
+	if strings.Contains(s, "/") {
+		parts := strings.Split(s, "/")
+		if len(parts) != 2 || (parts[0] == "" && parts[1] == "") {
+			return false
+		}
+		for _, part := range parts {
+			if part == "" || part == ".." {
+				continue
+			}
+			if !isEDTFDate(part) {
+				return false
+			}
+		}
+		return true
+	}
+	return isEDTFDate(s)
+}
+
+// isEDTFDate checks a single EDTF date, YYYY[-MM[-DD]] with an
+// optional trailing qualifier.
+func isEDTFDate(s string) bool {
+	s = strings.TrimRight(s, "?~%")
+	if s == "" {
+		return false
+	}
+	negative := strings.HasPrefix(s, "-")
+	if negative {
+		s = s[1:]
+	}
+	parts := strings.Split(s, "-")
+	if len(parts) > 3 || len(parts[0]) != 4 {
+		return false
+	}
+	values := []int{}
+	for i, part := range parts {
+		if (i > 0 && len(part) != 2) || !isDigits(part) {
+			return false
+		}
+		n, _ := strconv.Atoi(part)
+		values = append(values, n)
+	}
+	if len(values) > 1 {
+		month := values[1]
+		// Months 21 through 24 are EDTF seasons
+		if len(values) == 2 && month >= 21 && month <= 24 {
+			return true
+		}
+		if month < 1 || month > 12 {
+			return false
+		}
+	}
+	if len(values) > 2 {
+		if values[2] < 1 || values[2] > daysInMonth(values[0], values[1]) {
+			return false
+		}
+	}
+	return true
+}
+
+// isDigits checks a string is made only of ASCII digits.
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// isISODate checks for a YYYY-MM-DD date.
+func isISODate(s string) bool {
+	return len(s) == 10 && isEDTFDate(s) && !strings.ContainsAny(s, "?~%")
+}
+
+func daysInMonth(year int, month int) int {
+	switch month {
+	case 2:
+		if (year%4 == 0 && year%100 != 0) || year%400 == 0 {
+			return 29
+		}
+		return 28
+	case 4, 6, 9, 11:
+		return 30
+	}
+	return 31
+}
@@ -0,0 +1,96 @@
+package simplified
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	rec := testRecord(t)
+	if err := rec.Validate(); err != nil {
+		t.Errorf("expected a valid record, got %s", err)
+	}
+
+	rec = new(Record)
+	src := []byte(`{
+    "metadata": {
+        "title": "  ",
+        "resource_type": {"title": {"en": "Journal Article"}},
+        "publication_date": "2023-02-30",
+        "contributors": [
+            {"person_or_org": {"type": "personal", "given_name": "Jane"}},
+            {"person_or_org": {"type": "group", "name": "The Group"}},
+            {"person_or_org": {"type": "organizational"}},
+            {"role": {"id": "editor"}}
+        ],
+        "dates": [{"date": "2020/2021-13"}]
+    },
+    "access": {
+        "record": "open",
+        "files": "restricted",
+        "embargo": {"active": true}
+    }
+}`)
+	if err := json.Unmarshal(src, &rec); err != nil {
+		t.Fatal(err)
+	}
+	err := rec.Validate()
+	if err == nil {
+		t.Fatalf("expected validation errors")
+	}
+	violations, ok := err.(ValidationError)
+	if !ok {
+		t.Fatalf("expected ValidationError, got %T", err)
+	}
+	expected := map[string]ViolationKind{
+		"/metadata/title":                                    Required,
+		"/metadata/resource_type/id":                         Required,
+		"/metadata/creators":                                 Required,
+		"/metadata/publication_date":                         InvalidDate,
+		"/metadata/contributors/0/person_or_org/family_name": Required,
+		"/metadata/contributors/1/person_or_org/type":        InvalidValue,
+		"/metadata/contributors/2/person_or_org/name":        Required,
+		"/metadata/contributors/3/person_or_org":             Required,
+		"/metadata/dates/0/date":                             InvalidDate,
+		"/access/record":                                     InvalidValue,
+		"/access/embargo/until":                              Required,
+	}
+	found := map[string]ViolationKind{}
+	for _, v := range violations {
+		found[v.Path] = v.Kind
+	}
+	for path, kind := range expected {
+		if k, ok := found[path]; !ok {
+			t.Errorf("expected violation for %s", path)
+		} else if k != kind {
+			t.Errorf("expected %s to be %q, got %q", path, kind, k)
+		}
+	}
+	if len(violations) != len(expected) {
+		t.Errorf("expected %d violations, got %d -> %s", len(expected), len(violations), err)
+	}
+
+	if err := new(Record).Validate(); err == nil {
+		t.Errorf("expected an empty record to fail validation")
+	}
+}
+
+func TestIsEDTF(t *testing.T) {
+	for _, s := range []string{
+		"2023", "2023-12", "2023-12-19", "2024-02-29", "2018/2020-09",
+		"2020/..", "../2020", "/2020", "2020?", "2020-06~", "2020-21", "-0100",
+	} {
+		if !IsEDTF(s) {
+			t.Errorf("expected %q to be valid EDTF", s)
+		}
+	}
+	for _, s := range []string{
+		"", "20", "2023-2-1", "2023-00", "2023-13", "2023-02-29", "2023-04-31",
+		"2023/12/19", "/", "December 2023", "2023-12-19T10:00:00",
+		"+123", "2020-+1", "+2020", "2020-1 ", "2020-12-+1", "--2020",
+	} {
+		if IsEDTF(s) {
+			t.Errorf("expected %q to be invalid EDTF", s)
+		}
+	}
+}